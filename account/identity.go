@@ -236,6 +236,13 @@ func IdentityFilterByUsename(username string) func(db *gorm.DB) *gorm.DB {
 	}
 }
 
+// IdentityFilterByProvider is a gorm filter by the identity provider
+func IdentityFilterByProvider(provider string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("provider = ?", provider)
+	}
+}
+
 // IdentityFilterByID is a gorm filter for Idenity ID.
 func IdentityFilterByID(identityID uuid.UUID) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
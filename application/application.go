@@ -5,7 +5,9 @@ import (
 	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 )
@@ -14,6 +16,7 @@ import (
 type Application interface {
 	WorkItems() workitem.WorkItemRepository
 	WorkItemTypes() workitem.WorkItemTypeRepository
+	WorkItemRevisions() workitem.RevisionRepository
 	Trackers() TrackerRepository
 	TrackerQueries() TrackerQueryRepository
 	SearchItems() SearchRepository
@@ -22,10 +25,13 @@ type Application interface {
 	WorkItemLinkTypes() link.WorkItemLinkTypeRepository
 	WorkItemLinks() link.WorkItemLinkRepository
 	Comments() comment.Repository
+	CommentReactions() comment.ReactionRepository
 	Spaces() space.Repository
 	Iterations() iteration.Repository
 	Users() account.UserRepository
 	Areas() area.Repository
+	Webhooks() webhook.Repository
+	References() reference.Repository
 }
 
 // A Transaction abstracts a database transaction. The repositories created for the transaction object make changes inside the the transaction
@@ -35,6 +41,14 @@ type Transaction interface {
 	Rollback() error
 }
 
+// Savepoints is implemented by transactions that can undo the changes made after a savepoint while keeping
+// the earlier changes of the transaction
+type Savepoints interface {
+	Savepoint(name string) error
+	ReleaseSavepoint(name string) error
+	RollbackToSavepoint(name string) error
+}
+
 // A DB stands for a particular database (or a mock/fake thereof). It also includes "Application" for creating transactionless repositories
 type DB interface {
 	Application
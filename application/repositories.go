@@ -3,6 +3,9 @@ package application
 import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 )
 
@@ -17,14 +20,16 @@ type TrackerRepository interface {
 
 // TrackerQueryRepository encapsulate storage & retrieval of tracker queries
 type TrackerQueryRepository interface {
-	Create(ctx context.Context, query string, schedule string, tracker string) (*app.TrackerQuery, error)
+	Create(ctx context.Context, query string, schedule string, tracker string, spaceID *uuid.UUID, workItemType *string, mapping []*app.FieldMapping) (*app.TrackerQuery, error)
 	Save(ctx context.Context, tq app.TrackerQuery) (*app.TrackerQuery, error)
 	Load(ctx context.Context, ID string) (*app.TrackerQuery, error)
 	Delete(ctx context.Context, ID string) error
 	List(ctx context.Context) ([]*app.TrackerQuery, error)
+	ListRuns(ctx context.Context, ID string, limit int) ([]*app.TrackerQueryRun, error)
 }
 
 // SearchRepository encapsulates searching of woritems,users,etc
 type SearchRepository interface {
-	SearchFullText(ctx context.Context, searchStr string, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	SearchFullText(ctx context.Context, searchStr string, sort []workitem.SortKey, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	SearchFullTextPage(ctx context.Context, searchStr string, sort []workitem.SortKey, page gormsupport.Page) ([]*app.WorkItem, bool, uint64, error)
 }
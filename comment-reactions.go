@@ -0,0 +1,90 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// CommentReactionsController implements the comment-reactions resource.
+type CommentReactionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewCommentReactionsController creates a comment-reactions controller.
+func NewCommentReactionsController(service *goa.Service, db application.DB) *CommentReactionsController {
+	return &CommentReactionsController{Controller: service.NewController("CommentReactionsController"), db: db}
+}
+
+// Create runs the create action.
+func (c *CommentReactionsController) Create(ctx *app.CreateCommentReactionsContext) error {
+	currentUserID, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err := appl.CommentReactions().Add(ctx, ctx.CommentID, currentUserID, ctx.Payload.Data.Attributes.Emoji)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := reactedComment(ctx, ctx.RequestData, appl, ctx.CommentID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Delete runs the delete action.
+func (c *CommentReactionsController) Delete(ctx *app.DeleteCommentReactionsContext) error {
+	currentUserID, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		err := appl.CommentReactions().Remove(ctx, ctx.CommentID, currentUserID, ctx.Emoji)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res, err := reactedComment(ctx, ctx.RequestData, appl, ctx.CommentID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// contextIdentityID returns the ID of the identity of the current user
+func contextIdentityID(ctx context.Context) (uuid.UUID, error) {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return uuid.Nil, goa.ErrUnauthorized(err.Error())
+	}
+	currentUserID, err := uuid.FromString(currentUser)
+	if err != nil {
+		return uuid.Nil, goa.ErrUnauthorized(err.Error())
+	}
+	return currentUserID, nil
+}
+
+// reactedComment returns the given comment with its updated reactions
+func reactedComment(ctx context.Context, request *goa.RequestData, appl application.Application, id uuid.UUID) (*app.CommentSingle, error) {
+	cm, err := appl.Comments().Load(ctx, id)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	details, err := loadCommentDetails(ctx, appl, cm)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return &app.CommentSingle{
+		Data: ConvertComment(request, cm, CommentIncludeParentWorkItem(), details),
+	}, nil
+}
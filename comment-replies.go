@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/goadesign/goa"
+)
+
+// CommentRepliesController implements the comment-replies resource.
+type CommentRepliesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewCommentRepliesController creates a comment-replies controller.
+func NewCommentRepliesController(service *goa.Service, db application.DB) *CommentRepliesController {
+	return &CommentRepliesController{Controller: service.NewController("CommentRepliesController"), db: db}
+}
+
+// List runs the list action.
+func (c *CommentRepliesController) List(ctx *app.ListCommentRepliesContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		replies, err := appl.Comments().ListReplies(ctx, ctx.CommentID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		details, err := loadCommentDetails(ctx, appl, replies...)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.CommentList{
+			Data: ConvertComments(ctx.RequestData, replies, CommentIncludeParentWorkItem(), details),
+			Meta: &app.CommentListMeta{TotalCount: len(replies)},
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *CommentRepliesController) Create(ctx *app.CreateCommentRepliesContext) error {
+	currentUserID, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		replied, err := appl.Comments().Load(ctx, ctx.CommentID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		reqComment := ctx.Payload.Data
+		reply := comment.Comment{
+			ParentID:        replied.ParentID,
+			ParentCommentID: &replied.ID,
+			Body:            reqComment.Attributes.Body,
+			Markup:          rendering.NilSafeGetMarkup(reqComment.Attributes.Markup),
+			CreatedBy:       currentUserID,
+		}
+		if err := appl.Comments().Create(ctx, &reply); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		details, err := loadCommentDetails(ctx, appl, &reply)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.CommentSingle{
+			Data: ConvertComment(ctx.RequestData, &reply, CommentIncludeParentWorkItem(), details),
+		})
+	})
+}
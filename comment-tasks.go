@@ -0,0 +1,56 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/goadesign/goa"
+)
+
+// CommentTasksController implements the comment-tasks resource.
+type CommentTasksController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewCommentTasksController creates a comment-tasks controller.
+func NewCommentTasksController(service *goa.Service, db application.DB) *CommentTasksController {
+	return &CommentTasksController{Controller: service.NewController("CommentTasksController"), db: db}
+}
+
+// Toggle runs the toggle action.
+func (c *CommentTasksController) Toggle(ctx *app.ToggleCommentTasksContext) error {
+	identity, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		cm, err := appl.Comments().Load(ctx.Context, ctx.CommentID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if identity != cm.CreatedBy.String() {
+			// need to use the goa.NewErrorClass() func as there is no native support for 403 in goa
+			// and it is not planned to be supported yet: https://github.com/goadesign/goa/pull/1030
+			return jsonapi.JSONErrorResponse(ctx, goa.NewErrorClass("forbidden", 403)("User is not the comment author"))
+		}
+		cm.Body, err = rendering.ToggleTask(cm.Body, cm.Markup, ctx.Index, ctx.Payload.Data.Attributes.Checked)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		cm.Version = ctx.Payload.Data.Attributes.Version
+		cm, err = appl.Comments().Save(ctx.Context, cm)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		details, err := loadCommentDetails(ctx, appl, cm)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.CommentSingle{
+			Data: ConvertComment(ctx.RequestData, cm, CommentIncludeParentWorkItem(), details),
+		})
+	})
+}
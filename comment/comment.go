@@ -1,14 +1,18 @@
 package comment
 
 import (
+	"strconv"
 	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/eventstream"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
@@ -23,6 +27,14 @@ type Comment struct {
 	CreatedBy uuid.UUID `sql:"type:uuid"` // Belongs To Identity
 	Body      string
 	Markup    string
+	// Version is incremented by each update
+	Version int
+	// ParentCommentID is the first comment of the thread a reply belongs to, nil for the first comments
+	// of threads. Threads are not nested: the replies to a reply join the thread of the replied comment.
+	ParentCommentID *uuid.UUID `sql:"type:uuid"`
+	// RemoteAuthor is the name of the author on the remote tracker the comment was imported from, empty for
+	// local comments
+	RemoteAuthor string
 }
 
 // Repository describes interactions with comments
@@ -31,6 +43,8 @@ type Repository interface {
 	Save(ctx context.Context, comment *Comment) (*Comment, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, parent string, start *int, limit *int) ([]*Comment, uint64, error)
+	ListPage(ctx context.Context, parent string, page gormsupport.Page) ([]*Comment, bool, uint64, error)
+	ListReplies(ctx context.Context, id uuid.UUID) ([]*Comment, error)
 	Load(ctx context.Context, id uuid.UUID) (*Comment, error)
 	Count(ctx context.Context, parent string) (int, error)
 }
@@ -51,9 +65,17 @@ func (m *GormCommentRepository) TableName() string {
 	return "comments"
 }
 
-// Create creates a new record.
+// Create creates a new record. A reply must have the same parent as the comment it replies to.
+// returns BadParameterError or InternalError
 func (m *GormCommentRepository) Create(ctx context.Context, comment *Comment) error {
 	defer goa.MeasureSince([]string{"goa", "db", "comment", "create"}, time.Now())
+	if comment.ParentCommentID != nil {
+		root, err := m.threadRoot(ctx, comment.ParentID, *comment.ParentCommentID)
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		comment.ParentCommentID = &root
+	}
 	comment.ID = uuid.NewV4()
 	// make sure no comment is created with an empty 'markup' value
 	if comment.Markup == "" {
@@ -66,6 +88,12 @@ func (m *GormCommentRepository) Create(ctx context.Context, comment *Comment) er
 		}, "unable to create the comment")
 		return errs.WithStack(err)
 	}
+	if err := m.updateReferences(ctx, *comment); err != nil {
+		return errs.WithStack(err)
+	}
+	if err := m.emit(ctx, webhook.EventCommentCreated, *comment); err != nil {
+		return errs.WithStack(err)
+	}
 
 	log.Debug(ctx, map[string]interface{}{
 		"pkg":       "comment",
@@ -75,7 +103,8 @@ func (m *GormCommentRepository) Create(ctx context.Context, comment *Comment) er
 	return nil
 }
 
-// Save a single comment
+// Save a single comment. The version must be the one of the stored comment, it is incremented.
+// returns NotFoundError, VersionConflictError or InternalError
 func (m *GormCommentRepository) Save(ctx context.Context, comment *Comment) (*Comment, error) {
 	c := Comment{}
 	tx := m.db.Where("id=?", comment.ID).First(&c)
@@ -94,11 +123,18 @@ func (m *GormCommentRepository) Save(ctx context.Context, comment *Comment) (*Co
 
 		return nil, errors.NewInternalError(err.Error())
 	}
+	if comment.Version != c.Version {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
 	// make sure no comment is created with an empty 'markup' value
 	if comment.Markup == "" {
 		comment.Markup = rendering.SystemMarkupDefault
 	}
-	tx = tx.Save(comment)
+	tx = m.db.Model(&c).Where("version = ?", c.Version).Updates(map[string]interface{}{
+		"body":    comment.Body,
+		"markup":  comment.Markup,
+		"version": c.Version + 1,
+	})
 	if err := tx.Error; err != nil {
 		log.Error(ctx, map[string]interface{}{
 			"commentID": comment.ID,
@@ -107,6 +143,17 @@ func (m *GormCommentRepository) Save(ctx context.Context, comment *Comment) (*Co
 
 		return nil, errors.NewInternalError(err.Error())
 	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	comment.Version = c.Version
+	comment.UpdatedAt = c.UpdatedAt
+	if err := m.updateReferences(ctx, *comment); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := m.emit(ctx, webhook.EventCommentUpdated, *comment); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
 
 	log.Debug(ctx, map[string]interface{}{
 		"pkg":       "comment",
@@ -116,26 +163,112 @@ func (m *GormCommentRepository) Save(ctx context.Context, comment *Comment) (*Co
 	return comment, nil
 }
 
-// Delete a single comment
+// threadRoot returns the first comment of the thread a reply to the given comment joins
+// returns BadParameterError or InternalError
+func (m *GormCommentRepository) threadRoot(ctx context.Context, parent string, repliedID uuid.UUID) (uuid.UUID, error) {
+	replied := Comment{}
+	tx := m.db.Where("id = ?", repliedID).First(&replied)
+	if tx.RecordNotFound() {
+		return uuid.Nil, errors.NewBadParameterError("parent comment", repliedID.String())
+	}
+	if err := tx.Error; err != nil {
+		return uuid.Nil, errors.NewInternalError(err.Error())
+	}
+	if replied.ParentID != parent {
+		// replies stay on the commented item
+		return uuid.Nil, errors.NewBadParameterError("parent comment", repliedID.String())
+	}
+	if replied.ParentCommentID != nil {
+		return *replied.ParentCommentID, nil
+	}
+	return replied.ID, nil
+}
+
+// Delete a single comment, deleting the first comment of a thread deletes the replies as well. Comments are
+// soft deleted, the cascade of the parent comment key only applies when they are purged.
 func (m *GormCommentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	if id == uuid.Nil {
 		return errors.NewNotFoundError("comment", id.String())
 	}
-	tx := m.db.Delete(&Comment{ID: id})
+	c := Comment{}
+	tx := m.db.Where("id=?", id).First(&c)
+	if tx.RecordNotFound() {
+		return errors.NewNotFoundError("comment", id.String())
+	}
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	replies := []*Comment{}
+	if err := m.db.Where("parent_comment_id = ?", id).Find(&replies).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	// replies have no replies, they all go in one statement
+	if err := m.db.Where("parent_comment_id = ?", id).Delete(&Comment{}).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	for _, reply := range replies {
+		if err := reference.NewRepository(m.db).Delete(ctx, reference.Source{Type: reference.SourceComment, ID: reply.ID.String()}); err != nil {
+			return errs.WithStack(err)
+		}
+		if err := m.emit(ctx, webhook.EventCommentDeleted, *reply); err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	tx = m.db.Delete(&Comment{ID: id})
 	if tx.RowsAffected == 0 {
 		return errors.NewNotFoundError("comment", id.String())
 	}
 	if err := tx.Error; err != nil {
 		return errors.NewInternalError(err.Error())
 	}
+	if err := reference.NewRepository(m.db).Delete(ctx, reference.Source{Type: reference.SourceComment, ID: id.String()}); err != nil {
+		return errs.WithStack(err)
+	}
+	if err := m.emit(ctx, webhook.EventCommentDeleted, c); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
 	return nil
 }
 
-// List all comments related to a single item
+// updateReferences records the mentions and work item references of the given comment of a work item
+func (m *GormCommentRepository) updateReferences(ctx context.Context, c Comment) error {
+	workItemID, err := strconv.ParseUint(c.ParentID, 10, 64)
+	if err != nil {
+		// not a work item comment
+		return nil
+	}
+	source := reference.Source{Type: reference.SourceComment, ID: c.ID.String(), WorkItemID: workItemID}
+	_, err = reference.NewRepository(m.db).Update(ctx, source, c.Body, c.Markup)
+	return err
+}
+
+// emit queues the given event for the webhooks and the event stream of the space of the commented work item
+func (m *GormCommentRepository) emit(ctx context.Context, eventType string, c Comment) error {
+	workItemID, err := strconv.ParseUint(c.ParentID, 10, 64)
+	if err != nil {
+		// not a work item comment
+		return nil
+	}
+	err = webhook.EmitForWorkItem(ctx, m.db, workItemID, eventType, map[string]interface{}{
+		"id":             c.ID,
+		"parent":         c.ParentID,
+		"body":           c.Body,
+		"markup":         c.Markup,
+		"created-by":     c.CreatedBy,
+		"parent-comment": c.ParentCommentID,
+	})
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	return eventstream.PublishForWorkItem(m.db, workItemID, eventstream.Notification{Type: eventType, ID: c.ID.String(), WorkItemID: c.ParentID})
+}
+
+// List all comments related to a single item as threads: the start, limit and total count apply to the
+// first comments of the threads, each of them is followed by its replies, oldest first
 func (m *GormCommentRepository) List(ctx context.Context, parent string, start *int, limit *int) ([]*Comment, uint64, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
 
-	db := m.db.Model(&Comment{}).Where("parent_id = ?", parent)
+	db := m.db.Model(&Comment{}).Where("parent_id = ? and parent_comment_id is null", parent)
 	orgDB := db
 	if start != nil {
 		if *start < 0 {
@@ -198,15 +331,108 @@ func (m *GormCommentRepository) List(ctx context.Context, parent string, start *
 		rows2.Next() // count(*) will always return a row
 		rows2.Scan(&count)
 	}
+	result, err = m.withReplies(result)
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
 	return result, count, nil
 }
 
-// Count all comments related to a single item
+// ListPage returns the given page of the threads of comments related to a single item, newest first,
+// see List. It also returns whether there are more threads beyond the page in paging direction and the
+// total number of threads.
+func (m *GormCommentRepository) ListPage(ctx context.Context, parent string, page gormsupport.Page) ([]*Comment, bool, uint64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
+	if page.ID != "" {
+		if _, err := uuid.FromString(page.ID); err != nil {
+			return nil, false, 0, errors.NewBadParameterError("cursor", page.ID)
+		}
+	}
+	keyset := gormsupport.Keyset{
+		Table:    m.TableName(),
+		IDColumn: "id",
+		Order: []gormsupport.OrderTerm{
+			{Expression: m.TableName() + ".created_at", Descending: true},
+			{Expression: m.TableName() + ".id", Descending: true},
+		},
+	}
+
+	db := m.db.Model(&Comment{}).Where("parent_id = ? and parent_comment_id is null", parent)
+	var count uint64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	db, err := keyset.Apply(db, page)
+	if err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	result := []*Comment{}
+	if err := db.Find(&result).Error; err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	more := len(result) > page.Limit
+	if more {
+		result = result[:page.Limit]
+	}
+	if page.Before {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+	result, err = m.withReplies(result)
+	if err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	return result, more, count, nil
+}
+
+// ListReplies returns the replies to the given comment, oldest first
+// returns NotFoundError or InternalError
+func (m *GormCommentRepository) ListReplies(ctx context.Context, id uuid.UUID) ([]*Comment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
+	if _, err := m.Load(ctx, id); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	result := []*Comment{}
+	if err := m.db.Where("parent_comment_id = ?", id).Order("created_at, id").Find(&result).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return result, nil
+}
+
+// withReplies returns the given first comments of threads each followed by its replies, oldest first
+func (m *GormCommentRepository) withReplies(roots []*Comment) ([]*Comment, error) {
+	if len(roots) == 0 {
+		return roots, nil
+	}
+	ids := make([]string, len(roots))
+	for i, root := range roots {
+		ids[i] = root.ID.String()
+	}
+	replies := []*Comment{}
+	if err := m.db.Where("parent_comment_id in (?)", ids).Order("created_at, id").Find(&replies).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	thread := map[uuid.UUID][]*Comment{}
+	for _, reply := range replies {
+		thread[*reply.ParentCommentID] = append(thread[*reply.ParentCommentID], reply)
+	}
+	result := make([]*Comment, 0, len(roots)+len(replies))
+	for _, root := range roots {
+		result = append(result, root)
+		result = append(result, thread[root.ID]...)
+	}
+	return result, nil
+}
+
+// Count the threads of comments related to a single item, as the total count of List does
 func (m *GormCommentRepository) Count(ctx context.Context, parent string) (int, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
 	var count int
 
-	m.db.Model(&Comment{}).Where("parent_id = ?", parent).Count(&count)
+	if err := m.db.Model(&Comment{}).Where("parent_id = ? and parent_comment_id is null", parent).Count(&count).Error; err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
 
 	return count, nil
 }
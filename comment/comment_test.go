@@ -6,11 +6,14 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
+	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -124,6 +127,27 @@ func (test *TestCommentRepository) TestSaveCommentWithoutMarkup() {
 	assert.Equal(test.T(), rendering.SystemMarkupPlainText, comments[0].Markup)
 }
 
+func (test *TestCommentRepository) TestSaveCommentVersionConflict() {
+	// given
+	repo := comment.NewCommentRepository(test.DB)
+	c := newComment("A", "Test A", rendering.SystemMarkupPlainText)
+	test.createComment(c)
+	stale := *c
+	// when
+	c.Body = "Test AB"
+	_, err := repo.Save(context.Background(), c)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 1, c.Version)
+	stale.Body = "Test AC"
+	_, err = repo.Save(context.Background(), &stale)
+	// then
+	require.NotNil(test.T(), err)
+	assert.IsType(test.T(), errors.VersionConflictError{}, errs.Cause(err))
+	loaded, err := repo.Load(context.Background(), c.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), "Test AB", loaded.Body)
+}
+
 func (test *TestCommentRepository) TestDeleteComment() {
 	t := test.T()
 	resource.Require(t, resource.Database)
@@ -227,3 +251,140 @@ func (test *TestCommentRepository) TestLoadComment() {
 	assert.Equal(test.T(), comment.ID, loadedComment.ID)
 	assert.Equal(test.T(), comment.Body, loadedComment.Body)
 }
+
+func (test *TestCommentRepository) TestListCommentsPage() {
+	// given
+	repo := comment.NewCommentRepository(test.DB)
+	comment1 := newComment("A", "Test A", rendering.SystemMarkupMarkdown)
+	comment2 := newComment("A", "Test B", rendering.SystemMarkupMarkdown)
+	comment3 := newComment("A", "Test C", rendering.SystemMarkupMarkdown)
+	comment4 := newComment("B", "Test D", rendering.SystemMarkupMarkdown)
+	test.createComments([]*comment.Comment{comment1, comment2, comment3, comment4})
+	// when
+	comments, more, count, err := repo.ListPage(context.Background(), "A", gormsupport.Page{Limit: 2})
+	// then
+	require.Nil(test.T(), err)
+	require.Len(test.T(), comments, 2)
+	assert.Equal(test.T(), comment3.ID, comments[0].ID)
+	assert.Equal(test.T(), comment2.ID, comments[1].ID)
+	assert.True(test.T(), more)
+	assert.Equal(test.T(), uint64(3), count)
+	// when
+	comments, more, _, err = repo.ListPage(context.Background(), "A", gormsupport.Page{ID: comment2.ID.String(), Limit: 2})
+	// then
+	require.Nil(test.T(), err)
+	require.Len(test.T(), comments, 1)
+	assert.Equal(test.T(), comment1.ID, comments[0].ID)
+	assert.False(test.T(), more)
+	// when
+	comments, more, _, err = repo.ListPage(context.Background(), "A", gormsupport.Page{ID: comment1.ID.String(), Before: true, Limit: 1})
+	// then
+	require.Nil(test.T(), err)
+	require.Len(test.T(), comments, 1)
+	assert.Equal(test.T(), comment2.ID, comments[0].ID)
+	assert.True(test.T(), more)
+}
+
+func (test *TestCommentRepository) TestListCommentsPageWrongCursor() {
+	// given
+	repo := comment.NewCommentRepository(test.DB)
+	// when
+	_, _, _, err := repo.ListPage(context.Background(), "A", gormsupport.Page{ID: "foo", Limit: 2})
+	// then
+	assert.NotNil(test.T(), err)
+	// when
+	_, _, _, err = repo.ListPage(context.Background(), "A", gormsupport.Page{ID: uuid.NewV4().String(), Limit: 2})
+	// then
+	assert.NotNil(test.T(), err)
+}
+
+func (test *TestCommentRepository) TestListCommentThreads() {
+	// given
+	repo := comment.NewCommentRepository(test.DB)
+	parentID := uuid.NewV4().String()
+	first := newComment(parentID, "first", rendering.SystemMarkupPlainText)
+	second := newComment(parentID, "second", rendering.SystemMarkupPlainText)
+	test.createComments([]*comment.Comment{first, second})
+	reply := newComment(parentID, "reply", rendering.SystemMarkupPlainText)
+	reply.ParentCommentID = &first.ID
+	test.createComment(reply)
+	replyToReply := newComment(parentID, "reply to reply", rendering.SystemMarkupPlainText)
+	replyToReply.ParentCommentID = &reply.ID
+	test.createComment(replyToReply)
+	// when
+	comments, count, err := repo.List(context.Background(), parentID, nil, nil)
+	// then the threads are listed newest first, their replies oldest first
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), uint64(2), count)
+	require.Len(test.T(), comments, 4)
+	assert.Equal(test.T(), []string{"second", "first", "reply", "reply to reply"},
+		[]string{comments[0].Body, comments[1].Body, comments[2].Body, comments[3].Body})
+	// a reply to a reply joins the thread
+	assert.Equal(test.T(), first.ID, *replyToReply.ParentCommentID)
+	replies, err := repo.ListReplies(context.Background(), first.ID)
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), replies, 2)
+	// the replies are not counted on their own
+	threads, err := repo.Count(context.Background(), parentID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 2, threads)
+	// deleting the first comment of a thread deletes the replies
+	require.Nil(test.T(), repo.Delete(context.Background(), first.ID))
+	threads, err = repo.Count(context.Background(), parentID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 1, threads)
+	for _, r := range replies {
+		_, err := repo.Load(context.Background(), r.ID)
+		assert.IsType(test.T(), errors.NotFoundError{}, errs.Cause(err))
+	}
+}
+
+func (test *TestCommentRepository) TestReplyToCommentOfOtherParent() {
+	// given
+	c := newComment("A", "Test A", rendering.SystemMarkupPlainText)
+	test.createComment(c)
+	reply := newComment("B", "reply", rendering.SystemMarkupPlainText)
+	reply.ParentCommentID = &c.ID
+	// when
+	err := comment.NewCommentRepository(test.DB).Create(context.Background(), reply)
+	// then
+	require.NotNil(test.T(), err)
+	assert.IsType(test.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (test *TestCommentRepository) TestReactions() {
+	// given
+	ctx := context.Background()
+	identities := account.NewIdentityRepository(test.DB)
+	alice := account.Identity{Username: "alice-" + uuid.NewV4().String(), Provider: account.KeycloakIDP}
+	require.Nil(test.T(), identities.Create(ctx, &alice))
+	bob := account.Identity{Username: "bob-" + uuid.NewV4().String(), Provider: account.KeycloakIDP}
+	require.Nil(test.T(), identities.Create(ctx, &bob))
+	c := newComment("A", "Test A", rendering.SystemMarkupPlainText)
+	test.createComment(c)
+	repo := comment.NewReactionRepository(test.DB)
+	// when
+	added, err := repo.Add(ctx, c.ID, alice.ID, ":+1:")
+	require.Nil(test.T(), err)
+	again, err := repo.Add(ctx, c.ID, alice.ID, ":+1:")
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), added.ID, again.ID)
+	_, err = repo.Add(ctx, c.ID, bob.ID, ":+1:")
+	require.Nil(test.T(), err)
+	_, err = repo.Add(ctx, c.ID, bob.ID, "🎉")
+	require.Nil(test.T(), err)
+	// then each identity counts once per emoji
+	counts, err := repo.Count(ctx, c.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), []comment.ReactionCount{{Emoji: ":+1:", Count: 2}, {Emoji: "🎉", Count: 1}}, counts[c.ID])
+
+	require.Nil(test.T(), repo.Remove(ctx, c.ID, alice.ID, ":+1:"))
+	err = repo.Remove(ctx, c.ID, alice.ID, ":+1:")
+	assert.IsType(test.T(), errors.NotFoundError{}, errs.Cause(err))
+	counts, err = repo.Count(ctx, c.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), []comment.ReactionCount{{Emoji: ":+1:", Count: 1}, {Emoji: "🎉", Count: 1}}, counts[c.ID])
+
+	_, err = repo.Add(ctx, c.ID, alice.ID, "not an emoji")
+	assert.IsType(test.T(), errors.BadParameterError{}, errs.Cause(err))
+}
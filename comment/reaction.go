@@ -0,0 +1,151 @@
+package comment
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// maxEmojiLength limits the number of runes of an emoji, enough for the sequences of joined emojis
+const maxEmojiLength = 16
+
+// a short code of an emoji, as in ":+1:"
+var emojiShortCode = regexp.MustCompile(`^:[a-z0-9_+-]+:$`)
+
+// Reaction is the reaction of an identity to a comment with an emoji. An identity reacts at most once
+// with the same emoji to a comment.
+type Reaction struct {
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CreatedAt  time.Time
+	CommentID  uuid.UUID `sql:"type:uuid"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	// Emoji is either the emoji itself or its short code, as in ":+1:"
+	Emoji string
+}
+
+// TableName implements gorm.tabler
+func (r Reaction) TableName() string {
+	return "comment_reactions"
+}
+
+// ReactionCount is the number of identities that reacted to a comment with an emoji
+type ReactionCount struct {
+	Emoji string
+	Count int
+}
+
+// ReactionRepository describes interactions with the reactions to comments
+type ReactionRepository interface {
+	Add(ctx context.Context, commentID, identityID uuid.UUID, emoji string) (*Reaction, error)
+	Remove(ctx context.Context, commentID, identityID uuid.UUID, emoji string) error
+	Count(ctx context.Context, commentIDs ...uuid.UUID) (map[uuid.UUID][]ReactionCount, error)
+}
+
+// NewReactionRepository creates a new storage type.
+func NewReactionRepository(db *gorm.DB) ReactionRepository {
+	return &GormReactionRepository{db: db}
+}
+
+// GormReactionRepository is the implementation of the storage interface for reactions.
+type GormReactionRepository struct {
+	db *gorm.DB
+}
+
+// Add records the reaction of the given identity to the given comment with the given emoji. Adding a
+// reaction twice returns the existing one.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormReactionRepository) Add(ctx context.Context, commentID, identityID uuid.UUID, emoji string) (*Reaction, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "reaction", "create"}, time.Now())
+	if !isEmoji(emoji) {
+		return nil, errors.NewBadParameterError("emoji", emoji)
+	}
+	if _, err := NewCommentRepository(r.db).Load(ctx, commentID); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	// concurrent requests adding the same reaction both end up with the one inserted first
+	err := r.db.Exec(`INSERT INTO comment_reactions (id, comment_id, identity_id, emoji) VALUES (?, ?, ?, ?)
+		ON CONFLICT (comment_id, identity_id, emoji) DO NOTHING`, uuid.NewV4(), commentID, identityID, emoji).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"commentID": commentID,
+			"err":       err,
+		}, "unable to create the reaction")
+		return nil, errors.NewInternalError(err.Error())
+	}
+	reaction := Reaction{}
+	if err := r.db.Where("comment_id = ? and identity_id = ? and emoji = ?", commentID, identityID, emoji).First(&reaction).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &reaction, nil
+}
+
+// Remove deletes the reaction of the given identity to the given comment with the given emoji
+// returns NotFoundError or InternalError
+func (r *GormReactionRepository) Remove(ctx context.Context, commentID, identityID uuid.UUID, emoji string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "reaction", "delete"}, time.Now())
+	tx := r.db.Where("comment_id = ? and identity_id = ? and emoji = ?", commentID, identityID, emoji).Delete(&Reaction{})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("reaction", emoji)
+	}
+	return nil
+}
+
+// Count returns the number of reactions with each emoji to the given comments, in the order the emojis
+// were first used on a comment. Comments without reactions are missing in the result.
+// returns InternalError
+func (r *GormReactionRepository) Count(ctx context.Context, commentIDs ...uuid.UUID) (map[uuid.UUID][]ReactionCount, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "reaction", "query"}, time.Now())
+	result := map[uuid.UUID][]ReactionCount{}
+	if len(commentIDs) == 0 {
+		return result, nil
+	}
+	ids := make([]string, len(commentIDs))
+	for i, id := range commentIDs {
+		ids[i] = id.String()
+	}
+	rows, err := r.db.Model(&Reaction{}).Select("comment_id, emoji, count(*)").Where("comment_id in (?)", ids).
+		Group("comment_id, emoji").Order("min(created_at), emoji").Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var commentID uuid.UUID
+		var count ReactionCount
+		if err := rows.Scan(&commentID, &count.Emoji, &count.Count); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		result[commentID] = append(result[commentID], count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return result, nil
+}
+
+// isEmoji tells whether the given string is the short code of an emoji or a short sequence of symbols
+func isEmoji(emoji string) bool {
+	if emojiShortCode.MatchString(emoji) {
+		return true
+	}
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxEmojiLength {
+		return false
+	}
+	return strings.IndexFunc(emoji, func(r rune) bool {
+		return r < utf8.RuneSelf || unicode.IsSpace(r) || unicode.IsControl(r) || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) < 0
+}
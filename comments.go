@@ -3,14 +3,19 @@ package main
 import (
 	"html"
 
+	"golang.org/x/net/context"
+
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 // CommentsController implements the comments resource.
@@ -33,11 +38,16 @@ func (c *CommentsController) Show(ctx *app.ShowCommentsContext) error {
 			return ctx.NotFound(jerrors)
 		}
 
+		details, err := loadCommentDetails(ctx, appl, c)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		res := &app.CommentSingle{}
 		res.Data = ConvertComment(
 			ctx.RequestData,
 			c,
-			CommentIncludeParentWorkItem())
+			CommentIncludeParentWorkItem(),
+			details)
 
 		return ctx.OK(res)
 	})
@@ -64,13 +74,20 @@ func (c *CommentsController) Update(ctx *app.UpdateCommentsContext) error {
 
 		cm.Body = *ctx.Payload.Data.Attributes.Body
 		cm.Markup = rendering.NilSafeGetMarkup(ctx.Payload.Data.Attributes.Markup)
+		if ctx.Payload.Data.Attributes.Version != nil {
+			cm.Version = *ctx.Payload.Data.Attributes.Version
+		}
 		cm, err = appl.Comments().Save(ctx.Context, cm)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
 
+		details, err := loadCommentDetails(ctx, appl, cm)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		res := &app.CommentSingle{
-			Data: ConvertComment(ctx.RequestData, cm, CommentIncludeParentWorkItem()),
+			Data: ConvertComment(ctx.RequestData, cm, CommentIncludeParentWorkItem(), details),
 		}
 		return ctx.OK(res)
 	})
@@ -149,6 +166,8 @@ func ConvertComment(request *goa.RequestData, comment *comment.Comment, addition
 			BodyRendered: &bodyRendered,
 			Markup:       &markup,
 			CreatedAt:    &comment.CreatedAt,
+			Version:      &comment.Version,
+			Reactions:    []*app.CommentReactionCount{},
 		},
 		Relationships: &app.CommentRelations{
 			CreatedBy: &app.CommentCreatedBy{
@@ -162,12 +181,75 @@ func ConvertComment(request *goa.RequestData, comment *comment.Comment, addition
 			Self: &selfURL,
 		},
 	}
+	if comment.ParentCommentID != nil {
+		parentCommentType := "comments"
+		parentCommentID := comment.ParentCommentID.String()
+		parentCommentSelf := rest.AbsoluteURL(request, app.CommentsHref(*comment.ParentCommentID))
+		c.Relationships.ParentComment = &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: &parentCommentType,
+				ID:   &parentCommentID,
+			},
+			Links: &app.GenericLinks{
+				Self: &parentCommentSelf,
+			},
+		}
+	} else {
+		repliesRelated := selfURL + "/replies"
+		c.Relationships.Replies = &app.RelationGeneric{
+			Links: &app.GenericLinks{
+				Related: &repliesRelated,
+			},
+		}
+	}
+	if comment.RemoteAuthor != "" {
+		c.Attributes.RemoteAuthor = &comment.RemoteAuthor
+	}
 	for _, add := range additional {
 		add(request, comment, c)
 	}
 	return c
 }
 
+// CommentIncludeReactions sets the numbers of reactions with each emoji to a comment
+func CommentIncludeReactions(reactions map[uuid.UUID][]comment.ReactionCount) CommentConvertFunc {
+	return func(request *goa.RequestData, comment *comment.Comment, data *app.Comment) {
+		if data.Attributes == nil {
+			return
+		}
+		counts := []*app.CommentReactionCount{}
+		for _, reaction := range reactions[comment.ID] {
+			counts = append(counts, &app.CommentReactionCount{Emoji: reaction.Emoji, Count: reaction.Count})
+		}
+		data.Attributes.Reactions = counts
+	}
+}
+
+// loadCommentDetails loads the references and the reactions of the given comments and returns a
+// CommentConvertFunc including them
+func loadCommentDetails(ctx context.Context, appl application.Application, comments ...*comment.Comment) (CommentConvertFunc, error) {
+	ids := make([]string, len(comments))
+	commentIDs := make([]uuid.UUID, len(comments))
+	for i, c := range comments {
+		ids[i] = c.ID.String()
+		commentIDs[i] = c.ID
+	}
+	refs, err := appl.References().List(ctx, reference.SourceComment, ids...)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	reactions, err := appl.CommentReactions().Count(ctx, commentIDs...)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	includeReferences := CommentIncludeReferences(refs)
+	includeReactions := CommentIncludeReactions(reactions)
+	return func(request *goa.RequestData, comment *comment.Comment, data *app.Comment) {
+		includeReferences(request, comment, data)
+		includeReactions(request, comment, data)
+	}, nil
+}
+
 // HrefFunc generic function to greate a relative Href to a resource
 type HrefFunc func(id interface{}) string
 
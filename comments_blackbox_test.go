@@ -5,6 +5,8 @@ import (
 	"html"
 	"testing"
 
+	"golang.org/x/net/context"
+
 	. "github.com/almighty/almighty-core"
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
@@ -14,6 +16,7 @@ import (
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
 	testsupport "github.com/almighty/almighty-core/test"
 	almtoken "github.com/almighty/almighty-core/token"
 	"github.com/almighty/almighty-core/workitem"
@@ -77,6 +80,7 @@ func (s *CommentsSuite) createWorkItem(identity account.Identity) string {
 				workitem.SystemTitle: "work item title",
 				workitem.SystemState: workitem.SystemStateNew},
 			Relationships: &app.WorkItemRelationships{
+				Space: spaceRelation(space.SystemSpace),
 				BaseType: &app.RelationBaseType{
 					Data: &app.BaseTypeData{
 						Type: "workitemtypes",
@@ -263,3 +267,46 @@ func (s *CommentsSuite) TestDeleteCommentWithOtherAuthenticatedUser() {
 	userSvc, _, _, commentsCtrl := s.securedControllers(testsupport.TestIdentity2)
 	test.DeleteCommentsForbidden(s.T(), userSvc.Context, userSvc, commentsCtrl, commentId)
 }
+
+func (s *CommentsSuite) TestRepliesAndReactions() {
+	// given
+	identity := account.Identity{Username: "reacting-" + uuid.NewV4().String(), Provider: account.KeycloakIDP}
+	require.Nil(s.T(), account.NewIdentityRepository(s.DB).Create(context.Background(), &identity))
+	workitemId := s.createWorkItem(identity)
+	commentId := s.createWorkItemComment(identity, workitemId, "first", &plaintextMarkup)
+	userSvc, _, workitemCommentsCtrl, _ := s.securedControllers(identity)
+	repliesCtrl := NewCommentRepliesController(userSvc, s.db)
+	reactionsCtrl := NewCommentReactionsController(userSvc, s.db)
+	// when
+	_, reply := test.CreateCommentRepliesOK(s.T(), userSvc.Context, userSvc, repliesCtrl, commentId, &app.CreateCommentRepliesPayload{
+		Data: &app.CreateComment{
+			Type:       "comments",
+			Attributes: &app.CreateCommentAttributes{Body: "reply"},
+		},
+	})
+	reaction := &app.CreateCommentReactionsPayload{
+		Data: &app.CommentReaction{
+			Type:       "reactions",
+			Attributes: &app.CommentReactionAttributes{Emoji: ":+1:"},
+		},
+	}
+	test.CreateCommentReactionsOK(s.T(), userSvc.Context, userSvc, reactionsCtrl, commentId, reaction)
+	_, reacted := test.CreateCommentReactionsOK(s.T(), userSvc.Context, userSvc, reactionsCtrl, commentId, reaction)
+	// then
+	require.NotNil(s.T(), reply.Data.Relationships.ParentComment)
+	assert.Equal(s.T(), commentId.String(), *reply.Data.Relationships.ParentComment.Data.ID)
+	assert.Equal(s.T(), []*app.CommentReactionCount{{Emoji: ":+1:", Count: 1}}, reacted.Data.Attributes.Reactions)
+	_, replies := test.ListCommentRepliesOK(s.T(), userSvc.Context, userSvc, repliesCtrl, commentId)
+	require.Len(s.T(), replies.Data, 1)
+	assert.Equal(s.T(), "reply", *replies.Data[0].Attributes.Body)
+	// the work item comments are listed as threads
+	_, comments := test.ListWorkItemCommentsOK(s.T(), userSvc.Context, userSvc, workitemCommentsCtrl, workitemId, nil, nil, nil, nil)
+	require.Len(s.T(), comments.Data, 2)
+	assert.Equal(s.T(), commentId, *comments.Data[0].ID)
+	assert.Equal(s.T(), 1, comments.Meta.TotalCount)
+	assert.Len(s.T(), comments.Data[0].Attributes.Reactions, 1)
+
+	_, unreacted := test.DeleteCommentReactionsOK(s.T(), userSvc.Context, userSvc, reactionsCtrl, commentId, ":+1:")
+	assert.Empty(s.T(), unreacted.Data.Attributes.Reactions)
+	test.DeleteCommentReactionsNotFound(s.T(), userSvc.Context, userSvc, reactionsCtrl, commentId, ":+1:")
+}
@@ -75,6 +75,8 @@ const (
 	varHTTPAddress                  = "http.address"
 	varDeveloperModeEnabled         = "developer.mode.enabled"
 	varGithubAuthToken              = "github.auth.token"
+	varGitlabAuthToken              = "gitlab.auth.token"
+	varBugzillaAPIKey               = "bugzilla.api.key"
 	varKeycloakSecret               = "keycloak.secret"
 	varKeycloakClientID             = "keycloak.client.id"
 	varKeycloakEndpointAuth         = "keycloak.endpoint.auth"
@@ -218,6 +220,17 @@ func GetGithubAuthToken() string {
 	return viper.GetString(varGithubAuthToken)
 }
 
+// GetGitlabAuthToken returns the private token used to list GitLab issues, empty to list the issues of public
+// projects only
+func GetGitlabAuthToken() string {
+	return viper.GetString(varGitlabAuthToken)
+}
+
+// GetBugzillaAPIKey returns the API key used to search Bugzilla bugs, empty to search the public bugs only
+func GetBugzillaAPIKey() string {
+	return viper.GetString(varBugzillaAPIKey)
+}
+
 // GetKeycloakSecret returns the keycloak client secret (as set via config file or environment variable)
 // that is used to make authorized Keycloak API Calls.
 func GetKeycloakSecret() string {
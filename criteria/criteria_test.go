@@ -14,3 +14,14 @@ func TestGetParent(t *testing.T) {
 		t.Errorf("parent should be %v, but is %v", expr, l.Parent())
 	}
 }
+
+func TestGetParentOfNot(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	l := Field("a")
+	r := Literal(5)
+	eq := Equals(l, r)
+	expr := Not(eq)
+	if eq.Parent() != expr {
+		t.Errorf("parent should be %v, but is %v", expr, eq.Parent())
+	}
+}
@@ -42,6 +42,41 @@ var commentAttributes = a.Type("CommentAttributes", func() {
 	a.Attribute("markup", d.String, "The comment markup associated with the body", func() {
 		a.Example("Markdown")
 	})
+	a.Attribute("version", d.Integer, "The version of the comment, updates of another version fail", func() {
+		a.Example(1)
+	})
+	a.Attribute("reactions", a.ArrayOf(commentReactionCount), "The number of reactions with each emoji, in the order the emojis were first used")
+	a.Attribute("remote-author", d.String, "The name of the author on the remote tracker the comment was imported from", func() {
+		a.Example("aslak")
+	})
+})
+
+var commentReactionCount = a.Type("CommentReactionCount", func() {
+	a.Attribute("emoji", d.String, "The emoji or its short code", func() {
+		a.Example(":+1:")
+	})
+	a.Attribute("count", d.Integer, "The number of identities that reacted with the emoji", func() {
+		a.Example(3)
+	})
+	a.Required("emoji", "count")
+})
+
+var commentReaction = a.Type("CommentReaction", func() {
+	a.Description(`JSONAPI store for the data of a reaction to a comment.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("reactions")
+	})
+	a.Attribute("attributes", commentReactionAttributes)
+	a.Required("type", "attributes")
+})
+
+var commentReactionAttributes = a.Type("CommentReactionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a reaction to a comment. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("emoji", d.String, "The emoji or its short code", func() {
+		a.MinLength(1)
+		a.Example(":+1:")
+	})
+	a.Required("emoji")
 })
 
 var createCommentAttributes = a.Type("CreateCommentAttributes", func() {
@@ -59,6 +94,8 @@ var createCommentAttributes = a.Type("CreateCommentAttributes", func() {
 var commentRelationships = a.Type("CommentRelations", func() {
 	a.Attribute("created-by", commentCreatedBy, "This defines the created by relation")
 	a.Attribute("parent", relationGeneric, "This defines the owning resource of the comment")
+	a.Attribute("parent-comment", relationGeneric, "The first comment of the thread of a reply")
+	a.Attribute("replies", relationGeneric, "The replies to the first comment of a thread")
 })
 
 var commentCreatedBy = a.Type("CommentCreatedBy", func() {
@@ -103,6 +140,11 @@ var createSingleComment = JSONSingle(
 	createComment,
 	nil,
 )
+var commentReactionSingle = JSONSingle(
+	"CommentReaction", "Holds the reaction to a comment",
+	commentReaction,
+	nil,
+)
 
 var _ = a.Resource("comments", func() {
 	a.BasePath("/comments")
@@ -172,6 +214,10 @@ var _ = a.Resource("work-item-comments", func() {
 			a.Param("page[offset]", d.String, `Paging start position is a string pointing to
 			the beginning of pagination.  The value starts from 0 onwards.`)
 			a.Param("page[limit]", d.Integer, `Paging size is the number of items in a page`)
+			a.Param("page[after]", d.String, `Paging cursor taken from a next link, returns the items following the item the cursor points to.
+An empty value starts at the beginning of the list. Takes precedence over page[offset]`)
+			a.Param("page[before]", d.String, `Paging cursor taken from a prev link, returns the items preceding the item the cursor points to.
+An empty value starts at the end of the list. Takes precedence over page[offset]`)
 		})
 		a.Response(d.OK, func() {
 			a.Media(commentArray)
@@ -214,3 +260,74 @@ var _ = a.Resource("work-item-comments", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
 })
+
+var _ = a.Resource("comment-replies", func() {
+	a.Parent("comments")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("replies"),
+		)
+		a.Description("List the replies to the given comment, oldest first")
+		a.Response(d.OK, func() {
+			a.Media(commentArray)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("replies"),
+		)
+		a.Description("Reply to the given comment, a reply to a reply joins the thread of the replied comment")
+		a.Payload(createSingleComment)
+		a.Response(d.OK, func() {
+			a.Media(commentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("comment-reactions", func() {
+	a.Parent("comments")
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("reactions"),
+		)
+		a.Description("React to the given comment with an emoji, reacting twice with the same emoji has no effect")
+		a.Payload(commentReactionSingle)
+		a.Response(d.OK, func() {
+			a.Media(commentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("reactions/:emoji"),
+		)
+		a.Description("Remove the reaction of the current user with the given emoji from the given comment")
+		a.Params(func() {
+			a.Param("emoji", d.String, "The emoji or its short code")
+		})
+		a.Response(d.OK, func() {
+			a.Media(commentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
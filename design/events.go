@@ -0,0 +1,27 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("events", func() {
+	a.BasePath("/events")
+
+	a.Action("stream", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description(`Stream the changes committed to the work items, comments and links of a space as Server-Sent Events.
+Every event is named after the kind of change, e.g. workitem.updated, and carries the ID and version of the changed entity as JSON data.`)
+		a.Params(func() {
+			a.Param("space", d.String, "ID of the space to stream the changes of")
+			a.Required("space")
+		})
+		a.Response(d.OK)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -64,6 +64,7 @@ var fieldDefinition = a.Type("fieldDefinition", func() {
 	a.Description("A fieldDescription aggregates a fieldType and additional field metadata")
 	a.Attribute("required", d.Boolean)
 	a.Attribute("type", fieldType)
+	a.Attribute("deprecated", d.Boolean, "Deprecated fields are kept for existing work items, but should not be used anymore")
 
 	a.Required("required")
 	a.Required("type")
@@ -84,6 +85,28 @@ var fieldType = a.Type("fieldType", func() {
 	a.Required("kind")
 })
 
+// workflow restricts the states of the work items of a type and the transitions between them
+var workflow = a.Type("workflow", func() {
+	a.Description("A workflow declares the states of a work item type, the allowed transitions and the fields required to enter a state")
+	a.Attribute("states", a.ArrayOf(d.String), "The states a work item can be in, a subset of the values of system.state", func() {
+		a.Example([]string{"new", "open", "closed"})
+	})
+	a.Attribute("transitions", a.HashOf(d.String, a.ArrayOf(d.String)), "Maps a state to the states a work item can move to from it", func() {
+		a.Example(map[string]interface{}{
+			"new":    []string{"open", "closed"},
+			"open":   []string{"closed"},
+			"closed": []string{"open"},
+		})
+	})
+	a.Attribute("guards", a.HashOf(d.String, a.ArrayOf(d.String)), "Maps a state to the fields that must have a value before a work item can move to it", func() {
+		a.Example(map[string]interface{}{
+			"in progress": []string{"system.assignees"},
+		})
+	})
+
+	a.Required("states")
+})
+
 // workItemType is the media type representing a work item type.
 var workItemType = a.MediaType("application/vnd.workitemtype+json", func() {
 	a.TypeName("WorkItemType")
@@ -91,6 +114,8 @@ var workItemType = a.MediaType("application/vnd.workitemtype+json", func() {
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
 	a.Attribute("name", d.String, "User Readable Name of this item type")
 	a.Attribute("fields", a.HashOf(d.String, fieldDefinition), "Definitions of fields in this work item type")
+	a.Attribute("space", d.UUID, "ID of the space owning this work item type, not present for types available in every space")
+	a.Attribute("workflow", workflow, "The workflow of work items of this type, not present if any state can follow any other")
 
 	a.Required("version")
 	a.Required("name")
@@ -100,6 +125,8 @@ var workItemType = a.MediaType("application/vnd.workitemtype+json", func() {
 		a.Attribute("version")
 		a.Attribute("name")
 		a.Attribute("fields")
+		a.Attribute("space")
+		a.Attribute("workflow")
 	})
 	a.View("link", func() {
 		a.Attribute("name")
@@ -126,6 +153,71 @@ var Tracker = a.MediaType("application/vnd.tracker+json", func() {
 	})
 })
 
+// TrackerQueryRun represents an import of the items of a tracker query
+var TrackerQueryRun = a.MediaType("application/vnd.trackerqueryrun+json", func() {
+	a.TypeName("TrackerQueryRun")
+	a.Description("Import of the items of a tracker query")
+	a.Attribute("id", d.String, "unique id per installation")
+	a.Attribute("trackerQueryID", d.String, "Tracker query ID")
+	a.Attribute("batchID", d.String, "ID of the run in the logs")
+	a.Attribute("startedAt", d.DateTime, "When the run started")
+	a.Attribute("finishedAt", d.DateTime, "When the run finished, missing while it goes on")
+	a.Attribute("fetched", d.Integer, "Number of items fetched from the tracker")
+	a.Attribute("created", d.Integer, "Number of work items created")
+	a.Attribute("updated", d.Integer, "Number of work items updated")
+	a.Attribute("failed", d.Integer, "Number of items which failed to import")
+	a.Attribute("errors", a.ArrayOf(d.String), "Why items failed to import or the import stopped")
+
+	a.Required("id")
+	a.Required("trackerQueryID")
+	a.Required("batchID")
+	a.Required("startedAt")
+	a.Required("fetched")
+	a.Required("created")
+	a.Required("updated")
+	a.Required("failed")
+	a.Required("errors")
+
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("trackerQueryID")
+		a.Attribute("batchID")
+		a.Attribute("startedAt")
+		a.Attribute("finishedAt")
+		a.Attribute("fetched")
+		a.Attribute("created")
+		a.Attribute("updated")
+		a.Attribute("failed")
+		a.Attribute("errors")
+	})
+})
+
+// fieldMapping maps a value of the remote items of a tracker query to a work item field
+var fieldMapping = a.Type("fieldMapping", func() {
+	a.Description("A fieldMapping imports a value of the flattened remote items into a work item field")
+	a.Attribute("path", d.String, "Dot delimited key of the value in the remote item", func() {
+		a.Example("fields.customfield_10002")
+		a.MinLength(1)
+	})
+	a.Attribute("field", d.String, "Name of the work item field", func() {
+		a.Example("system.storypoints")
+		a.MinLength(1)
+	})
+	a.Attribute("converter", d.String, "Converter of the value, it must fit the kind of the field", func() {
+		a.Enum("string", "number", "date", "list", "state")
+	})
+	a.Attribute("key", d.String, "Key of the values in the elements of a list of objects, for the list converter", func() {
+		a.Example("name")
+	})
+	a.Attribute("states", a.HashOf(d.String, d.String), "Maps the remote values to the values of the field, for the state converter", func() {
+		a.Example(map[string]interface{}{"In Review": "resolved"})
+	})
+
+	a.Required("path")
+	a.Required("field")
+	a.Required("converter")
+})
+
 // TrackerQuery represents the search query with schedule
 var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 	a.TypeName("TrackerQuery")
@@ -134,6 +226,9 @@ var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 	a.Attribute("query", d.String, "Search query")
 	a.Attribute("schedule", d.String, "Schedule for fetch and import")
 	a.Attribute("trackerID", d.String, "Tracker ID")
+	a.Attribute("spaceID", d.UUID, "Space of the work items created by the import")
+	a.Attribute("workItemType", d.String, "Type of the work items created by the import")
+	a.Attribute("mapping", a.ArrayOf(fieldMapping), "Field mappings in addition to the mapping of the tracker")
 
 	a.Required("id")
 	a.Required("query")
@@ -145,5 +240,8 @@ var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 		a.Attribute("query")
 		a.Attribute("schedule")
 		a.Attribute("trackerID")
+		a.Attribute("spaceID")
+		a.Attribute("workItemType")
+		a.Attribute("mapping")
 	})
 })
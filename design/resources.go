@@ -40,6 +40,26 @@ var _ = a.Resource("workitemtype", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:name"),
+		)
+		a.Description(`Change the fields of the work item type with given name and of all types extending it.
+The version of the changed types is incremented.`)
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+		})
+		a.Payload(UpdateWorkItemTypePayload)
+		a.Response(d.OK, func() {
+			a.Media(workItemType)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
 	a.Action("list", func() {
 		a.Routing(
 			a.GET(""),
@@ -88,6 +108,58 @@ given work item type can be used in the target of the link.`)
 	})
 })
 
+var _ = a.Resource("space-workitemtypes", func() {
+	a.Parent("space")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("workitemtypes/:name"),
+		)
+		a.Description("Retrieve the work item type with given name as seen from the given space.")
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemType)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("workitemtypes"),
+		)
+		a.Description(`Create a work item type owned by the given space. It may extend a global work item type
+like planneritem or another type of the space.`)
+		a.Payload(CreateWorkItemTypePayload)
+		a.Response(d.Created, "/spaces/.*/workitemtypes/.*", func() {
+			a.Media(workItemType)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("workitemtypes"),
+		)
+		a.Description("List the work item types available in the given space, i.e. the global ones and the ones owned by the space.")
+		a.Params(func() {
+			a.Param("page", d.String, "Paging in the format <start>,<limit>")
+		})
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(workItemType))
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
+
 var _ = a.Resource("status", func() {
 
 	a.DefaultMedia(ALMStatus)
@@ -261,4 +333,38 @@ var _ = a.Resource("trackerquery", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
+	a.Action("list-runs", func() {
+		a.Routing(
+			a.GET("/:id/runs"),
+		)
+		a.Description("List the latest imports of the tracker query, newest first.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(TrackerQueryRun))
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+	a.Action("run", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/runs"),
+		)
+		a.Description("Import the items of the tracker query right away. The run goes on in the background. Only one import of a tracker query runs at a time.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.Accepted, func() {
+			a.Media(TrackerQueryRun)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
 })
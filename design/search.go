@@ -31,9 +31,16 @@ var _ = a.Resource("search", func() {
 				1) "id:100" :- Look for work item hainvg id 100
 				2) "url:http://demo.almighty.io/details/500" :- Search on WI having id 500 and check 
 					if this URL is mentioned in searchable columns of work item
-				3) "simple keywords separated by space" :- Search in Work Items based on these keywords.`)
+				3) "simple keywords separated by space" :- Search in Work Items based on these keywords.
+				4) "space:40bbdd3d-8b5d-4fd6-ac90-7236b669af04" :- Only search Work Items of the given space.`)
 			a.Param("page[offset]", d.String, "Paging start position") // #428
 			a.Param("page[limit]", d.Integer, "Paging size")
+			a.Param("page[after]", d.String, `Paging cursor taken from a next link, returns the items following the item the cursor points to.
+An empty value starts at the beginning of the list. Takes precedence over page[offset]`)
+			a.Param("page[before]", d.String, `Paging cursor taken from a prev link, returns the items preceding the item the cursor points to.
+An empty value starts at the end of the list. Takes precedence over page[offset]`)
+			a.Param("sort", d.String, `comma separated list of fields to order the results by, prefix a field with "-" for descending order,
+e.g. -system.created_at. Results are ordered by relevance otherwise`)
 			a.Required("q")
 		})
 		a.Response(d.OK, func() {
@@ -21,6 +21,8 @@ var space = a.Type("Space", func() {
 var spaceRelationships = a.Type("SpaceRelationships", func() {
 	a.Attribute("iterations", relationGeneric, "Space can have one or many iterations")
 	a.Attribute("areas", relationGeneric, "Space can have one or many areas")
+	a.Attribute("workitems", relationGeneric, "Space can have one or many work items")
+	a.Attribute("workitemtypes", relationGeneric, "Space can have its own work item types in addition to the global ones")
 })
 
 var spaceAttributes = a.Type("SpaceAttributes", func() {
@@ -0,0 +1,78 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+// taskToggle checks or unchecks a checkbox of the task lists in a Markdown description or comment
+var taskToggle = a.Type("TaskToggle", func() {
+	a.Description(`JSONAPI store for the data of a checkbox toggle.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("tasks")
+	})
+	a.Attribute("attributes", taskToggleAttributes)
+	a.Required("type", "attributes")
+})
+
+var taskToggleAttributes = a.Type("TaskToggleAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a checkbox toggle. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("version", d.Integer, "The version of the work item or comment the toggle applies to, toggles of another version fail", func() {
+		a.Example(3)
+	})
+	a.Attribute("checked", d.Boolean, "The new state of the checkbox, the checkbox is toggled if missing")
+	a.Required("version")
+})
+
+var taskToggleSingle = JSONSingle(
+	"TaskToggle", "Holds the checkbox toggle of a task list",
+	taskToggle,
+	nil,
+)
+
+var _ = a.Resource("work-item-tasks", func() {
+	a.Parent("workitem")
+
+	a.Action("toggle", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("tasks/:index"),
+		)
+		a.Description("Check or uncheck the checkbox with the given index, starting at 0, of the task lists in the description of the given work item")
+		a.Params(func() {
+			a.Param("index", d.Integer, "index of the checkbox")
+		})
+		a.Payload(taskToggleSingle)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("comment-tasks", func() {
+	a.Parent("comments")
+
+	a.Action("toggle", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("tasks/:index"),
+		)
+		a.Description("Check or uncheck the checkbox with the given index, starting at 0, of the task lists in the body of the given comment")
+		a.Params(func() {
+			a.Param("index", d.Integer, "index of the checkbox")
+		})
+		a.Payload(taskToggleSingle)
+		a.Response(d.OK, func() {
+			a.Media(commentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
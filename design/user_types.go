@@ -64,6 +64,27 @@ var CreateWorkItemTypePayload = a.Type("CreateWorkItemTypePayload", func() {
 	a.Required("name", "fields")
 })
 
+// UpdateWorkItemTypePayload defines how the fields and the workflow of an existing work item type can be changed
+var UpdateWorkItemTypePayload = a.Type("UpdateWorkItemTypePayload", func() {
+	a.Attribute("version", d.Integer, "Version of the work item type the changes are based on", func() {
+		a.Example(0)
+	})
+	a.Attribute("fields", a.HashOf(d.String, fieldDefinition), `New optional fields and changed definitions of existing fields.
+Existing fields can be deprecated and the values of enum fields can be changed, fields not given are left unchanged.`, func() {
+		a.MinLength(1)
+	})
+	a.Attribute("valueMapping", a.HashOf(d.String, a.HashOf(d.String, d.Any)), `Replacements for removed enum values by field name.
+Existing work items holding a removed value are converted in the background, removed values of optional fields without replacement are cleared.`, func() {
+		a.Example(map[string]interface{}{
+			"system.state": map[string]interface{}{
+				"in progress": "open",
+			},
+		})
+	})
+	a.Attribute("workflow", workflow, "The new workflow of the type, the workflow is left unchanged if not given")
+	a.Required("version")
+})
+
 // CreateTrackerAlternatePayload defines the structure of tracker payload for create
 var CreateTrackerAlternatePayload = a.Type("CreateTrackerAlternatePayload", func() {
 	a.Attribute("url", d.String, "URL of the tracker", func() {
@@ -108,6 +129,13 @@ var CreateTrackerQueryAlternatePayload = a.Type("CreateTrackerQueryAlternatePayl
 		a.MinLength(1)
 		a.Pattern("^[\\p{N}]+$")
 	})
+	a.Attribute("spaceID", d.UUID, "Space of the work items created by the import, the work item type is looked up in it")
+	a.Attribute("workItemType", d.String, "Type of the work items created by the import, bugs if not given", func() {
+		a.Example("userstory")
+		a.MinLength(1)
+	})
+	a.Attribute("mapping", a.ArrayOf(fieldMapping), `Mappings of values of the remote items to work item fields, they take precedence
+over the mapping of the tracker for the same fields. The mappings must fit the fields of the work item type.`)
 	a.Required("query", "schedule", "trackerID")
 })
 
@@ -127,5 +155,12 @@ var UpdateTrackerQueryAlternatePayload = a.Type("UpdateTrackerQueryAlternatePayl
 		a.MinLength(1)
 		a.Pattern("[\\p{N}]+")
 	})
+	a.Attribute("spaceID", d.UUID, "Space of the work items created by the import, left unchanged if not given")
+	a.Attribute("workItemType", d.String, "Type of the work items created by the import, left unchanged if not given", func() {
+		a.Example("userstory")
+		a.MinLength(1)
+	})
+	a.Attribute("mapping", a.ArrayOf(fieldMapping), `Mappings of values of the remote items to work item fields, they take precedence
+over the mapping of the tracker for the same fields, left unchanged if not given.`)
 	a.Required("query", "schedule", "trackerID")
 })
@@ -0,0 +1,144 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var webhook = a.Type("Webhook", func() {
+	a.Description(`JSONAPI store for the data of a webhook subscription.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("webhooks")
+	})
+	a.Attribute("id", d.UUID, "ID of the webhook", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", webhookAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var webhookAttributes = a.Type("WebhookAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a webhook. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("url", d.String, "The URL the events are posted to", func() {
+		a.Example("https://chat.example.com/hooks/almighty")
+	})
+	a.Attribute("events", a.ArrayOf(d.String), `The events sent to the URL, either event names or kinds of events, e.g. "workitem.*". All events are sent if empty.`, func() {
+		a.Example([]string{"workitem.*", "comment.created"})
+	})
+	a.Attribute("secret", d.String, "The key the X-Almighty-Signature header of the deliveries is computed with. Generated if not given on creation and only returned by it.")
+	a.Attribute("created-at", d.DateTime, "When the webhook was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var webhookList = JSONList(
+	"Webhook", "Holds the list of webhooks",
+	webhook,
+	nil,
+	nil)
+
+var webhookSingle = JSONSingle(
+	"Webhook", "Holds a single webhook",
+	webhook,
+	nil)
+
+var webhookDelivery = a.Type("WebhookDelivery", func() {
+	a.Description(`JSONAPI store for the data of a webhook delivery.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("webhookdeliveries")
+	})
+	a.Attribute("id", d.UUID, "ID of the delivery, sent in the X-Almighty-Delivery header", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", webhookDeliveryAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var webhookDeliveryAttributes = a.Type("WebhookDeliveryAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a webhook delivery. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("event", d.String, "The event sent", func() {
+		a.Example("workitem.updated")
+	})
+	a.Attribute("payload", d.Any, "The request body sent")
+	a.Attribute("status", d.String, "The state of the delivery", func() {
+		a.Enum("pending", "delivered", "failed")
+	})
+	a.Attribute("attempts", d.Integer, "The number of attempts made so far")
+	a.Attribute("created-at", d.DateTime, "When the event occurred")
+	a.Attribute("next-attempt-at", d.DateTime, "When the next attempt is made if the delivery is pending")
+	a.Attribute("delivered-at", d.DateTime, "When the receiver accepted the delivery")
+	a.Attribute("last-status-code", d.Integer, "The HTTP status returned by the receiver in the last attempt, 0 if it could not be reached")
+	a.Attribute("last-error", d.String, "Why the last attempt failed")
+	a.Required("event", "status", "attempts", "created-at")
+})
+
+var webhookDeliveryList = JSONList(
+	"WebhookDelivery", "Holds the latest deliveries of a webhook",
+	webhookDelivery,
+	nil,
+	nil)
+
+var _ = a.Resource("space-webhooks", func() {
+	a.Parent("space")
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("webhooks"),
+		)
+		a.Description("Register a webhook receiving the events of the space.")
+		a.Payload(webhookSingle)
+		a.Response(d.Created, "/spaces/.*/webhooks/.*", func() {
+			a.Media(webhookSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("webhooks"),
+		)
+		a.Description("List the webhooks of the space.")
+		a.Response(d.OK, func() {
+			a.Media(webhookList)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("webhooks/:webhookID"),
+		)
+		a.Description("Delete the webhook with the given id.")
+		a.Params(func() {
+			a.Param("webhookID", d.String, "ID of the webhook")
+		})
+		a.Response(d.OK)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("list-deliveries", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("webhooks/:webhookID/deliveries"),
+		)
+		a.Description("List the latest deliveries of the webhook with the given id, newest first.")
+		a.Params(func() {
+			a.Param("webhookID", d.String, "ID of the webhook")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, func() {
+			a.Media(webhookDeliveryList)
+		})
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,117 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+// workItemRevision describes a single change to a work item
+var workItemRevision = a.Type("WorkItemRevision", func() {
+	a.Description(`JSONAPI store for the data of a work item revision.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemrevisions")
+	})
+	a.Attribute("id", d.UUID, "ID of the revision", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemRevisionAttributes)
+	a.Attribute("relationships", workItemRevisionRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "id", "attributes")
+})
+
+var workItemRevisionAttributes = a.Type("WorkItemRevisionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item revision. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("revision-type", d.String, "The kind of change", func() {
+		a.Enum("create", "update", "delete", "undelete")
+	})
+	a.Attribute("time", d.DateTime, "When the change was made", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("version", d.Integer, "The version of the work item after the change", func() {
+		a.Example(2)
+	})
+	a.Attribute("type", d.String, "The type of the work item after the change", func() {
+		a.Example("userstory")
+	})
+	a.Attribute("changes", a.HashOf(d.String, fieldChange), `The old and new values of the fields changed by this revision,
+not present for revisions recorded before changes were tracked`)
+	a.Attribute("fields", a.HashOf(d.String, d.Any), "The field values of the work item after the change, only present when retrieving a single revision")
+	a.Required("revision-type", "time", "version", "type")
+})
+
+var fieldChange = a.Type("FieldChange", func() {
+	a.Attribute("old", d.Any, "The value before the change")
+	a.Attribute("new", d.Any, "The value after the change")
+})
+
+var workItemRevisionRelationships = a.Type("WorkItemRevisionRelations", func() {
+	a.Attribute("modifier", commentCreatedBy, "The identity that made the change, not present for changes imported from remote trackers")
+	a.Attribute("workitem", relationGeneric, "The work item the revision belongs to")
+})
+
+var workItemRevisionList = JSONList(
+	"WorkItemRevision", "Holds the revisions of a work item",
+	workItemRevision,
+	nil,
+	nil,
+)
+
+var workItemRevisionSingle = JSONSingle(
+	"WorkItemRevision", "Holds a single revision of a work item",
+	workItemRevision,
+	nil,
+)
+
+var _ = a.Resource("work-item-revisions", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("revisions"),
+		)
+		a.Description("List the revisions of the given work item, oldest first")
+		a.Response(d.OK, func() {
+			a.Media(workItemRevisionList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("revisions/:version"),
+		)
+		a.Description("Retrieve the revision of the given work item that resulted in the given version")
+		a.Params(func() {
+			a.Param("version", d.Integer, "version of the work item")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemRevisionSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+	a.Action("restore", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("revisions/:version/restore"),
+		)
+		a.Description(`Restore the type and field values the given work item had in the given version.
+The restored values are stored as a new version of the work item, the current version must be the
+one the client knows of`)
+		a.Params(func() {
+			a.Param("version", d.Integer, "version of the work item to restore")
+			a.Param("currentVersion", d.Integer, "current version of the work item as known by the client")
+			a.Required("currentVersion")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,55 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+// workItemTransition describes a state a work item can move to
+var workItemTransition = a.Type("WorkItemTransition", func() {
+	a.Description(`JSONAPI store for the data of a work item transition.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemtransitions")
+	})
+	a.Attribute("id", d.String, "The state the work item can move to", func() {
+		a.Example("in progress")
+	})
+	a.Attribute("attributes", workItemTransitionAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var workItemTransitionAttributes = a.Type("WorkItemTransitionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item transition. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("to", d.String, "The state the work item can move to", func() {
+		a.Example("in progress")
+	})
+	a.Attribute("available", d.Boolean, "Whether the work item can move to the state right away")
+	a.Attribute("missing-fields", a.ArrayOf(d.String), "The fields that need a value before the work item can move to the state", func() {
+		a.Example([]string{"system.assignees"})
+	})
+	a.Required("to", "available")
+})
+
+var workItemTransitionList = JSONList(
+	"WorkItemTransition", "Holds the transitions available for a work item",
+	workItemTransition,
+	nil,
+	nil,
+)
+
+var _ = a.Resource("work-item-transitions", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("transitions"),
+		)
+		a.Description("List the states the given work item can move to according to the workflow of its type")
+		a.Response(d.OK, func() {
+			a.Media(workItemTransitionList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
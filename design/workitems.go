@@ -40,6 +40,7 @@ var workItemRelationships = a.Type("WorkItemRelationships", func() {
 	a.Attribute("comments", relationGeneric, "This defines comments on the Work Item")
 	a.Attribute("iteration", relationGeneric, "This defines the iteration this work item belong to")
 	a.Attribute("area", relationGeneric, "This defines the area this work item belongs to")
+	a.Attribute("space", relationGeneric, "This defines the space this work item belongs to")
 
 })
 
@@ -68,6 +69,24 @@ var workItemLinks = a.Type("WorkItemLinks", func() {
 	a.Required("self")
 })
 
+// workItemListParams defines the parameters for listing work items
+var workItemListParams = func() {
+	a.Param("filter", d.String, `a query language expression restricting the set of found work items,
+e.g. system.state in ("open", "in progress") and (system.assignees = me or system.creator = me)`)
+	a.Param("page[offset]", d.String, "Paging start position")
+	a.Param("page[limit]", d.Integer, "Paging size")
+	a.Param("page[after]", d.String, `Paging cursor taken from a next link, returns the items following the item the cursor points to.
+An empty value starts at the beginning of the list. Takes precedence over page[offset]`)
+	a.Param("page[before]", d.String, `Paging cursor taken from a prev link, returns the items preceding the item the cursor points to.
+An empty value starts at the end of the list. Takes precedence over page[offset]`)
+	a.Param("filter[assignee]", d.String, "Work Items assigned to the given user")
+	a.Param("filter[iteration]", d.String, "IterationID to filter work items")
+	a.Param("filter[workitemtype]", d.String, "work item type to filter work items by")
+	a.Param("filter[area]", d.String, "AreaID to filter work items")
+	a.Param("sort", d.String, `comma separated list of fields to order the work items by, prefix a field with "-" for descending order,
+e.g. -system.created_at,system.title`)
+}
+
 // workItemList contains paged results for listing work items and paging links
 var workItemList = JSONList(
 	"WorkItem2", "Holds the paginated response to a work item list request",
@@ -81,6 +100,40 @@ var workItemSingle = JSONSingle(
 	workItem2,
 	workItemLinks)
 
+// workItemBulkPayload describes a change applied to many work items in one transaction
+var workItemBulkPayload = a.Type("WorkItemBulkPayload", func() {
+	a.Attribute("operation", d.String, "The operation to apply to the work items, defaults to update", func() {
+		a.Enum("update", "delete")
+	})
+	a.Attribute("data", a.ArrayOf(workItem2), `The work items to change. Updates need the version and the changed
+attributes and relationships of each work item, deletes only the id`)
+	a.Attribute("filter", d.String, `a query language expression selecting further work items to change,
+e.g. system.iteration = "1a8d3f5c-61f2-4b6c-a6e4-8a9f8ec0b2d0" and system.state != "closed"`)
+	a.Attribute("patch", workItem2, `The attributes and relationships to change in the work items selected by the filter.
+The changes are applied to the current version of each work item`)
+	a.Attribute("atomic", d.Boolean, "If set, either all work items are changed or, if any change fails, none")
+})
+
+// workItemBulkResult reports the outcome of a bulk operation for a single work item
+var workItemBulkResult = a.Type("WorkItemBulkResult", func() {
+	a.Attribute("id", d.String, "ID of the work item", func() {
+		a.Example("42")
+	})
+	a.Attribute("status", d.String, "The outcome of the operation", func() {
+		a.Enum("updated", "deleted", "failed")
+	})
+	a.Attribute("data", workItem2, "The updated work item")
+	a.Attribute("errors", a.ArrayOf(JSONAPIError), "Why the operation failed for the work item")
+	a.Required("id", "status")
+})
+
+// workItemBulkResultList holds the outcome of a bulk operation per work item
+var workItemBulkResultList = JSONList(
+	"WorkItemBulkResult", "Holds the outcome of a bulk operation for each work item",
+	workItemBulkResult,
+	nil,
+	nil)
+
 // new version of "list" for migration
 var _ = a.Resource("workitem", func() {
 	a.BasePath("/workitems")
@@ -105,14 +158,8 @@ var _ = a.Resource("workitem", func() {
 		)
 		a.Description("List work items.")
 		a.Params(func() {
-			a.Param("filter", d.String, "a query language expression restricting the set of found work items")
-			a.Param("page[offset]", d.String, "Paging start position")
-			a.Param("page[limit]", d.Integer, "Paging size")
-			a.Param("filter[assignee]", d.String, "Work Items assigned to the given user")
-			a.Param("filter[iteration]", d.String, "IterationID to filter work items")
-			a.Param("filter[workitemtype]", d.String, "work item type to filter work items by")
-			a.Param("filter[area]", d.String, "AreaID to filter work items")
-
+			workItemListParams()
+			a.Param("filter[space]", d.String, "SpaceID to filter work items")
 		})
 		a.Response(d.OK, func() {
 			a.Media(workItemList)
@@ -149,6 +196,23 @@ var _ = a.Resource("workitem", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
+	a.Action("undelete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/undelete"),
+		)
+		a.Description("Recover the deleted work item with the given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
 	a.Action("update", func() {
 		a.Security("jwt")
 		a.Routing(
@@ -167,4 +231,52 @@ var _ = a.Resource("workitem", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
+	a.Action("bulk-update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH(""),
+		)
+		a.Description(`update or delete many work items in one transaction. The outcome is reported per work item,
+in atomic mode a failure for any work item rolls back all changes and responds with the errors of all failed work items.`)
+		a.Payload(workItemBulkPayload)
+		a.Response(d.OK, func() {
+			a.Media(workItemBulkResultList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("space-workitems", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("workitems"),
+		)
+		a.Description("List the work items of the given space.")
+		a.Params(workItemListParams)
+		a.Response(d.OK, func() {
+			a.Media(workItemList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("workitems"),
+		)
+		a.Description("create work item with type and id in the given space.")
+		a.Payload(workItemSingle)
+		a.Response(d.Created, "/workitems/.*", func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
 })
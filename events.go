@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/eventstream"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// eventsPath is the path the events are streamed at
+const eventsPath = "/api/events"
+
+// EventsController implements the events resource.
+type EventsController struct {
+	*goa.Controller
+	db     application.DB
+	broker *eventstream.Broker
+}
+
+// NewEventsController creates an events controller streaming the notifications of the given broker.
+func NewEventsController(service *goa.Service, db application.DB, broker *eventstream.Broker) *EventsController {
+	return &EventsController{Controller: service.NewController("EventsController"), db: db, broker: broker}
+}
+
+// Stream runs the stream action.
+func (c *EventsController) Stream(ctx *app.StreamEventsContext) error {
+	if _, err := login.ContextIdentity(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.Space)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		_, err := appl.Spaces().Load(ctx, spaceID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	// the underlying writer of the response flushes the events to the client, see skipEvents
+	if err := c.broker.Stream(ctx.ResponseData.ResponseWriter, ctx.Request.Context().Done(), spaceID); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
+	}
+	return nil
+}
+
+// skipEvents applies the given middleware to all requests but the ones streaming events. Middleware
+// wrapping the response writer, like the gzip one, buffers the events and hides the http.Flusher of
+// the underlying writer.
+func skipEvents(m goa.Middleware) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		wrapped := m(h)
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if req.URL.Path == eventsPath {
+				return h(ctx, rw, req)
+			}
+			return wrapped(ctx, rw, req)
+		}
+	}
+}
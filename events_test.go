@@ -0,0 +1,52 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/eventstream"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestEventsREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunEventsREST(t *testing.T) {
+	suite.Run(t, &TestEventsREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestEventsREST) SetupTest() {
+	resource.Require(rest.T(), resource.Database)
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestEventsREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestEventsREST) TestStreamEventsUnknownSpace() {
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	svc := testsupport.ServiceAsUser("Events-Service", almtoken.NewManagerWithPrivateKey(priv), testsupport.TestIdentity)
+	ctrl := NewEventsController(svc, rest.db, eventstream.NewBroker())
+	test.StreamEventsNotFound(rest.T(), svc.Context, svc, ctrl, uuid.NewV4().String())
+}
+
+func (rest *TestEventsREST) TestStreamEventsUnauthorized() {
+	svc := goa.New("Events-Service")
+	ctrl := NewEventsController(svc, rest.db, eventstream.NewBroker())
+	test.StreamEventsUnauthorized(rest.T(), svc.Context, svc, ctrl, uuid.NewV4().String())
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/gzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipEvents(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	var writer http.ResponseWriter
+	h := skipEvents(gzip.Middleware(9))(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		writer = goa.ContextResponse(ctx).ResponseWriter
+		return nil
+	})
+	serve := func(path string) http.ResponseWriter {
+		req, err := http.NewRequest("GET", path, nil)
+		require.Nil(t, err)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rw := httptest.NewRecorder()
+		require.Nil(t, h(goa.NewContext(context.Background(), rw, req, nil), rw, req))
+		return rw
+	}
+
+	// the stream writes to the underlying writer, which flushes
+	rw := serve(eventsPath + "?space=2e0698d8-753e-4cef-bb7c-f027634824a2")
+	assert.Equal(t, rw, writer)
+	_, ok := writer.(http.Flusher)
+	assert.True(t, ok)
+
+	rw = serve("/api/workitems")
+	assert.NotEqual(t, rw, writer)
+}
@@ -0,0 +1,104 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/almighty/almighty-core/log"
+	"github.com/lib/pq"
+	uuid "github.com/satori/go.uuid"
+)
+
+// subscriberBuffer is the number of notifications queued for a client before it is considered too slow
+// and disconnected
+const subscriberBuffer = 64
+
+// Broker fans the notifications received from the database out to the clients listening on their space
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan Notification]bool
+}
+
+// NewBroker creates a Broker
+func NewBroker() *Broker {
+	return &Broker{subscribers: map[uuid.UUID]map[chan Notification]bool{}}
+}
+
+// Subscribe returns a channel receiving the notifications of the given space and a function ending the
+// subscription. The channel is closed when the subscriber falls behind, it should reconnect and reload.
+func (b *Broker) Subscribe(spaceID uuid.UUID) (<-chan Notification, func()) {
+	ch := make(chan Notification, subscriberBuffer)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscribers[spaceID] == nil {
+		b.subscribers[spaceID] = map[chan Notification]bool{}
+	}
+	b.subscribers[spaceID][ch] = true
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(spaceID, ch)
+	}
+}
+
+// remove closes the given subscription if it is still open, the caller must hold the lock
+func (b *Broker) remove(spaceID uuid.UUID, ch chan Notification) {
+	if !b.subscribers[spaceID][ch] {
+		return
+	}
+	delete(b.subscribers[spaceID], ch)
+	if len(b.subscribers[spaceID]) == 0 {
+		delete(b.subscribers, spaceID)
+	}
+	close(ch)
+}
+
+// Run dispatches the notifications received from a pq.Listener until the given channel is closed.
+// The listener sends nil after it reconnected to the database, in which case all clients are told to resync.
+func (b *Broker) Run(notifications <-chan *pq.Notification) {
+	for notification := range notifications {
+		if notification == nil {
+			b.broadcast(Notification{Type: EventResync})
+			continue
+		}
+		var n Notification
+		if err := json.Unmarshal([]byte(notification.Extra), &n); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err":     err,
+				"payload": notification.Extra,
+			}, "unable to decode the event notification")
+			continue
+		}
+		b.Dispatch(n)
+	}
+}
+
+// Dispatch sends the notification to the clients listening on its space
+func (b *Broker) Dispatch(n Notification) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[n.SpaceID] {
+		b.send(n.SpaceID, ch, n)
+	}
+}
+
+// broadcast sends the notification to all clients, whatever their space
+func (b *Broker) broadcast(n Notification) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for spaceID, subscribers := range b.subscribers {
+		n.SpaceID = spaceID
+		for ch := range subscribers {
+			b.send(spaceID, ch, n)
+		}
+	}
+}
+
+// send queues the notification for the subscriber without blocking, the caller must hold the lock
+func (b *Broker) send(spaceID uuid.UUID, ch chan Notification, n Notification) {
+	select {
+	case ch <- n:
+	default:
+		b.remove(spaceID, ch)
+	}
+}
@@ -0,0 +1,106 @@
+package eventstream_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/almighty/almighty-core/eventstream"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/lib/pq"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func notification(t *testing.T, n eventstream.Notification) *pq.Notification {
+	payload, err := json.Marshal(n)
+	require.Nil(t, err)
+	return &pq.Notification{Channel: eventstream.Channel, Extra: string(payload)}
+}
+
+func TestBrokerDispatchesBySpace(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	broker := eventstream.NewBroker()
+	space1, space2 := uuid.NewV4(), uuid.NewV4()
+	ch1, unsubscribe1 := broker.Subscribe(space1)
+	defer unsubscribe1()
+	ch2, unsubscribe2 := broker.Subscribe(space2)
+	defer unsubscribe2()
+	version := 3
+	notifications := make(chan *pq.Notification, 3)
+	notifications <- notification(t, eventstream.Notification{Type: "workitem.updated", SpaceID: space1, ID: "42", Version: &version})
+	notifications <- &pq.Notification{Channel: eventstream.Channel, Extra: "not json"}
+	notifications <- nil
+	close(notifications)
+	// when
+	broker.Run(notifications)
+	// then
+	n := <-ch1
+	assert.Equal(t, "workitem.updated", n.Type)
+	assert.Equal(t, "42", n.ID)
+	require.NotNil(t, n.Version)
+	assert.Equal(t, 3, *n.Version)
+	assert.Equal(t, eventstream.EventResync, (<-ch1).Type)
+	n = <-ch2
+	assert.Equal(t, eventstream.EventResync, n.Type)
+	assert.Equal(t, space2, n.SpaceID)
+	assert.Empty(t, ch2)
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	broker := eventstream.NewBroker()
+	spaceID := uuid.NewV4()
+	ch, unsubscribe := broker.Subscribe(spaceID)
+	for i := 0; i < 100; i++ {
+		broker.Dispatch(eventstream.Notification{Type: "comment.created", SpaceID: spaceID})
+	}
+	received := 0
+	for range ch {
+		received++
+	}
+	assert.True(t, received < 100)
+	// ending a dropped subscription is harmless
+	unsubscribe()
+}
+
+func TestStreamEvents(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	broker := eventstream.NewBroker()
+	spaceID := uuid.NewV4()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		broker.Stream(w, r.Context().Done(), spaceID)
+	}))
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.Nil(t, err)
+	assert.Equal(t, ": listening\n", line)
+	// when
+	version := 2
+	broker.Dispatch(eventstream.Notification{Type: "workitem.created", SpaceID: uuid.NewV4(), ID: "6"})
+	broker.Dispatch(eventstream.Notification{Type: "workitem.created", SpaceID: spaceID, ID: "7", Version: &version})
+	// then only the events of the space are streamed
+	reader.ReadString('\n')
+	line, err = reader.ReadString('\n')
+	require.Nil(t, err)
+	assert.Equal(t, "event: workitem.created\n", line)
+	line, err = reader.ReadString('\n')
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(line, "data: "))
+	var n eventstream.Notification
+	require.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &n))
+	assert.Equal(t, "7", n.ID)
+	assert.Equal(t, 2, *n.Version)
+}
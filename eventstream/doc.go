@@ -0,0 +1,3 @@
+// Package eventstream pushes the changes committed to work items, comments and links to clients
+// listening on a space, e.g. boards which would otherwise poll for changes.
+package eventstream
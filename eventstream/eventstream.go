@@ -0,0 +1,64 @@
+package eventstream
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Channel is the Postgres notification channel the changes are published on
+const Channel = "almighty_events"
+
+// EventResync tells the clients that notifications may have been lost, e.g. because the connection
+// to the database was interrupted, so they should reload what they show
+const EventResync = "resync"
+
+// Notification tells about a committed change. It only identifies the changed entity, clients load
+// the entity if they need more than its version.
+type Notification struct {
+	// the kind of change, e.g. "workitem.updated"
+	Type    string    `json:"type"`
+	SpaceID uuid.UUID `json:"space"`
+	// the ID of the changed work item, comment or link
+	ID string `json:"id,omitempty"`
+	// the version of the entity after the change, for entities with optimistic locking
+	Version *int `json:"version,omitempty"`
+	// the work item a comment or link belongs to
+	WorkItemID string `json:"workitem,omitempty"`
+}
+
+// Publish sends the notification to the clients listening on its space. Call it with the database
+// transaction that makes the change: Postgres only delivers the notification once the transaction is
+// committed, to all instances of the service.
+func Publish(db *gorm.DB, n Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	return errs.WithStack(db.Exec("SELECT pg_notify(?, ?)", Channel, string(payload)).Error)
+}
+
+// PublishForWorkItem sends the notification to the clients listening on the space of the work item
+// with the given id. Nothing is sent for work items without space.
+func PublishForWorkItem(db *gorm.DB, workItemID uint64, n Notification) error {
+	var spaceID sql.NullString
+	// deleted work items are included, their links are removed after them
+	if err := db.Raw("SELECT space_id FROM work_items WHERE id = ?", workItemID).Row().Scan(&spaceID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return errs.WithStack(err)
+	}
+	if !spaceID.Valid {
+		return nil
+	}
+	id, err := uuid.FromString(spaceID.String)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	n.SpaceID = id
+	return Publish(db, n)
+}
@@ -0,0 +1,80 @@
+package eventstream_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/eventstream"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type publishSuite struct {
+	gormsupport.DBTestSuite
+	listener *pq.Listener
+}
+
+func TestRunPublishSuite(t *testing.T) {
+	suite.Run(t, &publishSuite{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *publishSuite) SetupTest() {
+	resource.Require(s.T(), resource.Database)
+	s.listener = pq.NewListener(configuration.GetPostgresConfigString(), time.Second, time.Second, nil)
+	require.Nil(s.T(), s.listener.Listen(eventstream.Channel))
+}
+
+func (s *publishSuite) TearDownTest() {
+	s.listener.Close()
+}
+
+func (s *publishSuite) receive() *eventstream.Notification {
+	select {
+	case notification := <-s.listener.Notify:
+		require.NotNil(s.T(), notification)
+		var n eventstream.Notification
+		require.Nil(s.T(), json.Unmarshal([]byte(notification.Extra), &n))
+		return &n
+	case <-time.After(2 * time.Second):
+		return nil
+	}
+}
+
+func (s *publishSuite) TestPublishOnCommit() {
+	// given
+	spaceID := uuid.NewV4()
+	version := 1
+	// when
+	err := models.Transactional(s.DB, func(tx *gorm.DB) error {
+		return eventstream.Publish(tx, eventstream.Notification{Type: "workitem.created", SpaceID: spaceID, ID: "1", Version: &version})
+	})
+	// then
+	require.Nil(s.T(), err)
+	n := s.receive()
+	require.NotNil(s.T(), n)
+	assert.Equal(s.T(), spaceID, n.SpaceID)
+	assert.Equal(s.T(), "workitem.created", n.Type)
+}
+
+func (s *publishSuite) TestNoPublishOnRollback() {
+	// when
+	err := models.Transactional(s.DB, func(tx *gorm.DB) error {
+		if err := eventstream.Publish(tx, eventstream.Notification{Type: "workitem.created", SpaceID: uuid.NewV4()}); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	// then
+	require.NotNil(s.T(), err)
+	assert.Nil(s.T(), s.receive())
+}
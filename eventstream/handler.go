@@ -0,0 +1,58 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// keepAliveInterval is the time after which an idle stream gets a comment, so proxies keep it open
+const keepAliveInterval = 30 * time.Second
+
+// Stream writes the notifications of the given space to the given response as Server-Sent Events until
+// the given channel is closed or the client falls behind. Every event is named after the kind of change
+// and carries the Notification as JSON data. The caller checks that the client may read the space.
+func (b *Broker) Stream(w http.ResponseWriter, done <-chan struct{}, spaceID uuid.UUID) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errs.New("streaming is not supported")
+	}
+	notifications, unsubscribe := b.Subscribe(spaceID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// keep nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": listening\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case n, ok := <-notifications:
+			if !ok {
+				// too slow to keep up, the client reconnects and reloads
+				return nil
+			}
+			data, err := json.Marshal(n)
+			if err != nil {
+				return errs.WithStack(err)
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", n.Type, data)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-done:
+			return nil
+		}
+	}
+}
@@ -2,6 +2,7 @@ package gormapplication
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 
 	"github.com/almighty/almighty-core/account"
@@ -9,9 +10,11 @@ import (
 	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/almighty/almighty-core/search"
 	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 	"github.com/jinzhu/gorm"
@@ -46,6 +49,8 @@ var x application.Application = &GormDB{}
 
 var y application.Application = &GormTransaction{}
 
+var z application.Savepoints = &GormTransaction{}
+
 func NewGormDB(db *gorm.DB) *GormDB {
 	return &GormDB{GormBase{db}, ""}
 }
@@ -72,6 +77,10 @@ func (g *GormBase) WorkItemTypes() workitem.WorkItemTypeRepository {
 	return workitem.NewWorkItemTypeRepository(g.db)
 }
 
+func (g *GormBase) WorkItemRevisions() workitem.RevisionRepository {
+	return workitem.NewRevisionRepository(g.db)
+}
+
 func (g *GormBase) Spaces() space.Repository {
 	return space.NewRepository(g.db)
 }
@@ -117,6 +126,11 @@ func (g *GormBase) Comments() comment.Repository {
 	return comment.NewCommentRepository(g.db)
 }
 
+// CommentReactions returns a comment reaction repository
+func (g *GormBase) CommentReactions() comment.ReactionRepository {
+	return comment.NewReactionRepository(g.db)
+}
+
 // Iterations returns a iteration repository
 func (g *GormBase) Iterations() iteration.Repository {
 	return iteration.NewIterationRepository(g.db)
@@ -127,6 +141,16 @@ func (g *GormBase) Areas() area.Repository {
 	return area.NewAreaRepository(g.db)
 }
 
+// Webhooks returns a webhook repository
+func (g *GormBase) Webhooks() webhook.Repository {
+	return webhook.NewRepository(g.db)
+}
+
+// References returns a repository of the references of markup content
+func (g *GormBase) References() reference.Repository {
+	return reference.NewRepository(g.db)
+}
+
 func (g *GormBase) DB() *gorm.DB {
 	return g.db
 }
@@ -178,3 +202,29 @@ func (g *GormTransaction) Rollback() error {
 	g.db = nil
 	return errors.WithStack(err)
 }
+
+// savepointName matches the names accepted for savepoints, they can't be given as query parameters
+var savepointName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// execSavepoint executes the given savepoint statement with the quoted name of the savepoint
+func (g *GormTransaction) execSavepoint(statement, name string) error {
+	if !savepointName.MatchString(name) {
+		return errors.Errorf("invalid savepoint name %q", name)
+	}
+	return errors.WithStack(g.db.Exec(statement + ` "` + name + `"`).Error)
+}
+
+// Savepoint implements application.Savepoints, the name consists of lower case letters, digits and underscores
+func (g *GormTransaction) Savepoint(name string) error {
+	return g.execSavepoint("SAVEPOINT", name)
+}
+
+// ReleaseSavepoint implements application.Savepoints
+func (g *GormTransaction) ReleaseSavepoint(name string) error {
+	return g.execSavepoint("RELEASE SAVEPOINT", name)
+}
+
+// RollbackToSavepoint implements application.Savepoints
+func (g *GormTransaction) RollbackToSavepoint(name string) error {
+	return g.execSavepoint("ROLLBACK TO SAVEPOINT", name)
+}
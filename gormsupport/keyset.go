@@ -0,0 +1,92 @@
+package gormsupport
+
+import (
+	"strings"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+)
+
+// A Page describes a page of at most Limit rows following the row with the given ID in a keyset,
+// or preceding it if Before is set. An empty ID denotes the start (or the end if Before is set) of the keyset.
+type Page struct {
+	ID     string
+	Before bool
+	Limit  int
+}
+
+// An OrderTerm is an SQL expression the rows of a keyset are ordered by.
+type OrderTerm struct {
+	Expression string
+	Descending bool
+}
+
+// A Keyset orders the rows of a table by a list of terms and pages through them relative to
+// the position of a given row instead of an offset, so that pages stay stable when rows are
+// added or removed in between. The last term must be unique, usually it is the primary key.
+type Keyset struct {
+	Table    string
+	IDColumn string
+	Order    []OrderTerm
+}
+
+// Apply orders the given query by the terms of the keyset and restricts it to the given page.
+// One more row than the page limit is requested, so callers can tell whether there are more rows in paging
+// direction. If page.Before is set, the rows are returned in reverse order.
+func (k Keyset) Apply(db *gorm.DB, page Page) (*gorm.DB, error) {
+	if page.Limit <= 0 {
+		return nil, errors.NewBadParameterError("limit", page.Limit)
+	}
+	if page.ID != "" {
+		// the boundary row might have been soft-deleted in the meantime, which is fine
+		var count int
+		if err := db.New().Table(k.Table).Where(k.IDColumn+" = ?", page.ID).Count(&count).Error; err != nil {
+			return nil, errs.WithStack(err)
+		}
+		if count == 0 {
+			return nil, errors.NewBadParameterError("cursor", page.ID)
+		}
+		where, parameters := k.where(page)
+		db = db.Where(where, parameters...)
+	}
+	order := make([]string, len(k.Order))
+	for i, term := range k.Order {
+		order[i] = term.Expression + direction(term.Descending != page.Before)
+	}
+	return db.Order(strings.Join(order, ",")).Limit(page.Limit + 1), nil
+}
+
+// where returns the condition for rows following (or preceding) the boundary row of the given page,
+// i.e. "t0 > b0 or (t0 = b0 and t1 > b1) or ..." where b0, b1, ... are the values of the order terms
+// for the boundary row. Null values are ordered the postgres way: last in ascending, first in descending order.
+func (k Keyset) where(page Page) (string, []interface{}) {
+	boundary := func(term OrderTerm) string {
+		return "(select " + term.Expression + " from " + k.Table + " where " + k.IDColumn + " = ?)"
+	}
+	var clauses []string
+	var parameters []interface{}
+	for i, term := range k.Order {
+		var conditions []string
+		for _, previous := range k.Order[:i] {
+			conditions = append(conditions, "("+previous.Expression+" is not distinct from "+boundary(previous)+")")
+			parameters = append(parameters, page.ID)
+		}
+		b := boundary(term)
+		if term.Descending != page.Before {
+			conditions = append(conditions, "("+term.Expression+" < "+b+" or ("+b+" is null and "+term.Expression+" is not null))")
+		} else {
+			conditions = append(conditions, "("+b+" is not null and ("+term.Expression+" > "+b+" or "+term.Expression+" is null))")
+		}
+		parameters = append(parameters, page.ID, page.ID)
+		clauses = append(clauses, "("+strings.Join(conditions, " and ")+")")
+	}
+	return "(" + strings.Join(clauses, " or ") + ")", parameters
+}
+
+func direction(descending bool) string {
+	if descending {
+		return " desc"
+	}
+	return " asc"
+}
@@ -0,0 +1,30 @@
+package gormsupport
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysetWhere(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	keyset := Keyset{
+		Table:    "things",
+		IDColumn: "id",
+		Order:    []OrderTerm{{Expression: "a", Descending: true}, {Expression: "id"}},
+	}
+
+	where, parameters := keyset.where(Page{ID: "7"})
+	assert.Equal(t, "(((a < (select a from things where id = ?) or ((select a from things where id = ?) is null and a is not null)))"+
+		" or ((a is not distinct from (select a from things where id = ?)) and"+
+		" ((select id from things where id = ?) is not null and (id > (select id from things where id = ?) or id is null))))", where)
+	assert.Equal(t, []interface{}{"7", "7", "7", "7", "7"}, parameters)
+
+	where, parameters = keyset.where(Page{ID: "7", Before: true})
+	assert.Equal(t, "((((select a from things where id = ?) is not null and (a > (select a from things where id = ?) or a is null)))"+
+		" or ((a is not distinct from (select a from things where id = ?)) and"+
+		" (id < (select id from things where id = ?) or ((select id from things where id = ?) is null and id is not null))))", where)
+	assert.Len(t, parameters, 5)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/webhook"
 
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
@@ -75,6 +76,9 @@ func (m *GormIterationRepository) Create(ctx context.Context, u *Iteration) erro
 		}, "unable to create the iteration")
 		return errs.WithStack(err)
 	}
+	if err := m.emit(ctx, webhook.EventIterationCreated, *u); err != nil {
+		return errs.WithStack(err)
+	}
 
 	return nil
 }
@@ -144,9 +148,28 @@ func (m *GormIterationRepository) Save(ctx context.Context, i Iteration) (*Itera
 		}, "unable to save the iterations")
 		return nil, errors.NewInternalError(err.Error())
 	}
+	if err := m.emit(ctx, webhook.EventIterationUpdated, i); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
 	return &i, nil
 }
 
+// emit queues the given event for the webhooks of the space of the iteration
+func (m *GormIterationRepository) emit(ctx context.Context, eventType string, i Iteration) error {
+	data := map[string]interface{}{
+		"id":          i.ID,
+		"name":        i.Name,
+		"description": i.Description,
+		"state":       i.State,
+		"startAt":     i.StartAt,
+		"endAt":       i.EndAt,
+	}
+	if i.ParentID != uuid.Nil {
+		data["parent"] = i.ParentID
+	}
+	return webhook.Emit(ctx, m.db, i.SpaceID, eventType, data)
+}
+
 // CanStartIteration checks the rule - Only one iteration from a space can have state=start at a time.
 // More rules can be added as needed in this function
 func (m *GormIterationRepository) CanStartIteration(ctx context.Context, i *Iteration) (bool, error) {
@@ -13,12 +13,13 @@ import (
 	"golang.org/x/oauth2"
 
 	"github.com/jinzhu/gorm"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	logrus "github.com/Sirupsen/logrus"
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/eventstream"
 	"github.com/almighty/almighty-core/gormapplication"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/log"
@@ -27,6 +28,7 @@ import (
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 
@@ -149,13 +151,57 @@ func main() {
 	defer scheduler.Stop()
 	scheduler.ScheduleAllQueries()
 
+	// Push the local changes of work items imported from Github back to the issues
+	githubSyncer := remoteworkitem.NewGithubSyncer(db)
+	defer githubSyncer.Stop()
+	if err := githubSyncer.Start("@every 1m"); err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": fmt.Sprintf("%+v", err),
+		}, "failed to schedule the Github sync")
+	}
+
+	// Background conversion of work item fields after incompatible work item type changes
+	fieldMigrator := workitem.NewFieldMigrator(db)
+	defer fieldMigrator.Stop()
+	if err := fieldMigrator.Start("@every 1m"); err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": fmt.Sprintf("%+v", err),
+		}, "failed to schedule work item field migrations")
+	}
+
+	// Background delivery of webhook events
+	webhookDispatcher := webhook.NewDispatcher(db, webhook.NewClient(10*time.Second))
+	defer webhookDispatcher.Stop()
+	if err := webhookDispatcher.Start("@every 10s"); err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": fmt.Sprintf("%+v", err),
+		}, "failed to schedule webhook deliveries")
+	}
+
+	// Live stream of the changes committed by any instance of the service
+	eventListener := pq.NewListener(configuration.GetPostgresConfigString(), 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+			}, "event stream listener problem")
+		}
+	})
+	defer eventListener.Close()
+	if err := eventListener.Listen(eventstream.Channel); err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": fmt.Sprintf("%+v", err),
+		}, "failed to listen for events")
+	}
+	eventBroker := eventstream.NewBroker()
+	go eventBroker.Run(eventListener.Notify)
+
 	// Create service
 	service := goa.New("alm")
 
 	// Mount middleware
 	service.Use(middleware.RequestID())
 	service.Use(middleware.LogRequest(configuration.IsPostgresDeveloperModeEnabled()))
-	service.Use(gzip.Middleware(9))
+	service.Use(skipEvents(gzip.Middleware(9)))
 	service.Use(jsonapi.ErrorHandler(service, true))
 	service.Use(middleware.Recover())
 
@@ -221,6 +267,22 @@ func main() {
 	workItemCommentsCtrl := NewWorkItemCommentsController(service, appDB)
 	app.MountWorkItemCommentsController(service, workItemCommentsCtrl)
 
+	// Mount "work item revisions" controller
+	workItemRevisionsCtrl := NewWorkItemRevisionsController(service, appDB)
+	app.MountWorkItemRevisionsController(service, workItemRevisionsCtrl)
+
+	// Mount "work item transitions" controller
+	workItemTransitionsCtrl := NewWorkItemTransitionsController(service, appDB)
+	app.MountWorkItemTransitionsController(service, workItemTransitionsCtrl)
+
+	// Mount "work item tasks" controller
+	workItemTasksCtrl := NewWorkItemTasksController(service, appDB)
+	app.MountWorkItemTasksController(service, workItemTasksCtrl)
+
+	// Mount "comment tasks" controller
+	commentTasksCtrl := NewCommentTasksController(service, appDB)
+	app.MountCommentTasksController(service, commentTasksCtrl)
+
 	// Mount "work item relationships links" controller
 	workItemRelationshipsLinksCtrl := NewWorkItemRelationshipsLinksController(service, appDB)
 	app.MountWorkItemRelationshipsLinksController(service, workItemRelationshipsLinksCtrl)
@@ -229,6 +291,14 @@ func main() {
 	commentsCtrl := NewCommentsController(service, appDB)
 	app.MountCommentsController(service, commentsCtrl)
 
+	// Mount "comment replies" controller
+	commentRepliesCtrl := NewCommentRepliesController(service, appDB)
+	app.MountCommentRepliesController(service, commentRepliesCtrl)
+
+	// Mount "comment reactions" controller
+	commentReactionsCtrl := NewCommentReactionsController(service, appDB)
+	app.MountCommentReactionsController(service, commentReactionsCtrl)
+
 	// Mount "tracker" controller
 	c5 := NewTrackerController(service, appDB, scheduler)
 	app.MountTrackerController(service, c5)
@@ -280,6 +350,22 @@ func main() {
 	spaceAreaCtrl := NewSpaceAreasController(service, appDB)
 	app.MountSpaceAreasController(service, spaceAreaCtrl)
 
+	// Mount "space-workitems" controller
+	spaceWorkitemsCtrl := NewSpaceWorkitemsController(service, appDB)
+	app.MountSpaceWorkitemsController(service, spaceWorkitemsCtrl)
+
+	// Mount "space-workitemtypes" controller
+	spaceWorkitemtypesCtrl := NewSpaceWorkitemtypesController(service, appDB)
+	app.MountSpaceWorkitemtypesController(service, spaceWorkitemtypesCtrl)
+
+	// Mount "space-webhooks" controller
+	spaceWebhooksCtrl := NewSpaceWebhooksController(service, appDB)
+	app.MountSpaceWebhooksController(service, spaceWebhooksCtrl)
+
+	// Mount "events" controller
+	eventsCtrl := NewEventsController(service, appDB, eventBroker)
+	app.MountEventsController(service, eventsCtrl)
+
 	log.Logger().Infoln("Git Commit SHA: ", Commit)
 	log.Logger().Infoln("UTC Build Time: ", BuildTime)
 	log.Logger().Infoln("UTC Start Time: ", StartTime)
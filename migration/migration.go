@@ -179,6 +179,63 @@ func getMigrations() migrations {
 	// version 27
 	m = append(m, steps{executeSQLFile("027-areas-index.sql")})
 
+	// Version 28
+	m = append(m, steps{executeSQLFile("028-work-item-revisions.sql")})
+
+	// Version 29
+	m = append(m, steps{executeSQLFile("029-work-items-space.sql")})
+
+	// Version 30
+	m = append(m, steps{executeSQLFile("030-space-work-item-types.sql")})
+
+	// Version 31
+	m = append(m, steps{executeSQLFile("031-work-item-field-migrations.sql")})
+
+	// Version 32
+	m = append(m, steps{executeSQLFile("032-work-item-type-workflows.sql")})
+
+	// Version 33
+	m = append(m, steps{executeSQLFile("033-webhooks.sql")})
+
+	// Version 34
+	m = append(m, steps{executeSQLFile("034-tracker-item-sync.sql")})
+
+	// Version 35
+	m = append(m, steps{executeSQLFile("035-tracker-query-sync-cursor.sql")})
+
+	// Version 36
+	m = append(m, steps{executeSQLFile("036-tracker-query-runs.sql")})
+
+	// Version 37
+	m = append(m, steps{executeSQLFile("037-tracker-query-mapping.sql")})
+
+	// Version 38
+	m = append(m, steps{executeSQLFile("038-tracker-item-comments-import.sql")})
+
+	// Version 39
+	m = append(m, steps{executeSQLFile("039-markup-references.sql")})
+
+	// Version 40
+	m = append(m, steps{executeSQLFile("040-comment-version.sql")})
+
+	// Version 41
+	m = append(m, steps{executeSQLFile("041-comment-threads-reactions.sql")})
+
+	// Version 42
+	m = append(m, steps{executeSQLFile("042-tracker-query-running-run.sql")})
+
+	// Version 43
+	m = append(m, steps{executeSQLFile("043-comment-remote-author.sql")})
+
+	// Version 44
+	m = append(m, steps{executeSQLFile("044-tracker-query-space.sql")})
+
+	// Version 45
+	m = append(m, steps{executeSQLFile("045-field-migration-types.sql")})
+
+	// Version 46
+	m = append(m, steps{executeSQLFile("046-work-items-space-required.sql")})
+
 	// Version N
 	//
 	// In order to add an upgrade, simply append an array of MigrationFunc to the
@@ -0,0 +1,21 @@
+-- work_item_revisions: one row per change applied to a work item, holding the state of its fields after the change
+-- and the old and new values of the changed fields
+CREATE TABLE work_item_revisions (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    revision_time timestamp with time zone NOT NULL default now(),
+    revision_type integer NOT NULL,
+    modifier text,
+    work_item_id bigint NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    work_item_type text,
+    work_item_version integer NOT NULL,
+    work_item_fields jsonb,
+    work_item_changes jsonb
+);
+
+CREATE UNIQUE INDEX work_item_revisions_version_idx ON work_item_revisions USING btree (work_item_id, work_item_version);
+
+-- record the current state of all existing work items as their first revision, their changes are unknown
+INSERT INTO work_item_revisions (revision_time, revision_type, modifier, work_item_id, work_item_type, work_item_version, work_item_fields)
+    SELECT coalesce(updated_at, created_at, now()), 1, fields->>'system.creator', id, type, coalesce(version, 0), fields FROM work_items WHERE deleted_at IS NULL;
+INSERT INTO work_item_revisions (revision_time, revision_type, modifier, work_item_id, work_item_type, work_item_version, work_item_fields)
+    SELECT deleted_at, 3, NULL, id, type, coalesce(version, 0), fields FROM work_items WHERE deleted_at IS NOT NULL;
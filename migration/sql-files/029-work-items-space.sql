@@ -0,0 +1,9 @@
+-- work items belong to a space, like iterations and areas do
+ALTER TABLE work_items ADD COLUMN space_id uuid;
+CREATE INDEX work_items_space_id_idx ON work_items USING btree (space_id);
+
+-- existing work items belong to the space of their iteration or area, if they have one
+UPDATE work_items SET space_id = iterations.space_id FROM iterations
+    WHERE work_items.fields->>'system.iteration' = iterations.id::text;
+UPDATE work_items SET space_id = areas.space_id FROM areas
+    WHERE work_items.space_id IS NULL AND work_items.fields->>'system.area' = areas.id::text;
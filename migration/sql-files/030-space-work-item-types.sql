@@ -0,0 +1,10 @@
+-- work item types can be owned by a space. The global types available in every
+-- space belong to the nil space, so that the space can be part of the primary key.
+ALTER TABLE work_item_types ADD COLUMN space_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+-- type names are no longer unique on their own, so link types can't reference them
+ALTER TABLE work_item_link_types DROP CONSTRAINT work_item_link_types_source_type_name_fkey;
+ALTER TABLE work_item_link_types DROP CONSTRAINT work_item_link_types_target_type_name_fkey;
+
+ALTER TABLE work_item_types DROP CONSTRAINT work_item_types_pkey;
+ALTER TABLE work_item_types ADD PRIMARY KEY (space_id, name);
@@ -0,0 +1,13 @@
+-- work_item_field_migrations: incompatible changes to the fields of work item types, the values of the
+-- changed fields in existing work items are converted in the background
+CREATE TABLE work_item_field_migrations (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at timestamp with time zone NOT NULL default now(),
+    type_path ltree NOT NULL,
+    type_version integer NOT NULL,
+    field_name text NOT NULL,
+    value_mapping jsonb,
+    completed_at timestamp with time zone
+);
+
+CREATE INDEX work_item_field_migrations_pending_idx ON work_item_field_migrations (created_at) WHERE completed_at IS NULL;
@@ -0,0 +1,3 @@
+-- workflow of a work item type: the allowed states, the transitions between them and the fields required to
+-- enter a state. Types without workflow allow any state to follow any other.
+ALTER TABLE work_item_types ADD COLUMN workflow jsonb;
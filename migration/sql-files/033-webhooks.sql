@@ -0,0 +1,33 @@
+-- webhook_subscriptions: URLs registered to receive the events of a space, filtered by event name or kind
+CREATE TABLE webhook_subscriptions (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    space_id uuid NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    url text NOT NULL,
+    secret text NOT NULL,
+    events jsonb,
+    created_by uuid
+);
+
+CREATE INDEX webhook_subscriptions_space_idx ON webhook_subscriptions (space_id) WHERE deleted_at IS NULL;
+
+-- webhook_deliveries: the delivery log, one row per event sent to a subscription along with the outcome of
+-- the last attempt. Pending deliveries are sent in the background.
+CREATE TABLE webhook_deliveries (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at timestamp with time zone NOT NULL default now(),
+    subscription_id uuid NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_type text NOT NULL,
+    payload jsonb NOT NULL,
+    status text NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp with time zone NOT NULL,
+    delivered_at timestamp with time zone,
+    last_status_code integer NOT NULL DEFAULT 0,
+    last_error text
+);
+
+CREATE INDEX webhook_deliveries_subscription_idx ON webhook_deliveries (subscription_id, created_at);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
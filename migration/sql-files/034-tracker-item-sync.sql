@@ -0,0 +1,13 @@
+-- state of the two-way synchronization of tracker items: the values of the synchronized fields as of the
+-- last sync, the fields changed on both sides since then and when local changes were last pushed
+ALTER TABLE tracker_items ADD COLUMN synced_fields jsonb;
+ALTER TABLE tracker_items ADD COLUMN conflicts jsonb;
+ALTER TABLE tracker_items ADD COLUMN synced_at timestamp with time zone;
+
+-- tracker_item_comments: the comments on work items pushed to the remote tracker
+CREATE TABLE tracker_item_comments (
+    comment_id uuid primary key REFERENCES comments(id) ON DELETE CASCADE,
+    tracker_item_id bigint NOT NULL REFERENCES tracker_items(id) ON DELETE CASCADE,
+    remote_comment_id integer NOT NULL,
+    synced_body text
+);
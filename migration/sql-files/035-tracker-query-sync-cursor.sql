@@ -0,0 +1,4 @@
+-- incremental import of tracker queries: the last update time of the items imported so far and the
+-- item counts of the last import
+ALTER TABLE tracker_queries ADD COLUMN sync_cursor timestamp with time zone;
+ALTER TABLE tracker_queries ADD COLUMN last_run_stats jsonb;
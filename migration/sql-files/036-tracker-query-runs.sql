@@ -0,0 +1,12 @@
+-- tracker_query_runs: the history of the imports of tracker queries
+CREATE TABLE tracker_query_runs (
+    id bigserial primary key,
+    tracker_query_id bigint NOT NULL REFERENCES tracker_queries(id) ON DELETE CASCADE,
+    batch_id uuid NOT NULL,
+    started_at timestamp with time zone NOT NULL,
+    finished_at timestamp with time zone,
+    stats jsonb,
+    errors jsonb
+);
+
+CREATE INDEX ix_tracker_query_runs_query ON tracker_query_runs USING btree (tracker_query_id, started_at);
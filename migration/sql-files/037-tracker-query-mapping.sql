@@ -0,0 +1,4 @@
+-- configurable import of tracker queries: the type of the work items created and the mapping of the
+-- remote items to its fields
+ALTER TABLE tracker_queries ADD COLUMN work_item_type text;
+ALTER TABLE tracker_queries ADD COLUMN mapping jsonb;
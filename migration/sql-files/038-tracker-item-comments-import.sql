@@ -0,0 +1,4 @@
+-- comments imported from the remote tracker: the author on the remote tracker and the lookup of a remote
+-- comment on re-import
+ALTER TABLE tracker_item_comments ADD COLUMN remote_author text;
+CREATE UNIQUE INDEX ix_tracker_item_comments_remote ON tracker_item_comments (tracker_item_id, remote_comment_id);
@@ -0,0 +1,17 @@
+-- markup_references: the identities mentioned and the work items referenced in the description of a work
+-- item or in a comment. work_item_id is the work item the description or comment belongs to.
+CREATE TABLE markup_references (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at timestamp with time zone NOT NULL default now(),
+    source_type text NOT NULL,
+    source_id text NOT NULL,
+    work_item_id bigint NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    kind text NOT NULL,
+    key text NOT NULL,
+    identity_id uuid REFERENCES identities(id) ON DELETE CASCADE,
+    target_id bigint REFERENCES work_items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX markup_references_source_idx ON markup_references (source_type, source_id);
+CREATE INDEX markup_references_identity_idx ON markup_references (identity_id) WHERE identity_id IS NOT NULL;
+CREATE INDEX markup_references_target_idx ON markup_references (target_id) WHERE target_id IS NOT NULL;
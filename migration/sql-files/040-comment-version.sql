@@ -0,0 +1,2 @@
+-- the version of a comment is incremented by each update, updates of a version changed in the meantime fail
+ALTER TABLE comments ADD COLUMN version integer NOT NULL DEFAULT 0;
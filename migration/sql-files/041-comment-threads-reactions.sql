@@ -0,0 +1,13 @@
+-- the replies to a comment point to the first comment of their thread
+ALTER TABLE comments ADD COLUMN parent_comment_id uuid REFERENCES comments(id) ON DELETE CASCADE;
+CREATE INDEX comments_parent_comment_id_idx ON comments (parent_comment_id) WHERE parent_comment_id IS NOT NULL;
+
+-- comment_reactions: the emoji reactions of identities to comments, one per identity and emoji
+CREATE TABLE comment_reactions (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at timestamp with time zone NOT NULL default now(),
+    comment_id uuid NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    identity_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    emoji text NOT NULL,
+    UNIQUE (comment_id, identity_id, emoji)
+);
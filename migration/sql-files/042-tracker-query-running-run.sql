@@ -0,0 +1,3 @@
+-- only one import of a tracker query runs at a time, runs interrupted before this migration are over
+UPDATE tracker_query_runs SET finished_at = now() WHERE finished_at IS NULL;
+CREATE UNIQUE INDEX tracker_query_runs_running_idx ON tracker_query_runs (tracker_query_id) WHERE finished_at IS NULL;
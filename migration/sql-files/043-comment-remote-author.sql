@@ -0,0 +1,5 @@
+-- the author on the remote tracker belongs to the imported comment itself
+ALTER TABLE comments ADD COLUMN remote_author text;
+UPDATE comments SET remote_author = tracker_item_comments.remote_author FROM tracker_item_comments
+    WHERE tracker_item_comments.comment_id = comments.id AND tracker_item_comments.remote_author IS NOT NULL;
+ALTER TABLE tracker_item_comments DROP COLUMN remote_author;
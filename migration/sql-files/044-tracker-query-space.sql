@@ -0,0 +1,2 @@
+-- the space of the work items imported by a tracker query and of their type
+ALTER TABLE tracker_queries ADD COLUMN space_id uuid REFERENCES spaces(id) ON DELETE CASCADE;
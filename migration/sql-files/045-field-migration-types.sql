@@ -0,0 +1,11 @@
+-- field migrations record the work item types that received the change, extending types
+-- that redefined the field keep their values
+ALTER TABLE work_item_field_migrations ADD COLUMN types jsonb NOT NULL DEFAULT '[]';
+
+-- pending migrations keep applying to all types below the changed one
+UPDATE work_item_field_migrations m SET types = (
+    SELECT coalesce(jsonb_agg(jsonb_build_object('space_id', t.space_id, 'name', t.name)), '[]')
+    FROM work_item_types t WHERE t.path <@ m.type_path)
+WHERE m.completed_at IS NULL;
+
+ALTER TABLE work_item_field_migrations ALTER COLUMN types DROP DEFAULT;
@@ -0,0 +1,9 @@
+-- the system space holds the work items that have no space of their own
+INSERT INTO spaces (created_at, updated_at, id, name, description)
+    VALUES (now(), now(), '2e0698d8-753e-4cef-bb7c-f027634824a2', 'system.space', 'The space of work items created without a space')
+    ON CONFLICT (id) DO NOTHING;
+
+-- work items without an iteration or area did not get a space in 029-work-items-space.sql
+UPDATE work_items SET space_id = '2e0698d8-753e-4cef-bb7c-f027634824a2' WHERE space_id IS NULL;
+
+ALTER TABLE work_items ALTER COLUMN space_id SET NOT NULL;
@@ -3,11 +3,14 @@ package main
 // this file contains some paging related utility functions
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
@@ -114,6 +117,71 @@ func setPagingLinks(links *app.PagingLinks, path string, resultLen, offset, limi
 	links.Last = &last
 }
 
+// computePage returns the keyset page requested by the page[after] or page[before] parameter,
+// or nil if neither is given and offset based paging is to be used. An empty cursor denotes
+// the start (for page[after]) or the end (for page[before]) of the list.
+func computePage(afterParam *string, beforeParam *string, limitParam *int) (*gormsupport.Page, error) {
+	if afterParam == nil && beforeParam == nil {
+		return nil, nil
+	}
+	if afterParam != nil && beforeParam != nil {
+		return nil, errors.NewBadParameterError("page[before]", *beforeParam).Expected("no page[after] parameter")
+	}
+	_, limit := computePagingLimts(nil, limitParam)
+	page := gormsupport.Page{Limit: limit}
+	cursor := afterParam
+	if beforeParam != nil {
+		cursor = beforeParam
+		page.Before = true
+	}
+	if *cursor != "" {
+		id, err := base64.RawURLEncoding.DecodeString(*cursor)
+		if err != nil {
+			return nil, errors.NewBadParameterError("cursor", *cursor)
+		}
+		page.ID = string(id)
+	}
+	return &page, nil
+}
+
+func encodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// setCursorLinks sets the paging links for the given keyset page, ids are the IDs of the items on the page
+// and more tells whether there are more items beyond the page in paging direction.
+func setCursorLinks(links *app.PagingLinks, path string, page gormsupport.Page, ids []string, more bool, additionalQuery ...string) {
+	format := func(param string, id string) *string {
+		link := fmt.Sprintf("%s?%s=%s&page[limit]=%d", path, param, encodeCursor(id), page.Limit)
+		if len(additionalQuery) > 0 {
+			link += "&" + strings.Join(additionalQuery, "&")
+		}
+		return &link
+	}
+	// when paging forward, there is something before the page if we did not start at the beginning
+	// and vice versa. An empty page in the middle links to the end (or the start) of the list.
+	hasPrev, hasNext := page.ID != "", more
+	if page.Before {
+		hasPrev, hasNext = more, page.ID != ""
+	}
+	if hasPrev {
+		if len(ids) > 0 {
+			links.Prev = format("page[before]", ids[0])
+		} else {
+			links.Prev = format("page[before]", "")
+		}
+	}
+	if hasNext {
+		if len(ids) > 0 {
+			links.Next = format("page[after]", ids[len(ids)-1])
+		} else {
+			links.Next = format("page[after]", "")
+		}
+	}
+	links.First = format("page[after]", "")
+	links.Last = format("page[before]", "")
+}
+
 func buildAbsoluteURL(req *goa.RequestData) string {
 	return rest.AbsoluteURL(req, req.URL.Path)
 }
@@ -0,0 +1,7 @@
+// Package lang implements the textual query language used to filter entities, e.g.
+//
+//	system.state in ("open", "in progress") and (system.assignees = me or system.creator = me)
+//
+// Queries are parsed into criteria.Expression trees, so the language is independent of the
+// way the expressions are finally executed against the database.
+package lang
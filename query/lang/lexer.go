@@ -0,0 +1,243 @@
+package lang
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies the different kinds of tokens produced by the lexer
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenNumber
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+	tokenEquals
+	tokenNotEquals
+	tokenLessThan
+	tokenLessOrEquals
+	tokenGreaterThan
+	tokenGreaterOrEquals
+	tokenSubstring
+)
+
+var tokenNames = map[tokenKind]string{
+	tokenEOF:             "end of input",
+	tokenIdentifier:      "identifier",
+	tokenString:          "string",
+	tokenNumber:          "number",
+	tokenLeftParen:       "'('",
+	tokenRightParen:      "')'",
+	tokenComma:           "','",
+	tokenEquals:          "'='",
+	tokenNotEquals:       "'!='",
+	tokenLessThan:        "'<'",
+	tokenLessOrEquals:    "'<='",
+	tokenGreaterThan:     "'>'",
+	tokenGreaterOrEquals: "'>='",
+	tokenSubstring:       "'~'",
+}
+
+func (k tokenKind) String() string {
+	return tokenNames[k]
+}
+
+// Position is a location in the query text. Line and column are 1-based.
+type Position struct {
+	Line   int
+	Column int
+}
+
+type token struct {
+	kind tokenKind
+	// text holds the identifier or number as written, or the unescaped value of a string
+	text string
+	pos  Position
+}
+
+// is checks whether the token is the given keyword. Keywords are case insensitive.
+func (t token) is(keyword string) bool {
+	return t.kind == tokenIdentifier && strings.EqualFold(t.text, keyword)
+}
+
+// lexer splits the query text into tokens
+type lexer struct {
+	input []rune
+	index int
+	pos   Position
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: []rune(input), pos: Position{Line: 1, Column: 1}}
+}
+
+func (l *lexer) peekRune() (rune, bool) {
+	if l.index >= len(l.input) {
+		return 0, false
+	}
+	return l.input[l.index], true
+}
+
+func (l *lexer) nextRune() rune {
+	r := l.input[l.index]
+	l.index++
+	if r == '\n' {
+		l.pos.Line++
+		l.pos.Column = 1
+	} else {
+		l.pos.Column++
+	}
+	return r
+}
+
+func (l *lexer) skipWhitespace() {
+	for {
+		r, ok := l.peekRune()
+		if !ok || !unicode.IsSpace(r) {
+			return
+		}
+		l.nextRune()
+	}
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// next returns the next token or a *ParseError if the input contains an invalid token
+func (l *lexer) next() (token, error) {
+	l.skipWhitespace()
+	start := l.pos
+	r, ok := l.peekRune()
+	if !ok {
+		return token{kind: tokenEOF, pos: start}, nil
+	}
+	switch {
+	case r == '(':
+		l.nextRune()
+		return token{kind: tokenLeftParen, text: "(", pos: start}, nil
+	case r == ')':
+		l.nextRune()
+		return token{kind: tokenRightParen, text: ")", pos: start}, nil
+	case r == ',':
+		l.nextRune()
+		return token{kind: tokenComma, text: ",", pos: start}, nil
+	case r == '=':
+		l.nextRune()
+		return token{kind: tokenEquals, text: "=", pos: start}, nil
+	case r == '~':
+		l.nextRune()
+		return token{kind: tokenSubstring, text: "~", pos: start}, nil
+	case r == '!':
+		l.nextRune()
+		if next, ok := l.peekRune(); ok && next == '=' {
+			l.nextRune()
+			return token{kind: tokenNotEquals, text: "!=", pos: start}, nil
+		}
+		return token{}, newParseError(start, "unexpected character '!', did you mean '!='?")
+	case r == '<':
+		l.nextRune()
+		if next, ok := l.peekRune(); ok && next == '=' {
+			l.nextRune()
+			return token{kind: tokenLessOrEquals, text: "<=", pos: start}, nil
+		}
+		if next, ok := l.peekRune(); ok && next == '>' {
+			l.nextRune()
+			return token{kind: tokenNotEquals, text: "<>", pos: start}, nil
+		}
+		return token{kind: tokenLessThan, text: "<", pos: start}, nil
+	case r == '>':
+		l.nextRune()
+		if next, ok := l.peekRune(); ok && next == '=' {
+			l.nextRune()
+			return token{kind: tokenGreaterOrEquals, text: ">=", pos: start}, nil
+		}
+		return token{kind: tokenGreaterThan, text: ">", pos: start}, nil
+	case r == '"' || r == '\'':
+		return l.lexString(start)
+	case r == '-' || unicode.IsDigit(r):
+		return l.lexNumber(start)
+	case isIdentifierStart(r):
+		var text []rune
+		for {
+			r, ok := l.peekRune()
+			if !ok || !isIdentifierPart(r) {
+				break
+			}
+			text = append(text, l.nextRune())
+		}
+		return token{kind: tokenIdentifier, text: string(text), pos: start}, nil
+	}
+	return token{}, newParseError(start, "unexpected character %q", r)
+}
+
+func (l *lexer) lexString(start Position) (token, error) {
+	quote := l.nextRune()
+	var text []rune
+	for {
+		r, ok := l.peekRune()
+		if !ok {
+			return token{}, newParseError(start, "unterminated string")
+		}
+		l.nextRune()
+		switch r {
+		case quote:
+			return token{kind: tokenString, text: string(text), pos: start}, nil
+		case '\\':
+			escapePos := l.pos
+			escaped, ok := l.peekRune()
+			if !ok {
+				return token{}, newParseError(start, "unterminated string")
+			}
+			l.nextRune()
+			switch escaped {
+			case '\\', '"', '\'':
+				text = append(text, escaped)
+			case 'n':
+				text = append(text, '\n')
+			case 't':
+				text = append(text, '\t')
+			default:
+				escapePos.Column--
+				return token{}, newParseError(escapePos, "unknown escape sequence \\%c", escaped)
+			}
+		default:
+			text = append(text, r)
+		}
+	}
+}
+
+func (l *lexer) lexNumber(start Position) (token, error) {
+	var text []rune
+	if r, _ := l.peekRune(); r == '-' {
+		text = append(text, l.nextRune())
+	}
+	digits := 0
+	seenDot := false
+	for {
+		r, ok := l.peekRune()
+		if !ok {
+			break
+		}
+		if unicode.IsDigit(r) {
+			digits++
+		} else if r == '.' && !seenDot {
+			seenDot = true
+		} else {
+			break
+		}
+		text = append(text, l.nextRune())
+	}
+	if digits == 0 || text[len(text)-1] == '.' {
+		return token{}, newParseError(start, "malformed number %q", string(text))
+	}
+	return token{kind: tokenNumber, text: string(text), pos: start}, nil
+}
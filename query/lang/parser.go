@@ -0,0 +1,317 @@
+package lang
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/criteria"
+	query "github.com/almighty/almighty-core/query/simple"
+)
+
+// ParseError reports a syntax error in a query together with the position it occurred at
+type ParseError struct {
+	Position
+	Msg string
+}
+
+func newParseError(pos Position, format string, args ...interface{}) *ParseError {
+	return &ParseError{Position: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// VariableResolver returns the value of a named variable like "me" used on the right hand
+// side of a comparison. It returns an error if the variable is unknown or cannot be resolved.
+type VariableResolver func(name string) (interface{}, error)
+
+// Parse parses a query of the form
+//
+//	system.state in ("open", "in progress") and (system.assignees = me or not system.title = "foo")
+//
+// into an expression tree. The following grammar is supported, keywords are case insensitive:
+//
+//	query      = or
+//	or         = and { "or" and }
+//	and        = not { "and" not }
+//	not        = "not" not | "(" or ")" | comparison
+//	comparison = field ( op value | [ "not" ] "in" "(" value { "," value } ")" )
+//	op         = "=" | "!=" | "<>" | "<" | "<=" | ">" | ">="
+//	value      = string | number | "true" | "false" | variable
+//
+// The "~" operator matches fields that contain the given string, ignoring case.
+// Variables are resolved with the given resolver, which may be nil if no variables are supported.
+// For compatibility with older clients, the JSON form { "attribute1":value1,"attribute2":value2 }
+// is still accepted. Returns the expression "true" if the query is empty.
+func Parse(exp *string, resolver VariableResolver) (criteria.Expression, error) {
+	if exp == nil || len(strings.TrimSpace(*exp)) == 0 {
+		return criteria.Literal(true), nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(*exp), "{") {
+		return query.Parse(exp)
+	}
+	p := parser{lexer: newLexer(*exp), resolver: resolver}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.current.kind != tokenEOF {
+		return nil, p.unexpected("'and', 'or' or end of input")
+	}
+	return result, nil
+}
+
+// parser is a recursive descent parser with one token lookahead
+type parser struct {
+	lexer    *lexer
+	resolver VariableResolver
+	current  token
+}
+
+func (p *parser) advance() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.current = t
+	return nil
+}
+
+func (p *parser) unexpected(expected string) *ParseError {
+	found := p.current.kind.String()
+	if p.current.kind != tokenEOF {
+		found = fmt.Sprintf("%s %q", found, p.current.text)
+	}
+	return newParseError(p.current.pos, "expected %s but found %s", expected, found)
+}
+
+func (p *parser) expect(kind tokenKind) error {
+	if p.current.kind != kind {
+		return p.unexpected(kind.String())
+	}
+	return p.advance()
+}
+
+func (p *parser) parseOr() (criteria.Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.current.is("or") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = criteria.Or(left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (criteria.Expression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.current.is("and") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = criteria.And(left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (criteria.Expression, error) {
+	switch {
+	case p.current.is("not"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return criteria.Not(operand), nil
+	case p.current.kind == tokenLeftParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		result, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRightParen); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	return p.parseComparison()
+}
+
+func isKeyword(t token) bool {
+	for _, keyword := range []string{"and", "or", "not", "in", "is", "null", "true", "false"} {
+		if t.is(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseComparison() (criteria.Expression, error) {
+	if p.current.kind != tokenIdentifier || isKeyword(p.current) {
+		return nil, p.unexpected("field name, 'not' or '('")
+	}
+	field := criteria.Field(p.current.text)
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.current.is("not") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if !p.current.is("in") {
+			return nil, p.unexpected("'in'")
+		}
+		in, err := p.parseIn(field)
+		if err != nil {
+			return nil, err
+		}
+		return criteria.Not(in), nil
+	}
+	if p.current.is("in") {
+		return p.parseIn(field)
+	}
+	if p.current.is("is") {
+		return p.parseIsNull(field)
+	}
+
+	var constructor func(criteria.Expression, criteria.Expression) criteria.Expression
+	switch p.current.kind {
+	case tokenEquals:
+		constructor = criteria.Equals
+	case tokenNotEquals:
+		constructor = criteria.NotEquals
+	case tokenLessThan:
+		constructor = criteria.LessThan
+	case tokenLessOrEquals:
+		constructor = criteria.LessOrEquals
+	case tokenGreaterThan:
+		constructor = criteria.GreaterThan
+	case tokenGreaterOrEquals:
+		constructor = criteria.GreaterOrEquals
+	case tokenSubstring:
+		constructor = criteria.Substring
+	default:
+		return nil, p.unexpected("comparison operator, 'in' or 'is'")
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return constructor(field, criteria.Literal(value)), nil
+}
+
+// parseIn parses the value list of an "in" expression, the current token is the "in" keyword
+func (p *parser) parseIn(field criteria.Expression) (criteria.Expression, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenLeftParen); err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if p.current.kind != tokenComma {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(tokenRightParen); err != nil {
+		return nil, err
+	}
+	return criteria.In(field, criteria.Literal(values)), nil
+}
+
+// parseIsNull parses "is [not] null", the current token is the "is" keyword
+func (p *parser) parseIsNull(field criteria.Expression) (criteria.Expression, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	negated := p.current.is("not")
+	if negated {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.current.is("null") {
+		return nil, p.unexpected("'null'")
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if negated {
+		return criteria.Not(criteria.IsNull(field)), nil
+	}
+	return criteria.IsNull(field), nil
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	t := p.current
+	var value interface{}
+	switch {
+	case t.kind == tokenString:
+		value = t.text
+	case t.kind == tokenNumber:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			value = i
+		} else if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+			value = f
+		} else {
+			return nil, newParseError(t.pos, "malformed number %q", t.text)
+		}
+	case t.is("true"):
+		value = true
+	case t.is("false"):
+		value = false
+	case t.kind == tokenIdentifier && !isKeyword(t):
+		if p.resolver == nil {
+			return nil, newParseError(t.pos, "unknown variable '%s'", t.text)
+		}
+		resolved, err := p.resolver(t.text)
+		if err != nil {
+			return nil, newParseError(t.pos, "cannot resolve variable '%s': %s", t.text, err.Error())
+		}
+		value = resolved
+	case t.is("null"):
+		return nil, newParseError(t.pos, "null is not a value, use 'is null' or 'is not null' instead")
+	default:
+		return nil, p.unexpected("string, number, 'true', 'false' or variable")
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
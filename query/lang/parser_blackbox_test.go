@@ -0,0 +1,150 @@
+package lang_test
+
+import (
+	"fmt"
+	"testing"
+
+	c "github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/query/lang"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func me(name string) (interface{}, error) {
+	if name == "me" {
+		return "4b3f2b7c-c2e5-4a27-9d8e-8b4d6c1a5c0d", nil
+	}
+	return nil, fmt.Errorf("no such variable")
+}
+
+func parse(t *testing.T, query string) c.Expression {
+	result, err := lang.Parse(&query, me)
+	require.Nil(t, err, "parsing %s", query)
+	return result
+}
+
+func TestParseEmpty(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, c.Literal(true), parse(t, ""))
+	assert.Equal(t, c.Literal(true), parse(t, "  \n "))
+	result, err := lang.Parse(nil, nil)
+	require.Nil(t, err)
+	assert.Equal(t, c.Literal(true), result)
+}
+
+func TestParseComparisons(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, c.Equals(c.Field("system.title"), c.Literal("foo")), parse(t, `system.title = "foo"`))
+	assert.Equal(t, c.Equals(c.Field("system.title"), c.Literal("it's")), parse(t, `system.title = 'it\'s'`))
+	assert.Equal(t, c.NotEquals(c.Field("Type"), c.Literal("bug")), parse(t, `Type != "bug"`))
+	assert.Equal(t, c.NotEquals(c.Field("Type"), c.Literal("bug")), parse(t, `Type <> "bug"`))
+	assert.Equal(t, c.LessThan(c.Field("ID"), c.Literal(int64(10))), parse(t, `ID < 10`))
+	assert.Equal(t, c.LessOrEquals(c.Field("ID"), c.Literal(int64(-10))), parse(t, `ID <= -10`))
+	assert.Equal(t, c.GreaterThan(c.Field("estimate"), c.Literal(2.5)), parse(t, `estimate > 2.5`))
+	assert.Equal(t, c.GreaterOrEquals(c.Field("Version"), c.Literal(int64(3))), parse(t, `Version>=3`))
+	assert.Equal(t, c.Equals(c.Field("done"), c.Literal(true)), parse(t, `done = TRUE`))
+	assert.Equal(t, c.Equals(c.Field("done"), c.Literal(false)), parse(t, `done = false`))
+	assert.Equal(t, c.Substring(c.Field("system.title"), c.Literal("login")), parse(t, `system.title ~ "login"`))
+}
+
+func TestParseIsNull(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, c.IsNull(c.Field("system.iteration")), parse(t, `system.iteration is null`))
+	assert.Equal(t, c.Not(c.IsNull(c.Field("system.iteration"))), parse(t, `system.iteration IS NOT NULL`))
+}
+
+func TestParseVariables(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, c.Equals(c.Field("system.creator"), c.Literal("4b3f2b7c-c2e5-4a27-9d8e-8b4d6c1a5c0d")), parse(t, `system.creator = me`))
+
+	q := "system.creator = me"
+	_, err := lang.Parse(&q, nil)
+	require.NotNil(t, err)
+	assert.Equal(t, "line 1, column 18: unknown variable 'me'", err.Error())
+
+	q = "system.creator = you"
+	_, err = lang.Parse(&q, me)
+	require.NotNil(t, err)
+	assert.Equal(t, "line 1, column 18: cannot resolve variable 'you': no such variable", err.Error())
+}
+
+func TestParseIn(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, c.In(c.Field("system.state"), c.Literal([]interface{}{"open", "in progress"})), parse(t, `system.state in ("open","in progress")`))
+	assert.Equal(t, c.In(c.Field("ID"), c.Literal([]interface{}{int64(1)})), parse(t, `ID IN (1)`))
+	assert.Equal(t, c.Not(c.In(c.Field("ID"), c.Literal([]interface{}{int64(1), int64(2)}))), parse(t, `ID not in (1, 2)`))
+}
+
+func TestParseBooleanOperators(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	a := func() c.Expression { return c.Equals(c.Field("a"), c.Literal(int64(1))) }
+	b := func() c.Expression { return c.Equals(c.Field("b"), c.Literal(int64(2))) }
+	d := func() c.Expression { return c.Equals(c.Field("d"), c.Literal(int64(3))) }
+
+	// and binds tighter than or
+	assert.Equal(t, c.Or(a(), c.And(b(), d())), parse(t, `a = 1 or b = 2 and d = 3`))
+	assert.Equal(t, c.And(c.Or(a(), b()), d()), parse(t, `(a = 1 or b = 2) and d = 3`))
+	// operators are left associative
+	assert.Equal(t, c.And(c.And(a(), b()), d()), parse(t, `a = 1 and b = 2 and d = 3`))
+	// not binds tighter than and
+	assert.Equal(t, c.And(c.Not(a()), b()), parse(t, `not a = 1 and b = 2`))
+	assert.Equal(t, c.Not(c.And(a(), b())), parse(t, `NOT (a = 1 AND b = 2)`))
+	assert.Equal(t, c.Not(c.Not(a())), parse(t, `not not a = 1`))
+}
+
+func TestParseExample(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expected := c.And(
+		c.In(c.Field("system.state"), c.Literal([]interface{}{"open", "in progress"})),
+		c.Or(
+			c.Equals(c.Field("system.assignees"), c.Literal("4b3f2b7c-c2e5-4a27-9d8e-8b4d6c1a5c0d")),
+			c.Equals(c.Field("system.creator"), c.Literal("4b3f2b7c-c2e5-4a27-9d8e-8b4d6c1a5c0d"))))
+	assert.Equal(t, expected, parse(t, `system.state in ("open","in progress") and (system.assignees = me or system.creator = me)`))
+}
+
+func TestParseLegacyJSON(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, c.Equals(c.Field("system.state"), c.Literal("open")), parse(t, ` {"system.state": "open"}`))
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	tests := map[string]string{
+		`system.state`:                          `line 1, column 13: expected comparison operator, 'in' or 'is' but found end of input`,
+		`system.state = null`:                   `line 1, column 16: null is not a value, use 'is null' or 'is not null' instead`,
+		`system.state is not 5`:                 `line 1, column 21: expected 'null' but found number "5"`,
+		`system.state = `:                       `line 1, column 16: expected string, number, 'true', 'false' or variable but found end of input`,
+		`= "open"`:                              `line 1, column 1: expected field name, 'not' or '(' but found '=' "="`,
+		`a = 1 b = 2`:                           `line 1, column 7: expected 'and', 'or' or end of input but found identifier "b"`,
+		`(a = 1`:                                `line 1, column 7: expected ')' but found end of input`,
+		"a = 1 and\n  b = \"open":               `line 2, column 7: unterminated string`,
+		"a = 1 or\nb ! 2":                       `line 2, column 3: unexpected character '!', did you mean '!='?`,
+		`a in ()`:                               `line 1, column 7: expected string, number, 'true', 'false' or variable but found ')' ")"`,
+		`a in (1, 2`:                            `line 1, column 11: expected ')' but found end of input`,
+		`a not = 1`:                             `line 1, column 7: expected 'in' but found '=' "="`,
+		`a = 1.`:                                `line 1, column 5: malformed number "1."`,
+		`a = "\x"`:                              `line 1, column 6: unknown escape sequence \x`,
+		`a = 1 and and = 2`:                     `line 1, column 11: expected field name, 'not' or '(' but found identifier "and"`,
+		`a = 1 & b = 2`:                         `line 1, column 7: unexpected character '&'`,
+		`system.state in ("open") and (b = 1))`: `line 1, column 37: expected 'and', 'or' or end of input but found ')' ")"`,
+	}
+	for query, expected := range tests {
+		q := query
+		_, err := lang.Parse(&q, me)
+		if assert.NotNil(t, err, "expected error for %s", query) {
+			assert.Equal(t, expected, err.Error(), "wrong error for %s", query)
+			_, ok := err.(*lang.ParseError)
+			assert.True(t, ok, "expected a ParseError for %s", query)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// Package reference keeps track of the identities mentioned and the work items referenced in the
+// descriptions of work items and in comments.
+package reference
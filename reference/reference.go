@@ -0,0 +1,183 @@
+package reference
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// The types of the markup content holding references
+const (
+	// SourceWorkItem is the description of a work item, the source ID is the ID of the work item
+	SourceWorkItem = "workitem"
+	// SourceComment is a comment of a work item, the source ID is the ID of the comment
+	SourceComment = "comment"
+)
+
+// Source identifies the markup content holding references
+type Source struct {
+	Type string
+	ID   string
+	// WorkItemID is the work item the content belongs to: the described or the commented work item
+	WorkItemID uint64
+}
+
+// Reference is a mention of an identity or a reference to a work item in the description of a work item
+// or in a comment. Only the references to existing identities and work items are kept.
+type Reference struct {
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CreatedAt  time.Time
+	SourceType string
+	SourceID   string
+	WorkItemID uint64
+	// Kind is rendering.ReferenceMention or rendering.ReferenceWorkItem
+	Kind string
+	// Key is the username or the work item ID, as written in the content
+	Key string
+	// IdentityID is the mentioned identity
+	IdentityID *uuid.UUID `sql:"type:uuid"`
+	// TargetID is the referenced work item
+	TargetID *uint64
+}
+
+// TableName implements gorm.tabler
+func (r Reference) TableName() string {
+	return "markup_references"
+}
+
+// Repository describes interactions with the references of markup content
+type Repository interface {
+	Update(ctx context.Context, source Source, content, markup string) ([]*Reference, error)
+	Delete(ctx context.Context, source Source) error
+	List(ctx context.Context, sourceType string, sourceIDs ...string) ([]*Reference, error)
+	ListReferencing(ctx context.Context, workItemID uint64) ([]*Reference, error)
+	ListMentions(ctx context.Context, identityID uuid.UUID) ([]*Reference, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for references.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Update replaces the references of the given source with the ones of its given content. The mentions are
+// resolved by username and the work item references by ID, the unresolved ones are dropped.
+// returns InternalError
+func (r *GormRepository) Update(ctx context.Context, source Source, content, markup string) ([]*Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "reference", "update"}, time.Now())
+	if err := r.Delete(ctx, source); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	identities := account.NewIdentityRepository(r.db)
+	result := []*Reference{}
+	for _, ref := range rendering.ParseReferences(content, markup) {
+		reference := Reference{
+			ID:         uuid.NewV4(),
+			SourceType: source.Type,
+			SourceID:   source.ID,
+			WorkItemID: source.WorkItemID,
+			Kind:       ref.Kind,
+			Key:        ref.Key,
+		}
+		switch ref.Kind {
+		case rendering.ReferenceMention:
+			found, err := identities.Query(account.IdentityFilterByUsename(ref.Key))
+			if err != nil {
+				return nil, errors.NewInternalError(err.Error())
+			}
+			if len(found) == 0 {
+				continue
+			}
+			reference.IdentityID = &found[0].ID
+		case rendering.ReferenceWorkItem:
+			id, err := strconv.ParseUint(ref.Key, 10, 64)
+			if err != nil || id == source.WorkItemID {
+				// not a work item ID or a reference of the work item to itself
+				continue
+			}
+			var count int
+			if err := r.db.Table("work_items").Where("id = ? and deleted_at is null", id).Count(&count).Error; err != nil {
+				return nil, errors.NewInternalError(err.Error())
+			}
+			if count == 0 {
+				continue
+			}
+			reference.TargetID = &id
+		}
+		if err := r.db.Create(&reference).Error; err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"sourceType": source.Type,
+				"sourceID":   source.ID,
+				"err":        err,
+			}, "unable to create the reference")
+			return nil, errors.NewInternalError(err.Error())
+		}
+		result = append(result, &reference)
+	}
+	return result, nil
+}
+
+// Delete removes the references of the given source
+// returns InternalError
+func (r *GormRepository) Delete(ctx context.Context, source Source) error {
+	defer goa.MeasureSince([]string{"goa", "db", "reference", "delete"}, time.Now())
+	err := r.db.Where("source_type = ? and source_id = ?", source.Type, source.ID).Delete(&Reference{}).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// List returns the references of the given sources of the given type
+// returns InternalError
+func (r *GormRepository) List(ctx context.Context, sourceType string, sourceIDs ...string) ([]*Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "reference", "list"}, time.Now())
+	result := []*Reference{}
+	if len(sourceIDs) == 0 {
+		return result, nil
+	}
+	err := r.db.Where("source_type = ? and source_id in (?)", sourceType, sourceIDs).Order("created_at").Find(&result).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return result, nil
+}
+
+// ListReferencing returns the references to the given work item in the descriptions and comments of the
+// other work items, the "mentioned in" list of the work item. The references of deleted work items don't count.
+// returns InternalError
+func (r *GormRepository) ListReferencing(ctx context.Context, workItemID uint64) ([]*Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "reference", "list"}, time.Now())
+	return r.listOfLiveWorkItems(r.db.Where("markup_references.target_id = ?", workItemID))
+}
+
+// ListMentions returns the mentions of the given identity, newest first. The mentions in deleted work items
+// don't count.
+// returns InternalError
+func (r *GormRepository) ListMentions(ctx context.Context, identityID uuid.UUID) ([]*Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "reference", "list"}, time.Now())
+	return r.listOfLiveWorkItems(r.db.Where("markup_references.identity_id = ?", identityID))
+}
+
+func (r *GormRepository) listOfLiveWorkItems(db *gorm.DB) ([]*Reference, error) {
+	result := []*Reference{}
+	err := db.Select("markup_references.*").Joins("join work_items on work_items.id = markup_references.work_item_id and work_items.deleted_at is null").
+		Order("markup_references.created_at desc").Find(&result).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package reference_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/migration"
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/reference"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type referenceSuite struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunReferenceSuite(t *testing.T) {
+	suite.Run(t, &referenceSuite{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *referenceSuite) SetupSuite() {
+	s.DBTestSuite.SetupSuite()
+	if _, c := os.LookupEnv(resource.Database); c {
+		if err := models.Transactional(s.DB, func(tx *gorm.DB) error {
+			return migration.PopulateCommonTypes(context.Background(), tx, workitem.NewWorkItemTypeRepository(tx))
+		}); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+func (s *referenceSuite) SetupTest() {
+	resource.Require(s.T(), resource.Database)
+	s.clean = cleaner.DeleteCreatedEntities(s.DB)
+}
+
+func (s *referenceSuite) TearDownTest() {
+	s.clean()
+}
+
+func (s *referenceSuite) createWorkItem(description string) uint64 {
+	wi, err := workitem.NewWorkItemRepository(s.DB).Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemSpace:       space.SystemSpace.String(),
+		workitem.SystemTitle:       "Title",
+		workitem.SystemState:       workitem.SystemStateNew,
+		workitem.SystemDescription: rendering.NewMarkupContent(description, rendering.SystemMarkupMarkdown),
+	}, "xx")
+	require.Nil(s.T(), err)
+	id, err := strconv.ParseUint(wi.ID, 10, 64)
+	require.Nil(s.T(), err)
+	return id
+}
+
+func (s *referenceSuite) TestReferences() {
+	ctx := context.Background()
+	identity := account.Identity{Username: "mentioned-" + uuid.NewV4().String(), Provider: account.KeycloakIDP}
+	require.Nil(s.T(), account.NewIdentityRepository(s.DB).Create(ctx, &identity))
+	repo := reference.NewRepository(s.DB)
+
+	described := s.createWorkItem("no references in `@" + identity.Username + "`")
+	referencing := s.createWorkItem(fmt.Sprintf("duplicate of #%d, cc @%s @unknown-user #999999999", described, identity.Username))
+
+	// when
+	refs, err := repo.List(ctx, reference.SourceWorkItem, strconv.FormatUint(described, 10), strconv.FormatUint(referencing, 10))
+	// then only the resolved references are kept
+	require.Nil(s.T(), err)
+	require.Len(s.T(), refs, 2)
+	assert.Equal(s.T(), strconv.FormatUint(referencing, 10), refs[0].SourceID)
+	assert.Equal(s.T(), rendering.ReferenceWorkItem, refs[0].Kind)
+	assert.Equal(s.T(), described, *refs[0].TargetID)
+	assert.Equal(s.T(), rendering.ReferenceMention, refs[1].Kind)
+	assert.Equal(s.T(), identity.ID, *refs[1].IdentityID)
+
+	// a comment mentioning the identity
+	c := comment.Comment{ParentID: strconv.FormatUint(described, 10), Body: "@" + identity.Username + " please review", Markup: rendering.SystemMarkupPlainText, CreatedBy: uuid.NewV4()}
+	require.Nil(s.T(), comment.NewCommentRepository(s.DB).Create(ctx, &c))
+	mentions, err := repo.ListMentions(ctx, identity.ID)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), mentions, 2)
+	assert.Equal(s.T(), reference.SourceComment, mentions[0].SourceType)
+	assert.Equal(s.T(), described, mentions[0].WorkItemID)
+
+	backlinks, err := repo.ListReferencing(ctx, described)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), backlinks, 1)
+	assert.Equal(s.T(), referencing, backlinks[0].WorkItemID)
+
+	// edits replace the references
+	c.Body = "never mind"
+	_, err = comment.NewCommentRepository(s.DB).Save(ctx, &c)
+	require.Nil(s.T(), err)
+	refs, err = repo.List(ctx, reference.SourceComment, c.ID.String())
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), refs)
+
+	// the references of deleted work items don't count
+	require.Nil(s.T(), workitem.NewWorkItemRepository(s.DB).Delete(ctx, strconv.FormatUint(referencing, 10), "xx"))
+	backlinks, err = repo.ListReferencing(ctx, described)
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), backlinks)
+}
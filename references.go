@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/reference"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// CommentIncludeReferences links the mentions and work item references of the rendered body of a comment
+// to the identities and work items recorded for them, see reference.Repository
+func CommentIncludeReferences(refs []*reference.Reference) CommentConvertFunc {
+	return func(request *goa.RequestData, comment *comment.Comment, data *app.Comment) {
+		if data.Attributes == nil || data.Attributes.BodyRendered == nil {
+			return
+		}
+		rendered := rendering.LinkReferences(*data.Attributes.BodyRendered, referenceLinks(request, refs, reference.SourceComment, comment.ID.String()))
+		data.Attributes.BodyRendered = &rendered
+	}
+}
+
+// WorkItemIncludeReferences links the mentions and work item references of the rendered description of a
+// work item to the identities and work items recorded for them, see reference.Repository
+func WorkItemIncludeReferences(refs []*reference.Reference) WorkItemConvertFunc {
+	return func(request *goa.RequestData, wi *app.WorkItem, data *app.WorkItem2) {
+		rendered, ok := data.Attributes[workitem.SystemDescriptionRendered].(string)
+		if !ok {
+			return
+		}
+		data.Attributes[workitem.SystemDescriptionRendered] = rendering.LinkReferences(rendered, referenceLinks(request, refs, reference.SourceWorkItem, wi.ID))
+	}
+}
+
+// referenceLinks returns the URLs of the identities and work items the references of the given source point to
+func referenceLinks(request *goa.RequestData, refs []*reference.Reference, sourceType, sourceID string) func(rendering.Reference) string {
+	return func(ref rendering.Reference) string {
+		for _, r := range refs {
+			if r.SourceType != sourceType || r.SourceID != sourceID || r.Kind != ref.Kind || r.Key != ref.Key {
+				continue
+			}
+			if r.IdentityID != nil {
+				return rest.AbsoluteURL(request, app.UsersHref(r.IdentityID.String()))
+			}
+			if r.TargetID != nil {
+				return rest.AbsoluteURL(request, app.WorkitemHref(strconv.FormatUint(*r.TargetID, 10)))
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,187 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/log"
+	"github.com/pkg/errors"
+)
+
+// bugzillaPageSize is the number of bugs fetched per request
+const bugzillaPageSize = 20
+
+// bugzillaFetcher provides bug listing
+type bugzillaFetcher interface {
+	// searchBugs returns the bugs matching the parameters of a Bugzilla search
+	searchBugs(params url.Values) ([]map[string]interface{}, error)
+	// getComments returns the comments of the bug with the given id, its description first
+	getComments(id string) ([]bugzillaComment, error)
+}
+
+// bugzillaComment is a comment on a Bugzilla bug
+type bugzillaComment struct {
+	Text string `json:"text"`
+}
+
+// BugzillaTracker represents the Bugzilla tracker provider. The query holds the parameters of a search of the
+// Bugzilla REST API: either fields, e.g. "product=Fedora&component=kernel&status=NEW", or a saved search,
+// e.g. "savedsearch=kernel%20bugs".
+// The bugs fetched get the link to the bug as "url" and the text of their first comment as "description".
+type BugzillaTracker struct {
+	URL   string
+	Query string
+	// Since restricts the search to the bugs changed since then
+	Since *time.Time
+	err   error
+}
+
+// bugzillaBugFetcher fetch bugs from the REST API of a Bugzilla instance
+type bugzillaBugFetcher struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+// get decodes the response of the REST API to the given resource
+func (f *bugzillaBugFetcher) get(resource string, params url.Values, result interface{}) error {
+	u := fmt.Sprintf("%s/rest/%s", strings.TrimSuffix(f.baseURL, "/"), resource)
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if f.apiKey != "" {
+		req.Header.Set("X-Bugzilla-Api-Key", f.apiKey)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		decoder.Decode(&failure)
+		return errors.Errorf("getting %s failed with status %s: %s", resource, resp.Status, failure.Message)
+	}
+	return errors.WithStack(decoder.Decode(result))
+}
+
+// searchBugs lists the bugs matching the search
+func (f *bugzillaBugFetcher) searchBugs(params url.Values) ([]map[string]interface{}, error) {
+	var result struct {
+		Bugs []map[string]interface{} `json:"bugs"`
+	}
+	if err := f.get("bug", params, &result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return result.Bugs, nil
+}
+
+// getComments lists the comments of a bug
+func (f *bugzillaBugFetcher) getComments(id string) ([]bugzillaComment, error) {
+	var result struct {
+		Bugs map[string]struct {
+			Comments []bugzillaComment `json:"comments"`
+		} `json:"bugs"`
+	}
+	if err := f.get("bug/"+url.QueryEscape(id)+"/comment", nil, &result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return result.Bugs[id].Comments, nil
+}
+
+// Fetch tracker items from Bugzilla
+func (b *BugzillaTracker) Fetch() chan TrackerItemContent {
+	f := bugzillaBugFetcher{
+		client:  &http.Client{Timeout: time.Minute},
+		baseURL: b.URL,
+		apiKey:  configuration.GetBugzillaAPIKey(),
+	}
+	return b.fetch(&f)
+}
+
+// Err returns the error which cut the fetch short, once its channel is closed
+func (b *BugzillaTracker) Err() error {
+	return b.err
+}
+
+func (b *BugzillaTracker) fetch(f bugzillaFetcher) chan TrackerItemContent {
+	item := make(chan TrackerItemContent)
+	go func() {
+		defer close(item)
+		params, err := url.ParseQuery(strings.TrimSpace(b.Query))
+		if err != nil {
+			b.err = errors.Wrapf(err, "invalid Bugzilla search %q", b.Query)
+			return
+		}
+		// the id orders the bugs changed in the same second, changeddate has no fractions of seconds
+		params.Set("order", "changeddate,bug_id")
+		params.Set("limit", strconv.Itoa(bugzillaPageSize))
+		if b.Since != nil {
+			params.Set("last_change_time", b.Since.UTC().Format(time.RFC3339))
+		}
+		for offset := 0; ; offset += bugzillaPageSize {
+			params.Set("offset", strconv.Itoa(offset))
+			bugs, err := f.searchBugs(params)
+			if err != nil {
+				b.fail(err, "unable to search Bugzilla bugs")
+				return
+			}
+			for _, bug := range bugs {
+				id := fmt.Sprint(bug["id"])
+				comments, err := f.getComments(id)
+				if err != nil {
+					b.fail(err, "unable to get the comments of Bugzilla bug "+id)
+					return
+				}
+				if len(comments) > 0 {
+					bug[BugzillaDescription] = comments[0].Text
+				}
+				bug[BugzillaID] = fmt.Sprintf("%s/show_bug.cgi?id=%s", strings.TrimSuffix(b.URL, "/"), id)
+				remoteID, _ := json.Marshal(bug[BugzillaID])
+				content, _ := json.Marshal(bug)
+				item <- TrackerItemContent{ID: string(remoteID), Content: content, UpdatedAt: bugzillaChanged(bug)}
+			}
+			if len(bugs) < bugzillaPageSize {
+				return
+			}
+		}
+	}()
+	return item
+}
+
+// fail logs the error which cuts the fetch short and keeps it
+func (b *BugzillaTracker) fail(err error, message string) {
+	log.Error(nil, map[string]interface{}{
+		"query": b.Query,
+		"err":   err,
+	}, message)
+	b.err = errors.Wrap(err, message)
+}
+
+// bugzillaChanged returns the time the given bug was last changed, nil if it is unknown
+func bugzillaChanged(bug map[string]interface{}) *time.Time {
+	changed, ok := bug["last_change_time"].(string)
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, changed)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,170 @@
+package remoteworkitem
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBugzillaBugFetcher serves the canned bugs one per page
+type fakeBugzillaBugFetcher struct {
+	bugs   []map[string]interface{}
+	params []url.Values
+}
+
+func newFakeBugzillaBugFetcher(t *testing.T) *fakeBugzillaBugFetcher {
+	content, err := ioutil.ReadFile("../test/data/bugzilla_bugs.json")
+	require.Nil(t, err)
+	var result struct {
+		Bugs []map[string]interface{} `json:"bugs"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	require.Nil(t, decoder.Decode(&result))
+	return &fakeBugzillaBugFetcher{bugs: result.Bugs}
+}
+
+func (f *fakeBugzillaBugFetcher) searchBugs(params url.Values) ([]map[string]interface{}, error) {
+	copied := url.Values{}
+	for k, v := range params {
+		copied[k] = v
+	}
+	f.params = append(f.params, copied)
+	if params.Get("offset") != "0" {
+		return nil, nil
+	}
+	return f.bugs, nil
+}
+
+func (f *fakeBugzillaBugFetcher) getComments(id string) ([]bugzillaComment, error) {
+	return []bugzillaComment{{Text: "description of " + id}, {Text: "a comment"}}, nil
+}
+
+func TestBugzillaFetch(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := newFakeBugzillaBugFetcher(t)
+	since := time.Date(2016, 11, 1, 0, 0, 0, 0, time.UTC)
+	b := BugzillaTracker{URL: "https://bugzilla.redhat.com/", Query: "product=Fedora&component=kernel", Since: &since}
+	var items []TrackerItemContent
+	for i := range b.fetch(f) {
+		items = append(items, i)
+	}
+	require.Nil(t, b.Err())
+	require.Len(t, items, 2)
+	assert.Equal(t, `"https://bugzilla.redhat.com/show_bug.cgi?id=1391027"`, items[0].ID)
+	require.NotNil(t, items[0].UpdatedAt)
+	assert.Equal(t, time.Date(2016, 11, 3, 10, 0, 0, 0, time.UTC), items[0].UpdatedAt.UTC())
+	var bug map[string]interface{}
+	require.Nil(t, json.Unmarshal(items[0].Content, &bug))
+	assert.Equal(t, "description of 1391027", bug[BugzillaDescription])
+	assert.Equal(t, "https://bugzilla.redhat.com/show_bug.cgi?id=1391027", bug[BugzillaID])
+	assert.Equal(t, "https://bugs.kde.org/show_bug.cgi?id=372001", bug["url"])
+	// a page smaller than the page size is the last one
+	require.Len(t, f.params, 1)
+	assert.Equal(t, "Fedora", f.params[0].Get("product"))
+	assert.Equal(t, "changeddate,bug_id", f.params[0].Get("order"))
+	assert.Equal(t, "2016-11-01T00:00:00Z", f.params[0].Get("last_change_time"))
+}
+
+func TestBugzillaBugFetcher(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	bugs, err := ioutil.ReadFile("../test/data/bugzilla_bugs.json")
+	require.Nil(t, err)
+	comments, err := ioutil.ReadFile("../test/data/bugzilla_comments.json")
+	require.Nil(t, err)
+	var requested []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r)
+		switch r.URL.Path {
+		case "/rest/bug":
+			w.Write(bugs)
+		case "/rest/bug/1391027/comment":
+			w.Write(comments)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":true,"code":101,"message":"Bug #1 does not exist."}`))
+		}
+	}))
+	defer server.Close()
+	f := bugzillaBugFetcher{client: http.DefaultClient, baseURL: server.URL, apiKey: "secret"}
+
+	result, err := f.searchBugs(url.Values{"savedsearch": {"kernel bugs"}})
+	require.Nil(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "1391027", (result[0]["id"]).(json.Number).String())
+	assert.Equal(t, "kernel bugs", requested[0].URL.Query().Get("savedsearch"))
+	assert.Equal(t, "secret", requested[0].Header.Get("X-Bugzilla-Api-Key"))
+
+	description, err := f.getComments("1391027")
+	require.Nil(t, err)
+	require.Len(t, description, 2)
+	assert.Equal(t, "Description of problem:\nThe laptop panics when resuming from suspend.", description[0].Text)
+
+	_, err = f.getComments("1")
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Bug #1 does not exist.")
+}
+
+type bugzillaResolution string
+
+func (r bugzillaResolution) Get(field AttributeExpression) interface{} {
+	if field == BugzillaResolution {
+		return string(r)
+	}
+	return nil
+}
+
+func TestBugzillaStateConverter(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	data := []struct {
+		status     string
+		resolution string
+		state      interface{}
+	}{
+		{"NEW", "", workitem.SystemStateNew},
+		{"ASSIGNED", "", workitem.SystemStateInProgress},
+		{"REOPENED", "", workitem.SystemStateOpen},
+		{"ON_QA", "", workitem.SystemStateResolved},
+		{"RESOLVED", "FIXED", workitem.SystemStateResolved},
+		{"RESOLVED", "WONTFIX", workitem.SystemStateClosed},
+		{"VERIFIED", "DUPLICATE", workitem.SystemStateClosed},
+		{"CLOSED", "FIXED", workitem.SystemStateClosed},
+		{"NEEDINFO", "", "NEEDINFO"},
+	}
+	for _, d := range data {
+		state, err := BugzillaStateConverter{}.Convert(d.status, bugzillaResolution(d.resolution))
+		require.Nil(t, err)
+		assert.Equal(t, d.state, state, "%s %s", d.status, d.resolution)
+	}
+}
+
+func TestBugzillaMapping(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	b := BugzillaTracker{URL: "https://bugzilla.redhat.com", Query: ""}
+	var items []TrackerItemContent
+	for i := range b.fetch(newFakeBugzillaBugFetcher(t)) {
+		items = append(items, i)
+	}
+	require.Len(t, items, 2)
+	accessor, err := NewBugzillaRemoteWorkItem(TrackerItem{Item: string(items[0].Content)})
+	require.Nil(t, err)
+	wi, err := Map(accessor, WorkItemKeyMaps[ProviderBugzilla])
+	require.Nil(t, err)
+	assert.Equal(t, "kernel panic on resume from suspend", wi.Fields[workitem.SystemTitle])
+	assert.Equal(t, rendering.NewMarkupContent("description of 1391027", rendering.SystemMarkupPlainText), wi.Fields[workitem.SystemDescription])
+	assert.Equal(t, workitem.SystemStateInProgress, wi.Fields[workitem.SystemState])
+	assert.Equal(t, "https://bugzilla.redhat.com/show_bug.cgi?id=1391027", wi.Fields[workitem.SystemRemoteItemID])
+	assert.Equal(t, "aslak@redhat.com", wi.Fields[workitem.SystemCreator])
+	assert.Equal(t, []interface{}{"sbose@redhat.com"}, wi.Fields[workitem.SystemAssignees])
+}
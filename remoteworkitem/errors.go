@@ -20,6 +20,11 @@ type VersionConflictError struct {
 	simpleError
 }
 
+// RunningError means that an import of the tracker query is already in progress
+type RunningError struct {
+	simpleError
+}
+
 // BadParameterError means that a parameter was not as required
 type BadParameterError struct {
 	parameter string
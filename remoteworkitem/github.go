@@ -2,23 +2,33 @@ package remoteworkitem
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/almighty/almighty-core/configuration"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/rendering"
 
 	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
-// githubFetcher provides issue listing
+// githubFetcher provides issue and comment listing
 type githubFetcher interface {
 	listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error)
+	listComments(owner, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error)
 }
 
+// githubTimeQualifier is the layout of the times in qualifiers of Github searches
+const githubTimeQualifier = "2006-01-02T15:04:05Z"
+
 // GithubTracker represents the Github tracker provider
 type GithubTracker struct {
 	URL   string
 	Query string
+	// Since restricts the search to the issues updated since then
+	Since *time.Time
+	err   error
 }
 
 // GithubIssueFetcher fetch issues from github
@@ -31,6 +41,11 @@ func (f *githubIssueFetcher) listIssues(query string, opts *github.SearchOptions
 	return f.client.Search.Issues(query, opts)
 }
 
+// listComments lists the comments on an issue
+func (f *githubIssueFetcher) listComments(owner, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	return f.client.Issues.ListComments(owner, repo, number, opts)
+}
+
 // Fetch tracker items from Github
 func (g *GithubTracker) Fetch() chan TrackerItemContent {
 	f := githubIssueFetcher{}
@@ -42,30 +57,57 @@ func (g *GithubTracker) Fetch() chan TrackerItemContent {
 	return g.fetch(&f)
 }
 
+// Err returns the error which cut the fetch short, once its channel is closed
+func (g *GithubTracker) Err() error {
+	return g.err
+}
+
 func (g *GithubTracker) fetch(f githubFetcher) chan TrackerItemContent {
 	item := make(chan TrackerItemContent)
 	go func() {
+		// Github sorts the search results by the update time of the issues, 20 per page
 		opts := &github.SearchOptions{
+			Sort:  "updated",
+			Order: "asc",
 			ListOptions: github.ListOptions{
 				PerPage: 20,
 			},
 		}
+		query := g.Query
+		if g.Since != nil {
+			query += " updated:>=" + g.Since.UTC().Format(githubTimeQualifier)
+		}
 		for {
-			result, response, err := f.listIssues(g.Query, opts)
+			result, response, err := f.listIssues(query, opts)
 			if _, ok := err.(*github.RateLimitError); ok {
 				log.Warn(nil, map[string]interface{}{
-					"query": g.Query,
+					"query": query,
 					"opts":  opts,
 				}, "reached rate limit when listing Github issues")
+				g.err = errors.Wrap(err, "import cut off by the rate limit, it resumes in the next run")
+				break
+			}
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"query": query,
+					"err":   err,
+				}, "unable to list Github issues")
+				g.err = errors.Wrap(err, "unable to list Github issues")
 				break
 			}
 			issues := result.Issues
 			for _, l := range issues {
+				comments, err := fetchGithubComments(f, l)
+				if err != nil {
+					// the issue is imported with its comments in the next run
+					g.err = err
+					break
+				}
 				id, _ := json.Marshal(l.URL)
 				content, _ := json.Marshal(l)
-				item <- TrackerItemContent{ID: string(id), Content: content}
+				item <- TrackerItemContent{ID: string(id), Content: content, UpdatedAt: l.UpdatedAt, Comments: comments}
 			}
-			if response.NextPage == 0 {
+			if g.err != nil || response.NextPage == 0 {
 				break
 			}
 			opts.ListOptions.Page = response.NextPage
@@ -74,3 +116,42 @@ func (g *GithubTracker) fetch(f githubFetcher) chan TrackerItemContent {
 	}()
 	return item
 }
+
+// fetchGithubComments returns the comments on the given issue, oldest first
+func fetchGithubComments(f githubFetcher, issue github.Issue) ([]RemoteCommentContent, error) {
+	if issue.Comments == nil || *issue.Comments == 0 || issue.URL == nil {
+		return nil, nil
+	}
+	owner, repo, number, err := parseGithubIssueURL(*issue.URL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var comments []RemoteCommentContent
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, response, err := f.listComments(owner, repo, number, opts)
+		if _, ok := err.(*github.RateLimitError); ok {
+			log.Warn(nil, map[string]interface{}{
+				"issue": *issue.URL,
+			}, "reached rate limit when listing Github comments")
+			return nil, errors.Wrap(err, "import cut off by the rate limit, it resumes in the next run")
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to list the comments on %s", *issue.URL)
+		}
+		for _, c := range page {
+			if c.ID == nil || c.Body == nil {
+				continue
+			}
+			comment := RemoteCommentContent{ID: *c.ID, Body: *c.Body, Markup: rendering.SystemMarkupMarkdown}
+			if c.User != nil && c.User.Login != nil {
+				comment.Author = *c.User.Login
+			}
+			comments = append(comments, comment)
+		}
+		if response.NextPage == 0 {
+			return comments, nil
+		}
+		opts.ListOptions.Page = response.NextPage
+	}
+}
@@ -0,0 +1,422 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/google/go-github/github"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+)
+
+// The two-way synchronized fields of Github issues
+const (
+	githubSyncTitle    = "title"
+	githubSyncState    = "state"
+	githubSyncAssignee = "assignee"
+)
+
+// githubSyncedFields maps the two-way synchronized fields of Github issues to the work item fields
+var githubSyncedFields = map[string]string{
+	githubSyncTitle:    workitem.SystemTitle,
+	githubSyncState:    workitem.SystemState,
+	githubSyncAssignee: workitem.SystemAssignees,
+}
+
+// githubIssueURL matches the API URL of a Github issue, which is the remote item ID of imported issues
+var githubIssueURL = regexp.MustCompile(`/repos/([^/]+)/([^/]+)/issues/(\d+)$`)
+
+// githubIssueEditor provides the changes pushed back to Github issues
+type githubIssueEditor interface {
+	getIssue(owner, repo string, number int) (*github.Issue, *github.Response, error)
+	editIssue(owner, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+	createComment(owner, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error)
+	editComment(owner, repo string, id int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error)
+}
+
+func (f *githubIssueFetcher) getIssue(owner, repo string, number int) (*github.Issue, *github.Response, error) {
+	return f.client.Issues.Get(owner, repo, number)
+}
+
+func (f *githubIssueFetcher) editIssue(owner, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return f.client.Issues.Edit(owner, repo, number, issue)
+}
+
+func (f *githubIssueFetcher) createComment(owner, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	return f.client.Issues.CreateComment(owner, repo, number, comment)
+}
+
+func (f *githubIssueFetcher) editComment(owner, repo string, id int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	return f.client.Issues.EditComment(owner, repo, id, comment)
+}
+
+// syncPlan is the outcome of the three-way comparison of synchronized fields
+type syncPlan struct {
+	// the fields only changed locally, with their local values
+	Push map[string]interface{}
+	// the fields only changed remotely or never synchronized, with their remote values
+	Pull map[string]interface{}
+	// the fields with the same value on both sides
+	Agreed map[string]interface{}
+	// the fields changed differently on both sides, mapped to their local and remote values
+	Conflicts workitem.Fields
+}
+
+// planSync compares the local and remote values of the synchronized fields with their values as of the last sync
+func planSync(base, local, remote map[string]interface{}) syncPlan {
+	plan := syncPlan{
+		Push:      map[string]interface{}{},
+		Pull:      map[string]interface{}{},
+		Agreed:    map[string]interface{}{},
+		Conflicts: workitem.Fields{},
+	}
+	for field := range githubSyncedFields {
+		l, r := local[field], remote[field]
+		b, synced := base[field]
+		localChanged := !reflect.DeepEqual(l, b)
+		remoteChanged := !reflect.DeepEqual(r, b)
+		switch {
+		case reflect.DeepEqual(l, r):
+			plan.Agreed[field] = r
+		case !synced || (remoteChanged && !localChanged):
+			plan.Pull[field] = r
+		case localChanged && !remoteChanged:
+			plan.Push[field] = l
+		default:
+			plan.Conflicts[field] = map[string]interface{}{"local": l, "remote": r}
+		}
+	}
+	return plan
+}
+
+// apply records the synchronized values and the conflicts of the plan in the tracker item
+func (p syncPlan) apply(ti *TrackerItem, synced ...map[string]interface{}) {
+	if ti.SyncedFields == nil {
+		ti.SyncedFields = workitem.Fields{}
+	}
+	for _, fields := range append(synced, p.Agreed) {
+		for field, value := range fields {
+			ti.SyncedFields[field] = value
+		}
+	}
+	ti.Conflicts = nil
+	if len(p.Conflicts) > 0 {
+		ti.Conflicts = p.Conflicts
+	}
+}
+
+// githubRemoteFields returns the synchronized fields of the given flattened Github issue
+func githubRemoteFields(issue map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		githubSyncTitle:    issue[GithubTitle],
+		githubSyncState:    issue[GithubState],
+		githubSyncAssignee: issue[GithubAssignee],
+	}
+}
+
+// githubLocalFields returns the values the synchronized fields of a Github issue get from the given work item.
+// Work item states without Github equivalent map to "open", assigned identities to their user names.
+func githubLocalFields(ctx context.Context, db *gorm.DB, wi app.WorkItem) map[string]interface{} {
+	state := "open"
+	switch wi.Fields[workitem.SystemState] {
+	case workitem.SystemStateResolved, workitem.SystemStateClosed:
+		state = "closed"
+	}
+	var assignee interface{}
+	if assignees, ok := wi.Fields[workitem.SystemAssignees].([]interface{}); ok && len(assignees) > 0 {
+		assignee = assignees[0]
+		if login, ok := assignee.(string); ok {
+			if id, err := uuid.FromString(login); err == nil {
+				if identity, err := account.NewIdentityRepository(db).Load(ctx, id); err == nil {
+					assignee = identity.Username
+				}
+			}
+		}
+	}
+	return map[string]interface{}{
+		githubSyncTitle:    wi.Fields[workitem.SystemTitle],
+		githubSyncState:    state,
+		githubSyncAssignee: assignee,
+	}
+}
+
+// parseGithubIssueURL returns the owner, repository and number of the Github issue with the given API URL
+func parseGithubIssueURL(url string) (string, string, int, error) {
+	match := githubIssueURL.FindStringSubmatch(url)
+	if match == nil {
+		return "", "", 0, errors.Errorf("not the URL of a Github issue: %s", url)
+	}
+	number, err := strconv.Atoi(match[3])
+	if err != nil {
+		return "", "", 0, errors.WithStack(err)
+	}
+	return match[1], match[2], number, nil
+}
+
+// GithubSyncer pushes the changes of the state, title, assignee and comments of work items imported from
+// Github back to the issues. Fields changed on both sides since the last sync are left alone and
+// recorded as conflicts of the tracker item.
+type GithubSyncer struct {
+	db     *gorm.DB
+	editor githubIssueEditor
+	cron   *cron.Cron
+}
+
+// NewGithubSyncer creates a GithubSyncer authenticated with the configured Github token
+func NewGithubSyncer(db *gorm.DB) *GithubSyncer {
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: configuration.GetGithubAuthToken()},
+	)
+	tc := oauth2.NewClient(oauth2.NoContext, ts)
+	return newGithubSyncer(db, &githubIssueFetcher{client: github.NewClient(tc)})
+}
+
+func newGithubSyncer(db *gorm.DB, editor githubIssueEditor) *GithubSyncer {
+	return &GithubSyncer{db: db, editor: editor, cron: cron.New()}
+}
+
+// Start pushes the local changes periodically with the given cron schedule
+func (s *GithubSyncer) Start(schedule string) error {
+	if err := s.cron.AddFunc(schedule, func() {
+		if err := s.Run(context.Background()); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+			}, "Github sync failed")
+		}
+	}); err != nil {
+		return errors.WithStack(err)
+	}
+	s.cron.Start()
+	return nil
+}
+
+// Stop stops pushing local changes
+// This should be called only from main
+func (s *GithubSyncer) Stop() {
+	s.cron.Stop()
+}
+
+// githubLocalChanges selects the tracker items (ti) whose work items (wi) were changed or commented on since the
+// last sync. The import moves the sync time past its own writes, so only local changes match.
+const githubLocalChanges = "wi.updated_at > ti.synced_at or exists (select 1 from comments c where c.parent_id = wi.id::text and c.updated_at > ti.synced_at)"
+
+// githubImportedItems joins the tracker items of Github issues (ti) with their work items (wi)
+func githubImportedItems(db *gorm.DB) *gorm.DB {
+	return db.Table("tracker_items ti").
+		Joins("join trackers t on t.id = ti.tracker_id and t.deleted_at is null").
+		Joins("join work_items wi on wi.fields->'"+workitem.SystemRemoteItemID+"' = ti.remote_item_id::jsonb and wi.deleted_at is null").
+		Where("t.type = ? and ti.deleted_at is null", ProviderGithub)
+}
+
+// githubChangesPending tells whether the work item imported from the given Github issue has local changes which
+// were not pushed yet
+func githubChangesPending(db *gorm.DB, tID int, remoteID string) (bool, error) {
+	var count int
+	err := githubImportedItems(db).Where("ti.tracker_id = ? and ti.remote_item_id = ?", tID, remoteID).
+		Where(githubLocalChanges).Count(&count).Error
+	return count > 0, errors.WithStack(err)
+}
+
+// markGithubSynced sets the sync time of the given Github issue to the last write of its work item and comments,
+// which the import just made
+func markGithubSynced(db *gorm.DB, tID int, remoteID string) error {
+	return errors.WithStack(db.Exec(`UPDATE tracker_items ti SET synced_at = greatest(wi.updated_at,
+			(select max(c.updated_at) from comments c where c.parent_id = wi.id::text))
+		FROM work_items wi
+		WHERE ti.tracker_id = ? AND ti.remote_item_id = ? AND wi.deleted_at IS NULL
+			AND wi.fields->'`+workitem.SystemRemoteItemID+`' = ti.remote_item_id::jsonb`, tID, remoteID).Error)
+}
+
+// Run pushes the changes made to imported work items and their comments since the last sync. Every item is
+// pushed on its own, an item failing to sync is logged and retried in the next run.
+func (s *GithubSyncer) Run(ctx context.Context) error {
+	var items []TrackerItem
+	err := githubImportedItems(s.db).Select("ti.*").Where("ti.synced_at is null or " + githubLocalChanges).
+		Order("ti.id").Find(&items).Error
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, ti := range items {
+		if err := s.push(ctx, ti); err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"remoteItemID": ti.RemoteItemID,
+				"err":          err,
+			}, "unable to push the changes to Github")
+		}
+	}
+	return nil
+}
+
+// push sends the local changes of the work item imported as the given tracker item to Github. The calls to
+// Github are made outside of any transaction, their outcome is recorded in one afterwards.
+func (s *GithubSyncer) push(ctx context.Context, ti TrackerItem) error {
+	// changes made while pushing are pushed in the next run
+	syncedAt := time.Now()
+	var remoteID string
+	if err := json.Unmarshal([]byte(ti.RemoteItemID), &remoteID); err != nil {
+		return errors.WithStack(err)
+	}
+	wi, err := findImportedWorkItem(s.db, remoteID)
+	if err != nil || wi == nil {
+		return errors.WithStack(err)
+	}
+	owner, repo, number, err := parseGithubIssueURL(remoteID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if ti.SyncedFields == nil {
+		// imported before the two-way sync, the last imported state of the issue is the common ground
+		var issue map[string]interface{}
+		if err := json.Unmarshal([]byte(ti.Item), &issue); err != nil {
+			return errors.WithStack(err)
+		}
+		ti.SyncedFields = workitem.Fields(githubRemoteFields(Flatten(issue)))
+	}
+	local := githubLocalFields(ctx, s.db, *wi)
+	if !reflect.DeepEqual(map[string]interface{}(ti.SyncedFields), local) {
+		issue, _, err := s.editor.getIssue(owner, repo, number)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		content, err := json.Marshal(issue)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var remote map[string]interface{}
+		if err := json.Unmarshal(content, &remote); err != nil {
+			return errors.WithStack(err)
+		}
+		plan := planSync(ti.SyncedFields, local, githubRemoteFields(Flatten(remote)))
+		if len(plan.Push) > 0 {
+			if _, _, err := s.editor.editIssue(owner, repo, number, githubIssueRequest(plan.Push)); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if len(plan.Conflicts) > 0 {
+			log.Warn(ctx, map[string]interface{}{
+				"remoteItemID": remoteID,
+				"conflicts":    plan.Conflicts,
+			}, "fields changed locally and on Github since the last sync")
+		}
+		// remote changes are left for the next import
+		plan.apply(&ti, plan.Push)
+	}
+	created, edited, pushErr := s.pushComments(ctx, ti, wi.ID, owner, repo, number)
+	// the comments pushed before a failure are recorded all the same
+	return models.Transactional(s.db, func(tx *gorm.DB) error {
+		for i := range created {
+			if err := tx.Create(&created[i]).Error; err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		for i := range edited {
+			if err := tx.Save(&edited[i]).Error; err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if pushErr != nil {
+			return errors.WithStack(pushErr)
+		}
+		return errors.WithStack(tx.Model(&ti).Updates(map[string]interface{}{
+			"synced_fields": ti.SyncedFields,
+			"conflicts":     ti.Conflicts,
+			"synced_at":     syncedAt,
+		}).Error)
+	})
+}
+
+// pushComments creates the new comments of the work item on the Github issue, oldest first, and updates the edited
+// ones. Github issues have no threads, so a reply is pushed as a comment quoting the first comment of its thread.
+// It returns the records of the created and of the updated comments.
+func (s *GithubSyncer) pushComments(ctx context.Context, ti TrackerItem, workItemID, owner, repo string, number int) ([]RemoteComment, []RemoteComment, error) {
+	var comments []comment.Comment
+	if err := s.db.Where("parent_id = ?", workItemID).Order("created_at, id").Find(&comments).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	threads := map[uuid.UUID]string{}
+	for _, c := range comments {
+		if c.ParentCommentID == nil {
+			threads[c.ID] = c.Body
+		}
+	}
+	var created, edited []RemoteComment
+	for _, c := range comments {
+		var remote RemoteComment
+		res := s.db.Where("comment_id = ?", c.ID).First(&remote)
+		if res.Error != nil && !res.RecordNotFound() {
+			return created, edited, errors.WithStack(res.Error)
+		}
+		body := c.Body
+		if c.ParentCommentID != nil {
+			body = githubReplyBody(threads[*c.ParentCommentID], c.Body)
+		}
+		if res.RecordNotFound() {
+			pushed, _, err := s.editor.createComment(owner, repo, number, &github.IssueComment{Body: &body})
+			if err != nil {
+				return created, edited, errors.WithStack(err)
+			}
+			created = append(created, RemoteComment{CommentID: c.ID, TrackerItemID: ti.ID, RemoteCommentID: *pushed.ID, SyncedBody: body})
+			continue
+		}
+		if remote.SyncedBody == body {
+			continue
+		}
+		if _, _, err := s.editor.editComment(owner, repo, remote.RemoteCommentID, &github.IssueComment{Body: &body}); err != nil {
+			return created, edited, errors.WithStack(err)
+		}
+		remote.SyncedBody = body
+		edited = append(edited, remote)
+	}
+	return created, edited, nil
+}
+
+// githubReplyBody returns the body of the Github comment for a reply, which quotes the first comment of its thread
+func githubReplyBody(thread, reply string) string {
+	return "> " + strings.Replace(thread, "\n", "\n> ", -1) + "\n\n" + reply
+}
+
+// githubIssueRequest returns the edit of a Github issue setting the given synchronized fields
+func githubIssueRequest(fields map[string]interface{}) *github.IssueRequest {
+	req := &github.IssueRequest{}
+	if title, ok := fields[githubSyncTitle].(string); ok {
+		req.Title = &title
+	}
+	if state, ok := fields[githubSyncState].(string); ok {
+		req.State = &state
+	}
+	if assignee, ok := fields[githubSyncAssignee]; ok {
+		// an empty assignee removes the assignment
+		login, _ := assignee.(string)
+		req.Assignee = &login
+	}
+	return req
+}
+
+// findImportedWorkItem returns the work item imported with the given remote item ID, nil if there is none
+func findImportedWorkItem(db *gorm.DB, remoteID interface{}) (*app.WorkItem, error) {
+	expression := criteria.Equals(criteria.Field(workitem.SystemRemoteItemID), criteria.Literal(remoteID))
+	existing, _, err := workitem.NewWorkItemRepository(db).List(context.Background(), expression, nil, nil, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(existing) == 0 {
+		return nil, nil
+	}
+	return existing[0], nil
+}
@@ -0,0 +1,236 @@
+package remoteworkitem
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanSync(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	base := map[string]interface{}{"title": "base", "state": "open", "assignee": "pranav"}
+	t.Run("local change is pushed", func(t *testing.T) {
+		plan := planSync(base, map[string]interface{}{"title": "local", "state": "open", "assignee": "pranav"}, base)
+		assert.Equal(t, map[string]interface{}{"title": "local"}, plan.Push)
+		assert.Empty(t, plan.Pull)
+		assert.Empty(t, plan.Conflicts)
+	})
+	t.Run("remote change is pulled", func(t *testing.T) {
+		plan := planSync(base, base, map[string]interface{}{"title": "base", "state": "closed", "assignee": "pranav"})
+		assert.Equal(t, map[string]interface{}{"state": "closed"}, plan.Pull)
+		assert.Empty(t, plan.Push)
+		assert.Empty(t, plan.Conflicts)
+	})
+	t.Run("same change on both sides agrees", func(t *testing.T) {
+		changed := map[string]interface{}{"title": "base", "state": "open", "assignee": nil}
+		plan := planSync(base, changed, changed)
+		assert.Empty(t, plan.Push)
+		assert.Empty(t, plan.Pull)
+		assert.Empty(t, plan.Conflicts)
+		assert.Len(t, plan.Agreed, 3)
+	})
+	t.Run("different changes on both sides conflict", func(t *testing.T) {
+		plan := planSync(base,
+			map[string]interface{}{"title": "local", "state": "open", "assignee": "pranav"},
+			map[string]interface{}{"title": "remote", "state": "open", "assignee": "pranav"})
+		assert.Empty(t, plan.Push)
+		assert.Empty(t, plan.Pull)
+		assert.Equal(t, map[string]interface{}{"local": "local", "remote": "remote"}, plan.Conflicts["title"])
+	})
+	t.Run("never synchronized fields are pulled", func(t *testing.T) {
+		plan := planSync(nil, map[string]interface{}{"title": "local"}, map[string]interface{}{"title": "remote"})
+		assert.Equal(t, "remote", plan.Pull["title"])
+		assert.Empty(t, plan.Conflicts)
+	})
+}
+
+func TestParseGithubIssueURL(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	owner, repo, number, err := parseGithubIssueURL("https://api.github.com/repos/almighty/almighty-core/issues/42")
+	require.Nil(t, err)
+	assert.Equal(t, "almighty", owner)
+	assert.Equal(t, "almighty-core", repo)
+	assert.Equal(t, 42, number)
+	_, _, _, err = parseGithubIssueURL("https://github.com/almighty/almighty-core/pull/42")
+	assert.NotNil(t, err)
+}
+
+func TestGithubIssueRequest(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	req := githubIssueRequest(map[string]interface{}{"state": "closed", "assignee": nil})
+	assert.Nil(t, req.Title)
+	require.NotNil(t, req.State)
+	assert.Equal(t, "closed", *req.State)
+	require.NotNil(t, req.Assignee)
+	assert.Equal(t, "", *req.Assignee)
+}
+
+type fakeGithubIssueEditor struct {
+	issue    github.Issue
+	edits    []github.IssueRequest
+	comments []string
+}
+
+func (f *fakeGithubIssueEditor) getIssue(owner, repo string, number int) (*github.Issue, *github.Response, error) {
+	return &f.issue, &github.Response{}, nil
+}
+
+func (f *fakeGithubIssueEditor) editIssue(owner, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	f.edits = append(f.edits, *issue)
+	if issue.Title != nil {
+		f.issue.Title = issue.Title
+	}
+	if issue.State != nil {
+		f.issue.State = issue.State
+	}
+	return &f.issue, &github.Response{}, nil
+}
+
+func (f *fakeGithubIssueEditor) createComment(owner, repo string, number int, c *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	f.comments = append(f.comments, *c.Body)
+	id := len(f.comments)
+	return &github.IssueComment{ID: &id, Body: c.Body}, &github.Response{}, nil
+}
+
+func (f *fakeGithubIssueEditor) editComment(owner, repo string, id int, c *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	f.comments[id-1] = *c.Body
+	return &github.IssueComment{ID: &id, Body: c.Body}, &github.Response{}, nil
+}
+
+// importGithubIssue imports an issue with the given title the way the scheduler does
+func importGithubIssue(t *testing.T, tID int, title string) (TrackerItemContent, string) {
+	remoteID := "https://api.github.com/repos/almighty/almighty-test/issues/1"
+	item := TrackerItemContent{
+		Content: []byte(`{"title":"` + title + `","url":"` + remoteID + `","state":"open","body":"body of issue","user":{"login":"sbose78"},"assignee":{"login":"pranav"}}`),
+		ID:      `"` + remoteID + `"`,
+	}
+	_, err := importItem(db, tID, item, ProviderGithub, importTarget{workItemType: workitem.SystemBug, mapping: WorkItemKeyMaps[ProviderGithub]})
+	require.Nil(t, err)
+	return item, remoteID
+}
+
+// countGithubChanges returns the number of Github issues with local changes to push
+func countGithubChanges(t *testing.T) int {
+	var count int
+	require.Nil(t, githubImportedItems(db).Where(githubLocalChanges).Count(&count).Error)
+	return count
+}
+
+func TestGithubImportIsNoLocalChange(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer cleaner.DeleteCreatedEntities(db)()
+	tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub}
+	require.Nil(t, db.Create(&tr).Error)
+	// when
+	_, remoteID := importGithubIssue(t, int(tr.ID), "imported")
+	importGithubIssue(t, int(tr.ID), "imported again")
+	// then
+	assert.Equal(t, 0, countGithubChanges(t))
+	// and local changes stay pending across imports
+	wi, err := findImportedWorkItem(db, remoteID)
+	require.Nil(t, err)
+	wi.Fields[workitem.SystemTitle] = "changed locally"
+	_, err = workitem.NewWorkItemRepository(db).Save(context.Background(), *wi, "")
+	require.Nil(t, err)
+	assert.Equal(t, 1, countGithubChanges(t))
+	importGithubIssue(t, int(tr.ID), "imported again")
+	assert.Equal(t, 1, countGithubChanges(t))
+}
+
+func TestGithubSyncPushesLocalChanges(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer cleaner.DeleteCreatedEntities(db)()
+	tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub}
+	require.Nil(t, db.Create(&tr).Error)
+	_, remoteID := importGithubIssue(t, int(tr.ID), "imported")
+	wi, err := findImportedWorkItem(db, remoteID)
+	require.Nil(t, err)
+	require.NotNil(t, wi)
+	// when
+	wi.Fields[workitem.SystemTitle] = "changed locally"
+	wi.Fields[workitem.SystemState] = workitem.SystemStateResolved
+	_, err = workitem.NewWorkItemRepository(db).Save(context.Background(), *wi, "")
+	require.Nil(t, err)
+	err = comment.NewCommentRepository(db).Create(context.Background(), &comment.Comment{ParentID: wi.ID, Body: "a local comment", Markup: rendering.SystemMarkupMarkdown})
+	require.Nil(t, err)
+	title, state := "imported", "open"
+	editor := &fakeGithubIssueEditor{issue: github.Issue{Title: &title, State: &state}}
+	require.Nil(t, newGithubSyncer(db, editor).Run(context.Background()))
+	// then
+	require.Len(t, editor.edits, 1)
+	assert.Equal(t, "changed locally", *editor.edits[0].Title)
+	assert.Equal(t, "closed", *editor.edits[0].State)
+	assert.Nil(t, editor.edits[0].Assignee)
+	assert.Equal(t, []string{"a local comment"}, editor.comments)
+	// nothing changed since, nothing is pushed again
+	require.Nil(t, newGithubSyncer(db, editor).Run(context.Background()))
+	assert.Len(t, editor.edits, 1)
+	assert.Len(t, editor.comments, 1)
+}
+
+func TestGithubSyncPushesCommentsOldestFirst(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer cleaner.DeleteCreatedEntities(db)()
+	tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub}
+	require.Nil(t, db.Create(&tr).Error)
+	_, remoteID := importGithubIssue(t, int(tr.ID), "imported")
+	wi, err := findImportedWorkItem(db, remoteID)
+	require.Nil(t, err)
+	require.NotNil(t, wi)
+	// when
+	repo := comment.NewCommentRepository(db)
+	first := comment.Comment{ParentID: wi.ID, Body: "first comment", Markup: rendering.SystemMarkupMarkdown}
+	require.Nil(t, repo.Create(context.Background(), &first))
+	second := comment.Comment{ParentID: wi.ID, Body: "second comment", Markup: rendering.SystemMarkupMarkdown}
+	require.Nil(t, repo.Create(context.Background(), &second))
+	reply := comment.Comment{ParentID: wi.ID, Body: "a reply", Markup: rendering.SystemMarkupMarkdown, ParentCommentID: &first.ID}
+	require.Nil(t, repo.Create(context.Background(), &reply))
+	title, state := "imported", "open"
+	editor := &fakeGithubIssueEditor{issue: github.Issue{Title: &title, State: &state}}
+	require.Nil(t, newGithubSyncer(db, editor).Run(context.Background()))
+	// then the reply quotes the first comment of its thread
+	assert.Equal(t, []string{"first comment", "second comment", "> first comment\n\na reply"}, editor.comments)
+	require.Nil(t, newGithubSyncer(db, editor).Run(context.Background()))
+	assert.Len(t, editor.comments, 3)
+}
+
+func TestGithubReplyBody(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, "> line 1\n> line 2\n\nreply", githubReplyBody("line 1\nline 2", "reply"))
+}
+
+func TestGithubSyncDetectsConflicts(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer cleaner.DeleteCreatedEntities(db)()
+	tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub}
+	require.Nil(t, db.Create(&tr).Error)
+	_, remoteID := importGithubIssue(t, int(tr.ID), "imported")
+	wi, err := findImportedWorkItem(db, remoteID)
+	require.Nil(t, err)
+	wi.Fields[workitem.SystemTitle] = "changed locally"
+	_, err = workitem.NewWorkItemRepository(db).Save(context.Background(), *wi, "")
+	require.Nil(t, err)
+	// when the title changes on Github as well
+	title, state := "changed on Github", "open"
+	editor := &fakeGithubIssueEditor{issue: github.Issue{Title: &title, State: &state}}
+	require.Nil(t, newGithubSyncer(db, editor).Run(context.Background()))
+	// then nothing is pushed and the conflict is recorded
+	assert.Empty(t, editor.edits)
+	var ti TrackerItem
+	require.Nil(t, db.Where("tracker_id = ?", tr.ID).First(&ti).Error)
+	assert.Equal(t, map[string]interface{}{"local": "changed locally", "remote": "changed on Github"}, ti.Conflicts["title"])
+	// and the next import keeps the local title
+	importGithubIssue(t, int(tr.ID), "changed on Github")
+	wi, err = findImportedWorkItem(db, remoteID)
+	require.Nil(t, err)
+	assert.Equal(t, "changed locally", wi.Fields[workitem.SystemTitle])
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/dnaeon/go-vcr/recorder"
 	"github.com/google/go-github/github"
@@ -31,6 +32,10 @@ func (f *fakeGithubIssueFetcher) listIssues(query string, opts *github.SearchOpt
 
 }
 
+func (f *fakeGithubIssueFetcher) listComments(owner, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	return nil, &github.Response{}, nil
+}
+
 func TestGithubFetch(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	f := fakeGithubIssueFetcher{}
@@ -55,6 +60,10 @@ func (f *fakeGithubIssueFetcherWithRateLimit) listIssues(query string, opts *git
 
 }
 
+func (f *fakeGithubIssueFetcherWithRateLimit) listComments(owner, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	return nil, &github.Response{}, &github.RateLimitError{}
+}
+
 func TestGithubFetchWithRateLimit(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	f := fakeGithubIssueFetcherWithRateLimit{}
@@ -89,3 +98,64 @@ func TestGithubFetchWithRecording(t *testing.T) {
 		t.Errorf("Content is not matching: %#v", string(i2.Content))
 	}
 }
+
+type fakeGithubIssueFetcherWithQuery struct {
+	query string
+	opts  github.SearchOptions
+}
+
+func (f *fakeGithubIssueFetcherWithQuery) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	f.query = query
+	f.opts = *opts
+	return &github.IssuesSearchResult{}, &github.Response{}, nil
+}
+
+func (f *fakeGithubIssueFetcherWithQuery) listComments(owner, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	return nil, &github.Response{}, nil
+}
+
+func TestGithubFetchSince(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := fakeGithubIssueFetcherWithQuery{}
+	since := time.Date(2016, 9, 13, 14, 0, 54, 0, time.FixedZone("CEST", 2*60*60))
+	g := GithubTracker{URL: "", Query: "is:open user:almighty-test", Since: &since}
+	for range g.fetch(&f) {
+	}
+	require.Equal(t, "is:open user:almighty-test updated:>=2016-09-13T12:00:54Z", f.query)
+	require.Equal(t, "updated", f.opts.Sort)
+	require.Equal(t, "asc", f.opts.Order)
+}
+
+type fakeGithubCommentFetcher struct {
+	pages [][]*github.IssueComment
+}
+
+func (f *fakeGithubCommentFetcher) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	url, count := "https://api.github.com/repos/almighty-test/almighty-test-unit/issues/2", 3
+	return &github.IssuesSearchResult{Issues: []github.Issue{{URL: &url, Comments: &count}}}, &github.Response{}, nil
+}
+
+func (f *fakeGithubCommentFetcher) listComments(owner, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	r := &github.Response{}
+	if opts.ListOptions.Page+1 < len(f.pages) {
+		r.NextPage = opts.ListOptions.Page + 1
+	}
+	return f.pages[opts.ListOptions.Page], r, nil
+}
+
+func TestGithubFetchComments(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	id := func(i int) *int { return &i }
+	text := func(s string) *string { return &s }
+	f := fakeGithubCommentFetcher{pages: [][]*github.IssueComment{
+		{{ID: id(11), Body: text("first"), User: &github.User{Login: text("sbose78")}}, {ID: id(12), Body: text("second")}},
+		{{ID: id(13), Body: text("third"), User: &github.User{Login: text("pranav")}}},
+	}}
+	g := GithubTracker{URL: "", Query: ""}
+	i := <-g.fetch(&f)
+	require.Equal(t, []RemoteCommentContent{
+		{ID: 11, Author: "sbose78", Body: "first", Markup: rendering.SystemMarkupMarkdown},
+		{ID: 12, Body: "second", Markup: rendering.SystemMarkupMarkdown},
+		{ID: 13, Author: "pranav", Body: "third", Markup: rendering.SystemMarkupMarkdown},
+	}, i.Comments)
+}
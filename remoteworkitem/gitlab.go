@@ -0,0 +1,163 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/log"
+	"github.com/pkg/errors"
+)
+
+// gitlabPageSize is the number of issues fetched per request
+const gitlabPageSize = 20
+
+// gitlabFetcher provides issue listing
+type gitlabFetcher interface {
+	// listIssues returns a page of the issues of the project matching the given parameters,
+	// and the number of the next page, 0 on the last page
+	listIssues(project string, params url.Values) ([]json.RawMessage, int, error)
+}
+
+// GitlabTracker represents the GitLab tracker provider. The query is the path or ID of a project followed by
+// the parameters of the GitLab issues API, e.g. "gitlab-org/gitlab-ce?state=opened&labels=bug".
+type GitlabTracker struct {
+	URL   string
+	Query string
+	// Since restricts the search to the issues updated since then
+	Since *time.Time
+	err   error
+}
+
+// gitlabIssueFetcher fetch issues from the API of a GitLab instance
+type gitlabIssueFetcher struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// gitlabRateLimitError is returned when GitLab refuses requests until the rate limit is reset
+type gitlabRateLimitError struct {
+	retryAfter string
+}
+
+func (e gitlabRateLimitError) Error() string {
+	return fmt.Sprintf("reached the rate limit of GitLab, retry after %s seconds", e.retryAfter)
+}
+
+// listIssues lists the issues of a project
+func (f *gitlabIssueFetcher) listIssues(project string, params url.Values) ([]json.RawMessage, int, error) {
+	// the path of a project is a single segment of the URL
+	u := fmt.Sprintf("%s/api/v4/projects/%s/issues?%s", strings.TrimSuffix(f.baseURL, "/"),
+		strings.Replace(url.QueryEscape(project), "+", "%20", -1), params.Encode())
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	if f.token != "" {
+		req.Header.Set("Private-Token", f.token)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, 0, gitlabRateLimitError{retryAfter: resp.Header.Get("Retry-After")}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, errors.Errorf("listing the issues of %s failed with status %s", project, resp.Status)
+	}
+	var issues []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	// the header is empty on the last page
+	next, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	return issues, next, nil
+}
+
+// Fetch tracker items from GitLab
+func (g *GitlabTracker) Fetch() chan TrackerItemContent {
+	f := gitlabIssueFetcher{
+		client:  &http.Client{Timeout: time.Minute},
+		baseURL: g.URL,
+		token:   configuration.GetGitlabAuthToken(),
+	}
+	return g.fetch(&f)
+}
+
+// Err returns the error which cut the fetch short, once its channel is closed
+func (g *GitlabTracker) Err() error {
+	return g.err
+}
+
+func (g *GitlabTracker) fetch(f gitlabFetcher) chan TrackerItemContent {
+	item := make(chan TrackerItemContent)
+	go func() {
+		defer close(item)
+		project, params, err := g.parseQuery()
+		if err != nil {
+			g.err = err
+			return
+		}
+		// the parameters of the query can't change the order, it's the one the import relies on
+		params.Set("order_by", "updated_at")
+		params.Set("sort", "asc")
+		params.Set("per_page", strconv.Itoa(gitlabPageSize))
+		if g.Since != nil {
+			params.Set("updated_after", g.Since.UTC().Format(time.RFC3339))
+		}
+		for page := 1; page != 0; {
+			params.Set("page", strconv.Itoa(page))
+			issues, next, err := f.listIssues(project, params)
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"query": g.Query,
+					"page":  page,
+					"err":   err,
+				}, "unable to list GitLab issues")
+				g.err = errors.Wrap(err, "unable to list GitLab issues")
+				return
+			}
+			for _, content := range issues {
+				var issue struct {
+					WebURL    string     `json:"web_url"`
+					UpdatedAt *time.Time `json:"updated_at"`
+				}
+				if err := json.Unmarshal(content, &issue); err != nil {
+					log.Error(nil, map[string]interface{}{
+						"query": g.Query,
+						"err":   err,
+					}, "unable to decode a GitLab issue")
+					continue
+				}
+				id, _ := json.Marshal(issue.WebURL)
+				item <- TrackerItemContent{ID: string(id), Content: content, UpdatedAt: issue.UpdatedAt}
+			}
+			page = next
+		}
+	}()
+	return item
+}
+
+// parseQuery splits the query into the project and the parameters of the issues search
+func (g *GitlabTracker) parseQuery() (string, url.Values, error) {
+	parts := strings.SplitN(strings.TrimSpace(g.Query), "?", 2)
+	if parts[0] == "" {
+		return "", nil, errors.Errorf("the query %q does not start with a project", g.Query)
+	}
+	params := url.Values{}
+	if len(parts) == 2 {
+		var err error
+		if params, err = url.ParseQuery(parts[1]); err != nil {
+			return "", nil, errors.Wrapf(err, "invalid parameters in the query %q", g.Query)
+		}
+	}
+	return parts[0], params, nil
+}
@@ -0,0 +1,141 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitlabIssueFetcher serves the canned issues one per page
+type fakeGitlabIssueFetcher struct {
+	issues  []json.RawMessage
+	project string
+	params  []url.Values
+}
+
+func newFakeGitlabIssueFetcher(t *testing.T) *fakeGitlabIssueFetcher {
+	content, err := ioutil.ReadFile("../test/data/gitlab_issues.json")
+	require.Nil(t, err)
+	f := fakeGitlabIssueFetcher{}
+	require.Nil(t, json.Unmarshal(content, &f.issues))
+	return &f
+}
+
+func (f *fakeGitlabIssueFetcher) listIssues(project string, params url.Values) ([]json.RawMessage, int, error) {
+	f.project = project
+	copied := url.Values{}
+	for k, v := range params {
+		copied[k] = v
+	}
+	f.params = append(f.params, copied)
+	page := len(f.params)
+	if page == len(f.issues) {
+		return f.issues[page-1:], 0, nil
+	}
+	return f.issues[page-1 : page], page + 1, nil
+}
+
+func TestGitlabFetch(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := newFakeGitlabIssueFetcher(t)
+	since := time.Date(2016, 11, 1, 0, 0, 0, 0, time.UTC)
+	g := GitlabTracker{URL: "https://gitlab.com", Query: "almighty-test/almighty-test-unit?labels=bug", Since: &since}
+	var items []TrackerItemContent
+	for i := range g.fetch(f) {
+		items = append(items, i)
+	}
+	require.Nil(t, g.Err())
+	require.Len(t, items, 2)
+	assert.Equal(t, `"https://gitlab.com/almighty-test/almighty-test-unit/issues/12"`, items[0].ID)
+	require.NotNil(t, items[0].UpdatedAt)
+	assert.Equal(t, time.Date(2016, 11, 4, 8, 30, 1, 519000000, time.UTC), items[0].UpdatedAt.UTC())
+	assert.Equal(t, `"https://gitlab.com/almighty-test/almighty-test-unit/issues/13"`, items[1].ID)
+	// the pages are requested in order, with the least recently updated issues first
+	assert.Equal(t, "almighty-test/almighty-test-unit", f.project)
+	require.Len(t, f.params, 2)
+	assert.Equal(t, "1", f.params[0].Get("page"))
+	assert.Equal(t, "2", f.params[1].Get("page"))
+	assert.Equal(t, "bug", f.params[0].Get("labels"))
+	assert.Equal(t, "updated_at", f.params[0].Get("order_by"))
+	assert.Equal(t, "asc", f.params[0].Get("sort"))
+	assert.Equal(t, "2016-11-01T00:00:00Z", f.params[0].Get("updated_after"))
+}
+
+func TestGitlabFetchInvalidQuery(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	g := GitlabTracker{URL: "https://gitlab.com", Query: "?state=opened"}
+	for range g.fetch(newFakeGitlabIssueFetcher(t)) {
+		t.Error("no item expected")
+	}
+	assert.NotNil(t, g.Err())
+}
+
+func TestGitlabIssueFetcher(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	content, err := ioutil.ReadFile("../test/data/gitlab_issues.json")
+	require.Nil(t, err)
+	var requested *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		w.Write(content)
+	}))
+	defer server.Close()
+	f := gitlabIssueFetcher{client: http.DefaultClient, baseURL: server.URL + "/", token: "secret"}
+
+	issues, next, err := f.listIssues("almighty-test/almighty-test-unit", url.Values{"page": {"1"}})
+	require.Nil(t, err)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, 2, next)
+	assert.Equal(t, "/api/v4/projects/almighty-test%2Falmighty-test-unit/issues", requested.URL.EscapedPath())
+	assert.Equal(t, "secret", requested.Header.Get("Private-Token"))
+
+	_, _, err = f.listIssues("almighty-test/almighty-test-unit", url.Values{"page": {"2"}})
+	assert.IsType(t, gitlabRateLimitError{}, err)
+}
+
+func TestGitlabMapping(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := newFakeGitlabIssueFetcher(t)
+	expected := []struct {
+		title       string
+		description interface{}
+		state       string
+		assignees   []interface{}
+	}{
+		{"Import of remote items stops on rate limit",
+			rendering.NewMarkupContent("Steps to reproduce:\n\n1. import a large project\n2. wait", rendering.SystemMarkupMarkdown),
+			workitem.SystemStateOpen, []interface{}{"sbose78", "aslakknutsen"}},
+		{"Document the tracker query syntax", nil, workitem.SystemStateClosed, []interface{}{}},
+	}
+	for i, e := range expected {
+		accessor, err := NewGitlabRemoteWorkItem(TrackerItem{Item: string(f.issues[i])})
+		require.Nil(t, err)
+		wi, err := Map(accessor, WorkItemKeyMaps[ProviderGitlab])
+		require.Nil(t, err)
+		assert.Equal(t, e.title, wi.Fields[workitem.SystemTitle])
+		assert.Equal(t, e.description, wi.Fields[workitem.SystemDescription])
+		assert.Equal(t, e.state, wi.Fields[workitem.SystemState])
+		assert.Equal(t, e.assignees, wi.Fields[workitem.SystemAssignees])
+	}
+	accessor, err := NewGitlabRemoteWorkItem(TrackerItem{Item: string(f.issues[0])})
+	require.Nil(t, err)
+	wi, err := Map(accessor, WorkItemKeyMaps[ProviderGitlab])
+	require.Nil(t, err)
+	assert.Equal(t, "aslakknutsen", wi.Fields[workitem.SystemCreator])
+	assert.Equal(t, "https://gitlab.com/almighty-test/almighty-test-unit/issues/12", wi.Fields[workitem.SystemRemoteItemID])
+}
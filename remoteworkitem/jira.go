@@ -2,14 +2,32 @@ package remoteworkitem
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/rendering"
 	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
 )
 
+// jiraOrderBy matches the ORDER BY clause which ends a JQL query
+var jiraOrderBy = regexp.MustCompile(`(?i)\s*\border\s+by\b.*$`)
+
+// jiraTimeLayouts are the layouts of the times of Jira issues
+var jiraTimeLayouts = []string{"2006-01-02T15:04:05.000-0700", time.RFC3339Nano}
+
 // JiraTracker represents the Jira tracker provider
 type JiraTracker struct {
 	URL   string
 	Query string
+	// Since restricts the search to the issues updated since then
+	Since *time.Time
+	err   error
 }
 
 type jiraFetcher interface {
@@ -37,17 +55,102 @@ func (j *JiraTracker) Fetch() chan TrackerItemContent {
 	return j.fetch(&f)
 }
 
+// Err returns the error which cut the fetch short, once its channel is closed
+func (j *JiraTracker) Err() error {
+	return j.err
+}
+
 func (j *JiraTracker) fetch(f jiraFetcher) chan TrackerItemContent {
 	item := make(chan TrackerItemContent)
 	go func() {
-		issues, _, _ := f.listIssues(j.Query, nil)
-		for _, l := range issues {
-			id, _ := json.Marshal(l.Key)
-			issue, _, _ := f.getIssue(l.Key)
-			content, _ := json.Marshal(issue)
-			item <- TrackerItemContent{ID: string(id), Content: content}
+		jql := j.jql(time.Now())
+		// the search returns a page of the issues, the next one starts after the issues returned so far
+		for startAt := 0; ; {
+			issues, resp, err := f.listIssues(jql, &jira.SearchOptions{StartAt: startAt})
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"jql":      jql,
+					"start_at": startAt,
+					"err":      err,
+				}, "unable to search Jira issues")
+				j.err = errors.Wrapf(err, "unable to search Jira issues with %s", jql)
+				break
+			}
+			for _, l := range issues {
+				id, _ := json.Marshal(l.Key)
+				issue, _, _ := f.getIssue(l.Key)
+				content, _ := json.Marshal(issue)
+				item <- TrackerItemContent{ID: string(id), Content: content, UpdatedAt: jiraUpdated(content), Comments: jiraComments(content)}
+			}
+			startAt += len(issues)
+			if len(issues) == 0 || resp == nil || startAt >= resp.Total {
+				break
+			}
 		}
 		close(item)
 	}()
 	return item
 }
+
+// jql returns the search of the issues of the query updated since the last import, in the order of their
+// updates. The ORDER BY clause of the query is replaced. The time is given relative to now, so it does not
+// depend on the time zone of the Jira server.
+func (j *JiraTracker) jql(now time.Time) string {
+	jql := strings.TrimSpace(jiraOrderBy.ReplaceAllString(j.Query, ""))
+	if j.Since != nil {
+		since := fmt.Sprintf(`updated >= "-%dm"`, int(math.Max(1, math.Ceil(now.Sub(*j.Since).Minutes()))))
+		if jql == "" {
+			jql = since
+		} else {
+			jql = fmt.Sprintf("(%s) AND %s", jql, since)
+		}
+	}
+	return strings.TrimSpace(jql + " ORDER BY updated ASC")
+}
+
+// jiraUpdated returns the update time of the given Jira issue, nil if it is unknown
+func jiraUpdated(content []byte) *time.Time {
+	var issue map[string]interface{}
+	if err := json.Unmarshal(content, &issue); err != nil {
+		return nil
+	}
+	updated, ok := Flatten(issue)["fields.updated"].(string)
+	if !ok {
+		return nil
+	}
+	for _, layout := range jiraTimeLayouts {
+		if t, err := time.Parse(layout, updated); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// jiraComments returns the comments on the given Jira issue, which come with the issue
+func jiraComments(content []byte) []RemoteCommentContent {
+	var issue struct {
+		Fields struct {
+			Comment struct {
+				Comments []struct {
+					ID     string `json:"id"`
+					Author struct {
+						Key string `json:"key"`
+					} `json:"author"`
+					Body string `json:"body"`
+				} `json:"comments"`
+			} `json:"comment"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(content, &issue); err != nil {
+		return nil
+	}
+	var comments []RemoteCommentContent
+	for _, c := range issue.Fields.Comment.Comments {
+		id, err := strconv.Atoi(c.ID)
+		if err != nil {
+			continue
+		}
+		comments = append(comments, RemoteCommentContent{ID: id, Author: c.Author.Key, Body: c.Body, Markup: rendering.SystemMarkupJiraWiki})
+	}
+	return comments
+}
@@ -5,9 +5,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
 	jira "github.com/andygrunwald/go-jira"
 	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type fakeJiraIssueFetcher struct{}
@@ -31,6 +34,43 @@ func TestJiraFetch(t *testing.T) {
 
 }
 
+// pagedJiraIssueFetcher returns the issues with the given keys in pages of the given size
+type pagedJiraIssueFetcher struct {
+	keys     []string
+	pageSize int
+	startAts []int
+}
+
+func (f *pagedJiraIssueFetcher) listIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error) {
+	f.startAts = append(f.startAts, options.StartAt)
+	end := options.StartAt + f.pageSize
+	if end > len(f.keys) {
+		end = len(f.keys)
+	}
+	var issues []jira.Issue
+	for _, key := range f.keys[options.StartAt:end] {
+		issues = append(issues, jira.Issue{Key: key})
+	}
+	return issues, &jira.Response{StartAt: options.StartAt, MaxResults: f.pageSize, Total: len(f.keys)}, nil
+}
+
+func (f *pagedJiraIssueFetcher) getIssue(issueID string) (*jira.Issue, *jira.Response, error) {
+	return &jira.Issue{Key: issueID}, &jira.Response{}, nil
+}
+
+func TestJiraFetchAllPages(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := pagedJiraIssueFetcher{keys: []string{"ARQ-1", "ARQ-2", "ARQ-3", "ARQ-4", "ARQ-5"}, pageSize: 2}
+	j := JiraTracker{}
+	var ids []string
+	for i := range j.fetch(&f) {
+		ids = append(ids, i.ID)
+	}
+	require.Nil(t, j.Err())
+	assert.Equal(t, []string{`"ARQ-1"`, `"ARQ-2"`, `"ARQ-3"`, `"ARQ-4"`, `"ARQ-5"`}, ids)
+	assert.Equal(t, []int{0, 2, 4}, f.startAts)
+}
+
 func TestJiraFetchWithRecording(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	r, err := recorder.New("../test/data/jira_fetch_test")
@@ -70,3 +110,35 @@ func TestJiraFetchWithRecording(t *testing.T) {
 		t.Errorf("ID is not matching: %#v", string(i.ID))
 	}
 }
+
+func TestJiraJQL(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	now := time.Date(2016, 11, 3, 12, 0, 0, 0, time.UTC)
+	j := JiraTracker{Query: "project = Arquillian ORDER BY created ASC"}
+	assert.Equal(t, "project = Arquillian ORDER BY updated ASC", j.jql(now))
+	since := now.Add(-90*time.Minute - time.Second)
+	j.Since = &since
+	assert.Equal(t, `(project = Arquillian) AND updated >= "-91m" ORDER BY updated ASC`, j.jql(now))
+	j.Query = ""
+	assert.Equal(t, `updated >= "-91m" ORDER BY updated ASC`, j.jql(now))
+}
+
+func TestJiraUpdated(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	updated := jiraUpdated([]byte(`{"key":"ARQ-1937","fields":{"updated":"2016-11-03T12:49:12.000+0100"}}`))
+	require.NotNil(t, updated)
+	assert.Equal(t, time.Date(2016, 11, 3, 11, 49, 12, 0, time.UTC), updated.UTC())
+	assert.Nil(t, jiraUpdated([]byte(`{"key":"ARQ-1937"}`)))
+}
+
+func TestJiraComments(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	content := []byte(`{"key":"ARQ-1","fields":{"comment":{"comments":[
+		{"id":"10100","author":{"key":"aslak"},"body":"h1. Cause"},
+		{"id":"10101","author":{"key":"mjobanek"},"body":"fixed in {{master}}"}]}}}`)
+	assert.Equal(t, []RemoteCommentContent{
+		{ID: 10100, Author: "aslak", Body: "h1. Cause", Markup: rendering.SystemMarkupJiraWiki},
+		{ID: 10101, Author: "mjobanek", Body: "fixed in {{master}}", Markup: rendering.SystemMarkupJiraWiki},
+	}, jiraComments(content))
+	assert.Empty(t, jiraComments([]byte(`{"key":"ARQ-2","fields":{"comment":{"comments":[]}}}`)))
+}
@@ -0,0 +1,286 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/pkg/errors"
+)
+
+// Names of the built-in converters of field mappings
+const (
+	ConverterString = "string"
+	ConverterNumber = "number"
+	ConverterDate   = "date"
+	ConverterList   = "list"
+	ConverterState  = "state"
+)
+
+// converterKinds lists the kinds of the work item fields each built-in converter can fill
+var converterKinds = map[string][]workitem.Kind{
+	ConverterString: {workitem.KindString, workitem.KindURL, workitem.KindUser, workitem.KindEnum, workitem.KindMarkup},
+	ConverterNumber: {workitem.KindInteger, workitem.KindDuration, workitem.KindFloat},
+	ConverterDate:   {workitem.KindInstant},
+	ConverterList:   {workitem.KindList},
+	ConverterState:  {workitem.KindString, workitem.KindEnum},
+}
+
+// FieldMapping maps a value of the flattened remote items of a tracker query to a work item field
+type FieldMapping struct {
+	// Path is the key of the value in the flattened remote item, see Flatten
+	Path string `json:"path"`
+	// Field is the name of the work item field the value is imported into
+	Field string `json:"field"`
+	// Converter is the name of the built-in converter of the value
+	Converter string `json:"converter"`
+	// Key selects the values in the elements of a list of objects, only used by the list converter
+	Key string `json:"key,omitempty"`
+	// States maps the remote values to the values of the field, only used by the state converter
+	States map[string]string `json:"states,omitempty"`
+}
+
+// FieldMappings are the field mappings of a tracker query, they take precedence over the mapping of the provider
+type FieldMappings []FieldMapping
+
+// Value implements driver.Valuer
+func (m FieldMappings) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner
+func (m *FieldMappings) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	}
+	return errors.Errorf("cannot scan %T into field mappings", src)
+}
+
+// workItemMap validates the field mappings against the fields of the given work item type and returns
+// the mapping of the provider with the field mappings in place of the provider's mapping of the same fields.
+// returns BadParameterError
+func (m FieldMappings) workItemMap(wit workitem.WorkItemType, provider WorkItemMap) (WorkItemMap, error) {
+	result := WorkItemMap{}
+	mapped := map[string]bool{}
+	for _, fm := range m {
+		converter, err := fm.converter(wit)
+		if err != nil {
+			return nil, err
+		}
+		result[AttributeMapper{AttributeExpression(fm.Path), converter}] = fm.Field
+		mapped[fm.Field] = true
+	}
+	for from, to := range provider {
+		if mapped[to] {
+			continue
+		}
+		if _, ok := wit.Fields[to]; !ok {
+			// the target type doesn't have all the fields of the default type
+			continue
+		}
+		result[from] = to
+	}
+	return result, nil
+}
+
+// converter returns the converter of the field mapping for the given work item type
+// returns BadParameterError
+func (fm FieldMapping) converter(wit workitem.WorkItemType) (AttributeConverter, error) {
+	if fm.Path == "" {
+		return nil, BadParameterError{parameter: "path", value: fm.Path}
+	}
+	def, ok := wit.Fields[fm.Field]
+	if !ok {
+		return nil, BadParameterError{parameter: "field", value: fm.Field}
+	}
+	kinds, ok := converterKinds[fm.Converter]
+	if !ok {
+		return nil, BadParameterError{parameter: "converter", value: fm.Converter}
+	}
+	kind := def.Type.GetKind()
+	compatible := false
+	for _, k := range kinds {
+		compatible = compatible || k == kind
+	}
+	if !compatible {
+		return nil, BadParameterError{parameter: "converter", value: fmt.Sprintf("%s for the %s field %s", fm.Converter, kind, fm.Field)}
+	}
+	switch fm.Converter {
+	case ConverterString:
+		if kind == workitem.KindMarkup {
+			return MarkupConverter{markup: rendering.SystemMarkupPlainText}, nil
+		}
+		return TextConverter{}, nil
+	case ConverterNumber:
+		return NumberConverter{integer: kind != workitem.KindFloat}, nil
+	case ConverterDate:
+		return DateConverter{}, nil
+	case ConverterList:
+		return ListConverter{path: fm.Path, key: fm.Key}, nil
+	}
+	if len(fm.States) == 0 {
+		return nil, BadParameterError{parameter: "states", value: fm.States}
+	}
+	states := map[string]string{}
+	for remote, local := range fm.States {
+		if enum, ok := def.Type.(workitem.EnumType); ok && !containsValue(enum.Values, local) {
+			return nil, BadParameterError{parameter: "states", value: local}
+		}
+		states[strings.ToLower(remote)] = local
+	}
+	// the converter is a key of the mapping, a map isn't comparable but the pointer to it is
+	return &StateMapConverter{states: states}, nil
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// TextConverter converts any value to its string
+type TextConverter struct{}
+
+// NumberConverter converts numbers and numeric strings to integers or floats
+type NumberConverter struct {
+	integer bool
+}
+
+// DateConverter converts the dates and times of the remote trackers
+type DateConverter struct{}
+
+// ListConverter collects the values of a flattened list, the values of the given key for lists of objects
+type ListConverter struct {
+	path string
+	key  string
+}
+
+// StateMapConverter maps remote values to field values, unknown values are kept as they are
+type StateMapConverter struct {
+	states map[string]string
+}
+
+// Convert returns the string of the value
+func (tc TextConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// Convert returns the number of the value
+func (nc NumberConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	var f float64
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		f = v
+	case string:
+		var err error
+		if f, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+			return nil, errors.Errorf("%s is not a number", v)
+		}
+	default:
+		return nil, errors.Errorf("Unexpected type of value to convert: %T", value)
+	}
+	if nc.integer {
+		if f != math.Trunc(f) {
+			return nil, errors.Errorf("%v is not an integer", value)
+		}
+		return int(f), nil
+	}
+	return f, nil
+}
+
+// dateLayouts are the layouts of the dates and times of the remote trackers
+var dateLayouts = []string{
+	time.RFC3339,
+	// Jira
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02",
+}
+
+// Convert parses the date of the value
+func (dc DateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return nil, errors.Errorf("%s is not a date", v)
+	case float64:
+		// seconds since the epoch
+		return time.Unix(int64(v), 0).UTC(), nil
+	}
+	return nil, errors.Errorf("Unexpected type of value to convert: %T", value)
+}
+
+// Convert returns the values of the flattened list, whatever the value of the expression
+func (lc ListConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	values := []interface{}{}
+	for i := 0; ; i++ {
+		expression := fmt.Sprintf("%s.%d", lc.path, i)
+		if lc.key != "" {
+			expression += "." + lc.key
+		}
+		v := item.Get(AttributeExpression(expression))
+		if v == nil {
+			return values, nil
+		}
+		values = append(values, v)
+	}
+}
+
+// Convert maps the remote value to the field value, unknown values are kept as they are
+func (sc *StateMapConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	return convertState(sc.states, value), nil
+}
+
+// convertFieldMappings converts the field mappings of the REST API
+func convertFieldMappings(mappings []*app.FieldMapping) FieldMappings {
+	result := make(FieldMappings, len(mappings))
+	for i, m := range mappings {
+		result[i] = FieldMapping{Path: m.Path, Field: m.Field, Converter: m.Converter, States: m.States}
+		if m.Key != nil {
+			result[i].Key = *m.Key
+		}
+	}
+	return result
+}
+
+// convertFieldMappingsFromModel converts the field mappings for the REST API
+func convertFieldMappingsFromModel(mappings FieldMappings) []*app.FieldMapping {
+	result := make([]*app.FieldMapping, len(mappings))
+	for i, m := range mappings {
+		result[i] = &app.FieldMapping{Path: m.Path, Field: m.Field, Converter: m.Converter, States: m.States}
+		if m.Key != "" {
+			key := m.Key
+			result[i].Key = &key
+		}
+	}
+	return result
+}
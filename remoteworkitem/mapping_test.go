@@ -0,0 +1,124 @@
+package remoteworkitem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storyType is a work item type with fields of the kinds imported by the built-in converters
+var storyType = workitem.WorkItemType{
+	Name: "story",
+	Fields: workitem.FieldDefinitions{
+		workitem.SystemTitle:       {Type: workitem.SimpleType{Kind: workitem.KindString}},
+		workitem.SystemDescription: {Type: workitem.SimpleType{Kind: workitem.KindMarkup}},
+		workitem.SystemState: {Type: workitem.EnumType{
+			SimpleType: workitem.SimpleType{Kind: workitem.KindEnum},
+			BaseType:   workitem.SimpleType{Kind: workitem.KindString},
+			Values:     []interface{}{workitem.SystemStateNew, workitem.SystemStateOpen, workitem.SystemStateClosed},
+		}},
+		"points":   {Type: workitem.SimpleType{Kind: workitem.KindInteger}},
+		"estimate": {Type: workitem.SimpleType{Kind: workitem.KindFloat}},
+		"due":      {Type: workitem.SimpleType{Kind: workitem.KindInstant}},
+		"components": {Type: workitem.ListType{
+			SimpleType:    workitem.SimpleType{Kind: workitem.KindList},
+			ComponentType: workitem.SimpleType{Kind: workitem.KindString},
+		}},
+	},
+}
+
+func TestFieldMappingsWorkItemMap(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	mappings := FieldMappings{
+		{Path: "fields.customfield_10002", Field: "points", Converter: ConverterNumber},
+		{Path: "fields.customfield_10003", Field: "estimate", Converter: ConverterNumber},
+		{Path: "fields.duedate", Field: "due", Converter: ConverterDate},
+		{Path: "fields.components", Field: "components", Converter: ConverterList, Key: "name"},
+		{Path: "fields.status.name", Field: workitem.SystemState, Converter: ConverterState, States: map[string]string{"In Review": workitem.SystemStateOpen, "Done": workitem.SystemStateClosed}},
+	}
+	mapping, err := mappings.workItemMap(storyType, WorkItemKeyMaps[ProviderJira])
+	require.Nil(t, err)
+	remote := JiraRemoteWorkItem{issue: Flatten(map[string]interface{}{
+		"self": "http://issues.jboss.com/rest/api/2/issue/1",
+		"fields": map[string]interface{}{
+			"summary":           "imported story",
+			"customfield_10002": float64(5),
+			"customfield_10003": "2.5",
+			"duedate":           "2017-01-31",
+			"components":        []interface{}{map[string]interface{}{"name": "core"}, map[string]interface{}{"name": "ui"}},
+			"status":            map[string]interface{}{"name": "in review"},
+		},
+	})}
+	// when
+	wi, err := Map(remote, mapping)
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, "imported story", wi.Fields[workitem.SystemTitle])
+	assert.Equal(t, 5, wi.Fields["points"])
+	assert.Equal(t, 2.5, wi.Fields["estimate"])
+	assert.Equal(t, time.Date(2017, 1, 31, 0, 0, 0, 0, time.UTC), wi.Fields["due"])
+	assert.Equal(t, []interface{}{"core", "ui"}, wi.Fields["components"])
+	assert.Equal(t, workitem.SystemStateOpen, wi.Fields[workitem.SystemState])
+	// the provider's fields missing in the type are not mapped
+	_, ok := wi.Fields[workitem.SystemRemoteItemID]
+	assert.False(t, ok)
+}
+
+func TestFieldMappingsValidation(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	invalid := map[string]FieldMapping{
+		"missing path":      {Field: "points", Converter: ConverterNumber},
+		"unknown field":     {Path: "fields.customfield_10002", Field: "storypoints", Converter: ConverterNumber},
+		"unknown converter": {Path: "fields.customfield_10002", Field: "points", Converter: "roman"},
+		"wrong kind":        {Path: "fields.duedate", Field: "points", Converter: ConverterDate},
+		"missing states":    {Path: "fields.status.name", Field: workitem.SystemState, Converter: ConverterState},
+		"unknown state":     {Path: "fields.status.name", Field: workitem.SystemState, Converter: ConverterState, States: map[string]string{"Done": "finished"}},
+	}
+	for name, fm := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := FieldMappings{fm}.workItemMap(storyType, nil)
+			assert.IsType(t, BadParameterError{}, err)
+		})
+	}
+}
+
+func TestMappingConverters(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Run("text", func(t *testing.T) {
+		v, err := TextConverter{}.Convert(float64(42), nil)
+		require.Nil(t, err)
+		assert.Equal(t, "42", v)
+	})
+	t.Run("number", func(t *testing.T) {
+		_, err := NumberConverter{integer: true}.Convert("many", nil)
+		assert.NotNil(t, err)
+		_, err = NumberConverter{integer: true}.Convert(2.5, nil)
+		assert.NotNil(t, err)
+		v, err := NumberConverter{integer: true}.Convert("3", nil)
+		require.Nil(t, err)
+		assert.Equal(t, 3, v)
+		v, err = NumberConverter{}.Convert(2.5, nil)
+		require.Nil(t, err)
+		assert.Equal(t, 2.5, v)
+		v, err = NumberConverter{}.Convert(nil, nil)
+		require.Nil(t, err)
+		assert.Nil(t, v)
+	})
+	t.Run("date", func(t *testing.T) {
+		v, err := DateConverter{}.Convert("2017-01-31T10:11:30.000+0100", nil)
+		require.Nil(t, err)
+		assert.True(t, time.Date(2017, 1, 31, 9, 11, 30, 0, time.UTC).Equal(v.(time.Time)))
+		_, err = DateConverter{}.Convert("tomorrow", nil)
+		assert.NotNil(t, err)
+	})
+	t.Run("list", func(t *testing.T) {
+		remote := GitHubRemoteWorkItem{issue: Flatten(map[string]interface{}{"labels": []interface{}{"bug", "ui"}})}
+		v, err := ListConverter{path: "labels"}.Convert(nil, remote)
+		require.Nil(t, err)
+		assert.Equal(t, []interface{}{"bug", "ui"}, v)
+	})
+}
@@ -2,6 +2,8 @@ package remoteworkitem
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/rendering"
@@ -29,8 +31,29 @@ const (
 	JiraCreator  = "fields.creator.key"
 	JiraAssignee = "fields.assignee"
 
-	ProviderGithub = "github"
-	ProviderJira   = "jira"
+	// The keys in the flattened response JSON of a typical GitLab issue.
+
+	GitlabTitle       = "title"
+	GitlabDescription = "description"
+	GitlabState       = "state"
+	GitlabID          = "web_url"
+	GitlabCreator     = "author.username"
+	GitlabAssignees   = "assignees"
+
+	// The keys in the flattened response JSON of a typical Bugzilla bug.
+
+	BugzillaTitle       = "summary"
+	BugzillaDescription = "description"
+	BugzillaState       = "status"
+	BugzillaResolution  = "resolution"
+	BugzillaID          = "show_bug_url" // added on fetching, "url" is the URL given by the reporter
+	BugzillaCreator     = "creator"
+	BugzillaAssignee    = "assigned_to"
+
+	ProviderGithub   = "github"
+	ProviderJira     = "jira"
+	ProviderGitlab   = "gitlab"
+	ProviderBugzilla = "bugzilla"
 )
 
 // WorkItemKeyMaps relate remote attribute keys to internal representation
@@ -52,6 +75,22 @@ var WorkItemKeyMaps = map[string]WorkItemMap{
 		AttributeMapper{AttributeExpression(JiraCreator), StringConverter{}}:                                    workitem.SystemCreator,
 		AttributeMapper{AttributeExpression(JiraAssignee), ListStringConverter{}}:                               workitem.SystemAssignees,
 	},
+	ProviderGitlab: {
+		AttributeMapper{AttributeExpression(GitlabTitle), StringConverter{}}:                                             workitem.SystemTitle,
+		AttributeMapper{AttributeExpression(GitlabDescription), MarkupConverter{markup: rendering.SystemMarkupMarkdown}}: workitem.SystemDescription,
+		AttributeMapper{AttributeExpression(GitlabState), GitlabStateConverter{}}:                                        workitem.SystemState,
+		AttributeMapper{AttributeExpression(GitlabID), StringConverter{}}:                                                workitem.SystemRemoteItemID,
+		AttributeMapper{AttributeExpression(GitlabCreator), StringConverter{}}:                                           workitem.SystemCreator,
+		AttributeMapper{AttributeExpression(GitlabAssignees), GitlabAssigneesConverter{}}:                                workitem.SystemAssignees,
+	},
+	ProviderBugzilla: {
+		AttributeMapper{AttributeExpression(BugzillaTitle), StringConverter{}}:                                              workitem.SystemTitle,
+		AttributeMapper{AttributeExpression(BugzillaDescription), MarkupConverter{markup: rendering.SystemMarkupPlainText}}: workitem.SystemDescription,
+		AttributeMapper{AttributeExpression(BugzillaState), BugzillaStateConverter{}}:                                       workitem.SystemState,
+		AttributeMapper{AttributeExpression(BugzillaID), StringConverter{}}:                                                 workitem.SystemRemoteItemID,
+		AttributeMapper{AttributeExpression(BugzillaCreator), StringConverter{}}:                                            workitem.SystemCreator,
+		AttributeMapper{AttributeExpression(BugzillaAssignee), ListStringConverter{}}:                                       workitem.SystemAssignees,
+	},
 }
 
 type AttributeConverter interface {
@@ -73,6 +112,14 @@ type GithubStateConverter struct{}
 
 type JiraStateConverter struct{}
 
+type GitlabStateConverter struct{}
+
+// BugzillaStateConverter maps the status of a Bugzilla bug and its resolution to a work item state
+type BugzillaStateConverter struct{}
+
+// GitlabAssigneesConverter collects the user names of the assignees of a GitLab issue
+type GitlabAssigneesConverter struct{}
+
 // Convert method map the external tracker item to ALM WorkItem
 func (sc StringConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
 	return value, nil
@@ -97,28 +144,105 @@ func (sc ListStringConverter) Convert(value interface{}, item AttributeAccessor)
 	return []interface{}{value}, nil
 }
 
+// githubStates maps the states of Github issues to the states of work items
+var githubStates = map[string]string{
+	"open":   workitem.SystemStateOpen,
+	"closed": workitem.SystemStateClosed,
+}
+
+// jiraStates maps the (lower case) names of the default Jira statuses to the states of work items
+var jiraStates = map[string]string{
+	"open":        workitem.SystemStateOpen,
+	"reopened":    workitem.SystemStateOpen,
+	"to do":       workitem.SystemStateNew,
+	"in progress": workitem.SystemStateInProgress,
+	"resolved":    workitem.SystemStateResolved,
+	"done":        workitem.SystemStateClosed,
+	"closed":      workitem.SystemStateClosed,
+}
+
+// Convert maps the state of a Github issue to the work item state, unknown states are kept as they are
 func (ghc GithubStateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
-	if value.(string) == "closed" {
-		value = "closed"
-	} else if value.(string) == "open" {
-		value = "open"
-	}
-	return value, nil
+	return convertState(githubStates, value), nil
 }
 
+// Convert maps the status of a Jira issue to the work item state, unknown statuses are kept as they are
 func (jhc JiraStateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
-	if value.(string) == "closed" {
-		value = "closed"
-	} else if value.(string) == "open" {
-		value = "open"
-	} else if value.(string) == "in progress" {
-		value = "in progress"
-	} else if value.(string) == "resolved" {
-		value = "resolved"
+	return convertState(jiraStates, value), nil
+}
+
+// gitlabStates maps the states of GitLab issues to the states of work items
+var gitlabStates = map[string]string{
+	"opened":   workitem.SystemStateOpen,
+	"reopened": workitem.SystemStateOpen,
+	"closed":   workitem.SystemStateClosed,
+}
+
+// Convert maps the state of a GitLab issue to the work item state, unknown states are kept as they are
+func (glc GitlabStateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	return convertState(gitlabStates, value), nil
+}
+
+// Convert returns the user names of the assignees of the flattened issue, whatever the value of the expression
+func (glc GitlabAssigneesConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	assignees := []interface{}{}
+	for i := 0; ; i++ {
+		username := item.Get(AttributeExpression(fmt.Sprintf("%s.%d.username", GitlabAssignees, i)))
+		if username == nil {
+			return assignees, nil
+		}
+		assignees = append(assignees, username)
+	}
+}
+
+// bugzillaStates maps the (upper case) statuses of open Bugzilla bugs, including the additional statuses
+// of the Red Hat Bugzilla, to the states of work items
+var bugzillaStates = map[string]string{
+	"UNCONFIRMED": workitem.SystemStateNew,
+	"NEW":         workitem.SystemStateNew,
+	"CONFIRMED":   workitem.SystemStateNew,
+	"REOPENED":    workitem.SystemStateOpen,
+	"ASSIGNED":    workitem.SystemStateInProgress,
+	"IN_PROGRESS": workitem.SystemStateInProgress,
+	"POST":        workitem.SystemStateInProgress,
+	"MODIFIED":    workitem.SystemStateInProgress,
+	"ON_DEV":      workitem.SystemStateInProgress,
+	"ON_QA":       workitem.SystemStateResolved,
+}
+
+// Convert maps the status and the resolution of a Bugzilla bug to the work item state. Fixed bugs waiting for
+// verification are resolved, the others with a resolution are closed. Unknown statuses are kept as they are.
+func (bzc BugzillaStateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	status, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	status = strings.ToUpper(status)
+	if state, ok := bugzillaStates[status]; ok {
+		return state, nil
+	}
+	switch status {
+	case "RESOLVED", "VERIFIED", "RELEASE_PENDING", "CLOSED":
+		resolution, _ := item.Get(BugzillaResolution).(string)
+		if strings.EqualFold(resolution, "FIXED") && status != "CLOSED" {
+			return workitem.SystemStateResolved, nil
+		}
+		return workitem.SystemStateClosed, nil
 	}
 	return value, nil
 }
 
+func convertState(states map[string]string, value interface{}) interface{} {
+	remoteState, ok := value.(string)
+	if !ok {
+		return value
+	}
+	if state, ok := states[strings.ToLower(remoteState)]; ok {
+		return state
+	}
+	return value
+}
+
 type AttributeMapper struct {
 	expression         AttributeExpression
 	attributeConverter AttributeConverter
@@ -138,8 +262,10 @@ type AttributeAccessor interface {
 
 // RemoteWorkItemImplRegistry contains all possible providers
 var RemoteWorkItemImplRegistry = map[string]func(TrackerItem) (AttributeAccessor, error){
-	ProviderGithub: NewGitHubRemoteWorkItem,
-	ProviderJira:   NewJiraRemoteWorkItem,
+	ProviderGithub:   NewGitHubRemoteWorkItem,
+	ProviderJira:     NewJiraRemoteWorkItem,
+	ProviderGitlab:   NewGitlabRemoteWorkItem,
+	ProviderBugzilla: NewBugzillaRemoteWorkItem,
 }
 
 // GitHubRemoteWorkItem knows how to implement a FieldAccessor on a GitHub Issue JSON struct
@@ -185,6 +311,48 @@ func (jira JiraRemoteWorkItem) Get(field AttributeExpression) interface{} {
 	return jira.issue[string(field)]
 }
 
+// GitlabRemoteWorkItem knows how to implement a FieldAccessor on a GitLab Issue JSON struct
+type GitlabRemoteWorkItem struct {
+	issue map[string]interface{}
+}
+
+// NewGitlabRemoteWorkItem creates a new Decoded AttributeAccessor for a GitLab Issue
+func NewGitlabRemoteWorkItem(item TrackerItem) (AttributeAccessor, error) {
+	var j map[string]interface{}
+	err := json.Unmarshal([]byte(item.Item), &j)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	j = Flatten(j)
+	return GitlabRemoteWorkItem{issue: j}, nil
+}
+
+// Get attribute from issue map
+func (gl GitlabRemoteWorkItem) Get(field AttributeExpression) interface{} {
+	return gl.issue[string(field)]
+}
+
+// BugzillaRemoteWorkItem knows how to implement a FieldAccessor on a Bugzilla Bug JSON struct
+type BugzillaRemoteWorkItem struct {
+	bug map[string]interface{}
+}
+
+// NewBugzillaRemoteWorkItem creates a new Decoded AttributeAccessor for a Bugzilla Bug
+func NewBugzillaRemoteWorkItem(item TrackerItem) (AttributeAccessor, error) {
+	var j map[string]interface{}
+	err := json.Unmarshal([]byte(item.Item), &j)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	j = Flatten(j)
+	return BugzillaRemoteWorkItem{bug: j}, nil
+}
+
+// Get attribute from bug map
+func (bz BugzillaRemoteWorkItem) Get(field AttributeExpression) interface{} {
+	return bz.bug[string(field)]
+}
+
 // Map maps the remote WorkItem to a local WorkItem
 func Map(item AttributeAccessor, mapping WorkItemMap) (app.WorkItem, error) {
 	workItem := app.WorkItem{Fields: make(map[string]interface{})}
@@ -349,3 +349,24 @@ func TestRemoteWorkItemImplRegistry(t *testing.T) {
 	assert.True(t, ok)
 
 }
+
+func TestStateConverters(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	state, err := JiraStateConverter{}.Convert("In Progress", nil)
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemStateInProgress, state)
+	state, err = JiraStateConverter{}.Convert("Done", nil)
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemStateClosed, state)
+	state, err = JiraStateConverter{}.Convert("Waiting for customer", nil)
+	require.Nil(t, err)
+	assert.Equal(t, "Waiting for customer", state)
+
+	state, err = GithubStateConverter{}.Convert("closed", nil)
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemStateClosed, state)
+	state, err = GithubStateConverter{}.Convert(nil, nil)
+	require.Nil(t, err)
+	assert.Nil(t, state)
+}
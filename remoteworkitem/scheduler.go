@@ -1,6 +1,12 @@
 package remoteworkitem
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/log"
 	"github.com/almighty/almighty-core/models"
 
@@ -8,15 +14,22 @@ import (
 	"github.com/pkg/errors"
 	"github.com/robfig/cron"
 	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
 )
 
+// runTimeout is the time after which an unfinished run is taken as abandoned, e.g. because the server stopped
+// during the import, and no longer keeps the tracker query from being imported again
+const runTimeout = time.Hour
+
 // TrackerSchedule capture all configuration
 type trackerSchedule struct {
-	TrackerID   int
-	URL         string
-	TrackerType string
-	Query       string
-	Schedule    string
+	TrackerQueryID uint64
+	TrackerID      int
+	URL            string
+	TrackerType    string
+	Query          string
+	Schedule       string
+	SyncCursor     *time.Time
 }
 
 // Scheduler represents scheduler
@@ -49,28 +62,62 @@ func (s *Scheduler) ScheduleAllQueries() {
 
 	trackerQueries := fetchTrackerQueries(s.db)
 	for _, tq := range trackerQueries {
+		tq := tq
 		cr.AddFunc(tq.Schedule, func() {
-			tr := lookupProvider(tq)
-			for i := range tr.Fetch() {
-				models.Transactional(s.db, func(tx *gorm.DB) error {
-					// Save the remote items in a 'temporary' table.
-					err := upload(tx, tq.TrackerID, i)
-					if err != nil {
-						return errors.WithStack(err)
-					}
-					// Convert the remote item into a local work item and persist in the DB.
-					_, err = convert(tx, tq.TrackerID, i, tq.TrackerType)
-					return errors.WithStack(err)
-				})
+			run, err := startRun(s.db, tq)
+			if _, ok := errors.Cause(err).(RunningError); ok {
+				log.Info(nil, map[string]interface{}{
+					"trackerQueryID": tq.TrackerQueryID,
+				}, "skipping the scheduled import, the tracker query is still being imported")
+				return
 			}
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"trackerQueryID": tq.TrackerQueryID,
+					"err":            err,
+				}, "unable to start the import of the tracker query")
+				return
+			}
+			runQuery(s.db, tq, run)
 		})
 	}
 	cr.Start()
 }
 
+// Trigger starts an import of the tracker query with the given id right away. It returns the run, which goes on
+// in the background.
+// returns NotFoundError, RunningError or InternalError
+func (s *Scheduler) Trigger(ctx context.Context, ID string) (*app.TrackerQueryRun, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	tsList, err := trackerSchedules(s.db.Where("tracker_queries.id = ?", id))
+	if err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	if len(tsList) == 0 {
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	run, err := startRun(s.db, tsList[0])
+	if _, ok := errors.Cause(err).(RunningError); ok {
+		return nil, errors.WithStack(err)
+	}
+	if err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	log.Info(ctx, map[string]interface{}{
+		"trackerQueryID": id,
+		"batchID":        run.BatchID,
+	}, "triggered the import of the tracker query")
+	result := convertRun(*run)
+	go runQuery(s.db, tsList[0], run)
+	return result, nil
+}
+
 func fetchTrackerQueries(db *gorm.DB) []trackerSchedule {
-	tsList := []trackerSchedule{}
-	err := db.Table("tracker_queries").Select("trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
+	tsList, err := trackerSchedules(db)
 	if err != nil {
 		log.Error(nil, map[string]interface{}{
 			"err": err,
@@ -79,13 +126,170 @@ func fetchTrackerQueries(db *gorm.DB) []trackerSchedule {
 	return tsList
 }
 
+// trackerSchedules returns the tracker queries matching the conditions of the given db
+func trackerSchedules(db *gorm.DB) ([]trackerSchedule, error) {
+	tsList := []trackerSchedule{}
+	err := db.Table("tracker_queries").Select("tracker_queries.id as tracker_query_id, trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule, tracker_queries.sync_cursor").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
+	return tsList, errors.WithStack(err)
+}
+
+// startRun records the start of an import of the given tracker query. It returns a RunningError if the
+// tracker query is being imported already.
+func startRun(db *gorm.DB, ts trackerSchedule) (*TrackerQueryRun, error) {
+	run := TrackerQueryRun{TrackerQueryID: ts.TrackerQueryID, BatchID: batchID(), StartedAt: time.Now()}
+	// runs left unfinished by a stopped server don't keep the tracker query from being imported
+	err := db.Model(&TrackerQueryRun{}).Where("tracker_query_id = ? and finished_at is null and started_at < ?", ts.TrackerQueryID, run.StartedAt.Add(-runTimeout)).
+		Updates(map[string]interface{}{
+			"finished_at": run.StartedAt,
+			"errors":      RunErrors{"the run was abandoned"},
+		}).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := db.Create(&run).Error; err != nil {
+		if gormsupport.IsUniqueViolation(err, "tracker_query_runs_running_idx") {
+			return nil, RunningError{simpleError{fmt.Sprintf("tracker query %d is being imported already", ts.TrackerQueryID)}}
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &run, nil
+}
+
+// runQuery imports the tracker query as the given run and logs the outcome
+func runQuery(db *gorm.DB, ts trackerSchedule, run *TrackerQueryRun) {
+	if err := importQuery(db, ts, run); err != nil {
+		log.Error(nil, map[string]interface{}{
+			"trackerQueryID": ts.TrackerQueryID,
+			"batchID":        run.BatchID,
+			"err":            err,
+		}, "unable to record the import of the tracker query")
+		return
+	}
+	log.Info(nil, map[string]interface{}{
+		"trackerQueryID": ts.TrackerQueryID,
+		"batchID":        run.BatchID,
+		"stats":          run.Stats,
+		"errors":         len(run.Errors),
+	}, "imported the tracker query")
+}
+
+// importQuery fetches the items of the tracker query changed since its sync cursor and converts them into work
+// items. The cursor stops before a failing item, so the next run retries it. The counts and errors are recorded
+// in the given run, the error returned is the failure to record them.
+func importQuery(db *gorm.DB, ts trackerSchedule, run *TrackerQueryRun) error {
+	// the cursor moved since the query was scheduled
+	var tq TrackerQuery
+	if err := db.First(&tq, ts.TrackerQueryID).Error; err != nil {
+		run.add(errors.Wrap(err, "unable to load the tracker query"))
+		return finishRun(db, run)
+	}
+	ts.SyncCursor = tq.SyncCursor
+	tr := lookupProvider(ts)
+	if tr == nil {
+		run.add(errors.Errorf("unknown tracker type %s", ts.TrackerType))
+		return finishRun(db, run)
+	}
+	target, err := importTargetOf(db, tq, ts.TrackerType)
+	if err != nil {
+		run.add(err)
+		return finishRun(db, run)
+	}
+	cursor := tq.SyncCursor
+	inOrder := true
+	for i := range tr.Fetch() {
+		run.Stats.Fetched++
+		created := false
+		err := models.Transactional(db, func(tx *gorm.DB) error {
+			wi, err := importItem(tx, ts.TrackerID, i, ts.TrackerType, target)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			// work items are saved with version 0 only when they are created
+			created = wi.Version == 0
+			return nil
+		})
+		if err != nil {
+			log.Error(nil, map[string]interface{}{
+				"trackerQueryID": ts.TrackerQueryID,
+				"batchID":        run.BatchID,
+				"remoteItemID":   i.ID,
+				"err":            err,
+			}, "unable to import the remote item")
+			run.add(errors.Wrapf(err, "unable to import %s", i.ID))
+			run.Stats.Failed++
+			inOrder = false
+			continue
+		}
+		if created {
+			run.Stats.Created++
+		} else {
+			run.Stats.Updated++
+		}
+		if inOrder && i.UpdatedAt != nil && (cursor == nil || i.UpdatedAt.After(*cursor)) {
+			cursor = i.UpdatedAt
+		}
+	}
+	if err := tr.Err(); err != nil {
+		run.add(err)
+	}
+	err = db.Model(&tq).Updates(map[string]interface{}{
+		"sync_cursor":    cursor,
+		"last_run_stats": run.Stats,
+	}).Error
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return finishRun(db, run)
+}
+
+// importItem stores the given remote item and converts it into a work item of the import target with its comments
+func importItem(db *gorm.DB, tID int, item TrackerItemContent, provider string, target importTarget) (*app.WorkItem, error) {
+	// local changes not pushed to Github yet are still pending after the import
+	pending := false
+	if provider == ProviderGithub {
+		var err error
+		if pending, err = githubChangesPending(db, tID, item.ID); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	// Save the remote items in a 'temporary' table.
+	if err := upload(db, tID, item); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Convert the remote item into a local work item and persist in the DB.
+	wi, err := convertTo(db, tID, item, provider, target)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := importComments(db, tID, provider, item, wi.ID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if provider == ProviderGithub && !pending {
+		if err := markGithubSynced(db, tID, item.ID); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return wi, nil
+}
+
+// finishRun records the end of the given run
+func finishRun(db *gorm.DB, run *TrackerQueryRun) error {
+	now := time.Now()
+	run.FinishedAt = &now
+	return errors.WithStack(db.Save(run).Error)
+}
+
 // lookupProvider provides the respective tracker based on the type
 func lookupProvider(ts trackerSchedule) TrackerProvider {
 	switch ts.TrackerType {
 	case ProviderGithub:
-		return &GithubTracker{URL: ts.URL, Query: ts.Query}
+		return &GithubTracker{URL: ts.URL, Query: ts.Query, Since: ts.SyncCursor}
 	case ProviderJira:
-		return &JiraTracker{URL: ts.URL, Query: ts.Query}
+		return &JiraTracker{URL: ts.URL, Query: ts.Query, Since: ts.SyncCursor}
+	case ProviderGitlab:
+		return &GitlabTracker{URL: ts.URL, Query: ts.Query, Since: ts.SyncCursor}
+	case ProviderBugzilla:
+		return &BugzillaTracker{URL: ts.URL, Query: ts.Query, Since: ts.SyncCursor}
 	}
 	return nil
 }
@@ -94,11 +298,28 @@ func lookupProvider(ts trackerSchedule) TrackerProvider {
 type TrackerItemContent struct {
 	ID      string
 	Content []byte
+	// UpdatedAt is the time the item was last changed on the remote tracker, nil if unknown
+	UpdatedAt *time.Time
+	// Comments are the comments on the item, oldest first
+	Comments []RemoteCommentContent
+}
+
+// RemoteCommentContent holds a comment on a remote tracker item
+type RemoteCommentContent struct {
+	// ID is the ID of the comment on the remote tracker
+	ID     int
+	Author string
+	Body   string
+	Markup string
 }
 
-// TrackerProvider represents a remote tracker
+// TrackerProvider represents a remote tracker. Fetch sends the items least recently updated first: the sync
+// cursor advances with the items imported in order, so a fetch cut short, e.g. by the rate limit of the
+// tracker, resumes after the last imported item in the next run.
 type TrackerProvider interface {
 	Fetch() chan TrackerItemContent // TODO: Change to an interface to enforce the contract
+	// Err returns the error which cut the fetch short, once its channel is closed
+	Err() error
 }
 
 func init() {
@@ -1,6 +1,12 @@
 package remoteworkitem
 
-import "github.com/almighty/almighty-core/gormsupport"
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+)
 
 // TrackerItem represents a remote tracker item
 // Staging area before pushing to work item
@@ -13,4 +19,26 @@ type TrackerItem struct {
 	Item string
 	// FK to tracker
 	TrackerID uint64 `gorm:"ForeignKey:Tracker"`
+	// the values of the two-way synchronized fields as of the last sync, in the representation of the remote
+	// tracker. A field which differs from it on both sides was changed concurrently.
+	SyncedFields workitem.Fields `sql:"type:jsonb"`
+	// the fields changed on both sides since the last sync, mapped to their local and remote values.
+	// They are not synchronized until both sides agree again.
+	Conflicts workitem.Fields `sql:"type:jsonb"`
+	// when the local changes were last pushed to the remote tracker
+	SyncedAt *time.Time
+}
+
+// RemoteComment links a comment on a work item to its copy on the remote tracker
+type RemoteComment struct {
+	CommentID       uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	TrackerItemID   uint64
+	RemoteCommentID int
+	// the body of the comment as last pushed or imported
+	SyncedBody string
+}
+
+// TableName implements gorm.tabler
+func (c RemoteComment) TableName() string {
+	return "tracker_item_comments"
 }
@@ -1,14 +1,21 @@
 package remoteworkitem
 
 import (
+	"encoding/json"
+
 	"golang.org/x/net/context"
 
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/reference"
+	"github.com/almighty/almighty-core/space"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 // upload imports the items into database
@@ -28,8 +35,34 @@ func upload(db *gorm.DB, tID int, item TrackerItemContent) error {
 	return db.Save(&ti).Error
 }
 
+// importTarget is the space and the type of the work items imported by a tracker query and the mapping of the
+// remote items to the fields of the type
+type importTarget struct {
+	spaceID      *uuid.UUID
+	workItemType string
+	mapping      WorkItemMap
+}
+
+// importTargetOf returns the import target of the given tracker query of a tracker of the given provider
+func importTargetOf(db *gorm.DB, tq TrackerQuery, provider string) (importTarget, error) {
+	wit, err := workitem.NewWorkItemTypeRepository(db).LoadTypeInSpaceFromDB(context.Background(), tq.SpaceID, tq.targetType())
+	if err != nil {
+		return importTarget{}, errors.Wrapf(err, "unable to load the work item type %s", tq.targetType())
+	}
+	mapping, err := tq.Mapping.workItemMap(*wit, WorkItemKeyMaps[provider])
+	if err != nil {
+		return importTarget{}, errors.Wrap(err, "invalid field mapping")
+	}
+	return importTarget{spaceID: tq.SpaceID, workItemType: wit.Name, mapping: mapping}, nil
+}
+
 // Map a remote work item into an ALM work item and persist it into the database.
 func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*app.WorkItem, error) {
+	return convertTo(db, tID, item, provider, importTarget{workItemType: workitem.SystemBug, mapping: WorkItemKeyMaps[provider]})
+}
+
+// convertTo maps a remote work item into a work item of the type of the given import target and persists it
+func convertTo(db *gorm.DB, tID int, item TrackerItemContent, provider string, target importTarget) (*app.WorkItem, error) {
 	remoteID := item.ID
 	content := string(item.Content)
 
@@ -45,7 +78,7 @@ func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*a
 	if err != nil {
 		return nil, InternalError{simpleError{message: " Error parsing the tracker data "}}
 	}
-	workItem, err := Map(remoteTrackerItem, WorkItemKeyMaps[provider])
+	workItem, err := Map(remoteTrackerItem, target.mapping)
 	if err != nil {
 		return nil, ConversionError{simpleError{message: " Error mapping to local work item "}}
 	}
@@ -58,7 +91,7 @@ func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*a
 	var newWorkItem *app.WorkItem
 
 	// Querying the database
-	existingWorkItems, _, err := wir.List(context.Background(), sqlExpression, nil, nil)
+	existingWorkItems, _, err := wir.List(context.Background(), sqlExpression, nil, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -70,15 +103,27 @@ func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*a
 		}, "Workitem exists, will be updated")
 
 		existingWorkItem := existingWorkItems[0]
+		keep := map[string]bool{}
+		if provider == ProviderGithub {
+			keep, err = mergeGithubImport(db, tID, item, *existingWorkItem)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
 		for key, value := range workItem.Fields {
+			if keep[key] {
+				continue
+			}
 			existingWorkItem.Fields[key] = value
 		}
-		newWorkItem, err = wir.Save(context.Background(), *existingWorkItem)
+		newWorkItem, err = wir.SaveFromRemote(context.Background(), *existingWorkItem)
 		if err != nil {
 			log.Error(nil, map[string]interface{}{
 				"existingWorkitem": existingWorkItem,
 				"err":              err,
 			}, "unable to update the work item")
+		} else if !keep[workitem.SystemDescription] {
+			err = dropReferences(db, reference.SourceWorkItem, newWorkItem.ID)
 		}
 	} else {
 		log.Info(nil, map[string]interface{}{
@@ -91,15 +136,159 @@ func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*a
 		if c != nil {
 			creator = c.(string)
 		}
-		newWorkItem, err = wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, creator)
+		// tracker queries without a space import into the system space
+		spaceID := space.SystemSpace
+		if target.spaceID != nil {
+			spaceID = *target.spaceID
+		}
+		workItem.Fields[workitem.SystemSpace] = spaceID.String()
+		newWorkItem, err = wir.CreateFromRemote(context.Background(), target.workItemType, workItem.Fields, creator)
 		if err != nil {
 			log.Error(nil, map[string]interface{}{
-				"creator":            creator,
-				"workItem.Fields":    workItem.Fields,
-				"workitem.SystemBug": workitem.SystemBug,
-				"err":                err,
+				"creator":         creator,
+				"workItem.Fields": workItem.Fields,
+				"workItemType":    target.workItemType,
+				"err":             err,
 			}, "unable to create the work item")
+		} else {
+			err = dropReferences(db, reference.SourceWorkItem, newWorkItem.ID)
 		}
 	}
 	return newWorkItem, errors.WithStack(err)
 }
+
+// mergeGithubImport compares the two-way synchronized fields of the imported Github issue with the work item
+// and the last sync. It returns the work item fields to keep because they were changed locally, only fields
+// changed on Github alone are imported. Items never synchronized are imported as they are.
+func mergeGithubImport(db *gorm.DB, tID int, item TrackerItemContent, wi app.WorkItem) (map[string]bool, error) {
+	var ti TrackerItem
+	res := db.Where("remote_item_id = ? AND tracker_id = ?", item.ID, tID).Find(&ti)
+	if res.RecordNotFound() {
+		return nil, nil
+	}
+	if res.Error != nil {
+		return nil, errors.WithStack(res.Error)
+	}
+	var issue map[string]interface{}
+	if err := json.Unmarshal(item.Content, &issue); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	remote := githubRemoteFields(Flatten(issue))
+	keep := map[string]bool{}
+	if ti.SyncedFields == nil {
+		ti.SyncedFields = workitem.Fields(remote)
+	} else {
+		plan := planSync(ti.SyncedFields, githubLocalFields(context.Background(), db, wi), remote)
+		for field := range githubSyncedFields {
+			if _, pulled := plan.Pull[field]; !pulled {
+				keep[githubSyncedFields[field]] = true
+			}
+		}
+		plan.apply(&ti, plan.Pull)
+	}
+	return keep, errors.WithStack(db.Save(&ti).Error)
+}
+
+// The identity comments imported from remote trackers are attributed to when their authors have no identity
+const (
+	importerUsername = "importer"
+	importerProvider = "remote-tracker"
+)
+
+// remoteAuthorIdentity returns the ID of the identity of the given author on a remote tracker of the given
+// provider. Authors without identity of that provider map to the importer identity, which is created on
+// first use.
+func remoteAuthorIdentity(db *gorm.DB, provider, author string) (uuid.UUID, error) {
+	identities := account.NewIdentityRepository(db)
+	if author != "" {
+		found, err := identities.Query(account.IdentityFilterByProvider(provider), account.IdentityFilterByUsename(author))
+		if err != nil {
+			return uuid.Nil, errors.WithStack(err)
+		}
+		if len(found) > 0 {
+			return found[0].ID, nil
+		}
+	}
+	found, err := identities.Query(account.IdentityFilterByProvider(importerProvider), account.IdentityFilterByUsename(importerUsername))
+	if err != nil {
+		return uuid.Nil, errors.WithStack(err)
+	}
+	if len(found) > 0 {
+		return found[0].ID, nil
+	}
+	importer := account.Identity{ID: uuid.NewV4(), Username: importerUsername, Provider: importerProvider}
+	if err := identities.Create(context.Background(), &importer); err != nil {
+		return uuid.Nil, errors.WithStack(err)
+	}
+	return importer.ID, nil
+}
+
+// importComments creates the comments on the remote item which were not imported yet on the work item with
+// the given ID. They are created by the identity of their author with the given provider of the remote tracker,
+// or by the importer identity. Comments edited on the remote tracker are updated unless they were edited
+// locally as well.
+func importComments(db *gorm.DB, tID int, provider string, item TrackerItemContent, workItemID string) error {
+	if len(item.Comments) == 0 {
+		return nil
+	}
+	var ti TrackerItem
+	if err := db.Where("remote_item_id = ? AND tracker_id = ?", item.ID, tID).First(&ti).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	repo := comment.NewCommentRepository(db)
+	ctx := context.Background()
+	for _, rc := range item.Comments {
+		var remote RemoteComment
+		res := db.Where("tracker_item_id = ? AND remote_comment_id = ?", ti.ID, rc.ID).First(&remote)
+		if res.Error != nil && !res.RecordNotFound() {
+			return errors.WithStack(res.Error)
+		}
+		if res.RecordNotFound() {
+			author, err := remoteAuthorIdentity(db, provider, rc.Author)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			c := comment.Comment{ParentID: workItemID, CreatedBy: author, Body: rc.Body, Markup: rc.Markup, RemoteAuthor: rc.Author}
+			if err := repo.Create(ctx, &c); err != nil {
+				return errors.WithStack(err)
+			}
+			if err := dropReferences(db, reference.SourceComment, c.ID.String()); err != nil {
+				return errors.WithStack(err)
+			}
+			remote = RemoteComment{CommentID: c.ID, TrackerItemID: ti.ID, RemoteCommentID: rc.ID, SyncedBody: rc.Body}
+			if err := db.Create(&remote).Error; err != nil {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+		if remote.SyncedBody == rc.Body {
+			continue
+		}
+		c, err := repo.Load(ctx, remote.CommentID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if c.Body != remote.SyncedBody {
+			// edited on both sides, the local edit wins
+			continue
+		}
+		c.Body = rc.Body
+		if _, err := repo.Save(ctx, c); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := dropReferences(db, reference.SourceComment, c.ID.String()); err != nil {
+			return errors.WithStack(err)
+		}
+		remote.SyncedBody = rc.Body
+		if err := db.Save(&remote).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// dropReferences removes the references recorded for imported content, its mentions and work item references
+// are the ones of the remote tracker
+func dropReferences(db *gorm.DB, sourceType, sourceID string) error {
+	return reference.NewRepository(db).Delete(context.Background(), reference.Source{Type: sourceType, ID: sourceID})
+}
@@ -5,6 +5,8 @@ import (
 
 	"testing"
 
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/rendering"
@@ -13,6 +15,7 @@ import (
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -55,7 +58,7 @@ func TestConvertNewWorkItem(t *testing.T) {
 		assert.Equal(t, rendering.SystemMarkupMarkdown, description.Markup)
 
 		wir := workitem.NewWorkItemRepository(db)
-		wir.Delete(context.Background(), workItem.ID)
+		wir.Delete(context.Background(), workItem.ID, "")
 
 		return errors.WithStack(err)
 	})
@@ -111,7 +114,7 @@ func TestConvertExistingWorkItem(t *testing.T) {
 		assert.Equal(t, "closed", workItemUpdated.Fields[workitem.SystemState])
 
 		wir := workitem.NewWorkItemRepository(tx)
-		wir.Delete(context.Background(), workItemUpdated.ID)
+		wir.Delete(context.Background(), workItemUpdated.ID, "")
 
 		return errors.WithStack(err)
 	})
@@ -158,3 +161,50 @@ func TestConvertGithubIssue(t *testing.T) {
 	})
 
 }
+
+func TestImportComments(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer cleaner.DeleteCreatedEntities(db)()
+	tr := Tracker{URL: "https://issues.jboss.org", Type: ProviderJira}
+	require.Nil(t, db.Create(&tr).Error)
+	item := TrackerItemContent{
+		Content: []byte(`{"self":"https://issues.jboss.org/rest/api/2/issue/ARQ-1","fields":{"summary":"imported","creator":{"key":"aslak"}}}`),
+		ID:      `"ARQ-1"`,
+		Comments: []RemoteCommentContent{
+			{ID: 10100, Author: "aslak", Body: "h1. Cause", Markup: rendering.SystemMarkupJiraWiki},
+		},
+	}
+	// the author has an identity with the provider of the tracker
+	aslak := account.Identity{ID: uuid.NewV4(), Username: "aslak", Provider: ProviderJira}
+	require.Nil(t, account.NewIdentityRepository(db).Create(context.Background(), &aslak))
+	doImport := func() string {
+		wi, err := importItem(db, int(tr.ID), item, ProviderJira, importTarget{workItemType: workitem.SystemBug, mapping: WorkItemKeyMaps[ProviderJira]})
+		require.Nil(t, err)
+		return wi.ID
+	}
+	wiID := doImport()
+	comments, _, err := comment.NewCommentRepository(db).List(context.Background(), wiID, nil, nil)
+	require.Nil(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "h1. Cause", comments[0].Body)
+	assert.Equal(t, rendering.SystemMarkupJiraWiki, comments[0].Markup)
+	assert.Equal(t, aslak.ID, comments[0].CreatedBy)
+	assert.Equal(t, "aslak", comments[0].RemoteAuthor)
+	// a re-import doesn't duplicate the comments, but brings over the remote edits and the new ones
+	item.Comments[0].Body = "h1. Root cause"
+	item.Comments = append(item.Comments, RemoteCommentContent{ID: 10101, Author: "mjobanek", Body: "fixed", Markup: rendering.SystemMarkupJiraWiki})
+	doImport()
+	comments, _, err = comment.NewCommentRepository(db).List(context.Background(), wiID, nil, nil)
+	require.Nil(t, err)
+	require.Len(t, comments, 2)
+	bodies := []string{comments[0].Body, comments[1].Body}
+	assert.Contains(t, bodies, "h1. Root cause")
+	assert.Contains(t, bodies, "fixed")
+	// authors without identity are represented by the importer
+	for _, c := range comments {
+		if c.RemoteAuthor == "mjobanek" {
+			assert.NotEqual(t, uuid.Nil, c.CreatedBy)
+			assert.NotEqual(t, aslak.ID, c.CreatedBy)
+		}
+	}
+}
@@ -1,6 +1,15 @@
 package remoteworkitem
 
-import "github.com/almighty/almighty-core/gormsupport"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
 
 // TrackerQuery represents tracker query
 type TrackerQuery struct {
@@ -12,4 +21,47 @@ type TrackerQuery struct {
 	Schedule string
 	// TrackerID is a foreign key for a tracker
 	TrackerID uint64 `gorm:"ForeignKey:Tracker"`
+	// SyncCursor is the last update time of the items imported so far, the next import only fetches
+	// the items changed since then. It is reset when the query or its tracker changes.
+	SyncCursor *time.Time
+	// LastRunStats counts the items of the last import
+	LastRunStats *ImportStats `sql:"type:jsonb"`
+	// SpaceID is the space of the work items created by the import, the type is looked up in it as well
+	SpaceID *uuid.UUID `sql:"type:uuid"`
+	// WorkItemType is the type of the work items created by the import, bugs if empty
+	WorkItemType string
+	// Mapping maps the remote items to the fields of the work item type in addition to the mapping of the provider
+	Mapping FieldMappings `sql:"type:jsonb"`
+}
+
+// targetType returns the type of the work items created by the import of the tracker query
+func (tq TrackerQuery) targetType() string {
+	if tq.WorkItemType == "" {
+		return workitem.SystemBug
+	}
+	return tq.WorkItemType
+}
+
+// ImportStats counts the items of an import of remote tracker items
+type ImportStats struct {
+	Fetched int `json:"fetched"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"`
+}
+
+// Value implements driver.Valuer
+func (s ImportStats) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan implements sql.Scanner
+func (s *ImportStats) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	}
+	return errors.Errorf("cannot scan %T into import stats", src)
 }
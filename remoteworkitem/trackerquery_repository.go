@@ -5,10 +5,14 @@ import (
 	"strconv"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/workitem"
 
 	"github.com/jinzhu/gorm"
-	"github.com/pkg/errors"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 )
 
@@ -22,9 +26,11 @@ func NewTrackerQueryRepository(db *gorm.DB) *GormTrackerQueryRepository {
 	return &GormTrackerQueryRepository{db}
 }
 
-// Create creates a new tracker query in the repository
+// Create creates a new tracker query in the repository. The remote items are imported as work items
+// of the given space, if any, and of the given type, bugs if nil, with the given field mappings in
+// addition to the mapping of the tracker.
 // returns BadParameterError, ConversionError or InternalError
-func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, schedule string, tracker string) (*app.TrackerQuery, error) {
+func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, schedule string, tracker string, spaceID *uuid.UUID, workItemType *string, mapping []*app.FieldMapping) (*app.TrackerQuery, error) {
 	tid, err := strconv.ParseUint(tracker, 10, 64)
 	if err != nil || tid == 0 {
 		// treating this as a not found error: the fact that we're using number internal is implementation detail
@@ -39,7 +45,15 @@ func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, s
 	tq := TrackerQuery{
 		Query:     query,
 		Schedule:  schedule,
-		TrackerID: tid}
+		TrackerID: tid,
+		SpaceID:   spaceID,
+		Mapping:   convertFieldMappings(mapping)}
+	if workItemType != nil {
+		tq.WorkItemType = *workItemType
+	}
+	if err := r.validateMapping(ctx, tq); err != nil {
+		return nil, err
+	}
 	tx := r.db
 	if err := tx.Create(&tq).Error; err != nil {
 		log.Error(ctx, map[string]interface{}{
@@ -49,11 +63,7 @@ func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, s
 		return nil, InternalError{simpleError{err.Error()}}
 	}
 
-	tq2 := app.TrackerQuery{
-		ID:        strconv.FormatUint(tq.ID, 10),
-		Query:     query,
-		Schedule:  schedule,
-		TrackerID: tracker}
+	tq2 := convertTrackerQuery(tq)
 
 	log.Info(ctx, map[string]interface{}{
 		"pkg":          "remoteworkitem",
@@ -61,7 +71,7 @@ func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, s
 		"trackerQuery": tq,
 	}, "Created tracker query")
 
-	return &tq2, nil
+	return tq2, nil
 }
 
 // Load returns the tracker query for the given id
@@ -85,17 +95,12 @@ func (r *GormTrackerQueryRepository) Load(ctx context.Context, ID string) (*app.
 		}, "tracker resource not found")
 		return nil, NotFoundError{"tracker query", ID}
 	}
-	tq := app.TrackerQuery{
-		ID:        strconv.FormatUint(res.ID, 10),
-		Query:     res.Query,
-		Schedule:  res.Schedule,
-		TrackerID: strconv.FormatUint(res.TrackerID, 10)}
-
-	return &tq, nil
+	return convertTrackerQuery(res), nil
 }
 
-// Save updates the given tracker query in storage.
-// returns NotFoundError, ConversionError or InternalError
+// Save updates the given tracker query in storage. The space, the work item type and the field mappings
+// are kept if not given.
+// returns NotFoundError, BadParameterError, ConversionError or InternalError
 func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQuery) (*app.TrackerQuery, error) {
 	res := TrackerQuery{}
 	id, err := strconv.ParseUint(tq.ID, 10, 64)
@@ -138,10 +143,30 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 	}
 
 	newTq := TrackerQuery{
-		ID:        id,
-		Schedule:  tq.Schedule,
-		Query:     tq.Query,
-		TrackerID: tid}
+		ID:           id,
+		Schedule:     tq.Schedule,
+		Query:        tq.Query,
+		TrackerID:    tid,
+		LastRunStats: res.LastRunStats,
+		SpaceID:      res.SpaceID,
+		WorkItemType: res.WorkItemType,
+		Mapping:      res.Mapping}
+	if res.Query == tq.Query && res.TrackerID == tid {
+		// a different query or tracker starts the import all over
+		newTq.SyncCursor = res.SyncCursor
+	}
+	if tq.SpaceID != nil {
+		newTq.SpaceID = tq.SpaceID
+	}
+	if tq.WorkItemType != nil {
+		newTq.WorkItemType = *tq.WorkItemType
+	}
+	if tq.Mapping != nil {
+		newTq.Mapping = convertFieldMappings(tq.Mapping)
+	}
+	if err := r.validateMapping(ctx, newTq); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Save(&newTq).Error; err != nil {
 		log.Error(ctx, map[string]interface{}{
@@ -157,13 +182,7 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 		"trackerQuery": newTq,
 	}, "Updated tracker query")
 
-	t2 := app.TrackerQuery{
-		ID:        tq.ID,
-		Schedule:  tq.Schedule,
-		Query:     tq.Query,
-		TrackerID: tq.TrackerID}
-
-	return &t2, nil
+	return convertTrackerQuery(newTq), nil
 }
 
 // Delete deletes the tracker query with the given id
@@ -191,16 +210,69 @@ func (r *GormTrackerQueryRepository) Delete(ctx context.Context, ID string) erro
 func (r *GormTrackerQueryRepository) List(ctx context.Context) ([]*app.TrackerQuery, error) {
 	var rows []TrackerQuery
 	if err := r.db.Find(&rows).Error; err != nil {
-		return nil, errors.WithStack(err)
+		return nil, errs.WithStack(err)
 	}
 	result := make([]*app.TrackerQuery, len(rows))
 	for i, tq := range rows {
-		t := app.TrackerQuery{
-			ID:        strconv.FormatUint(tq.ID, 10),
-			Schedule:  tq.Schedule,
-			Query:     tq.Query,
-			TrackerID: strconv.FormatUint(tq.TrackerID, 10)}
-		result[i] = &t
+		result[i] = convertTrackerQuery(tq)
 	}
 	return result, nil
 }
+
+// ListRuns returns the latest runs of the tracker query with the given id, at most limit and newest first
+// returns NotFoundError or InternalError
+func (r *GormTrackerQueryRepository) ListRuns(ctx context.Context, ID string, limit int) ([]*app.TrackerQueryRun, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	if r.db.First(&TrackerQuery{}, id).RecordNotFound() {
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	var rows []TrackerQueryRun
+	if err := r.db.Where("tracker_query_id = ?", id).Order("started_at desc, id desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	result := make([]*app.TrackerQueryRun, len(rows))
+	for i, run := range rows {
+		result[i] = convertRun(run)
+	}
+	return result, nil
+}
+
+// validateMapping checks that the space and the work item type of the tracker query exist and that its field
+// mappings fit the fields of the type
+// returns BadParameterError or InternalError
+func (r *GormTrackerQueryRepository) validateMapping(ctx context.Context, tq TrackerQuery) error {
+	if tq.SpaceID != nil {
+		if _, err := space.NewRepository(r.db).Load(ctx, *tq.SpaceID); err != nil {
+			if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+				return BadParameterError{parameter: "spaceID", value: *tq.SpaceID}
+			}
+			return InternalError{simpleError{err.Error()}}
+		}
+	}
+	wit, err := workitem.NewWorkItemTypeRepository(r.db).LoadTypeInSpaceFromDB(ctx, tq.SpaceID, tq.targetType())
+	if err != nil {
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return BadParameterError{parameter: "workItemType", value: tq.WorkItemType}
+		}
+		return InternalError{simpleError{err.Error()}}
+	}
+	_, err = tq.Mapping.workItemMap(*wit, nil)
+	return err
+}
+
+// convertTrackerQuery converts the tracker query for the REST API
+func convertTrackerQuery(tq TrackerQuery) *app.TrackerQuery {
+	workItemType := tq.targetType()
+	return &app.TrackerQuery{
+		ID:           strconv.FormatUint(tq.ID, 10),
+		Query:        tq.Query,
+		Schedule:     tq.Schedule,
+		TrackerID:    strconv.FormatUint(tq.TrackerID, 10),
+		SpaceID:      tq.SpaceID,
+		WorkItemType: &workItemType,
+		Mapping:      convertFieldMappingsFromModel(tq.Mapping)}
+}
@@ -63,7 +63,10 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailDeleteZeroID() {
 		context.Background(),
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		tr.ID)
+		tr.ID,
+		nil,
+		nil,
+		nil)
 	if err != nil {
 		s.T().Error("Could not create tracker query", err)
 	}
@@ -88,7 +91,10 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailSaveZeroID() {
 		context.Background(),
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		tr.ID)
+		tr.ID,
+		nil,
+		nil,
+		nil)
 	if err != nil {
 		s.T().Error("Could not create tracker query", err)
 	}
@@ -114,7 +120,10 @@ func (s *trackerQueryRepoBlackBoxTest) TestFaiLoadZeroID() {
 		context.Background(),
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		tr.ID)
+		tr.ID,
+		nil,
+		nil,
+		nil)
 	if err != nil {
 		s.T().Error("Could not create tracker query", err)
 	}
@@ -1,24 +1,31 @@
 package remoteworkitem
 
 import (
+	"strconv"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 
+	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTrackerQueryCreate(t *testing.T) {
 	doWithTrackerRepositories(t, func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository) {
-		query, err := queryRepo.Create(context.Background(), "abc", "xyz", "lmn")
+		query, err := queryRepo.Create(context.Background(), "abc", "xyz", "lmn", nil, nil, nil)
 		assert.IsType(t, NotFoundError{}, err)
 		assert.Nil(t, query)
 
 		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
-		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID)
+		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID, nil, nil, nil)
 		assert.Nil(t, err)
 		assert.Equal(t, "abc", query.Query)
 		assert.Equal(t, "xyz", query.Schedule)
@@ -38,7 +45,7 @@ func TestTrackerQuerySave(t *testing.T) {
 
 		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
 		tracker2, err := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
-		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID)
+		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID, nil, nil, nil)
 		query2, err := queryRepo.Load(context.Background(), query.ID)
 		assert.Nil(t, err)
 		assert.Equal(t, query, query2)
@@ -63,13 +70,138 @@ func TestTrackerQuerySave(t *testing.T) {
 	})
 }
 
+func TestTrackerQuerySaveResetsSyncCursor(t *testing.T) {
+	doWithTransaction(t, func(db *gorm.DB) {
+		trackerRepo := NewTrackerRepository(db)
+		queryRepo := NewTrackerQueryRepository(db)
+		tracker, err := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		require.Nil(t, err)
+		query, err := queryRepo.Create(context.Background(), "is:open", "15 * * * * *", tracker.ID, nil, nil, nil)
+		require.Nil(t, err)
+		cursor := time.Now()
+		require.Nil(t, db.Model(&TrackerQuery{}).Where("id = ?", query.ID).Update("sync_cursor", cursor).Error)
+		loadCursor := func() *time.Time {
+			var tq TrackerQuery
+			require.Nil(t, db.First(&tq, query.ID).Error)
+			return tq.SyncCursor
+		}
+		// a different schedule continues the import
+		query.Schedule = "30 * * * * *"
+		_, err = queryRepo.Save(context.Background(), *query)
+		require.Nil(t, err)
+		assert.NotNil(t, loadCursor())
+		// a different query starts it over
+		query.Query = "is:closed"
+		_, err = queryRepo.Save(context.Background(), *query)
+		require.Nil(t, err)
+		assert.Nil(t, loadCursor())
+	})
+}
+
+func TestTrackerQueryMapping(t *testing.T) {
+	doWithTrackerRepositories(t, func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository) {
+		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
+		require.Nil(t, err)
+		mapping := []*app.FieldMapping{{Path: "fields.customfield_10002", Field: workitem.SystemTitle, Converter: ConverterString}}
+		// invalid mappings are rejected
+		unknownType := "unknown"
+		_, err = queryRepo.Create(context.Background(), "project = ARQ", "15 * * * * *", tracker.ID, nil, &unknownType, nil)
+		assert.IsType(t, BadParameterError{}, err)
+		_, err = queryRepo.Create(context.Background(), "project = ARQ", "15 * * * * *", tracker.ID, nil, nil,
+			[]*app.FieldMapping{{Path: "fields.customfield_10002", Field: "system.storypoints", Converter: ConverterNumber}})
+		assert.IsType(t, BadParameterError{}, err)
+		// valid ones are kept
+		query, err := queryRepo.Create(context.Background(), "project = ARQ", "15 * * * * *", tracker.ID, nil, nil, mapping)
+		require.Nil(t, err)
+		assert.Equal(t, workitem.SystemBug, *query.WorkItemType)
+		assert.Equal(t, mapping, query.Mapping)
+		query.WorkItemType = nil
+		query.Mapping = nil
+		query, err = queryRepo.Save(context.Background(), *query)
+		require.Nil(t, err)
+		assert.Equal(t, mapping, query.Mapping)
+		query, err = queryRepo.Load(context.Background(), query.ID)
+		require.Nil(t, err)
+		assert.Equal(t, mapping, query.Mapping)
+	})
+}
+
+func TestTrackerQuerySpace(t *testing.T) {
+	doWithTransaction(t, func(db *gorm.DB) {
+		defer cleaner.DeleteCreatedEntities(db)()
+		ctx := context.Background()
+		tracker, err := NewTrackerRepository(db).Create(ctx, "http://issues.jboss.com", ProviderJira)
+		require.Nil(t, err)
+		s, err := space.NewRepository(db).Create(ctx, &space.Space{Name: "Space of tracker queries " + uuid.NewV4().String()})
+		require.Nil(t, err)
+		bug := workitem.SystemBug
+		_, err = workitem.NewWorkItemTypeRepository(db).CreateInSpace(ctx, s.ID, &bug, "spacebug", map[string]app.FieldDefinition{})
+		require.Nil(t, err)
+		queryRepo := NewTrackerQueryRepository(db)
+		// the type is only found in the space
+		spaceBug := "spacebug"
+		_, err = queryRepo.Create(ctx, "project = ARQ", "15 * * * * *", tracker.ID, nil, &spaceBug, nil)
+		assert.IsType(t, BadParameterError{}, err)
+		unknownSpace := uuid.NewV4()
+		_, err = queryRepo.Create(ctx, "project = ARQ", "15 * * * * *", tracker.ID, &unknownSpace, nil, nil)
+		assert.IsType(t, BadParameterError{}, err)
+		query, err := queryRepo.Create(ctx, "project = ARQ", "15 * * * * *", tracker.ID, &s.ID, &spaceBug, nil)
+		require.Nil(t, err)
+		require.NotNil(t, query.SpaceID)
+		assert.Equal(t, s.ID, *query.SpaceID)
+		// the space is kept if not given
+		query.SpaceID = nil
+		query, err = queryRepo.Save(ctx, *query)
+		require.Nil(t, err)
+		require.NotNil(t, query.SpaceID)
+		assert.Equal(t, s.ID, *query.SpaceID)
+
+		id, err := strconv.ParseUint(query.ID, 10, 64)
+		require.Nil(t, err)
+		var tq TrackerQuery
+		require.Nil(t, db.First(&tq, id).Error)
+		target, err := importTargetOf(db, tq, ProviderJira)
+		require.Nil(t, err)
+		assert.Equal(t, "spacebug", target.workItemType)
+		assert.Equal(t, &s.ID, target.spaceID)
+	})
+}
+
+func TestTrackerQueryRunRecordsErrors(t *testing.T) {
+	doWithTransaction(t, func(db *gorm.DB) {
+		tracker := Tracker{URL: "http://bugzilla.redhat.com", Type: "unknown"}
+		require.Nil(t, db.Create(&tracker).Error)
+		tq := TrackerQuery{Query: "product = RHEL", Schedule: "15 * * * * *", TrackerID: tracker.ID}
+		require.Nil(t, db.Create(&tq).Error)
+		tsList, err := trackerSchedules(db.Where("tracker_queries.id = ?", tq.ID))
+		require.Nil(t, err)
+		require.Len(t, tsList, 1)
+		// when
+		run, err := startRun(db, tsList[0])
+		require.Nil(t, err)
+		require.Nil(t, importQuery(db, tsList[0], run))
+		// then
+		queryRepo := NewTrackerQueryRepository(db)
+		runs, err := queryRepo.ListRuns(context.Background(), strconv.FormatUint(tq.ID, 10), 10)
+		require.Nil(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, run.BatchID, runs[0].BatchID)
+		assert.NotNil(t, runs[0].FinishedAt)
+		assert.Equal(t, 0, runs[0].Fetched)
+		assert.Equal(t, []string{"unknown tracker type unknown"}, runs[0].Errors)
+
+		_, err = queryRepo.ListRuns(context.Background(), "100000", 10)
+		assert.IsType(t, NotFoundError{}, err)
+	})
+}
+
 func TestTrackerQueryDelete(t *testing.T) {
 	doWithTrackerRepositories(t, func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository) {
 		err := queryRepo.Delete(context.Background(), "asdf")
 		assert.IsType(t, NotFoundError{}, err)
 
 		tracker, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
-		tq, _ := queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker.ID)
+		tq, _ := queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker.ID, nil, nil, nil)
 		err = queryRepo.Delete(context.Background(), tq.ID)
 		assert.Nil(t, err)
 
@@ -88,12 +220,12 @@ func TestTrackerQueryList(t *testing.T) {
 		trackerqueries1, _ := queryRepo.List(context.Background())
 
 		tracker1, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
-		queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID)
-		queryRepo.Create(context.Background(), "is:close is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID)
+		queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID, nil, nil, nil)
+		queryRepo.Create(context.Background(), "is:close is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID, nil, nil, nil)
 
 		tracker2, _ := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
-		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'arquillian'", "15 * * * * *", tracker2.ID)
-		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'javadoc'", "15 * * * * *", tracker2.ID)
+		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'arquillian'", "15 * * * * *", tracker2.ID, nil, nil, nil)
+		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'javadoc'", "15 * * * * *", tracker2.ID, nil, nil, nil)
 
 		trackerqueries2, _ := queryRepo.List(context.Background())
 		assert.Equal(t, len(trackerqueries1)+4, len(trackerqueries2))
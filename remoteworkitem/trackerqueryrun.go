@@ -0,0 +1,79 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/pkg/errors"
+)
+
+// maxRunErrors is the number of errors recorded per run, the others are only logged
+const maxRunErrors = 100
+
+// TrackerQueryRun records an import of the items of a tracker query
+type TrackerQueryRun struct {
+	ID             uint64 `gorm:"primary_key"`
+	TrackerQueryID uint64
+	// BatchID identifies the run in the logs
+	BatchID    string `sql:"type:uuid"`
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	Stats      ImportStats `sql:"type:jsonb"`
+	// Errors are the reasons why items failed to import or the import stopped
+	Errors RunErrors `sql:"type:jsonb"`
+}
+
+// RunErrors are the error messages of a run
+type RunErrors []string
+
+// Value implements driver.Valuer
+func (e RunErrors) Value() (driver.Value, error) {
+	if e == nil {
+		e = RunErrors{}
+	}
+	return json.Marshal(e)
+}
+
+// Scan implements sql.Scanner
+func (e *RunErrors) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, e)
+	case string:
+		return json.Unmarshal([]byte(s), e)
+	case nil:
+		*e = nil
+		return nil
+	}
+	return errors.Errorf("cannot scan %T into run errors", src)
+}
+
+// add records the given error, unless there are too many already
+func (r *TrackerQueryRun) add(err error) {
+	if len(r.Errors) < maxRunErrors {
+		r.Errors = append(r.Errors, err.Error())
+	}
+}
+
+// convertRun converts the run into its representation in the API
+func convertRun(r TrackerQueryRun) *app.TrackerQueryRun {
+	errs := r.Errors
+	if errs == nil {
+		errs = RunErrors{}
+	}
+	return &app.TrackerQueryRun{
+		ID:             strconv.FormatUint(r.ID, 10),
+		TrackerQueryID: strconv.FormatUint(r.TrackerQueryID, 10),
+		BatchID:        r.BatchID,
+		StartedAt:      r.StartedAt,
+		FinishedAt:     r.FinishedAt,
+		Fetched:        r.Stats.Fetched,
+		Created:        r.Stats.Created,
+		Updated:        r.Stats.Updated,
+		Failed:         r.Stats.Failed,
+		Errors:         []string(errs),
+	}
+}
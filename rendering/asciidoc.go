@@ -0,0 +1,411 @@
+package rendering
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	asciidocSection    = regexp.MustCompile(`^(={1,6})\s+(.+?)(?:\s*\[\[([\w-]+)\]\])?$`)
+	asciidocListItem   = regexp.MustCompile(`^(\*{1,5}|-|\.{1,5})\s+(.*)$`)
+	asciidocLabeled    = regexp.MustCompile(`^(.+?)::(?:\s+(.*))?$`)
+	asciidocAdmonition = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|WARNING|CAUTION):\s+(.*)$`)
+	asciidocAttribute  = regexp.MustCompile(`^:!?[\w-]+!?:`)
+	asciidocBlockTitle = regexp.MustCompile(`^\.([^\s.].*)$`)
+	// the block attribute lines and the block anchors, as in [source,go] or [[id]]
+	asciidocBlockAttributes = regexp.MustCompile(`^\[(.*)\]$`)
+	// the delimiters of the listing, literal, quote, example, sidebar, passthrough and comment blocks
+	asciidocDelimiter = regexp.MustCompile(`^(-{4,}|\.{4,}|_{4,}|={4,}|\*{4,}|\+{4,}|/{4,})$`)
+
+	asciidocMonospace = regexp.MustCompile("`([^`]+)`")
+	asciidocImage     = regexp.MustCompile(`image::?([^\s\[]+)\[([^\]]*)\]`)
+	asciidocLink      = regexp.MustCompile(`(?:link:([^\s\[]+)|((?:https?|ftp|irc|mailto):[^\s\[]+))\[([^\]]*)\]`)
+	asciidocURL       = regexp.MustCompile(`\b(?:https?|ftp)://[^\s\[\x00]+`)
+	asciidocXref      = regexp.MustCompile(`&lt;&lt;([\w-]+)(?:,\s*(.+?))?&gt;&gt;`)
+	asciidocAnchor    = regexp.MustCompile(`\[\[[\w-]+\]\]`)
+	asciidocEntity    = regexp.MustCompile(`&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z]+);`)
+	// the named attributes of a macro, as in [text, window=_blank]
+	asciidocNamedAttribute = regexp.MustCompile(`,\s*[\w-]+=.*$`)
+)
+
+// asciidocEffects are the constrained text formatting marks of AsciiDoc and their HTML elements, the
+// unconstrained ones double the mark
+var asciidocEffects = []struct {
+	mark    string
+	element string
+}{
+	{"*", "strong"},
+	{"_", "em"},
+	{"#", "mark"},
+	{"^", "sup"},
+	{"~", "sub"},
+}
+
+// renderAsciiDoc converts the given AsciiDoc content to HTML. The result is not sanitized.
+func renderAsciiDoc(content string) string {
+	r := asciidocRenderer{}
+	r.render(strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n"))
+	return r.out.String()
+}
+
+// asciidocRenderer renders the blocks of AsciiDoc content, it keeps track of the open paragraph and lists
+// and of the attributes and title given to the next block
+type asciidocRenderer struct {
+	out        bytes.Buffer
+	paragraph  []string
+	lists      []string
+	labeled    bool
+	attributes string
+	title      string
+}
+
+func (r *asciidocRenderer) render(lines []string) {
+	for i := 0; i < len(lines); i++ {
+		raw := strings.TrimRight(lines[i], " \t")
+		line := strings.TrimSpace(raw)
+		if asciidocDelimiter.MatchString(line) {
+			r.closeBlocks()
+			i = r.delimited(lines, i, line)
+			continue
+		}
+		if line == "|===" {
+			r.closeBlocks()
+			i = r.table(lines, i)
+			continue
+		}
+		switch {
+		case line == "":
+			r.closeBlocks()
+		case strings.HasPrefix(line, "//"):
+			// a comment
+		case line == "+" && len(r.lists) > 0:
+			// the continuation of a list item
+		case len(r.paragraph) == 0 && asciidocAttribute.MatchString(line):
+			// an attribute entry of the document header
+		case len(r.paragraph) == 0 && asciidocBlockAttributes.MatchString(line):
+			r.closeBlocks()
+			if !strings.HasPrefix(line, "[[") {
+				r.attributes = asciidocBlockAttributes.FindStringSubmatch(line)[1]
+			}
+		case len(r.paragraph) == 0 && asciidocBlockTitle.MatchString(line):
+			r.closeBlocks()
+			r.title = asciidocBlockTitle.FindStringSubmatch(line)[1]
+		case line == "'''":
+			r.closeBlocks()
+			r.out.WriteString("<hr>\n")
+		case asciidocSection.MatchString(line):
+			r.closeBlocks()
+			m := asciidocSection.FindStringSubmatch(line)
+			level := len(m[1])
+			if m[3] != "" {
+				fmt.Fprintf(&r.out, "<h%d id=\"%s\">%s</h%d>\n", level, m[3], renderAsciiDocInline(m[2]), level)
+			} else {
+				fmt.Fprintf(&r.out, "<h%d>%s</h%d>\n", level, renderAsciiDocInline(m[2]), level)
+			}
+		case asciidocListItem.MatchString(line):
+			r.closeParagraph()
+			r.closeLabeled()
+			m := asciidocListItem.FindStringSubmatch(line)
+			r.listItem(m[1], m[2])
+		case len(r.lists) == 0 && len(r.paragraph) == 0 && asciidocLabeled.MatchString(line):
+			m := asciidocLabeled.FindStringSubmatch(line)
+			r.labeledItem(m[1], m[2])
+		case len(r.lists) == 0 && len(r.paragraph) == 0 && !r.labeled && raw != line:
+			i = r.literalParagraph(lines, i)
+		case len(r.lists) > 0:
+			// the text of a list item may span several lines
+			r.out.WriteString(" " + renderAsciiDocInline(line))
+		default:
+			r.closeLabeled()
+			r.paragraph = append(r.paragraph, line)
+		}
+	}
+	r.closeBlocks()
+}
+
+// delimited renders the delimited block starting at the given line and returns its last line
+func (r *asciidocRenderer) delimited(lines []string, start int, delimiter string) int {
+	var body []string
+	end := len(lines) - 1
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == delimiter {
+			end = i
+			break
+		}
+		body = append(body, lines[i])
+	}
+	attributes := r.attributes
+	r.attributes = ""
+	if delimiter[0] == '/' {
+		// a comment block
+		r.title = ""
+		return end
+	}
+	r.writeTitle()
+	switch delimiter[0] {
+	case '-':
+		text := escapeHTML(strings.Join(body, "\n"))
+		if language := asciidocSourceLanguage(attributes); language != "" {
+			fmt.Fprintf(&r.out, "<pre><code class=\"language-%s\">%s</code></pre>\n", language, text)
+		} else {
+			fmt.Fprintf(&r.out, "<pre><code>%s</code></pre>\n", text)
+		}
+	case '.':
+		fmt.Fprintf(&r.out, "<pre>%s</pre>\n", escapeHTML(strings.Join(body, "\n")))
+	case '+':
+		// passthrough content, it is sanitized with the rest of the document
+		r.out.WriteString(strings.Join(body, "\n") + "\n")
+	case '_':
+		inner := asciidocRenderer{}
+		inner.render(body)
+		fmt.Fprintf(&r.out, "<blockquote>\n%s</blockquote>\n", inner.out.String())
+	default:
+		inner := asciidocRenderer{}
+		inner.render(body)
+		fmt.Fprintf(&r.out, "<div>\n%s</div>\n", inner.out.String())
+	}
+	return end
+}
+
+// asciidocSourceLanguage returns the language of a source block given by its attributes, as in [source,go]
+func asciidocSourceLanguage(attributes string) string {
+	parts := strings.Split(attributes, ",")
+	if len(parts) < 2 || strings.TrimSpace(parts[0]) != "source" {
+		return ""
+	}
+	if language := strings.TrimSpace(parts[1]); isAlphanumeric(language) {
+		return language
+	}
+	return ""
+}
+
+// table renders the table starting at the given line and returns its last line. The number of cells on the
+// first line gives the number of columns and the first line is the header if a blank line follows it.
+func (r *asciidocRenderer) table(lines []string, start int) int {
+	var cells []string
+	columns, header := 0, false
+	end := len(lines) - 1
+	first := -1
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "|===" {
+			end = i
+			break
+		}
+		if line == "" {
+			if first >= 0 && i == first+1 {
+				header = true
+			}
+			continue
+		}
+		lineCells := strings.Split(line, "|")
+		if !strings.HasPrefix(line, "|") && len(cells) > 0 {
+			// the text continues the previous cell
+			cells[len(cells)-1] += " " + strings.TrimSpace(lineCells[0])
+		}
+		for _, cell := range lineCells[1:] {
+			cells = append(cells, strings.TrimSpace(cell))
+		}
+		if first < 0 {
+			first = i
+			columns = len(lineCells) - 1
+		}
+	}
+	r.attributes = ""
+	r.writeTitle()
+	r.out.WriteString("<table>\n")
+	for row := 0; columns > 0 && row*columns < len(cells); row++ {
+		element := "td"
+		if row == 0 && header {
+			element = "th"
+		}
+		r.out.WriteString("<tr>")
+		for c := row * columns; c < (row+1)*columns && c < len(cells); c++ {
+			fmt.Fprintf(&r.out, "<%s>%s</%s>", element, renderAsciiDocInline(cells[c]), element)
+		}
+		r.out.WriteString("</tr>\n")
+	}
+	r.out.WriteString("</table>\n")
+	return end
+}
+
+// literalParagraph renders the indented lines starting at the given line as they are and returns the last one
+func (r *asciidocRenderer) literalParagraph(lines []string, start int) int {
+	end := start
+	for end+1 < len(lines) && strings.TrimSpace(lines[end+1]) != "" {
+		end++
+	}
+	body := make([]string, 0, end-start+1)
+	for _, line := range lines[start : end+1] {
+		body = append(body, strings.TrimSpace(line))
+	}
+	r.writeTitle()
+	fmt.Fprintf(&r.out, "<pre>%s</pre>\n", escapeHTML(strings.Join(body, "\n")))
+	return end
+}
+
+// listItem renders an item of the list with the given marker. A marker already used by an open list continues
+// that list, a new marker starts a list nested in the current item.
+func (r *asciidocRenderer) listItem(marker, text string) {
+	level := -1
+	for i, m := range r.lists {
+		if m == marker {
+			level = i
+		}
+	}
+	if level >= 0 {
+		for len(r.lists) > level+1 {
+			r.popList()
+		}
+		r.out.WriteString("</li>\n<li>")
+	} else {
+		if len(r.lists) == 0 {
+			r.writeTitle()
+		}
+		fmt.Fprintf(&r.out, "<%s>\n<li>", asciidocListElement(marker))
+		r.lists = append(r.lists, marker)
+	}
+	r.out.WriteString(renderAsciiDocInline(text))
+}
+
+func asciidocListElement(marker string) string {
+	if strings.HasPrefix(marker, ".") {
+		return "ol"
+	}
+	return "ul"
+}
+
+func (r *asciidocRenderer) popList() {
+	fmt.Fprintf(&r.out, "</li>\n</%s>\n", asciidocListElement(r.lists[len(r.lists)-1]))
+	r.lists = r.lists[:len(r.lists)-1]
+}
+
+// labeledItem renders an item of a labeled list
+func (r *asciidocRenderer) labeledItem(term, description string) {
+	if !r.labeled {
+		r.writeTitle()
+		r.out.WriteString("<dl>\n")
+		r.labeled = true
+	}
+	fmt.Fprintf(&r.out, "<dt>%s</dt>\n", renderAsciiDocInline(strings.TrimSpace(term)))
+	if description != "" {
+		fmt.Fprintf(&r.out, "<dd>%s</dd>\n", renderAsciiDocInline(description))
+	}
+}
+
+func (r *asciidocRenderer) closeParagraph() {
+	if len(r.paragraph) == 0 {
+		return
+	}
+	r.writeTitle()
+	var text bytes.Buffer
+	for i, line := range r.paragraph {
+		if i > 0 {
+			text.WriteString("\n")
+		}
+		if strings.HasSuffix(line, " +") {
+			// a hard line break
+			text.WriteString(renderAsciiDocInline(strings.TrimSuffix(line, " +")) + "<br>")
+			continue
+		}
+		text.WriteString(renderAsciiDocInline(line))
+	}
+	if m := asciidocAdmonition.FindStringSubmatch(r.paragraph[0]); m != nil {
+		label := strings.Title(strings.ToLower(m[1]))
+		fmt.Fprintf(&r.out, "<p><strong>%s:</strong> %s</p>\n", label, strings.TrimPrefix(text.String(), renderAsciiDocInline(m[1]+": ")))
+	} else {
+		fmt.Fprintf(&r.out, "<p>%s</p>\n", text.String())
+	}
+	r.paragraph = nil
+}
+
+func (r *asciidocRenderer) closeLists() {
+	for len(r.lists) > 0 {
+		r.popList()
+	}
+}
+
+func (r *asciidocRenderer) closeLabeled() {
+	if r.labeled {
+		r.out.WriteString("</dl>\n")
+		r.labeled = false
+	}
+}
+
+func (r *asciidocRenderer) closeBlocks() {
+	r.closeParagraph()
+	r.closeLists()
+	r.closeLabeled()
+}
+
+// writeTitle renders the title given to the next block
+func (r *asciidocRenderer) writeTitle() {
+	if r.title != "" {
+		fmt.Fprintf(&r.out, "<p><strong>%s</strong></p>\n", renderAsciiDocInline(r.title))
+		r.title = ""
+	}
+}
+
+// renderAsciiDocInline renders the monospaced text, images, links, cross references and text formatting of a line
+func renderAsciiDocInline(text string) string {
+	var rendered []string
+	// the rendered monospaced text, links and escaped characters are kept out of the text formatting
+	hold := func(html string) string {
+		rendered = append(rendered, html)
+		return fmt.Sprintf("\x00%d\x00", len(rendered)-1)
+	}
+	text = escapeHTML(text)
+	text = asciidocMonospace.ReplaceAllStringFunc(text, func(m string) string {
+		return hold("<code>" + asciidocMonospace.FindStringSubmatch(m)[1] + "</code>")
+	})
+	text = asciidocImage.ReplaceAllStringFunc(text, func(m string) string {
+		parts := asciidocImage.FindStringSubmatch(m)
+		alt := strings.TrimSpace(strings.Split(parts[2], ",")[0])
+		return hold(fmt.Sprintf(`<img src="%s" alt="%s">`, parts[1], alt))
+	})
+	text = asciidocLink.ReplaceAllStringFunc(text, func(m string) string {
+		parts := asciidocLink.FindStringSubmatch(m)
+		target := parts[1] + parts[2]
+		label := strings.TrimSpace(asciidocNamedAttribute.ReplaceAllString(parts[3], ""))
+		if label == "" {
+			label = target
+		}
+		return hold(fmt.Sprintf(`<a href="%s">%s</a>`, target, asciidocTextFormatting(label)))
+	})
+	text = asciidocXref.ReplaceAllStringFunc(text, func(m string) string {
+		parts := asciidocXref.FindStringSubmatch(m)
+		label := parts[2]
+		if label == "" {
+			label = parts[1]
+		}
+		return hold(fmt.Sprintf(`<a href="#%s">%s</a>`, parts[1], label))
+	})
+	text = asciidocURL.ReplaceAllStringFunc(text, func(m string) string {
+		url := strings.TrimRight(m, ".,;:!?)")
+		return hold(fmt.Sprintf(`<a href="%s">%s</a>`, url, url)) + m[len(url):]
+	})
+	text = asciidocAnchor.ReplaceAllString(text, "")
+	text = asciidocEntity.ReplaceAllStringFunc(text, hold)
+	text = asciidocTextFormatting(text)
+	// the held HTML may hold other held HTML, as links do
+	for i := len(rendered) - 1; i >= 0; i-- {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), rendered[i], -1)
+	}
+	return text
+}
+
+// asciidocTextFormatting renders the unconstrained and constrained formatting marks: **strong**, *strong*,
+// __emphasis__, _emphasis_, ##mark##, #mark#, ^superscript^ and ~subscript~
+func asciidocTextFormatting(text string) string {
+	for _, effect := range asciidocEffects {
+		double := regexp.QuoteMeta(effect.mark + effect.mark)
+		unconstrained := regexp.MustCompile(double + `(\S(?:.*?\S)?)` + double)
+		if effect.mark == "*" || effect.mark == "_" || effect.mark == "#" {
+			text = unconstrained.ReplaceAllString(text, "<"+effect.element+">$1</"+effect.element+">")
+		}
+		text = textEffect(text, effect.mark, effect.element)
+	}
+	return text
+}
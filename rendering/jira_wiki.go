@@ -0,0 +1,320 @@
+package rendering
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	jiraHeading    = regexp.MustCompile(`^h([1-6])\.\s+(.*)$`)
+	jiraListItem   = regexp.MustCompile(`^([*#-]+)\s+(.*)$`)
+	jiraRule       = regexp.MustCompile(`^-{4,}$`)
+	jiraBlockQuote = regexp.MustCompile(`^bq\.\s+(.*)$`)
+	// the {code} and {noformat} macros, with their parameters
+	jiraPreformatted = regexp.MustCompile(`^\{(code|noformat)(?::([^}]*))?\}`)
+	jiraMonospace    = regexp.MustCompile(`\{\{(.+?)\}\}`)
+	jiraLink         = regexp.MustCompile(`\[([^\[\]|]*)\|([^\[\]|]+)\]|\[([^\[\]|]+)\]`)
+)
+
+// jiraEffects are the text effects of the Jira wiki markup and their HTML elements
+var jiraEffects = []struct {
+	delimiter string
+	element   string
+}{
+	{"??", "cite"},
+	{"*", "strong"},
+	{"_", "em"},
+	{"-", "del"},
+	{"+", "ins"},
+	{"^", "sup"},
+	{"~", "sub"},
+}
+
+// renderJiraWiki converts the given Jira wiki markup to HTML. The result is not sanitized.
+func renderJiraWiki(content string) string {
+	r := jiraRenderer{}
+	r.render(strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n"))
+	return r.out.String()
+}
+
+// jiraRenderer renders the blocks of Jira wiki markup, it keeps track of the open paragraph, lists and table
+type jiraRenderer struct {
+	out       bytes.Buffer
+	paragraph []string
+	lists     []string
+	table     bool
+}
+
+func (r *jiraRenderer) render(lines []string) {
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if m := jiraPreformatted.FindStringSubmatch(line); m != nil {
+			r.closeBlocks()
+			i = r.preformatted(lines, i, m[1], m[2])
+			continue
+		}
+		if strings.HasPrefix(line, "{quote}") {
+			r.closeBlocks()
+			i = r.quote(lines, i)
+			continue
+		}
+		switch {
+		case line == "":
+			r.closeBlocks()
+		case jiraRule.MatchString(line):
+			r.closeBlocks()
+			r.out.WriteString("<hr>\n")
+		case jiraHeading.MatchString(line):
+			r.closeBlocks()
+			m := jiraHeading.FindStringSubmatch(line)
+			fmt.Fprintf(&r.out, "<h%s>%s</h%s>\n", m[1], renderJiraInline(m[2]), m[1])
+		case jiraBlockQuote.MatchString(line):
+			r.closeBlocks()
+			fmt.Fprintf(&r.out, "<blockquote><p>%s</p></blockquote>\n", renderJiraInline(jiraBlockQuote.FindStringSubmatch(line)[1]))
+		case jiraListItem.MatchString(line):
+			r.closeParagraph()
+			r.closeTable()
+			m := jiraListItem.FindStringSubmatch(line)
+			r.listItem(m[1], m[2])
+		case strings.HasPrefix(line, "|"):
+			r.closeParagraph()
+			r.closeLists()
+			r.tableRow(line)
+		default:
+			r.closeLists()
+			r.closeTable()
+			r.paragraph = append(r.paragraph, line)
+		}
+	}
+	r.closeBlocks()
+}
+
+// preformatted renders the {code} or {noformat} macro starting at the given line and returns its last line
+func (r *jiraRenderer) preformatted(lines []string, start int, macro, params string) int {
+	closing := "{" + macro + "}"
+	first := strings.TrimSpace(lines[start])
+	first = first[len(jiraPreformatted.FindString(first)):]
+	var body []string
+	end := len(lines) - 1
+	for i, line := start, first; i < len(lines); i++ {
+		if i > start {
+			line = lines[i]
+		}
+		if idx := strings.Index(line, closing); idx >= 0 {
+			body = append(body, line[:idx])
+			end = i
+			break
+		}
+		body = append(body, line)
+	}
+	if len(body) > 0 && strings.TrimSpace(body[0]) == "" {
+		body = body[1:]
+	}
+	text := escapeHTML(strings.TrimRight(strings.Join(body, "\n"), "\n "))
+	if macro == "noformat" {
+		fmt.Fprintf(&r.out, "<pre>%s</pre>\n", text)
+		return end
+	}
+	if language := jiraCodeLanguage(params); language != "" {
+		fmt.Fprintf(&r.out, "<pre><code class=\"language-%s\">%s</code></pre>\n", language, text)
+		return end
+	}
+	fmt.Fprintf(&r.out, "<pre><code>%s</code></pre>\n", text)
+	return end
+}
+
+// jiraCodeLanguage returns the language among the parameters of a {code} macro, the one without a name
+func jiraCodeLanguage(params string) string {
+	for _, param := range strings.Split(params, "|") {
+		param = strings.TrimSpace(param)
+		if param != "" && !strings.Contains(param, "=") && isAlphanumeric(param) {
+			return param
+		}
+	}
+	return ""
+}
+
+// quote renders the {quote} macro starting at the given line and returns its last line
+func (r *jiraRenderer) quote(lines []string, start int) int {
+	var body []string
+	end := len(lines) - 1
+	for i, line := start, strings.TrimPrefix(strings.TrimSpace(lines[start]), "{quote}"); i < len(lines); i++ {
+		if i > start {
+			line = lines[i]
+		}
+		if idx := strings.Index(line, "{quote}"); idx >= 0 {
+			body = append(body, line[:idx])
+			end = i
+			break
+		}
+		body = append(body, line)
+	}
+	inner := jiraRenderer{}
+	inner.render(body)
+	fmt.Fprintf(&r.out, "<blockquote>\n%s</blockquote>\n", inner.out.String())
+	return end
+}
+
+// listItem renders an item of the (nested) list given by the bullets, opening and closing the lists around it
+func (r *jiraRenderer) listItem(bullets, text string) {
+	kinds := make([]string, len(bullets))
+	for i, b := range bullets {
+		kinds[i] = "ul"
+		if b == '#' {
+			kinds[i] = "ol"
+		}
+	}
+	common := 0
+	for common < len(r.lists) && common < len(kinds) && r.lists[common] == kinds[common] {
+		common++
+	}
+	for len(r.lists) > common {
+		r.popList()
+	}
+	if len(r.lists) == len(kinds) {
+		r.out.WriteString("</li>\n<li>")
+	}
+	for len(r.lists) < len(kinds) {
+		kind := kinds[len(r.lists)]
+		fmt.Fprintf(&r.out, "<%s>\n<li>", kind)
+		r.lists = append(r.lists, kind)
+	}
+	r.out.WriteString(renderJiraInline(text))
+}
+
+func (r *jiraRenderer) popList() {
+	fmt.Fprintf(&r.out, "</li>\n</%s>\n", r.lists[len(r.lists)-1])
+	r.lists = r.lists[:len(r.lists)-1]
+}
+
+// tableRow renders a row of a table, || separates heading cells and | the others
+func (r *jiraRenderer) tableRow(line string) {
+	if !r.table {
+		r.out.WriteString("<table>\n")
+		r.table = true
+	}
+	r.out.WriteString("<tr>")
+	for len(line) > 0 {
+		element := "td"
+		if strings.HasPrefix(line, "||") {
+			element = "th"
+			line = line[2:]
+		} else {
+			line = line[1:]
+		}
+		end := jiraCellEnd(line)
+		cell := strings.TrimSpace(line[:end])
+		line = line[end:]
+		if cell == "" && strings.Trim(line, "| ") == "" {
+			// the delimiter closing the row
+			break
+		}
+		fmt.Fprintf(&r.out, "<%s>%s</%s>", element, renderJiraInline(cell), element)
+	}
+	r.out.WriteString("</tr>\n")
+}
+
+// jiraCellEnd returns the index of the delimiter ending the table cell, the | in links don't count
+func jiraCellEnd(line string) int {
+	depth := 0
+	for i, c := range line {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case c == '|' && depth == 0:
+			return i
+		}
+	}
+	return len(line)
+}
+
+func (r *jiraRenderer) closeParagraph() {
+	if len(r.paragraph) == 0 {
+		return
+	}
+	rendered := make([]string, len(r.paragraph))
+	for i, line := range r.paragraph {
+		rendered[i] = renderJiraInline(line)
+	}
+	fmt.Fprintf(&r.out, "<p>%s</p>\n", strings.Join(rendered, "<br>\n"))
+	r.paragraph = nil
+}
+
+func (r *jiraRenderer) closeLists() {
+	for len(r.lists) > 0 {
+		r.popList()
+	}
+}
+
+func (r *jiraRenderer) closeTable() {
+	if r.table {
+		r.out.WriteString("</table>\n")
+		r.table = false
+	}
+}
+
+func (r *jiraRenderer) closeBlocks() {
+	r.closeParagraph()
+	r.closeLists()
+	r.closeTable()
+}
+
+// renderJiraInline renders the monospaced text, links, mentions, forced line breaks and text effects of a line
+func renderJiraInline(text string) string {
+	var rendered []string
+	// the rendered monospaced text and links are kept out of the text effects
+	hold := func(html string) string {
+		rendered = append(rendered, html)
+		return fmt.Sprintf("\x00%d\x00", len(rendered)-1)
+	}
+	text = escapeHTML(text)
+	text = jiraMonospace.ReplaceAllStringFunc(text, func(m string) string {
+		return hold("<code>" + jiraMonospace.FindStringSubmatch(m)[1] + "</code>")
+	})
+	text = jiraLink.ReplaceAllStringFunc(text, func(m string) string {
+		parts := jiraLink.FindStringSubmatch(m)
+		if parts[3] == "" {
+			label := strings.TrimSpace(parts[1])
+			target := strings.TrimSpace(parts[2])
+			if label == "" {
+				label = target
+			}
+			return hold(fmt.Sprintf(`<a href="%s">%s</a>`, target, jiraTextEffects(label)))
+		}
+		target := strings.TrimSpace(parts[3])
+		if strings.HasPrefix(target, "~") {
+			return hold(fmt.Sprintf(`<span class="user-mention">@%s</span>`, strings.TrimPrefix(target, "~")))
+		}
+		if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+			return hold(fmt.Sprintf(`<a href="%s">%s</a>`, target, target))
+		}
+		return m
+	})
+	text = strings.Replace(jiraTextEffects(text), `\\`, "<br>", -1)
+	for i, html := range rendered {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), html, 1)
+	}
+	return text
+}
+
+// jiraTextEffects renders the text effects: *strong*, _emphasis_, ??citation??, -deleted-, +inserted+,
+// ^superscript^ and ~subscript~
+func jiraTextEffects(text string) string {
+	for _, effect := range jiraEffects {
+		text = textEffect(text, effect.delimiter, effect.element)
+	}
+	return text
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if !isWordChar(r) {
+			return false
+		}
+	}
+	return true
+}
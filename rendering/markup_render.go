@@ -9,7 +9,7 @@ import (
 
 // IsMarkupSupported indicates if the given markup is supported
 func IsMarkupSupported(markup string) bool {
-	if markup == SystemMarkupDefault || markup == SystemMarkupMarkdown {
+	if markup == SystemMarkupDefault || markup == SystemMarkupMarkdown || markup == SystemMarkupJiraWiki || markup == SystemMarkupAsciiDoc {
 		return true
 	}
 	return false
@@ -23,11 +23,22 @@ func RenderMarkupToHTML(content, markup string) string {
 		return content
 	case SystemMarkupMarkdown:
 		unsafe := blackfriday.MarkdownCommon([]byte(content))
-		p := bluemonday.UGCPolicy()
-		p.AllowAttrs("class").Matching(regexp.MustCompile("^language-[a-zA-Z0-9]+$")).OnElements("code")
-		html := string(p.SanitizeBytes(unsafe))
-		return html
+		return renderTaskLists(sanitize(unsafe))
+	case SystemMarkupJiraWiki:
+		unsafe := renderJiraWiki(content)
+		return sanitize([]byte(unsafe))
+	case SystemMarkupAsciiDoc:
+		unsafe := renderAsciiDoc(content)
+		return sanitize([]byte(unsafe))
 	default:
 		return ""
 	}
 }
+
+// sanitize removes the unsafe elements and attributes from the rendered HTML
+func sanitize(unsafe []byte) string {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile("^language-[a-zA-Z0-9]+$")).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile("^user-mention$")).OnElements("span")
+	return string(p.SanitizeBytes(unsafe))
+}
@@ -29,6 +29,122 @@ func TestIsMarkupSupported(t *testing.T) {
 	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupDefault))
 	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupPlainText))
 	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupMarkdown))
+	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupJiraWiki))
+	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupAsciiDoc))
 	assert.False(t, rendering.IsMarkupSupported(""))
 	assert.False(t, rendering.IsMarkupSupported("foo"))
 }
+
+func TestRenderJiraWikiContent(t *testing.T) {
+	t.Run("blocks", func(t *testing.T) {
+		content := "h2. Steps\n* one\n** nested\n# first\n\n||Head||Other||\n|cell|[docs|http://example.com]|\n----"
+		result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki)
+		assert.Contains(t, result, "<h2>Steps</h2>")
+		assert.Contains(t, result, "<ul>\n<li>one<ul>\n<li>nested</li>\n</ul>\n</li>\n</ul>")
+		assert.Contains(t, result, "<ol>\n<li>first</li>\n</ol>")
+		assert.Contains(t, result, "<tr><th>Head</th><th>Other</th></tr>")
+		assert.Contains(t, result, `<tr><td>cell</td><td><a href="http://example.com" rel="nofollow">docs</a></td></tr>`)
+		assert.Contains(t, result, "<hr>")
+	})
+	t.Run("text effects", func(t *testing.T) {
+		content := "*strong* _em_ -del- +ins+ ^sup^ ~sub~ ??cite?? {{*mono*}} well-known snake_case 3 - 2"
+		result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki)
+		assert.Equal(t, "<p><strong>strong</strong> <em>em</em> <del>del</del> <ins>ins</ins> <sup>sup</sup> <sub>sub</sub> <cite>cite</cite> <code>*mono*</code> well-known snake_case 3 - 2</p>\n", result)
+	})
+	t.Run("mentions and line breaks", func(t *testing.T) {
+		result := rendering.RenderMarkupToHTML(`ping [~aslak]\\thanks`, rendering.SystemMarkupJiraWiki)
+		assert.Equal(t, "<p>ping <span class=\"user-mention\">@aslak</span><br>thanks</p>\n", result)
+	})
+	t.Run("preformatted", func(t *testing.T) {
+		content := "{code:java}\nif (a < b) { *x*; }\n{code}\n{noformat}\n_raw_\n{noformat}"
+		result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki)
+		assert.Equal(t, "<pre><code class=\"language-java\">if (a &lt; b) { *x*; }</code></pre>\n<pre>_raw_</pre>\n", result)
+	})
+	t.Run("sanitized", func(t *testing.T) {
+		content := `<script>alert(1)</script> [click|javascript:alert(1)]`
+		result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki)
+		assert.NotContains(t, result, "<script>")
+		assert.NotContains(t, result, "javascript:")
+	})
+}
+
+func TestRenderAsciiDocContent(t *testing.T) {
+	t.Run("blocks", func(t *testing.T) {
+		content := "= Title\n:toc:\n\n== Steps [[steps]]\n* one\n** nested\n\n. first\n\nterm:: description\n\n|===\n|Head |Other\n\n|cell |link:http://example.com[docs]\n|===\n\n'''"
+		result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc)
+		assert.Contains(t, result, "<h1>Title</h1>")
+		assert.NotContains(t, result, "toc")
+		assert.Contains(t, result, `<h2 id="steps">Steps</h2>`)
+		assert.Contains(t, result, "<ul>\n<li>one<ul>\n<li>nested</li>\n</ul>\n</li>\n</ul>")
+		assert.Contains(t, result, "<ol>\n<li>first</li>\n</ol>")
+		assert.Contains(t, result, "<dl>\n<dt>term</dt>\n<dd>description</dd>\n</dl>")
+		assert.Contains(t, result, "<tr><th>Head</th><th>Other</th></tr>")
+		assert.Contains(t, result, `<tr><td>cell</td><td><a href="http://example.com" rel="nofollow">docs</a></td></tr>`)
+		assert.Contains(t, result, "<hr>")
+	})
+	t.Run("text formatting", func(t *testing.T) {
+		content := "*strong* _em_ #mark# ^sup^ ~sub~ **un**constrained `*mono*` snake_case a * b +\nnext"
+		result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc)
+		assert.Equal(t, "<p><strong>strong</strong> <em>em</em> <mark>mark</mark> <sup>sup</sup> <sub>sub</sub> <strong>un</strong>constrained <code>*mono*</code> snake_case a * b<br>\nnext</p>\n", result)
+	})
+	t.Run("delimited blocks", func(t *testing.T) {
+		content := "[source,go]\n----\nif a < b { *x* }\n----\n....\n_raw_\n....\n____\nquoted\n____\n////\nhidden\n////"
+		result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc)
+		assert.Equal(t, "<pre><code class=\"language-go\">if a &lt; b { *x* }</code></pre>\n<pre>_raw_</pre>\n<blockquote>\n<p>quoted</p>\n</blockquote>\n", result)
+	})
+	t.Run("admonitions and cross references", func(t *testing.T) {
+		result := rendering.RenderMarkupToHTML("NOTE: see <<steps,the steps>>", rendering.SystemMarkupAsciiDoc)
+		assert.Equal(t, "<p><strong>Note:</strong> see <a href=\"#steps\" rel=\"nofollow\">the steps</a></p>\n", result)
+	})
+	t.Run("sanitized", func(t *testing.T) {
+		content := "++++\n<script>alert(1)</script>\n++++\nlink:javascript:alert(1)[click]"
+		result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc)
+		assert.NotContains(t, result, "<script>")
+		assert.NotContains(t, result, "javascript:")
+	})
+}
+
+func TestParseReferences(t *testing.T) {
+	t.Run("mentions and work item references", func(t *testing.T) {
+		content := "@alice please look at #12 and #7 with @bob.smith, @alice and #12 again."
+		refs := rendering.ParseReferences(content, rendering.SystemMarkupPlainText)
+		assert.Equal(t, []rendering.Reference{
+			{Kind: rendering.ReferenceMention, Key: "alice"},
+			{Kind: rendering.ReferenceWorkItem, Key: "12"},
+			{Kind: rendering.ReferenceWorkItem, Key: "7"},
+			{Kind: rendering.ReferenceMention, Key: "bob.smith"},
+		}, refs)
+	})
+	t.Run("not references", func(t *testing.T) {
+		content := "mail alice@example.com, see http://example.com/#12, issue#3, #12a, don't `@bob #4`\n\n    @carol #5\n\n[#6](http://example.com)"
+		refs := rendering.ParseReferences(content, rendering.SystemMarkupMarkdown)
+		assert.Empty(t, refs)
+	})
+	t.Run("character entities", func(t *testing.T) {
+		for _, markup := range []string{rendering.SystemMarkupPlainText, rendering.SystemMarkupMarkdown, rendering.SystemMarkupJiraWiki} {
+			refs := rendering.ParseReferences(`it's "fixed" & closed, see #8`, markup)
+			assert.Equal(t, []rendering.Reference{{Kind: rendering.ReferenceWorkItem, Key: "8"}}, refs, markup)
+		}
+	})
+	t.Run("jira mentions", func(t *testing.T) {
+		refs := rendering.ParseReferences("ping [~aslak] about #3", rendering.SystemMarkupJiraWiki)
+		assert.Equal(t, []rendering.Reference{
+			{Kind: rendering.ReferenceMention, Key: "aslak"},
+			{Kind: rendering.ReferenceWorkItem, Key: "3"},
+		}, refs)
+	})
+}
+
+func TestLinkReferences(t *testing.T) {
+	rendered := rendering.RenderMarkupToHTML("@alice @bob see #12 and `#13`", rendering.SystemMarkupMarkdown)
+	result := rendering.LinkReferences(rendered, func(ref rendering.Reference) string {
+		switch ref.String() {
+		case "@alice":
+			return "http://example.com/users/1"
+		case "#12":
+			return "http://example.com/workitems/12"
+		}
+		return ""
+	})
+	assert.Equal(t, `<p><a href="http://example.com/users/1" class="user-mention">@alice</a> @bob see <a href="http://example.com/workitems/12" class="work-item-reference">#12</a> and <code>#13</code></p>`+"\n", result)
+}
@@ -0,0 +1,105 @@
+package rendering
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// htmlEntity matches an escaped HTML character
+var htmlEntity = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z]+);`)
+
+// textEffect wraps the text between the given delimiters into the given element. The opening delimiter
+// must not follow a letter or digit and the closing one must not be followed by one, the text between them
+// must not start or end with a space.
+func textEffect(text, delimiter, element string) string {
+	var out bytes.Buffer
+	// the text before pos is written
+	pos := 0
+	for search := 0; ; {
+		open := indexFrom(text, delimiter, search)
+		if open < 0 {
+			break
+		}
+		start := open + len(delimiter)
+		if (open > 0 && isWordChar(lastRune(text[:open]))) || start == len(text) || unicode.IsSpace(firstRune(text[start:])) {
+			search = open + 1
+			continue
+		}
+		closing := -1
+		for c := indexFrom(text, delimiter, start+1); c >= 0; c = indexFrom(text, delimiter, c+1) {
+			end := c + len(delimiter)
+			if !unicode.IsSpace(lastRune(text[:c])) && (end == len(text) || !isWordChar(firstRune(text[end:]))) {
+				closing = c
+				break
+			}
+		}
+		if closing < 0 {
+			search = open + 1
+			continue
+		}
+		out.WriteString(text[pos:open])
+		fmt.Fprintf(&out, "<%s>%s</%s>", element, text[start:closing], element)
+		pos = closing + len(delimiter)
+		search = pos
+	}
+	out.WriteString(text[pos:])
+	return out.String()
+}
+
+// indexFrom returns the index of the first sub string in s starting at the given index, -1 if there is none
+func indexFrom(s, sub string, from int) int {
+	if from > len(s) {
+		return -1
+	}
+	i := strings.Index(s[from:], sub)
+	if i < 0 {
+		return -1
+	}
+	return from + i
+}
+
+// escapeHTML escapes the HTML special characters of the given text, but keeps the entities already escaped
+func escapeHTML(text string) string {
+	var out bytes.Buffer
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '&':
+			if htmlEntity.MatchString(text[i:]) {
+				out.WriteByte(c)
+			} else {
+				out.WriteString("&amp;")
+			}
+		case '<':
+			out.WriteString("&lt;")
+		case '>':
+			out.WriteString("&gt;")
+		case '"':
+			out.WriteString("&#34;")
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+func lastRune(s string) rune {
+	r := []rune(s)
+	if len(r) == 0 {
+		return 0
+	}
+	return r[len(r)-1]
+}
@@ -0,0 +1,126 @@
+package rendering
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Kinds of the references found in markup content
+const (
+	// ReferenceMention is a mention of an identity by its username, as in @username
+	ReferenceMention = "mention"
+	// ReferenceWorkItem is a reference to a work item by its ID, as in #1234
+	ReferenceWorkItem = "workitem"
+)
+
+var (
+	htmlTag = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)[^>]*>`)
+	// character entities are matched as a whole so the escaped content doesn't yield references, as &amp;#39;
+	// does. The character before a mention or reference must not be part of a word, an e-mail address, a path
+	// or an entity.
+	referencePattern = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);|(^|[^\w@&/.#-])([@#])([a-zA-Z0-9](?:[\w.-]*[a-zA-Z0-9_])?)`)
+	workItemID       = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// referenceIgnoringElements are the elements whose text doesn't hold references
+var referenceIgnoringElements = map[string]bool{"a": true, "code": true, "pre": true}
+
+// Reference is a mention of an identity or a reference to a work item in markup content
+type Reference struct {
+	Kind string
+	// Key is the username of the mentioned identity or the ID of the referenced work item
+	Key string
+}
+
+// String returns the reference as written in the content
+func (r Reference) String() string {
+	if r.Kind == ReferenceMention {
+		return "@" + r.Key
+	}
+	return "#" + r.Key
+}
+
+// ParseReferences returns the mentions and work item references of the given content, in order of appearance
+// and without duplicates. The references in code and in links don't count.
+func ParseReferences(content, markup string) []Reference {
+	return FindReferences(RenderMarkupToHTML(html.EscapeString(content), markup))
+}
+
+// FindReferences returns the mentions and work item references of the given rendered content, in order of
+// appearance and without duplicates
+func FindReferences(rendered string) []Reference {
+	result := []Reference{}
+	found := map[Reference]bool{}
+	replaceReferences(rendered, func(ref Reference, text string) string {
+		if !found[ref] {
+			found[ref] = true
+			result = append(result, ref)
+		}
+		return text
+	})
+	return result
+}
+
+// LinkReferences links the mentions and work item references of the given rendered content to the URL
+// returned for them by the given function. The references without URL are left as they are.
+func LinkReferences(rendered string, href func(Reference) string) string {
+	return replaceReferences(rendered, func(ref Reference, text string) string {
+		url := href(ref)
+		if url == "" {
+			return text
+		}
+		class := "user-mention"
+		if ref.Kind == ReferenceWorkItem {
+			class = "work-item-reference"
+		}
+		return fmt.Sprintf(`<a href="%s" class="%s">%s</a>`, html.EscapeString(url), class, text)
+	})
+}
+
+// replaceReferences replaces the references in the text of the given HTML with the result of the given
+// function, the text of links, code and preformatted elements is left as it is
+func replaceReferences(rendered string, replace func(ref Reference, text string) string) string {
+	var out bytes.Buffer
+	ignored := 0
+	pos := 0
+	for _, tag := range htmlTag.FindAllStringSubmatchIndex(rendered, -1) {
+		out.WriteString(replaceTextReferences(rendered[pos:tag[0]], ignored > 0, replace))
+		out.WriteString(rendered[tag[0]:tag[1]])
+		pos = tag[1]
+		if referenceIgnoringElements[strings.ToLower(rendered[tag[4]:tag[5]])] {
+			if tag[3] > tag[2] {
+				if ignored > 0 {
+					ignored--
+				}
+			} else {
+				ignored++
+			}
+		}
+	}
+	out.WriteString(replaceTextReferences(rendered[pos:], ignored > 0, replace))
+	return out.String()
+}
+
+func replaceTextReferences(text string, ignored bool, replace func(ref Reference, text string) string) string {
+	if ignored || text == "" {
+		return text
+	}
+	return referencePattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := referencePattern.FindStringSubmatch(m)
+		if parts[2] == "" {
+			// a character entity
+			return m
+		}
+		ref := Reference{Kind: ReferenceMention, Key: parts[3]}
+		if parts[2] == "#" {
+			if !workItemID.MatchString(parts[3]) {
+				return m
+			}
+			ref.Kind = ReferenceWorkItem
+		}
+		return parts[1] + replace(ref, m[len(parts[1]):])
+	})
+}
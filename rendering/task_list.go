@@ -0,0 +1,110 @@
+package rendering
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+var (
+	// a task list item of GitHub flavored Markdown, as in "- [ ] task" or "1. [x] task"
+	taskListItem = regexp.MustCompile(`^(\s*(?:[-*+]|[0-9]+[.)])\s+\[)([ xX])(\](?:\s.*)?)$`)
+	// the fences of code blocks, their content is not part of task lists
+	codeFence = regexp.MustCompile("^ {0,3}(```|~~~)")
+	// a rendered list item starting with a checkbox
+	renderedTaskListItem = regexp.MustCompile(`<li>(<p>)?\[([ xX])\](\s|</)`)
+)
+
+// TaskListProgress counts the checked checkboxes of the task lists in markup content
+type TaskListProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// GetTaskListProgress returns the progress of the task lists in the given content, task lists are only
+// supported in Markdown
+func GetTaskListProgress(content, markup string) TaskListProgress {
+	progress := TaskListProgress{}
+	forEachTask(content, markup, func(line []string, checked bool) string {
+		progress.Total++
+		if checked {
+			progress.Done++
+		}
+		return ""
+	})
+	return progress
+}
+
+// ToggleTask checks or unchecks the checkbox with the given index, starting at 0, of the task lists in the
+// given content and returns the changed content. The checkbox is toggled if checked is nil.
+// returns BadParameterError
+func ToggleTask(content, markup string, index int, checked *bool) (string, error) {
+	found := false
+	i := 0
+	result := forEachTask(content, markup, func(line []string, wasChecked bool) string {
+		defer func() { i++ }()
+		if i != index {
+			return ""
+		}
+		found = true
+		check := !wasChecked
+		if checked != nil {
+			check = *checked
+		}
+		if check == wasChecked {
+			return ""
+		}
+		if check {
+			return line[1] + "x" + line[3]
+		}
+		return line[1] + " " + line[3]
+	})
+	if !found {
+		return "", errors.NewBadParameterError("index", index)
+	}
+	return result, nil
+}
+
+// forEachTask calls the given function with the submatches of taskListItem for each task of the task lists in
+// the given content and returns the content with the tasks replaced by the non empty results of the function
+func forEachTask(content, markup string, f func(line []string, checked bool) string) string {
+	if markup != SystemMarkupMarkdown {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	fence := ""
+	for i, line := range lines {
+		if m := codeFence.FindStringSubmatch(line); m != nil {
+			if fence == "" {
+				fence = m[1]
+			} else if fence == m[1] {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		m := taskListItem.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if replaced := f(m, m[2] != " "); replaced != "" {
+			lines[i] = replaced
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderTaskLists renders the checkboxes of the task list items in the given Markdown rendered to HTML
+func renderTaskLists(rendered string) string {
+	return renderedTaskListItem.ReplaceAllStringFunc(rendered, func(m string) string {
+		parts := renderedTaskListItem.FindStringSubmatch(m)
+		checkbox := `<input type="checkbox" class="task-list-item-checkbox" disabled>`
+		if parts[2] != " " {
+			checkbox = `<input type="checkbox" class="task-list-item-checkbox" disabled checked>`
+		}
+		return `<li class="task-list-item">` + parts[1] + checkbox + parts[3]
+	})
+}
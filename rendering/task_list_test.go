@@ -0,0 +1,53 @@
+package rendering_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const taskList = "Steps:\n\n- [x] write\n- [ ] review\n  1. [ ] nested\n\n```\n- [ ] not a task\n```\n\n* [X] ship"
+
+func TestRenderTaskList(t *testing.T) {
+	result := rendering.RenderMarkupToHTML(taskList, rendering.SystemMarkupMarkdown)
+	assert.Equal(t, 4, strings.Count(result, `<li class="task-list-item">`))
+	assert.Equal(t, 2, strings.Count(result, `<input type="checkbox" class="task-list-item-checkbox" disabled checked>`))
+	assert.Contains(t, result, `<li class="task-list-item"><input type="checkbox" class="task-list-item-checkbox" disabled checked> write</li>`)
+	assert.Contains(t, result, `<input type="checkbox" class="task-list-item-checkbox" disabled> review`)
+	assert.Contains(t, result, "<code>- [ ] not a task\n</code>")
+	// only Markdown has task lists
+	assert.Equal(t, "<ul>\n<li>[ ] task</li>\n</ul>\n", rendering.RenderMarkupToHTML("* [ ] task", rendering.SystemMarkupJiraWiki))
+}
+
+func TestGetTaskListProgress(t *testing.T) {
+	assert.Equal(t, rendering.TaskListProgress{Done: 2, Total: 4}, rendering.GetTaskListProgress(taskList, rendering.SystemMarkupMarkdown))
+	assert.Equal(t, rendering.TaskListProgress{}, rendering.GetTaskListProgress("- [x] done", rendering.SystemMarkupPlainText))
+	assert.Equal(t, rendering.TaskListProgress{}, rendering.GetTaskListProgress("- [x]done\n-[ ] todo", rendering.SystemMarkupMarkdown))
+}
+
+func TestToggleTask(t *testing.T) {
+	t.Run("toggle", func(t *testing.T) {
+		result, err := rendering.ToggleTask(taskList, rendering.SystemMarkupMarkdown, 2, nil)
+		require.Nil(t, err)
+		assert.Equal(t, "Steps:\n\n- [x] write\n- [ ] review\n  1. [x] nested\n\n```\n- [ ] not a task\n```\n\n* [X] ship", result)
+		result, err = rendering.ToggleTask(result, rendering.SystemMarkupMarkdown, 0, nil)
+		require.Nil(t, err)
+		assert.Equal(t, rendering.TaskListProgress{Done: 2, Total: 4}, rendering.GetTaskListProgress(result, rendering.SystemMarkupMarkdown))
+	})
+	t.Run("check", func(t *testing.T) {
+		checked := true
+		result, err := rendering.ToggleTask(taskList, rendering.SystemMarkupMarkdown, 3, &checked)
+		require.Nil(t, err)
+		assert.Equal(t, taskList, result)
+	})
+	t.Run("out of range", func(t *testing.T) {
+		_, err := rendering.ToggleTask(taskList, rendering.SystemMarkupMarkdown, 4, nil)
+		assert.IsType(t, errors.BadParameterError{}, err)
+		_, err = rendering.ToggleTask("- [ ] task", rendering.SystemMarkupPlainText, 0, nil)
+		assert.IsType(t, errors.BadParameterError{}, err)
+	})
+}
@@ -74,6 +74,40 @@ func (s *MarkupRenderingSuite) TestRenderMarkdown() {
 	assert.Equal(s.T(), "<p>foo</p>\n", result.Data.Attributes.RenderedContent)
 }
 
+func (s *MarkupRenderingSuite) TestRenderJiraWiki() {
+	// given
+	payload := app.MarkupRenderingPayload{Data: &app.MarkupRenderingPayloadData{
+		Type: RenderingType,
+		Attributes: &app.MarkupRenderingPayloadDataAttributes{
+			Content: "h1. foo",
+			Markup:  rendering.SystemMarkupJiraWiki,
+		}}}
+
+	// when
+	_, result := test.RenderRenderOK(s.T(), s.svc.Context, s.svc, s.controller, &payload)
+	// then
+	require.NotNil(s.T(), result)
+	require.NotNil(s.T(), result.Data)
+	assert.Equal(s.T(), "<h1>foo</h1>\n", result.Data.Attributes.RenderedContent)
+}
+
+func (s *MarkupRenderingSuite) TestRenderAsciiDoc() {
+	// given
+	payload := app.MarkupRenderingPayload{Data: &app.MarkupRenderingPayloadData{
+		Type: RenderingType,
+		Attributes: &app.MarkupRenderingPayloadDataAttributes{
+			Content: "== foo",
+			Markup:  rendering.SystemMarkupAsciiDoc,
+		}}}
+
+	// when
+	_, result := test.RenderRenderOK(s.T(), s.svc.Context, s.svc, s.controller, &payload)
+	// then
+	require.NotNil(s.T(), result)
+	require.NotNil(s.T(), result.Data)
+	assert.Equal(s.T(), "<h2>foo</h2>\n", result.Data.Attributes.RenderedContent)
+}
+
 func (s *MarkupRenderingSuite) TestRenderUnsupportedMarkup() {
 	// given
 	payload := app.MarkupRenderingPayload{Data: &app.MarkupRenderingPayloadData{
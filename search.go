@@ -11,6 +11,7 @@ import (
 	"github.com/almighty/almighty-core/log"
 	"github.com/almighty/almighty-core/search"
 	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
 )
@@ -35,6 +36,20 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 	var limit int
 
 	offset, limit = computePagingLimts(ctx.PageOffset, ctx.PageLimit)
+	sort, err := workitem.ParseSort(ctx.Sort)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Error listing work items: %s", err.Error())))
+		return ctx.BadRequest(jerrors)
+	}
+	additionalQuery := []string{"q=" + ctx.Q}
+	if len(sort) > 0 {
+		additionalQuery = append(additionalQuery, "sort="+workitem.FormatSort(sort))
+	}
+	page, err := computePage(ctx.PageAfter, ctx.PageBefore, ctx.PageLimit)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Error listing work items: %s", err.Error())))
+		return ctx.BadRequest(jerrors)
+	}
 
 	// ToDo : Keep URL registeration central somehow.
 	hostString := ctx.RequestData.Host
@@ -48,7 +63,14 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 
 	return application.Transactional(c.db, func(appl application.Application) error {
 		//return transaction.Do(c.ts, func() error {
-		result, c, err := appl.SearchItems().SearchFullText(ctx.Context, ctx.Q, &offset, &limit)
+		var result []*app.WorkItem
+		var c uint64
+		var more bool
+		if page != nil {
+			result, more, c, err = appl.SearchItems().SearchFullTextPage(ctx.Context, ctx.Q, sort, *page)
+		} else {
+			result, c, err = appl.SearchItems().SearchFullText(ctx.Context, ctx.Q, sort, &offset, &limit)
+		}
 		count := int(c)
 		if err != nil {
 			cause := errs.Cause(err)
@@ -71,7 +93,15 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 			Data:  ConvertWorkItems(ctx.RequestData, result),
 		}
 
-		setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count, "q="+ctx.Q)
+		if page != nil {
+			ids := make([]string, len(result))
+			for i, wi := range result {
+				ids[i] = wi.ID
+			}
+			setCursorLinks(response.Links, buildAbsoluteURL(ctx.RequestData), *page, ids, more, additionalQuery...)
+		} else {
+			setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count, additionalQuery...)
+		}
 		return ctx.OK(&response)
 	})
 }
@@ -16,11 +16,13 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/log"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/asaskevich/govalidator"
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 // KnownURL registration key constants
@@ -61,6 +63,7 @@ func convertFromModel(wiType workitem.WorkItemType, workItem workitem.WorkItem)
 			return nil, errs.WithStack(err)
 		}
 	}
+	result.Fields[workitem.SystemSpace] = workItem.SpaceID.String()
 
 	return &result, nil
 }
@@ -68,10 +71,23 @@ func convertFromModel(wiType workitem.WorkItemType, workItem workitem.WorkItem)
 //searchKeyword defines how a decomposed raw search query will look like
 type searchKeyword struct {
 	workItemTypes []string
+	spaces        []string
 	id            []string
 	words         []string
 }
 
+// spaceIDs returns the IDs of the spaces the search is restricted to
+func (k searchKeyword) spaceIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(k.spaces))
+	for _, space := range k.spaces {
+		// the spaces are parsed already
+		if id, err := uuid.FromString(space); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // KnownURL has a regex string format URL and compiled regex for the same
 type KnownURL struct {
 	URLRegex          string         // regex for URL, Exposed to make the code testable
@@ -243,6 +259,12 @@ func parseSearchString(rawSearchString string) (searchKeyword, error) {
 				return res, errors.NewBadParameterError("Type name must not be empty", part)
 			}
 			res.workItemTypes = append(res.workItemTypes, typeName)
+		} else if strings.HasPrefix(part, "space:") {
+			spaceID, err := uuid.FromString(strings.TrimPrefix(part, "space:"))
+			if err != nil {
+				return res, errors.NewBadParameterError("Space must be an id", part)
+			}
+			res.spaces = append(res.spaces, spaceID.String())
 		} else if govalidator.IsURL(part) {
 			part := strings.ToLower(part)
 			part = trimProtocolFromURLString(part)
@@ -269,10 +291,27 @@ func generateSQLSearchInfo(keywords searchKeyword) (sqlParameter string) {
 	return searchStr
 }
 
+// query returns the base query for work items matching the given search keywords
+func (r *GormSearchRepository) query(keywords searchKeyword) *gorm.DB {
+	db := r.db.Model(workitem.WorkItem{}).Where("tsv @@ query")
+	if len(keywords.workItemTypes) > 0 {
+		// restrict to all given types and their subtypes
+		query := fmt.Sprintf("%[1]s.type in ("+
+			"select distinct subtype.name from %[2]s subtype "+
+			"join %[2]s supertype on subtype.path <@ supertype.path "+
+			"where supertype.name in (?))", workitem.WorkItem{}.TableName(), workitem.WorkItemType{}.TableName())
+		db = db.Where(query, keywords.workItemTypes)
+	}
+	if len(keywords.spaces) > 0 {
+		db = db.Where(workitem.WorkItem{}.TableName()+".space_id in (?)", keywords.spaces)
+	}
+	return db.Joins(", to_tsquery('english', ?) as query, ts_rank(tsv, query) as rank", generateSQLSearchInfo(keywords))
+}
+
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
-func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []string, start *int, limit *int) ([]workitem.WorkItem, uint64, error) {
-	db := r.db.Model(workitem.WorkItem{}).Where("tsv @@ query")
+func (r *GormSearchRepository) search(ctx context.Context, keywords searchKeyword, sort []workitem.SortKey, start *int, limit *int) ([]workitem.WorkItem, uint64, error) {
+	db := r.query(keywords)
 	if start != nil {
 		if *start < 0 {
 			return nil, 0, errors.NewBadParameterError("start", *start)
@@ -285,18 +324,21 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 		}
 		db = db.Limit(*limit)
 	}
-	if len(workItemTypes) > 0 {
-		// restrict to all given types and their subtypes
-		query := fmt.Sprintf("%[1]s.type in ("+
-			"select distinct subtype.name from %[2]s subtype "+
-			"join %[2]s supertype on subtype.path <@ supertype.path "+
-			"where supertype.name in (?))", workitem.WorkItem{}.TableName(), workitem.WorkItemType{}.TableName())
-		db = db.Where(query, workItemTypes)
-	}
 
 	db = db.Select("count(*) over () as cnt2 , *")
-	db = db.Joins(", to_tsquery('english', ?) as query, ts_rank(tsv, query) as rank", sqlSearchQueryParameter)
-	db = db.Order(fmt.Sprintf("rank desc,%s.updated_at desc", workitem.WorkItem{}.TableName()))
+	if len(sort) > 0 {
+		kinds, err := r.wir.LoadFieldKinds(ctx, keywords.spaceIDs()...)
+		if err != nil {
+			return nil, 0, errs.WithStack(err)
+		}
+		order, err := workitem.CompileSort(sort, kinds)
+		if err != nil {
+			return nil, 0, errs.WithStack(err)
+		}
+		db = db.Order(order)
+	}
+	// rank, last update and ID are used to break ties if explicit sort keys are given
+	db = db.Order(fmt.Sprintf("rank desc,%[1]s.updated_at desc,%[1]s.id", workitem.WorkItem{}.TableName()))
 
 	rows, err := db.Rows()
 	if err != nil {
@@ -341,8 +383,9 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 	//*/
 }
 
-// SearchFullText Search returns work items for the given query
-func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchString string, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
+// SearchFullText Search returns work items for the given query, ordered by the given sort keys
+// and by relevance otherwise
+func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchString string, sort []workitem.SortKey, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
 	// parse
 	// generateSearchQuery
 	// ....
@@ -351,28 +394,97 @@ func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchStri
 		return nil, 0, errs.WithStack(err)
 	}
 
-	sqlSearchQueryParameter := generateSQLSearchInfo(parsedSearchDict)
 	var rows []workitem.WorkItem
-	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, start, limit)
+	rows, count, err := r.search(ctx, parsedSearchDict, sort, start, limit)
 	if err != nil {
 		return nil, 0, errs.WithStack(err)
 	}
-	result := make([]*app.WorkItem, len(rows))
+	result, err := r.convertWorkItems(ctx, rows)
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
+	return result, count, nil
+}
+
+// SearchFullTextPage returns the given page of work items for the given query, ordered like by SearchFullText.
+// It also returns whether there are more work items beyond the page in paging direction and the total number of
+// found work items.
+func (r *GormSearchRepository) SearchFullTextPage(ctx context.Context, rawSearchString string, sort []workitem.SortKey, page gormsupport.Page) ([]*app.WorkItem, bool, uint64, error) {
+	if page.ID != "" {
+		if id, err := strconv.ParseUint(page.ID, 10, 64); err != nil || id == 0 {
+			return nil, false, 0, errors.NewBadParameterError("cursor", page.ID)
+		}
+	}
+	parsedSearchDict, err := parseSearchString(rawSearchString)
+	if err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	var terms []gormsupport.OrderTerm
+	if len(sort) > 0 {
+		kinds, err := r.wir.LoadFieldKinds(ctx, parsedSearchDict.spaceIDs()...)
+		if err != nil {
+			return nil, false, 0, errs.WithStack(err)
+		}
+		terms, err = workitem.CompileSortTerms(sort, kinds)
+		if err != nil {
+			return nil, false, 0, errs.WithStack(err)
+		}
+	}
+	table := workitem.WorkItem{}.TableName()
+	keyset := gormsupport.Keyset{
+		Table:    table,
+		IDColumn: "id",
+		// rank is spelled out, so it refers to the boundary row when used in a sub-select
+		Order: append(terms,
+			gormsupport.OrderTerm{Expression: "ts_rank(" + table + ".tsv, query)", Descending: true},
+			gormsupport.OrderTerm{Expression: table + ".updated_at", Descending: true},
+			gormsupport.OrderTerm{Expression: table + ".id"}),
+	}
+
+	db := r.query(parsedSearchDict)
+	var count uint64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	db, err = keyset.Apply(db.Select(table+".*"), page)
+	if err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	rows := []workitem.WorkItem{}
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	more := len(rows) > page.Limit
+	if more {
+		rows = rows[:page.Limit]
+	}
+	if page.Before {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	result, err := r.convertWorkItems(ctx, rows)
+	if err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	return result, more, count, nil
+}
 
+// convertWorkItems converts the given work items from model to app representation
+func (r *GormSearchRepository) convertWorkItems(ctx context.Context, rows []workitem.WorkItem) ([]*app.WorkItem, error) {
+	result := make([]*app.WorkItem, len(rows))
 	for index, value := range rows {
-		var err error
 		// FIXME: Against best practice http://go-database-sql.org/retrieving.html
-		wiType, err := r.wir.LoadTypeFromDB(ctx, value.Type)
+		wiType, err := r.wir.LoadTypeInSpaceFromDB(ctx, &value.SpaceID, value.Type)
 		if err != nil {
-			return nil, 0, errors.NewInternalError(err.Error())
+			return nil, errors.NewInternalError(err.Error())
 		}
 		result[index], err = convertFromModel(*wiType, value)
 		if err != nil {
-			return nil, 0, errors.NewConversionError(err.Error())
+			return nil, errors.NewConversionError(err.Error())
 		}
 	}
-
-	return result, count, nil
+	return result, nil
 }
 
 func init() {
@@ -10,6 +10,7 @@ import (
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/search"
+	"github.com/almighty/almighty-core/space"
 	testsupport "github.com/almighty/almighty-core/test"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
@@ -50,11 +51,11 @@ func (s *searchRepositoryBlackboxTest) TestRestrictByType() {
 	searchRepo := search.NewGormSearchRepository(s.DB)
 
 	ctx := context.Background()
-	res, count, err := searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil)
+	res, count, err := searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil, nil)
 	require.Nil(s.T(), err)
 	require.True(s.T(), count == uint64(len(res))) // safety check for many, many instances of bogus search results.
 	for _, wi := range res {
-		wiRepo.Delete(ctx, wi.ID)
+		wiRepo.Delete(ctx, wi.ID, "")
 	}
 
 	s.DB.Unscoped().Delete(&workitem.WorkItemType{Name: "base"})
@@ -76,6 +77,7 @@ func (s *searchRepositoryBlackboxTest) TestRestrictByType() {
 	require.Nil(s.T(), err)
 
 	wi1, err := wiRepo.Create(ctx, "sub1", map[string]interface{}{
+		workitem.SystemSpace: space.SystemSpace.String(),
 		workitem.SystemTitle: "Test TestRestrictByType",
 		workitem.SystemState: "closed",
 	}, testsupport.TestIdentity.ID.String())
@@ -83,43 +85,44 @@ func (s *searchRepositoryBlackboxTest) TestRestrictByType() {
 	require.Nil(s.T(), err)
 
 	wi2, err := wiRepo.Create(ctx, "subtwo", map[string]interface{}{
+		workitem.SystemSpace: space.SystemSpace.String(),
 		workitem.SystemTitle: "Test TestRestrictByType 2",
 		workitem.SystemState: "closed",
 	}, testsupport.TestIdentity.ID.String())
 	require.NotNil(s.T(), wi2)
 	require.Nil(s.T(), err)
 
-	res, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil)
+	res, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	res, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:sub1", nil, nil)
+	res, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:sub1", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(1), count)
 	if count == 1 {
 		assert.Equal(s.T(), wi1.ID, res[0].ID)
 	}
 
-	res, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:subtwo", nil, nil)
+	res, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:subtwo", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(1), count)
 	if count == 1 {
 		assert.Equal(s.T(), wi2.ID, res[0].ID)
 	}
 
-	_, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:base", nil, nil)
+	_, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:base", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	_, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:subtwo type:sub1", nil, nil)
+	_, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:subtwo type:sub1", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	_, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:base type:sub1", nil, nil)
+	_, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:base type:sub1", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	_, count, err = searchRepo.SearchFullText(ctx, "TRBTgorxi type:base", nil, nil)
+	_, count, err = searchRepo.SearchFullText(ctx, "TRBTgorxi type:base", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(0), count)
 }
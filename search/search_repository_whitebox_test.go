@@ -13,12 +13,14 @@ import (
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
 	testsupport "github.com/almighty/almighty-core/test"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	_ "github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/net/context"
 )
@@ -154,12 +156,13 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 			minimumResults := testData.minimumResults
 			workItemURLInSearchString := "http://demo.almighty.io/work-item/list/detail/"
 
+			workItem.Fields[workitem.SystemSpace] = space.SystemSpace.String()
 			createdWorkItem, err := wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, testsupport.TestIdentity.ID.String())
 			if err != nil {
 				s.T().Fatal("Couldnt create test data")
 			}
 
-			defer wir.Delete(context.Background(), createdWorkItem.ID)
+			defer wir.Delete(context.Background(), createdWorkItem.ID, "")
 
 			// create the URL and use it in the search string
 			workItemURLInSearchString = workItemURLInSearchString + createdWorkItem.ID
@@ -171,7 +174,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 			s.T().Log("using search string: " + searchString)
 			sr := NewGormSearchRepository(tx)
 			var start, limit int = 0, 100
-			workItemList, _, err := sr.SearchFullText(context.Background(), searchString, &start, &limit)
+			workItemList, _, err := sr.SearchFullText(context.Background(), searchString, nil, &start, &limit)
 			if err != nil {
 				s.T().Fatal("Error getting search result ", err)
 			}
@@ -222,7 +225,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 						s.T().Errorf("%s neither found in title %s nor in the description: %s", keyWord, workItemTitle, workItemDescription)
 					}
 				}
-				//defer wir.Delete(context.Background(), workItemValue.ID)
+				//defer wir.Delete(context.Background(), workItemValue.ID, "")
 			}
 
 		}
@@ -248,6 +251,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByID() {
 		workItem := app.WorkItem{Fields: make(map[string]interface{})}
 
 		workItem.Fields = map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "Search Test Sbose",
 			workitem.SystemDescription: rendering.NewMarkupContentFromLegacy("Description"),
 			workitem.SystemCreator:     "sbose78",
@@ -259,7 +263,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByID() {
 		if err != nil {
 			s.T().Fatalf("Couldn't create test data: %+v", err)
 		}
-		defer wir.Delete(context.Background(), createdWorkItem.ID)
+		defer wir.Delete(context.Background(), createdWorkItem.ID, "")
 
 		// Create a new workitem to have the ID in it's title. This should not come
 		// up in search results
@@ -274,7 +278,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByID() {
 
 		var start, limit int = 0, 100
 		searchString := "id:" + createdWorkItem.ID
-		workItemList, _, err := sr.SearchFullText(context.Background(), searchString, &start, &limit)
+		workItemList, _, err := sr.SearchFullText(context.Background(), searchString, nil, &start, &limit)
 		if err != nil {
 			s.T().Fatal("Error gettig search result ", err)
 		}
@@ -405,6 +409,22 @@ func TestParseSearchStringCombination(t *testing.T) {
 	assert.True(t, assert.ObjectsAreEqualValues(expectedSearchRes, op))
 }
 
+func TestParseSearchStringSpace(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	input := "space:4aa8a18f-1bc5-4c2b-8f5d-c6c1d6ba6c3c golang"
+	op, err := parseSearchString(input)
+	require.Nil(t, err)
+	expectedSearchRes := searchKeyword{
+		words:  []string{"golang:*"},
+		spaces: []string{"4aa8a18f-1bc5-4c2b-8f5d-c6c1d6ba6c3c"},
+	}
+	assert.True(t, assert.ObjectsAreEqualValues(expectedSearchRes, op))
+
+	_, err = parseSearchString("space:foo")
+	assert.NotNil(t, err)
+}
+
 func TestRegisterAsKnownURL(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	// build 2 fake urls and cross check against RegisterAsKnownURL
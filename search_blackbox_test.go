@@ -17,6 +17,7 @@ import (
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/search"
+	"github.com/almighty/almighty-core/space"
 	testsupport "github.com/almighty/almighty-core/test"
 	almtoken "github.com/almighty/almighty-core/token"
 	"github.com/almighty/almighty-core/workitem"
@@ -44,6 +45,7 @@ func TestSearch(t *testing.T) {
 		context.Background(),
 		workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "specialwordforsearch",
 			workitem.SystemDescription: nil,
 			workitem.SystemCreator:     "baijum",
@@ -53,7 +55,7 @@ func TestSearch(t *testing.T) {
 	require.Nil(t, err)
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := "specialwordforsearch"
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, nil, q, nil)
 	require.NotEmpty(t, sr.Data)
 	r := sr.Data[0]
 	assert.Equal(t, "specialwordforsearch", r.Attributes[workitem.SystemTitle])
@@ -70,6 +72,7 @@ func TestSearchPagination(t *testing.T) {
 		context.Background(),
 		workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "specialwordforsearch2",
 			workitem.SystemDescription: nil,
 			workitem.SystemCreator:     "baijum",
@@ -80,7 +83,7 @@ func TestSearchPagination(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := "specialwordforsearch2"
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, nil, q, nil)
 
 	// defaults in paging.go is 'pageSizeDefault = 20'
 	assert.Equal(t, "http:///api/search?page[offset]=0&page[limit]=20&q=specialwordforsearch2", *sr.Links.First)
@@ -100,6 +103,7 @@ func TestSearchWithEmptyValue(t *testing.T) {
 		context.Background(),
 		workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "specialwordforsearch",
 			workitem.SystemDescription: nil,
 			workitem.SystemCreator:     "baijum",
@@ -110,7 +114,7 @@ func TestSearchWithEmptyValue(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := ""
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, nil, q, nil)
 	require.NotNil(t, sr.Data)
 	assert.Empty(t, sr.Data)
 }
@@ -127,6 +131,7 @@ func TestSearchWithDomainPortCombination(t *testing.T) {
 		context.Background(),
 		workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "specialwordforsearch_new",
 			workitem.SystemDescription: expectedDescription,
 			workitem.SystemCreator:     "baijum", workitem.SystemState: workitem.SystemStateClosed,
@@ -136,7 +141,7 @@ func TestSearchWithDomainPortCombination(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `"http://localhost:8080/detail/154687364529310"`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, nil, q, nil)
 	require.NotEmpty(t, sr.Data)
 	r := sr.Data[0]
 	assert.Equal(t, description, r.Attributes[workitem.SystemDescription])
@@ -154,6 +159,7 @@ func TestSearchURLWithoutPort(t *testing.T) {
 		context.Background(),
 		workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "specialwordforsearch_without_port",
 			workitem.SystemDescription: expectedDescription,
 			workitem.SystemCreator:     "baijum",
@@ -164,7 +170,7 @@ func TestSearchURLWithoutPort(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `"http://localhost/detail/876394"`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, nil, q, nil)
 	require.NotEmpty(t, sr.Data)
 	r := sr.Data[0]
 	assert.Equal(t, description, r.Attributes[workitem.SystemDescription])
@@ -182,6 +188,7 @@ func TestUnregisteredURLWithPort(t *testing.T) {
 		context.Background(),
 		workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "specialwordforsearch_new",
 			workitem.SystemDescription: expectedDescription,
 			workitem.SystemCreator:     "baijum",
@@ -192,7 +199,7 @@ func TestUnregisteredURLWithPort(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `http://some-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, nil, q, nil)
 	require.NotEmpty(t, sr.Data)
 	r := sr.Data[0]
 	assert.Equal(t, description, r.Attributes[workitem.SystemDescription])
@@ -210,6 +217,7 @@ func TestUnwantedCharactersRelatedToSearchLogic(t *testing.T) {
 		context.Background(),
 		workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "specialwordforsearch_new",
 			workitem.SystemDescription: expectedDescription,
 			workitem.SystemCreator:     "baijum",
@@ -221,7 +229,7 @@ func TestUnwantedCharactersRelatedToSearchLogic(t *testing.T) {
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	// add url: in the query, that is not expected by the code hence need to make sure it gives expected result.
 	q := `http://url:some-random-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, nil, q, nil)
 	require.NotNil(t, sr.Data)
 	assert.Empty(t, sr.Data)
 }
@@ -232,6 +240,7 @@ func getWICreatePayload() *app.CreateWorkitemPayload {
 			Type:       APIStringTypeWorkItem,
 			Attributes: map[string]interface{}{},
 			Relationships: &app.WorkItemRelationships{
+				Space: spaceRelation(space.SystemSpace),
 				BaseType: &app.RelationBaseType{
 					Data: &app.BaseTypeData{
 						Type: APIStringTypeWorkItemType,
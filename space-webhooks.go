@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/webhook"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Defines "type" strings to be used while validating jsonapi spec based payload
+const (
+	APIStringTypeWebhook         = "webhooks"
+	APIStringTypeWebhookDelivery = "webhookdeliveries"
+)
+
+// SpaceWebhooksController implements the space-webhooks resource.
+type SpaceWebhooksController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceWebhooksController creates a space-webhooks controller.
+func NewSpaceWebhooksController(service *goa.Service, db application.DB) *SpaceWebhooksController {
+	return &SpaceWebhooksController{Controller: service.NewController("SpaceWebhooksController"), db: db}
+}
+
+// Create runs the create action.
+func (c *SpaceWebhooksController) Create(ctx *app.CreateSpaceWebhooksContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	creator, err := uuid.FromString(currentUser)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attributes := ctx.Payload.Data.Attributes
+	if attributes.URL == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.url", nil).Expected("not nil"))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		s := webhook.Subscription{
+			SpaceID:   spaceID,
+			URL:       *attributes.URL,
+			Events:    attributes.Events,
+			CreatedBy: creator,
+		}
+		if attributes.Secret != nil {
+			s.Secret = *attributes.Secret
+		}
+		if err := appl.Webhooks().Create(ctx, &s); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WebhookSingle{
+			Data: ConvertWebhook(ctx.RequestData, &s),
+		}
+		// the secret is only revealed to the creator of the webhook
+		res.Data.Attributes.Secret = &s.Secret
+		ctx.ResponseData.Header().Set("Location", *res.Data.Links.Self)
+		return ctx.Created(res)
+	})
+}
+
+// List runs the list action.
+func (c *SpaceWebhooksController) List(ctx *app.ListSpaceWebhooksContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		subscriptions, err := appl.Webhooks().List(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WebhookList{Data: []*app.Webhook{}}
+		for _, s := range subscriptions {
+			res.Data = append(res.Data, ConvertWebhook(ctx.RequestData, s))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Delete runs the delete action.
+func (c *SpaceWebhooksController) Delete(ctx *app.DeleteSpaceWebhooksContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	webhookID, err := uuid.FromString(ctx.WebhookID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.Webhooks().Delete(ctx, spaceID, webhookID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// ListDeliveries runs the list-deliveries action.
+func (c *SpaceWebhooksController) ListDeliveries(ctx *app.ListDeliveriesSpaceWebhooksContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	webhookID, err := uuid.FromString(ctx.WebhookID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	_, limit := computePagingLimts(nil, ctx.PageLimit)
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if _, err := appl.Webhooks().Load(ctx, spaceID, webhookID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		deliveries, err := appl.Webhooks().ListDeliveries(ctx, webhookID, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WebhookDeliveryList{Data: []*app.WebhookDelivery{}}
+		for _, d := range deliveries {
+			res.Data = append(res.Data, ConvertWebhookDelivery(d))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// ConvertWebhook converts between internal and external REST representation. The secret is left out.
+func ConvertWebhook(request *goa.RequestData, s *webhook.Subscription) *app.Webhook {
+	selfURL := rest.AbsoluteURL(request, app.SpaceHref(s.SpaceID.String())+"/webhooks/"+s.ID.String())
+	events := []string(s.Events)
+	if events == nil {
+		events = []string{}
+	}
+	return &app.Webhook{
+		Type: APIStringTypeWebhook,
+		ID:   &s.ID,
+		Attributes: &app.WebhookAttributes{
+			URL:       &s.URL,
+			Events:    events,
+			CreatedAt: &s.CreatedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// ConvertWebhookDelivery converts between internal and external REST representation
+func ConvertWebhookDelivery(d *webhook.Delivery) *app.WebhookDelivery {
+	var payload interface{}
+	json.Unmarshal([]byte(d.Payload), &payload)
+	res := &app.WebhookDelivery{
+		Type: APIStringTypeWebhookDelivery,
+		ID:   d.ID,
+		Attributes: &app.WebhookDeliveryAttributes{
+			Event:          d.EventType,
+			Payload:        payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			CreatedAt:      d.CreatedAt,
+			DeliveredAt:    d.DeliveredAt,
+			LastStatusCode: &d.LastStatusCode,
+		},
+	}
+	if d.Status == webhook.DeliveryPending {
+		res.Attributes.NextAttemptAt = &d.NextAttemptAt
+	}
+	if d.LastError != "" {
+		res.Attributes.LastError = &d.LastError
+	}
+	return res
+}
@@ -0,0 +1,146 @@
+package main_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/webhook"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceWebhooksREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunSpaceWebhooksREST(t *testing.T) {
+	suite.Run(t, &TestSpaceWebhooksREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestSpaceWebhooksREST) SetupTest() {
+	resource.Require(rest.T(), resource.Database)
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestSpaceWebhooksREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestSpaceWebhooksREST) SecuredController() (*goa.Service, *SpaceWebhooksController) {
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+
+	svc := testsupport.ServiceAsUser("SpaceWebhooks-Service", almtoken.NewManagerWithPrivateKey(priv), testsupport.TestIdentity)
+	return svc, NewSpaceWebhooksController(svc, rest.db)
+}
+
+func (rest *TestSpaceWebhooksREST) UnSecuredController() (*goa.Service, *SpaceWebhooksController) {
+	svc := goa.New("SpaceWebhooks-Service")
+	return svc, NewSpaceWebhooksController(svc, rest.db)
+}
+
+func createWebhookPayload(url string, events ...string) *app.CreateSpaceWebhooksPayload {
+	return &app.CreateSpaceWebhooksPayload{
+		Data: &app.Webhook{
+			Type: APIStringTypeWebhook,
+			Attributes: &app.WebhookAttributes{
+				URL:    &url,
+				Events: events,
+			},
+		},
+	}
+}
+
+func (rest *TestSpaceWebhooksREST) TestCreateListAndDeleteWebhooks() {
+	// given
+	var p *space.Space
+	err := application.Transactional(rest.db, func(appl application.Application) error {
+		var err error
+		p, err = appl.Spaces().Create(context.Background(), &space.Space{Name: "Space with webhooks"})
+		return err
+	})
+	require.Nil(rest.T(), err)
+	svc, ctrl := rest.SecuredController()
+	// when
+	_, created := test.CreateSpaceWebhooksCreated(rest.T(), svc.Context, svc, ctrl, p.ID.String(), createWebhookPayload("https://chat.example.com/hooks", "workitem.*"))
+	// then
+	require.NotNil(rest.T(), created.Data.ID)
+	require.NotNil(rest.T(), created.Data.Attributes.Secret)
+	assert.NotEmpty(rest.T(), *created.Data.Attributes.Secret)
+	assert.Equal(rest.T(), []string{"workitem.*"}, created.Data.Attributes.Events)
+	webhookID := created.Data.ID.String()
+
+	_, list := test.ListSpaceWebhooksOK(rest.T(), svc.Context, svc, ctrl, p.ID.String())
+	require.Len(rest.T(), list.Data, 1)
+	assert.Equal(rest.T(), "https://chat.example.com/hooks", *list.Data[0].Attributes.URL)
+	assert.Nil(rest.T(), list.Data[0].Attributes.Secret)
+
+	// when a work item is created in the space
+	spaceID := p.ID.String()
+	err = application.Transactional(rest.db, func(appl application.Application) error {
+		_, err := appl.WorkItems().Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle: "Notify me",
+			workitem.SystemState: workitem.SystemStateNew,
+			workitem.SystemSpace: spaceID,
+		}, testsupport.TestIdentity.ID.String())
+		return err
+	})
+	require.Nil(rest.T(), err)
+	// then
+	_, deliveries := test.ListDeliveriesSpaceWebhooksOK(rest.T(), svc.Context, svc, ctrl, p.ID.String(), webhookID, nil)
+	require.Len(rest.T(), deliveries.Data, 1)
+	assert.Equal(rest.T(), webhook.EventWorkItemCreated, deliveries.Data[0].Attributes.Event)
+	assert.Equal(rest.T(), webhook.DeliveryPending, deliveries.Data[0].Attributes.Status)
+
+	// when
+	test.DeleteSpaceWebhooksOK(rest.T(), svc.Context, svc, ctrl, p.ID.String(), webhookID)
+	// then
+	_, list = test.ListSpaceWebhooksOK(rest.T(), svc.Context, svc, ctrl, p.ID.String())
+	assert.Empty(rest.T(), list.Data)
+	test.DeleteSpaceWebhooksNotFound(rest.T(), svc.Context, svc, ctrl, p.ID.String(), webhookID)
+}
+
+func (rest *TestSpaceWebhooksREST) TestCreateWebhookInvalidURL() {
+	var p *space.Space
+	err := application.Transactional(rest.db, func(appl application.Application) error {
+		var err error
+		p, err = appl.Spaces().Create(context.Background(), &space.Space{Name: "Space with invalid webhook"})
+		return err
+	})
+	require.Nil(rest.T(), err)
+	svc, ctrl := rest.SecuredController()
+	test.CreateSpaceWebhooksBadRequest(rest.T(), svc.Context, svc, ctrl, p.ID.String(), createWebhookPayload("not a url"))
+	test.CreateSpaceWebhooksBadRequest(rest.T(), svc.Context, svc, ctrl, p.ID.String(), createWebhookPayload("https://chat.example.com/hooks", "unknown.event"))
+}
+
+func (rest *TestSpaceWebhooksREST) TestWebhooksUnknownSpace() {
+	svc, ctrl := rest.SecuredController()
+	test.CreateSpaceWebhooksNotFound(rest.T(), svc.Context, svc, ctrl, uuid.NewV4().String(), createWebhookPayload("https://chat.example.com/hooks"))
+	test.ListSpaceWebhooksNotFound(rest.T(), svc.Context, svc, ctrl, uuid.NewV4().String())
+	test.ListDeliveriesSpaceWebhooksNotFound(rest.T(), svc.Context, svc, ctrl, uuid.NewV4().String(), uuid.NewV4().String(), nil)
+}
+
+func (rest *TestSpaceWebhooksREST) TestWebhooksUnauthorized() {
+	svc, ctrl := rest.UnSecuredController()
+	test.CreateSpaceWebhooksUnauthorized(rest.T(), svc.Context, svc, ctrl, uuid.NewV4().String(), createWebhookPayload("https://chat.example.com/hooks"))
+	test.ListSpaceWebhooksUnauthorized(rest.T(), svc.Context, svc, ctrl, uuid.NewV4().String())
+}
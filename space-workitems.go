@@ -0,0 +1,87 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SpaceWorkitemsController implements the space-workitems resource.
+type SpaceWorkitemsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceWorkitemsController creates a space-workitems controller.
+func NewSpaceWorkitemsController(service *goa.Service, db application.DB) *SpaceWorkitemsController {
+	return &SpaceWorkitemsController{Controller: service.NewController("SpaceWorkitemsController"), db: db}
+}
+
+// Create runs the create action.
+func (c *SpaceWorkitemsController) Create(ctx *app.CreateSpaceWorkitemsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		wi2, err := createWorkItem(ctx, appl, ctx.RequestData, ctx.Payload.Data, currentUser, &spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		resp := &app.WorkItem2Single{
+			Data: wi2,
+			Links: &app.WorkItemLinks{
+				Self: rest.AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi2.ID)),
+			},
+		}
+		ctx.ResponseData.Header().Set("Location", app.WorkitemHref(wi2.ID))
+		return ctx.Created(resp)
+	})
+}
+
+// List runs the list action.
+func (c *SpaceWorkitemsController) List(ctx *app.ListSpaceWorkitemsContext) error {
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		_, err := appl.Spaces().Load(ctx, spaceID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	query := workItemListQuery{
+		filter:             ctx.Filter,
+		filterAssignee:     ctx.FilterAssignee,
+		filterIteration:    ctx.FilterIteration,
+		filterWorkitemtype: ctx.FilterWorkitemtype,
+		filterArea:         ctx.FilterArea,
+		sort:               ctx.Sort,
+		pageOffset:         ctx.PageOffset,
+		pageLimit:          ctx.PageLimit,
+		pageAfter:          ctx.PageAfter,
+		pageBefore:         ctx.PageBefore,
+	}
+	restriction := criteria.Equals(criteria.Field(workitem.SystemSpace), criteria.Literal(spaceID.String()))
+	response, err := listWorkItems(ctx, c.db, ctx.RequestData, query, restriction)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(response)
+}
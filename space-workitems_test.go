@@ -0,0 +1,100 @@
+package main_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceWorkitemsREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunSpaceWorkitemsREST(t *testing.T) {
+	suite.Run(t, &TestSpaceWorkitemsREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestSpaceWorkitemsREST) SetupTest() {
+	resource.Require(rest.T(), resource.Database)
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestSpaceWorkitemsREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestSpaceWorkitemsREST) SecuredControllers() (*goa.Service, *WorkitemController, *SpaceWorkitemsController) {
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+
+	svc := testsupport.ServiceAsUser("SpaceWorkitems-Service", almtoken.NewManagerWithPrivateKey(priv), testsupport.TestIdentity)
+	return svc, NewWorkitemController(svc, rest.db), NewSpaceWorkitemsController(svc, rest.db)
+}
+
+func (rest *TestSpaceWorkitemsREST) createSpace(name string) *space.Space {
+	var p *space.Space
+	err := application.Transactional(rest.db, func(appl application.Application) error {
+		var err error
+		p, err = appl.Spaces().Create(context.Background(), &space.Space{Name: name})
+		return err
+	})
+	require.Nil(rest.T(), err)
+	return p
+}
+
+func (rest *TestSpaceWorkitemsREST) TestCreateAndListWorkItemsBySpace() {
+	// given
+	svc, wiCtrl, spaceCtrl := rest.SecuredControllers()
+	p := rest.createSpace("Space with work items")
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "In space"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, created := test.CreateSpaceWorkitemsCreated(rest.T(), svc.Context, svc, spaceCtrl, p.ID.String(), &payload)
+	require.NotNil(rest.T(), created.Data.Relationships.Space)
+	assert.Equal(rest.T(), p.ID.String(), *created.Data.Relationships.Space.Data.ID)
+
+	other := minimumRequiredCreateWithType(workitem.SystemBug)
+	other.Data.Attributes[workitem.SystemTitle] = "Without space"
+	other.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	test.CreateWorkitemCreated(rest.T(), svc.Context, svc, wiCtrl, &other)
+	// when
+	_, list := test.ListSpaceWorkitemsOK(rest.T(), svc.Context, svc, spaceCtrl, p.ID.String(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	// then
+	require.Len(rest.T(), list.Data, 1)
+	assert.Equal(rest.T(), created.Data.ID, list.Data[0].ID)
+	// when
+	spaceID := p.ID.String()
+	_, list = test.ListWorkitemOK(rest.T(), svc.Context, svc, wiCtrl, nil, nil, nil, nil, &spaceID, nil, nil, nil, nil, nil, nil)
+	// then
+	require.Len(rest.T(), list.Data, 1)
+	assert.Equal(rest.T(), created.Data.ID, list.Data[0].ID)
+}
+
+func (rest *TestSpaceWorkitemsREST) TestUnknownSpace() {
+	svc, _, spaceCtrl := rest.SecuredControllers()
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "In space"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	test.CreateSpaceWorkitemsNotFound(rest.T(), svc.Context, svc, spaceCtrl, uuid.NewV4().String(), &payload)
+	test.ListSpaceWorkitemsNotFound(rest.T(), svc.Context, svc, spaceCtrl, uuid.NewV4().String(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+}
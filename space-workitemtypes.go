@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SpaceWorkitemtypesController implements the space-workitemtypes resource.
+type SpaceWorkitemtypesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceWorkitemtypesController creates a space-workitemtypes controller.
+func NewSpaceWorkitemtypesController(service *goa.Service, db application.DB) *SpaceWorkitemtypesController {
+	return &SpaceWorkitemtypesController{Controller: service.NewController("SpaceWorkitemtypesController"), db: db}
+}
+
+// Show runs the show action.
+func (c *SpaceWorkitemtypesController) Show(ctx *app.ShowSpaceWorkitemtypesContext) error {
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		res, err := appl.WorkItemTypes().LoadInSpace(ctx, spaceID, ctx.Name)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *SpaceWorkitemtypesController) Create(ctx *app.CreateSpaceWorkitemtypesContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		var fields = map[string]app.FieldDefinition{}
+		for key, fd := range ctx.Payload.Fields {
+			fields[key] = *fd
+		}
+		wit, err := appl.WorkItemTypes().CreateInSpace(ctx, spaceID, ctx.Payload.ExtendedTypeName, ctx.Payload.Name, fields)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		ctx.ResponseData.Header().Set("Location", app.SpaceWorkitemtypesHref(spaceID.String(), wit.Name))
+		return ctx.Created(wit)
+	})
+}
+
+// List runs the list action.
+func (c *SpaceWorkitemtypesController) List(ctx *app.ListSpaceWorkitemtypesContext) error {
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	start, limit, err := parseLimit(ctx.Page)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrBadRequest(fmt.Sprintf("could not parse paging: %s", err.Error())))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+		result, err := appl.WorkItemTypes().ListInSpace(ctx, spaceID, start, &limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(result)
+	})
+}
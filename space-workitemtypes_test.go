@@ -0,0 +1,101 @@
+package main_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceWorkitemtypesREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunSpaceWorkitemtypesREST(t *testing.T) {
+	suite.Run(t, &TestSpaceWorkitemtypesREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestSpaceWorkitemtypesREST) SetupTest() {
+	resource.Require(rest.T(), resource.Database)
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestSpaceWorkitemtypesREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestSpaceWorkitemtypesREST) SecuredController() (*goa.Service, *SpaceWorkitemtypesController) {
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+
+	svc := testsupport.ServiceAsUser("SpaceWorkitemtypes-Service", almtoken.NewManagerWithPrivateKey(priv), testsupport.TestIdentity)
+	return svc, NewSpaceWorkitemtypesController(svc, rest.db)
+}
+
+func (rest *TestSpaceWorkitemtypesREST) TestCreateShowAndListSpaceWorkItemTypes() {
+	// given
+	var p *space.Space
+	err := application.Transactional(rest.db, func(appl application.Application) error {
+		var err error
+		p, err = appl.Spaces().Create(context.Background(), &space.Space{Name: "Space with own types"})
+		return err
+	})
+	require.Nil(rest.T(), err)
+	svc, ctrl := rest.SecuredController()
+	extendedTypeName := workitem.SystemPlannerItem
+	payload := app.CreateWorkItemTypePayload{
+		Name:             "spike",
+		ExtendedTypeName: &extendedTypeName,
+		Fields: map[string]*app.FieldDefinition{
+			"timebox": {
+				Type: &app.FieldType{Kind: "string"},
+			},
+		},
+	}
+	// when
+	_, wit := test.CreateSpaceWorkitemtypesCreated(rest.T(), svc.Context, svc, ctrl, p.ID.String(), &payload)
+	// then
+	require.NotNil(rest.T(), wit.Space)
+	assert.Equal(rest.T(), p.ID, *wit.Space)
+	assert.NotNil(rest.T(), wit.Fields[workitem.SystemTitle])
+	test.CreateSpaceWorkitemtypesBadRequest(rest.T(), svc.Context, svc, ctrl, p.ID.String(), &payload)
+
+	_, wit = test.ShowSpaceWorkitemtypesOK(rest.T(), svc.Context, svc, ctrl, p.ID.String(), "spike")
+	assert.NotNil(rest.T(), wit.Fields["timebox"])
+	_, wit = test.ShowSpaceWorkitemtypesOK(rest.T(), svc.Context, svc, ctrl, p.ID.String(), workitem.SystemBug)
+	assert.Nil(rest.T(), wit.Space)
+
+	_, wits := test.ListSpaceWorkitemtypesOK(rest.T(), svc.Context, svc, ctrl, p.ID.String(), nil)
+	names := []string{}
+	for _, wit := range wits {
+		names = append(names, wit.Name)
+	}
+	assert.Contains(rest.T(), names, "spike")
+	assert.Contains(rest.T(), names, workitem.SystemBug)
+}
+
+func (rest *TestSpaceWorkitemtypesREST) TestUnknownSpace() {
+	svc, ctrl := rest.SecuredController()
+	test.ShowSpaceWorkitemtypesNotFound(rest.T(), svc.Context, svc, ctrl, uuid.NewV4().String(), workitem.SystemBug)
+	test.ListSpaceWorkitemtypesNotFound(rest.T(), svc.Context, svc, ctrl, uuid.NewV4().String(), nil)
+}
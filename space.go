@@ -211,6 +211,8 @@ func ConvertSpace(request *goa.RequestData, p *space.Space, additional ...SpaceC
 	selfURL := rest.AbsoluteURL(request, app.SpaceHref(p.ID))
 	relatedIterationList := rest.AbsoluteURL(request, fmt.Sprintf("/api/spaces/%s/iterations", p.ID.String()))
 	relatedAreaList := rest.AbsoluteURL(request, fmt.Sprintf("/api/spaces/%s/areas", p.ID.String()))
+	relatedWorkItemList := rest.AbsoluteURL(request, fmt.Sprintf("/api/spaces/%s/workitems", p.ID.String()))
+	relatedWorkItemTypeList := rest.AbsoluteURL(request, fmt.Sprintf("/api/spaces/%s/workitemtypes", p.ID.String()))
 	return &app.Space{
 		ID:   &p.ID,
 		Type: "spaces",
@@ -235,6 +237,30 @@ func ConvertSpace(request *goa.RequestData, p *space.Space, additional ...SpaceC
 					Related: &relatedAreaList,
 				},
 			},
+			Workitems: &app.RelationGeneric{
+				Links: &app.GenericLinks{
+					Related: &relatedWorkItemList,
+				},
+			},
+			Workitemtypes: &app.RelationGeneric{
+				Links: &app.GenericLinks{
+					Related: &relatedWorkItemTypeList,
+				},
+			},
+		},
+	}
+}
+
+// ConvertSpaceSimple converts a simple space ID into a Generic Reletionship
+func ConvertSpaceSimple(request *goa.RequestData, id interface{}) *app.GenericData {
+	t := "spaces"
+	i := fmt.Sprint(id)
+	selfURL := rest.AbsoluteURL(request, app.SpaceHref(i))
+	return &app.GenericData{
+		Type: &t,
+		ID:   &i,
+		Links: &app.GenericLinks{
+			Self: &selfURL,
 		},
 	}
 }
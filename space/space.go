@@ -13,6 +13,10 @@ import (
 	"strings"
 )
 
+// SystemSpace is the space of the work items which did not belong to a space before a space was required,
+// e.g. the ones imported by tracker queries without a space. It is created by the database migration.
+var SystemSpace = satoriuuid.FromStringOrNil("2e0698d8-753e-4cef-bb7c-f027634824a2")
+
 // Space represents a Space on the domain and db layer
 type Space struct {
 	gormsupport.Lifecycle
@@ -85,8 +89,8 @@ func (r *GormRepository) Load(ctx context.Context, ID satoriuuid.UUID) (*Space,
 	return &res, nil
 }
 
-// Delete deletes the space with the given id
-// returns NotFoundError or InternalError
+// Delete deletes the space with the given id, the system space can't be deleted
+// returns NotFoundError, BadParameterError or InternalError
 func (r *GormRepository) Delete(ctx context.Context, ID satoriuuid.UUID) error {
 	if ID == satoriuuid.Nil {
 		log.Error(ctx, map[string]interface{}{
@@ -94,6 +98,9 @@ func (r *GormRepository) Delete(ctx context.Context, ID satoriuuid.UUID) error {
 		}, "unable to find the space by ID")
 		return errors.NewNotFoundError("space", ID.String())
 	}
+	if ID == SystemSpace {
+		return errors.NewBadParameterError("spaceID", ID.String()).Expected("a space other than the system space")
+	}
 	space := Space{ID: ID}
 	tx := r.db.Delete(space)
 
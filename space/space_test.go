@@ -91,6 +91,7 @@ func (test *repoBBTest) TestDelete() {
 	expectSpace(test.load(res.ID), test.assertNotFound())
 	expectSpace(test.delete(satoriuuid.NewV4()), test.assertNotFound())
 	expectSpace(test.delete(satoriuuid.Nil), test.assertNotFound())
+	expectSpace(test.delete(space.SystemSpace), test.assertBadParameter())
 }
 
 func (test *repoBBTest) TestList() {
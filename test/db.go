@@ -6,7 +6,9 @@ import (
 	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 )
@@ -25,6 +27,9 @@ func (db *MockDB) WorkItems() workitem.WorkItemRepository {
 func (db *MockDB) WorkItemTypes() workitem.WorkItemTypeRepository {
 	return nil
 }
+func (db *MockDB) WorkItemRevisions() workitem.RevisionRepository {
+	return nil
+}
 
 func (db *MockDB) Spaces() space.Repository {
 	return nil
@@ -58,6 +63,10 @@ func (db *MockDB) Comments() comment.Repository {
 	return nil
 }
 
+func (db *MockDB) CommentReactions() comment.ReactionRepository {
+	return nil
+}
+
 func (db *MockDB) Iterations() iteration.Repository {
 	return nil
 }
@@ -66,6 +75,14 @@ func (db *MockDB) Areas() area.Repository {
 	return nil
 }
 
+func (db *MockDB) Webhooks() webhook.Repository {
+	return nil
+}
+
+func (db *MockDB) References() reference.Repository {
+	return nil
+}
+
 func (db *MockDB) Commit() error {
 	return nil
 }
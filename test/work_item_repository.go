@@ -6,6 +6,7 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/workitem"
 	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
@@ -22,25 +23,51 @@ type WorkItemRepository struct {
 		result1 *app.WorkItem
 		result2 error
 	}
-	SaveStub        func(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error)
+	SaveStub        func(ctx context.Context, wi app.WorkItem, modifier string) (*app.WorkItem, error)
 	saveMutex       sync.RWMutex
 	saveArgsForCall []struct {
-		ctx context.Context
-		wi  app.WorkItem
+		ctx      context.Context
+		wi       app.WorkItem
+		modifier string
 	}
 	saveReturns struct {
 		result1 *app.WorkItem
 		result2 error
 	}
-	DeleteStub        func(ctx context.Context, ID string) error
+	DeleteStub        func(ctx context.Context, ID string, suppressor string) error
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
-		ctx context.Context
-		ID  string
+		ctx        context.Context
+		ID         string
+		suppressor string
 	}
 	deleteReturns struct {
 		result1 error
 	}
+	UndeleteStub        func(ctx context.Context, ID string, modifier string) (*app.WorkItem, error)
+	undeleteMutex       sync.RWMutex
+	undeleteArgsForCall []struct {
+		ctx      context.Context
+		ID       string
+		modifier string
+	}
+	undeleteReturns struct {
+		result1 *app.WorkItem
+		result2 error
+	}
+	RestoreStub        func(ctx context.Context, ID string, version int, currentVersion int, modifier string) (*app.WorkItem, error)
+	restoreMutex       sync.RWMutex
+	restoreArgsForCall []struct {
+		ctx            context.Context
+		ID             string
+		version        int
+		currentVersion int
+		modifier       string
+	}
+	restoreReturns struct {
+		result1 *app.WorkItem
+		result2 error
+	}
 	CreateStub        func(ctx context.Context, typeID string, fields map[string]interface{}) (*app.WorkItem, error)
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
@@ -52,11 +79,12 @@ type WorkItemRepository struct {
 		result1 *app.WorkItem
 		result2 error
 	}
-	ListStub        func(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	ListStub        func(ctx context.Context, criteria criteria.Expression, sort []workitem.SortKey, start *int, length *int) ([]*app.WorkItem, uint64, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
 		ctx      context.Context
 		criteria criteria.Expression
+		sort     []workitem.SortKey
 		start    *int
 		length   *int
 	}
@@ -65,6 +93,20 @@ type WorkItemRepository struct {
 		result2 uint64
 		result3 error
 	}
+	ListPageStub        func(ctx context.Context, criteria criteria.Expression, sort []workitem.SortKey, page gormsupport.Page) ([]*app.WorkItem, bool, uint64, error)
+	listPageMutex       sync.RWMutex
+	listPageArgsForCall []struct {
+		ctx      context.Context
+		criteria criteria.Expression
+		sort     []workitem.SortKey
+		page     gormsupport.Page
+	}
+	listPageReturns struct {
+		result1 []*app.WorkItem
+		result2 bool
+		result3 uint64
+		result4 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -104,16 +146,17 @@ func (fake *WorkItemRepository) LoadReturns(result1 *app.WorkItem, result2 error
 	}{result1, result2}
 }
 
-func (fake *WorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error) {
+func (fake *WorkItemRepository) Save(ctx context.Context, wi app.WorkItem, modifier string) (*app.WorkItem, error) {
 	fake.saveMutex.Lock()
 	fake.saveArgsForCall = append(fake.saveArgsForCall, struct {
-		ctx context.Context
-		wi  app.WorkItem
-	}{ctx, wi})
-	fake.recordInvocation("Save", []interface{}{ctx, wi})
+		ctx      context.Context
+		wi       app.WorkItem
+		modifier string
+	}{ctx, wi, modifier})
+	fake.recordInvocation("Save", []interface{}{ctx, wi, modifier})
 	fake.saveMutex.Unlock()
 	if fake.SaveStub != nil {
-		return fake.SaveStub(ctx, wi)
+		return fake.SaveStub(ctx, wi, modifier)
 	} else {
 		return fake.saveReturns.result1, fake.saveReturns.result2
 	}
@@ -125,10 +168,10 @@ func (fake *WorkItemRepository) SaveCallCount() int {
 	return len(fake.saveArgsForCall)
 }
 
-func (fake *WorkItemRepository) SaveArgsForCall(i int) (context.Context, app.WorkItem) {
+func (fake *WorkItemRepository) SaveArgsForCall(i int) (context.Context, app.WorkItem, string) {
 	fake.saveMutex.RLock()
 	defer fake.saveMutex.RUnlock()
-	return fake.saveArgsForCall[i].ctx, fake.saveArgsForCall[i].wi
+	return fake.saveArgsForCall[i].ctx, fake.saveArgsForCall[i].wi, fake.saveArgsForCall[i].modifier
 }
 
 func (fake *WorkItemRepository) SaveReturns(result1 *app.WorkItem, result2 error) {
@@ -139,16 +182,17 @@ func (fake *WorkItemRepository) SaveReturns(result1 *app.WorkItem, result2 error
 	}{result1, result2}
 }
 
-func (fake *WorkItemRepository) Delete(ctx context.Context, ID string) error {
+func (fake *WorkItemRepository) Delete(ctx context.Context, ID string, suppressor string) error {
 	fake.deleteMutex.Lock()
 	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
-		ctx context.Context
-		ID  string
-	}{ctx, ID})
-	fake.recordInvocation("Delete", []interface{}{ctx, ID})
+		ctx        context.Context
+		ID         string
+		suppressor string
+	}{ctx, ID, suppressor})
+	fake.recordInvocation("Delete", []interface{}{ctx, ID, suppressor})
 	fake.deleteMutex.Unlock()
 	if fake.DeleteStub != nil {
-		return fake.DeleteStub(ctx, ID)
+		return fake.DeleteStub(ctx, ID, suppressor)
 	} else {
 		return fake.deleteReturns.result1
 	}
@@ -160,10 +204,10 @@ func (fake *WorkItemRepository) DeleteCallCount() int {
 	return len(fake.deleteArgsForCall)
 }
 
-func (fake *WorkItemRepository) DeleteArgsForCall(i int) (context.Context, string) {
+func (fake *WorkItemRepository) DeleteArgsForCall(i int) (context.Context, string, string) {
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
-	return fake.deleteArgsForCall[i].ctx, fake.deleteArgsForCall[i].ID
+	return fake.deleteArgsForCall[i].ctx, fake.deleteArgsForCall[i].ID, fake.deleteArgsForCall[i].suppressor
 }
 
 func (fake *WorkItemRepository) DeleteReturns(result1 error) {
@@ -173,6 +217,80 @@ func (fake *WorkItemRepository) DeleteReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *WorkItemRepository) Undelete(ctx context.Context, ID string, modifier string) (*app.WorkItem, error) {
+	fake.undeleteMutex.Lock()
+	fake.undeleteArgsForCall = append(fake.undeleteArgsForCall, struct {
+		ctx      context.Context
+		ID       string
+		modifier string
+	}{ctx, ID, modifier})
+	fake.recordInvocation("Undelete", []interface{}{ctx, ID, modifier})
+	fake.undeleteMutex.Unlock()
+	if fake.UndeleteStub != nil {
+		return fake.UndeleteStub(ctx, ID, modifier)
+	} else {
+		return fake.undeleteReturns.result1, fake.undeleteReturns.result2
+	}
+}
+
+func (fake *WorkItemRepository) UndeleteCallCount() int {
+	fake.undeleteMutex.RLock()
+	defer fake.undeleteMutex.RUnlock()
+	return len(fake.undeleteArgsForCall)
+}
+
+func (fake *WorkItemRepository) UndeleteArgsForCall(i int) (context.Context, string, string) {
+	fake.undeleteMutex.RLock()
+	defer fake.undeleteMutex.RUnlock()
+	return fake.undeleteArgsForCall[i].ctx, fake.undeleteArgsForCall[i].ID, fake.undeleteArgsForCall[i].modifier
+}
+
+func (fake *WorkItemRepository) UndeleteReturns(result1 *app.WorkItem, result2 error) {
+	fake.UndeleteStub = nil
+	fake.undeleteReturns = struct {
+		result1 *app.WorkItem
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *WorkItemRepository) Restore(ctx context.Context, ID string, version int, currentVersion int, modifier string) (*app.WorkItem, error) {
+	fake.restoreMutex.Lock()
+	fake.restoreArgsForCall = append(fake.restoreArgsForCall, struct {
+		ctx            context.Context
+		ID             string
+		version        int
+		currentVersion int
+		modifier       string
+	}{ctx, ID, version, currentVersion, modifier})
+	fake.recordInvocation("Restore", []interface{}{ctx, ID, version, currentVersion, modifier})
+	fake.restoreMutex.Unlock()
+	if fake.RestoreStub != nil {
+		return fake.RestoreStub(ctx, ID, version, currentVersion, modifier)
+	} else {
+		return fake.restoreReturns.result1, fake.restoreReturns.result2
+	}
+}
+
+func (fake *WorkItemRepository) RestoreCallCount() int {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	return len(fake.restoreArgsForCall)
+}
+
+func (fake *WorkItemRepository) RestoreArgsForCall(i int) (context.Context, string, int, int, string) {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	return fake.restoreArgsForCall[i].ctx, fake.restoreArgsForCall[i].ID, fake.restoreArgsForCall[i].version, fake.restoreArgsForCall[i].currentVersion, fake.restoreArgsForCall[i].modifier
+}
+
+func (fake *WorkItemRepository) RestoreReturns(result1 *app.WorkItem, result2 error) {
+	fake.RestoreStub = nil
+	fake.restoreReturns = struct {
+		result1 *app.WorkItem
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *WorkItemRepository) Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
 	fake.createMutex.Lock()
 	fake.createArgsForCall = append(fake.createArgsForCall, struct {
@@ -209,18 +327,19 @@ func (fake *WorkItemRepository) CreateReturns(result1 *app.WorkItem, result2 err
 	}{result1, result2}
 }
 
-func (fake *WorkItemRepository) List(ctx context.Context, c criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error) {
+func (fake *WorkItemRepository) List(ctx context.Context, c criteria.Expression, sort []workitem.SortKey, start *int, length *int) ([]*app.WorkItem, uint64, error) {
 	fake.listMutex.Lock()
 	fake.listArgsForCall = append(fake.listArgsForCall, struct {
 		ctx      context.Context
 		criteria criteria.Expression
+		sort     []workitem.SortKey
 		start    *int
 		length   *int
-	}{ctx, c, start, length})
-	fake.recordInvocation("List", []interface{}{ctx, c, start, length})
+	}{ctx, c, sort, start, length})
+	fake.recordInvocation("List", []interface{}{ctx, c, sort, start, length})
 	fake.listMutex.Unlock()
 	if fake.ListStub != nil {
-		return fake.ListStub(ctx, c, start, length)
+		return fake.ListStub(ctx, c, sort, start, length)
 	} else {
 		return fake.listReturns.result1, fake.listReturns.result2, fake.listReturns.result3
 	}
@@ -232,10 +351,10 @@ func (fake *WorkItemRepository) ListCallCount() int {
 	return len(fake.listArgsForCall)
 }
 
-func (fake *WorkItemRepository) ListArgsForCall(i int) (context.Context, criteria.Expression, *int, *int) {
+func (fake *WorkItemRepository) ListArgsForCall(i int) (context.Context, criteria.Expression, []workitem.SortKey, *int, *int) {
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
-	return fake.listArgsForCall[i].ctx, fake.listArgsForCall[i].criteria, fake.listArgsForCall[i].start, fake.listArgsForCall[i].length
+	return fake.listArgsForCall[i].ctx, fake.listArgsForCall[i].criteria, fake.listArgsForCall[i].sort, fake.listArgsForCall[i].start, fake.listArgsForCall[i].length
 }
 
 func (fake *WorkItemRepository) ListReturns(result1 []*app.WorkItem, result2 uint64, result3 error) {
@@ -247,6 +366,45 @@ func (fake *WorkItemRepository) ListReturns(result1 []*app.WorkItem, result2 uin
 	}{result1, result2, result3}
 }
 
+func (fake *WorkItemRepository) ListPage(ctx context.Context, c criteria.Expression, sort []workitem.SortKey, page gormsupport.Page) ([]*app.WorkItem, bool, uint64, error) {
+	fake.listPageMutex.Lock()
+	fake.listPageArgsForCall = append(fake.listPageArgsForCall, struct {
+		ctx      context.Context
+		criteria criteria.Expression
+		sort     []workitem.SortKey
+		page     gormsupport.Page
+	}{ctx, c, sort, page})
+	fake.recordInvocation("ListPage", []interface{}{ctx, c, sort, page})
+	fake.listPageMutex.Unlock()
+	if fake.ListPageStub != nil {
+		return fake.ListPageStub(ctx, c, sort, page)
+	} else {
+		return fake.listPageReturns.result1, fake.listPageReturns.result2, fake.listPageReturns.result3, fake.listPageReturns.result4
+	}
+}
+
+func (fake *WorkItemRepository) ListPageCallCount() int {
+	fake.listPageMutex.RLock()
+	defer fake.listPageMutex.RUnlock()
+	return len(fake.listPageArgsForCall)
+}
+
+func (fake *WorkItemRepository) ListPageArgsForCall(i int) (context.Context, criteria.Expression, []workitem.SortKey, gormsupport.Page) {
+	fake.listPageMutex.RLock()
+	defer fake.listPageMutex.RUnlock()
+	return fake.listPageArgsForCall[i].ctx, fake.listPageArgsForCall[i].criteria, fake.listPageArgsForCall[i].sort, fake.listPageArgsForCall[i].page
+}
+
+func (fake *WorkItemRepository) ListPageReturns(result1 []*app.WorkItem, result2 bool, result3 uint64, result4 error) {
+	fake.ListPageStub = nil
+	fake.listPageReturns = struct {
+		result1 []*app.WorkItem
+		result2 bool
+		result3 uint64
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
 func (fake *WorkItemRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -256,10 +414,16 @@ func (fake *WorkItemRepository) Invocations() map[string][][]interface{} {
 	defer fake.saveMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
+	fake.undeleteMutex.RLock()
+	defer fake.undeleteMutex.RUnlock()
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
+	fake.listPageMutex.RLock()
+	defer fake.listPageMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -279,4 +443,8 @@ func (fake *WorkItemRepository) GetCountsPerIteration(ctx context.Context, space
 	return map[string]workitem.WICountsPerIteration{}, nil
 }
 
+func (fake *WorkItemRepository) Transitions(ctx context.Context, ID string) ([]workitem.Transition, error) {
+	return []workitem.Transition{}, nil
+}
+
 var _ workitem.WorkItemRepository = new(WorkItemRepository)
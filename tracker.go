@@ -7,7 +7,7 @@ import (
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/log"
-	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/almighty/almighty-core/query/lang"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
@@ -92,7 +92,7 @@ func (c *TrackerController) Show(ctx *app.ShowTrackerContext) error {
 
 // List runs the list action.
 func (c *TrackerController) List(ctx *app.ListTrackerContext) error {
-	exp, err := query.Parse(ctx.Filter)
+	exp, err := lang.Parse(ctx.Filter, nil)
 	if err != nil {
 		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("could not parse filter: %s", err.Error())))
 		return ctx.BadRequest(jerrors)
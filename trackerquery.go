@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
@@ -27,7 +28,7 @@ func NewTrackerqueryController(service *goa.Service, db application.DB, schedule
 // Create runs the create action.
 func (c *TrackerqueryController) Create(ctx *app.CreateTrackerqueryContext) error {
 	result := application.Transactional(c.db, func(appl application.Application) error {
-		tq, err := appl.TrackerQueries().Create(ctx.Context, ctx.Payload.Query, ctx.Payload.Schedule, ctx.Payload.TrackerID)
+		tq, err := appl.TrackerQueries().Create(ctx.Context, ctx.Payload.Query, ctx.Payload.Schedule, ctx.Payload.TrackerID, ctx.Payload.SpaceID, ctx.Payload.WorkItemType, ctx.Payload.Mapping)
 		if err != nil {
 			cause := errs.Cause(err)
 			switch cause.(type) {
@@ -72,10 +73,13 @@ func (c *TrackerqueryController) Update(ctx *app.UpdateTrackerqueryContext) erro
 	result := application.Transactional(c.db, func(appl application.Application) error {
 
 		toSave := app.TrackerQuery{
-			ID:        ctx.ID,
-			Query:     ctx.Payload.Query,
-			Schedule:  ctx.Payload.Schedule,
-			TrackerID: ctx.Payload.TrackerID,
+			ID:           ctx.ID,
+			Query:        ctx.Payload.Query,
+			Schedule:     ctx.Payload.Schedule,
+			TrackerID:    ctx.Payload.TrackerID,
+			SpaceID:      ctx.Payload.SpaceID,
+			WorkItemType: ctx.Payload.WorkItemType,
+			Mapping:      ctx.Payload.Mapping,
 		}
 		tq, err := appl.TrackerQueries().Save(ctx.Context, toSave)
 
@@ -129,3 +133,43 @@ func (c *TrackerqueryController) List(ctx *app.ListTrackerqueryContext) error {
 	})
 
 }
+
+// ListRuns runs the list-runs action.
+func (c *TrackerqueryController) ListRuns(ctx *app.ListRunsTrackerqueryContext) error {
+	_, limit := computePagingLimts(nil, ctx.PageLimit)
+	return application.Transactional(c.db, func(appl application.Application) error {
+		runs, err := appl.TrackerQueries().ListRuns(ctx.Context, ctx.ID, limit)
+		if err != nil {
+			cause := errs.Cause(err)
+			switch cause.(type) {
+			case remoteworkitem.NotFoundError:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+				return ctx.NotFound(jerrors)
+			default:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+				return ctx.InternalServerError(jerrors)
+			}
+		}
+		return ctx.OK(runs)
+	})
+}
+
+// Run runs the run action.
+func (c *TrackerqueryController) Run(ctx *app.RunTrackerqueryContext) error {
+	run, err := c.scheduler.Trigger(ctx.Context, ctx.ID)
+	if err != nil {
+		cause := errs.Cause(err)
+		switch cause.(type) {
+		case remoteworkitem.NotFoundError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+			return ctx.NotFound(jerrors)
+		case remoteworkitem.RunningError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.NewErrorClass("conflict", http.StatusConflict)(err.Error()))
+			return ctx.Conflict(jerrors)
+		default:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+			return ctx.InternalServerError(jerrors)
+		}
+	}
+	return ctx.Accepted(run)
+}
@@ -2,13 +2,18 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/app/test"
 	"github.com/almighty/almighty-core/gormapplication"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateTrackerQuery(t *testing.T) {
@@ -167,3 +172,80 @@ func TestCreateTrackerQueryValidId(t *testing.T) {
 		t.Error("Failed because fetched Tracker query not same as requested. Found: ", trackerquery.ID, " Expected, ", created.ID)
 	}
 }
+
+func TestTrackerQueryRuns(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer cleaner.DeleteCreatedEntities(DB)()
+	// a Bugzilla without bugs, which answers the search only once released
+	release := make(chan struct{})
+	bugzilla := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"bugs":[]}`)
+	}))
+	defer bugzilla.Close()
+	controller := TrackerController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	payload := app.CreateTrackerAlternatePayload{
+		URL:  bugzilla.URL,
+		Type: "bugzilla",
+	}
+	_, result := test.CreateTrackerCreated(t, nil, nil, &controller, &payload)
+	tqController := TrackerqueryController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	tqpayload := app.CreateTrackerQueryAlternatePayload{
+		Query:     "product=test",
+		Schedule:  "15 * * * * *",
+		TrackerID: result.ID,
+	}
+	_, trackerquery := test.CreateTrackerqueryCreated(t, nil, nil, &tqController, &tqpayload)
+
+	_, run := test.RunTrackerqueryAccepted(t, nil, nil, &tqController, trackerquery.ID)
+	require.NotNil(t, run)
+	assert.Equal(t, trackerquery.ID, run.TrackerQueryID)
+	assert.NotEmpty(t, run.BatchID)
+	assert.Nil(t, run.FinishedAt)
+
+	// only one import of the query runs at a time
+	test.RunTrackerqueryConflict(t, nil, nil, &tqController, trackerquery.ID)
+	close(release)
+
+	// the run goes on in the background
+	var runs app.TrackerQueryRunCollection
+	for i := 0; i < 100; i++ {
+		_, runs = test.ListRunsTrackerqueryOK(t, nil, nil, &tqController, trackerquery.ID, nil)
+		if len(runs) > 0 && runs[0].FinishedAt != nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.Len(t, runs, 1)
+	assert.Equal(t, run.ID, runs[0].ID)
+	assert.NotNil(t, runs[0].FinishedAt)
+
+	test.ListRunsTrackerqueryNotFound(t, nil, nil, &tqController, "100000", nil)
+	test.RunTrackerqueryNotFound(t, nil, nil, &tqController, "100000")
+}
+
+func TestTrackerQueryMapping(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer cleaner.DeleteCreatedEntities(DB)()
+	controller := TrackerController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	payload := app.CreateTrackerAlternatePayload{
+		URL:  "http://issues.jboss.com",
+		Type: "jira",
+	}
+	_, result := test.CreateTrackerCreated(t, nil, nil, &controller, &payload)
+	tqController := TrackerqueryController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	tqpayload := app.CreateTrackerQueryAlternatePayload{
+		Query:     "project = ARQ",
+		Schedule:  "15 * * * * *",
+		TrackerID: result.ID,
+		Mapping:   []*app.FieldMapping{{Path: "fields.customfield_10002", Field: "system.storypoints", Converter: "number"}},
+	}
+	test.CreateTrackerqueryBadRequest(t, nil, nil, &tqController, &tqpayload)
+
+	tqpayload.Mapping = []*app.FieldMapping{{Path: "fields.environment", Field: "system.title", Converter: "string"}}
+	_, trackerquery := test.CreateTrackerqueryCreated(t, nil, nil, &tqController, &tqpayload)
+	require.Len(t, trackerquery.Mapping, 1)
+	assert.Equal(t, "fields.environment", trackerquery.Mapping[0].Path)
+	assert.Equal(t, "system.bug", *trackerquery.WorkItemType)
+}
@@ -12,9 +12,11 @@ import (
 	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/space"
 	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 	token "github.com/dgrijalva/jwt-go"
@@ -185,6 +187,10 @@ func (g *GormTestBase) WorkItemTypes() workitem.WorkItemTypeRepository {
 	return nil
 }
 
+func (g *GormTestBase) WorkItemRevisions() workitem.RevisionRepository {
+	return nil
+}
+
 func (g *GormTestBase) Spaces() space.Repository {
 	return nil
 }
@@ -230,6 +236,11 @@ func (g *GormTestBase) Comments() comment.Repository {
 	return nil
 }
 
+// CommentReactions returns a comment reaction repository
+func (g *GormTestBase) CommentReactions() comment.ReactionRepository {
+	return nil
+}
+
 // Iterations returns a iteration repository
 func (g *GormTestBase) Iterations() iteration.Repository {
 	return nil
@@ -240,6 +251,16 @@ func (g *GormTestBase) Areas() area.Repository {
 	return nil
 }
 
+// Webhooks returns a webhook repository
+func (g *GormTestBase) Webhooks() webhook.Repository {
+	return nil
+}
+
+// References returns a reference repository
+func (g *GormTestBase) References() reference.Repository {
+	return nil
+}
+
 func (g *GormTestBase) DB() *gorm.DB {
 	return nil
 }
@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/models"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/robfig/cron"
+	"golang.org/x/net/context"
+)
+
+const (
+	// dispatchBatchSize is the maximum number of deliveries sent in one run
+	dispatchBatchSize = 100
+	// maxAttempts is the number of attempts after which a delivery is given up
+	maxAttempts = 6
+	// initialBackoff is the delay before the second attempt, it doubles with every further attempt
+	initialBackoff = time.Minute
+	// claimTimeout is the time after which a claimed delivery is sent again if the outcome of the
+	// attempt was never recorded, e.g. because the instance sending it stopped
+	claimTimeout = 5 * time.Minute
+)
+
+// Dispatcher sends the pending webhook deliveries
+type Dispatcher struct {
+	db     *gorm.DB
+	client *http.Client
+	cron   *cron.Cron
+}
+
+// NewDispatcher creates a Dispatcher sending the deliveries with the given client
+func NewDispatcher(db *gorm.DB, client *http.Client) *Dispatcher {
+	return &Dispatcher{db: db, client: client, cron: cron.New()}
+}
+
+// Start sends the pending deliveries periodically with the given cron schedule
+func (d *Dispatcher) Start(schedule string) error {
+	if err := d.cron.AddFunc(schedule, func() {
+		if err := d.Run(context.Background()); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+			}, "webhook dispatch failed")
+		}
+	}); err != nil {
+		return errs.WithStack(err)
+	}
+	d.cron.Start()
+	return nil
+}
+
+// Stop stops sending deliveries
+// This should be called only from main
+func (d *Dispatcher) Stop() {
+	d.cron.Stop()
+}
+
+// Run sends the deliveries which are due, oldest first, at most dispatchBatchSize per run.
+// A delivery is claimed before it is sent, so several instances can run side by side.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for i := 0; i < dispatchBatchSize; i++ {
+		delivery, subscription, err := d.claim()
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		if delivery == nil {
+			break
+		}
+		if subscription == nil {
+			// the subscription was deleted, the claim recorded the failure
+			continue
+		}
+		if err := d.deliver(ctx, *subscription, delivery); err != nil {
+			return errs.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// claim returns the oldest due delivery and its subscription, nil if there is none. The delivery is
+// postponed by claimTimeout in its own transaction, so the other instances skip it while it is sent
+// without holding a lock. A delivery of a deleted subscription is failed right away.
+func (d *Dispatcher) claim() (*Delivery, *Subscription, error) {
+	var delivery *Delivery
+	var subscription *Subscription
+	err := models.Transactional(d.db, func(tx *gorm.DB) error {
+		var due Delivery
+		res := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+			Where("status = ? and next_attempt_at <= ?", DeliveryPending, time.Now()).
+			Order("next_attempt_at").First(&due)
+		if res.RecordNotFound() {
+			return nil
+		}
+		if res.Error != nil {
+			return errs.WithStack(res.Error)
+		}
+		delivery = &due
+		var s Subscription
+		res = tx.Where("id = ?", due.SubscriptionID).First(&s)
+		if res.Error != nil && !res.RecordNotFound() {
+			return errs.WithStack(res.Error)
+		}
+		if res.RecordNotFound() {
+			due.Attempts++
+			due.Status = DeliveryFailed
+			due.LastError = "the webhook was deleted"
+			return errs.WithStack(tx.Save(&due).Error)
+		}
+		subscription = &s
+		return errs.WithStack(tx.Model(&due).Update("next_attempt_at", time.Now().Add(claimTimeout)).Error)
+	})
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	return delivery, subscription, nil
+}
+
+// deliver makes one attempt to send the given claimed delivery and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, subscription Subscription, delivery *Delivery) error {
+	statusCode, err := d.post(subscription, delivery)
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	switch {
+	case err == nil:
+		delivery.Status = DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= maxAttempts:
+		delivery.Status = DeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.NextAttemptAt = now.Add(initialBackoff << uint(delivery.Attempts-1))
+		delivery.LastError = err.Error()
+	}
+	log.Info(ctx, map[string]interface{}{
+		"pkg":        "webhook",
+		"webhookID":  subscription.ID,
+		"deliveryID": delivery.ID,
+		"attempts":   delivery.Attempts,
+		"status":     delivery.Status,
+		"statusCode": statusCode,
+	}, "Webhook delivery attempted")
+	return models.Transactional(d.db, func(tx *gorm.DB) error {
+		return errs.WithStack(tx.Save(delivery).Error)
+	})
+}
+
+// post sends the payload of the delivery to the URL of the subscription and returns the HTTP status.
+// Any status but 2xx is an error.
+func (d *Dispatcher) post(subscription Subscription, delivery *Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest("POST", subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errs.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, errs.WithStack(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("the receiver responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,3 @@
+// Package webhook notifies external services of the changes made in a space by posting signed
+// events to the URLs registered for the space.
+package webhook
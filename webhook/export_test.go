@@ -0,0 +1,6 @@
+package webhook
+
+// AllowPrivateTargets lets the tests post to their receivers on the loopback address
+func AllowPrivateTargets(allow bool) {
+	allowPrivateTargets = allow
+}
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	errs "github.com/pkg/errors"
+)
+
+// allowPrivateTargets lets webhooks post to loopback, private and link-local addresses, only tests set it
+var allowPrivateTargets = false
+
+// privateNetworks are the networks webhooks must not reach, the server itself and the internal networks
+// it may see
+var privateNetworks = parseNetworks(
+	"0.0.0.0/8",      // "this" network
+	"10.0.0.0/8",     // private
+	"100.64.0.0/10",  // carrier grade NAT
+	"127.0.0.0/8",    // loopback
+	"169.254.0.0/16", // link-local, includes the cloud metadata services
+	"172.16.0.0/12",  // private
+	"192.168.0.0/16", // private
+	"::/128",         // unspecified
+	"::1/128",        // loopback
+	"fc00::/7",       // unique local
+	"fe80::/10",      // link-local
+	"64:ff9b::/96",   // IPv4/IPv6 translation
+	"ff00::/8",       // multicast
+	"224.0.0.0/4",    // multicast
+	"255.255.255.255/32",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	result := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		result[i] = network
+	}
+	return result
+}
+
+// isPublicIP tells whether webhooks may post to the given address
+func isPublicIP(ip net.IP) bool {
+	if allowPrivateTargets {
+		return true
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTargetHost returns an error if the given host of a webhook URL is or resolves to an address
+// webhooks must not reach. Hosts that don't resolve are accepted, the address is checked again when
+// a delivery is sent.
+func checkTargetHost(host string) error {
+	if allowPrivateTargets {
+		return nil
+	}
+	name := strings.ToLower(strings.TrimSuffix(host, "."))
+	if name == "localhost" || strings.HasSuffix(name, ".localhost") {
+		return errs.Errorf("%s is a loopback host", host)
+	}
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		if !isPublicIP(ip) {
+			return errs.Errorf("%s is not a public address", host)
+		}
+		return nil
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return errs.Errorf("%s resolves to %s which is not a public address", host, ip)
+		}
+	}
+	return nil
+}
+
+// NewClient returns a HTTP client for sending deliveries which only connects to public addresses. The
+// address is checked when dialing, so a host changing its address after the subscription was created
+// can't reach the internal networks either.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	return &http.Client{
+		Timeout: timeout,
+		// no redirects, the receiver could redirect to an internal address
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				host, port, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, errs.WithStack(err)
+				}
+				ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+				if err != nil {
+					return nil, errs.WithStack(err)
+				}
+				for _, ip := range ips {
+					if !isPublicIP(ip.IP) {
+						return nil, errs.Errorf("%s resolves to %s which is not a public address", host, ip.IP)
+					}
+				}
+				if len(ips) == 0 {
+					return nil, errs.Errorf("no address found for %s", host)
+				}
+				// dial the checked address, not the name which could resolve differently now
+				return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+			},
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+		},
+	}
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/gormsupport"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// The events sent to webhook subscriptions
+const (
+	EventWorkItemCreated     = "workitem.created"
+	EventWorkItemUpdated     = "workitem.updated"
+	EventWorkItemDeleted     = "workitem.deleted"
+	EventCommentCreated      = "comment.created"
+	EventCommentUpdated      = "comment.updated"
+	EventCommentDeleted      = "comment.deleted"
+	EventWorkItemLinkCreated = "workitemlink.created"
+	EventWorkItemLinkUpdated = "workitemlink.updated"
+	EventWorkItemLinkDeleted = "workitemlink.deleted"
+	EventIterationCreated    = "iteration.created"
+	EventIterationUpdated    = "iteration.updated"
+)
+
+// Events lists all events, in the order they are documented
+var Events = []string{
+	EventWorkItemCreated, EventWorkItemUpdated, EventWorkItemDeleted,
+	EventCommentCreated, EventCommentUpdated, EventCommentDeleted,
+	EventWorkItemLinkCreated, EventWorkItemLinkUpdated, EventWorkItemLinkDeleted,
+	EventIterationCreated, EventIterationUpdated,
+}
+
+// The headers of a delivery request
+const (
+	HeaderEvent     = "X-Almighty-Event"
+	HeaderDelivery  = "X-Almighty-Delivery"
+	HeaderSignature = "X-Almighty-Signature"
+)
+
+// The states of a delivery
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// EventFilter lists the events a subscription receives. An entry is either the name of an event,
+// e.g. "workitem.updated", or a kind of events, e.g. "workitem.*". An empty filter matches all events.
+type EventFilter []string
+
+// Value implements driver.Valuer
+func (f EventFilter) Value() (driver.Value, error) {
+	if f == nil {
+		f = EventFilter{}
+	}
+	return json.Marshal(f)
+}
+
+// Scan implements sql.Scanner
+func (f *EventFilter) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, f)
+	case string:
+		return json.Unmarshal([]byte(s), f)
+	case nil:
+		*f = nil
+		return nil
+	}
+	return errs.Errorf("cannot scan %T into an event filter", src)
+}
+
+// Matches returns true if the given event passes the filter
+func (f EventFilter) Matches(event string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, pattern := range f {
+		if pattern == event {
+			return true
+		}
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(event, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// validPattern returns true if the given filter entry matches at least one event
+func validPattern(pattern string) bool {
+	for _, event := range Events {
+		if (EventFilter{pattern}).Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription registers a URL to receive the events of a space
+type Subscription struct {
+	gormsupport.Lifecycle
+	ID      uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	SpaceID uuid.UUID `sql:"type:uuid"`
+	URL     string
+	// the key the deliveries are signed with
+	Secret    string
+	Events    EventFilter `sql:"type:jsonb"`
+	CreatedBy uuid.UUID   `sql:"type:uuid"`
+}
+
+// TableName implements gorm.tabler
+func (s Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Delivery records the sending of an event to a subscription. Deliveries are created in the transaction
+// that causes the event, so they only become visible to the Dispatcher once the change is committed.
+type Delivery struct {
+	ID             uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CreatedAt      time.Time
+	SubscriptionID uuid.UUID `sql:"type:uuid"`
+	EventType      string
+	// the JSON encoded Event sent as request body
+	Payload       string `sql:"type:jsonb"`
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	DeliveredAt   *time.Time
+	// the HTTP status of the last attempt, 0 if the receiver could not be reached
+	LastStatusCode int
+	LastError      string
+}
+
+// TableName implements gorm.tabler
+func (d Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Event is the body of a delivery request
+type Event struct {
+	ID      uuid.UUID   `json:"id"`
+	Type    string      `json:"type"`
+	Time    time.Time   `json:"time"`
+	SpaceID uuid.UUID   `json:"space"`
+	Data    interface{} `json:"data"`
+}
+
+// Sign returns the signature of a request body for the X-Almighty-Signature header: the hex encoded
+// HMAC-SHA256 of the body keyed with the secret of the subscription, prefixed with "sha256="
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,185 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestEventFilter(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.True(t, webhook.EventFilter{}.Matches(webhook.EventCommentCreated))
+	assert.True(t, webhook.EventFilter{"workitem.*"}.Matches(webhook.EventWorkItemDeleted))
+	assert.False(t, webhook.EventFilter{"workitem.*"}.Matches(webhook.EventWorkItemLinkCreated))
+	assert.True(t, webhook.EventFilter{"comment.created", "iteration.*"}.Matches(webhook.EventCommentCreated))
+	assert.False(t, webhook.EventFilter{"comment.created", "iteration.*"}.Matches(webhook.EventCommentUpdated))
+}
+
+func TestSign(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// echo -n '{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=77325902caca812dc259733aacd046b73817372c777b8d95b402647474516e13", webhook.Sign("secret", []byte("{}")))
+	assert.NotEqual(t, webhook.Sign("secret", []byte("{}")), webhook.Sign("other", []byte("{}")))
+}
+
+type webhookSuite struct {
+	gormsupport.DBTestSuite
+	clean func()
+}
+
+func TestRunWebhookSuite(t *testing.T) {
+	// the receivers of the tests listen on the loopback address
+	webhook.AllowPrivateTargets(true)
+	defer webhook.AllowPrivateTargets(false)
+	suite.Run(t, &webhookSuite{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *webhookSuite) SetupTest() {
+	resource.Require(s.T(), resource.Database)
+	s.clean = cleaner.DeleteCreatedEntities(s.DB)
+}
+
+func (s *webhookSuite) TearDownTest() {
+	s.clean()
+}
+
+// receiver records the requests it gets and responds with the given status
+type receiver struct {
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.status)
+}
+
+func (s *webhookSuite) subscribe(url string, events ...string) *webhook.Subscription {
+	p, err := space.NewRepository(s.DB).Create(context.Background(), &space.Space{Name: "Space with webhooks " + uuid.NewV4().String()})
+	require.Nil(s.T(), err)
+	subscription := webhook.Subscription{SpaceID: p.ID, URL: url, Events: events}
+	require.Nil(s.T(), webhook.NewRepository(s.DB).Create(context.Background(), &subscription))
+	require.NotEmpty(s.T(), subscription.Secret)
+	return &subscription
+}
+
+func (s *webhookSuite) TestCreateValidatesSubscription() {
+	repo := webhook.NewRepository(s.DB)
+	err := repo.Create(context.Background(), &webhook.Subscription{SpaceID: uuid.NewV4(), URL: "/relative"})
+	assert.NotNil(s.T(), err)
+	err = repo.Create(context.Background(), &webhook.Subscription{SpaceID: uuid.NewV4(), URL: "http://example.com", Events: webhook.EventFilter{"workitem.moved"}})
+	assert.NotNil(s.T(), err)
+}
+
+func TestRejectPrivateTargets(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	repo := webhook.NewRepository(nil)
+	for _, url := range []string{"http://localhost:8080/hook", "http://127.0.0.1/hook", "http://169.254.169.254/latest/meta-data",
+		"https://10.1.2.3/hook", "http://192.168.0.1/hook", "http://[::1]/hook", "http://[fe80::1]/hook"} {
+		err := repo.Create(context.Background(), &webhook.Subscription{SpaceID: uuid.NewV4(), URL: url})
+		assert.IsType(t, errors.BadParameterError{}, err, url)
+	}
+	// the address is checked again when dialing
+	server := httptest.NewServer(&receiver{status: http.StatusOK})
+	defer server.Close()
+	_, err := webhook.NewClient(time.Second).Post(server.URL, "application/json", nil)
+	assert.NotNil(t, err)
+}
+
+func (s *webhookSuite) TestDeliverSignedEvent() {
+	// given
+	rcv := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+	subscription := s.subscribe(server.URL, "iteration.*")
+	err := iteration.NewIterationRepository(s.DB).Create(context.Background(), &iteration.Iteration{SpaceID: subscription.SpaceID, Name: "Sprint 1"})
+	require.Nil(s.T(), err)
+	// not matched by the filter
+	err = webhook.Emit(context.Background(), s.DB, subscription.SpaceID, webhook.EventCommentCreated, nil)
+	require.Nil(s.T(), err)
+	// when
+	err = webhook.NewDispatcher(s.DB, http.DefaultClient).Run(context.Background())
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), rcv.requests, 1)
+	req := rcv.requests[0]
+	assert.Equal(s.T(), webhook.EventIterationCreated, req.Header.Get(webhook.HeaderEvent))
+	assert.Equal(s.T(), webhook.Sign(subscription.Secret, rcv.bodies[0]), req.Header.Get(webhook.HeaderSignature))
+	var event map[string]interface{}
+	require.Nil(s.T(), json.Unmarshal(rcv.bodies[0], &event))
+	assert.Equal(s.T(), webhook.EventIterationCreated, event["type"])
+	assert.Equal(s.T(), subscription.SpaceID.String(), event["space"])
+	assert.Equal(s.T(), "Sprint 1", event["data"].(map[string]interface{})["name"])
+
+	deliveries, err := webhook.NewRepository(s.DB).ListDeliveries(context.Background(), subscription.ID, 10)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), deliveries, 1)
+	assert.Equal(s.T(), webhook.DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(s.T(), req.Header.Get(webhook.HeaderDelivery), deliveries[0].ID.String())
+	assert.Equal(s.T(), http.StatusOK, deliveries[0].LastStatusCode)
+	assert.NotNil(s.T(), deliveries[0].DeliveredAt)
+}
+
+func (s *webhookSuite) TestRetryFailedDelivery() {
+	// given
+	rcv := &receiver{status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+	subscription := s.subscribe(server.URL)
+	err := webhook.Emit(context.Background(), s.DB, subscription.SpaceID, webhook.EventWorkItemCreated, map[string]interface{}{"id": "1"})
+	require.Nil(s.T(), err)
+	dispatcher := webhook.NewDispatcher(s.DB, http.DefaultClient)
+	// when
+	require.Nil(s.T(), dispatcher.Run(context.Background()))
+	// then
+	deliveries, err := webhook.NewRepository(s.DB).ListDeliveries(context.Background(), subscription.ID, 10)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), deliveries, 1)
+	assert.Equal(s.T(), webhook.DeliveryPending, deliveries[0].Status)
+	assert.Equal(s.T(), 1, deliveries[0].Attempts)
+	assert.Equal(s.T(), http.StatusServiceUnavailable, deliveries[0].LastStatusCode)
+	assert.True(s.T(), deliveries[0].NextAttemptAt.After(time.Now()))
+	// the next attempt is not due yet
+	require.Nil(s.T(), dispatcher.Run(context.Background()))
+	assert.Len(s.T(), rcv.requests, 1)
+}
+
+func (s *webhookSuite) TestDeletedSubscriptionFailsDelivery() {
+	// given
+	rcv := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+	subscription := s.subscribe(server.URL)
+	err := webhook.Emit(context.Background(), s.DB, subscription.SpaceID, webhook.EventWorkItemCreated, nil)
+	require.Nil(s.T(), err)
+	repo := webhook.NewRepository(s.DB)
+	require.Nil(s.T(), repo.Delete(context.Background(), subscription.SpaceID, subscription.ID))
+	// when
+	require.Nil(s.T(), webhook.NewDispatcher(s.DB, http.DefaultClient).Run(context.Background()))
+	// then
+	assert.Empty(s.T(), rcv.requests)
+	deliveries, err := repo.ListDeliveries(context.Background(), subscription.ID, 10)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), deliveries, 1)
+	assert.Equal(s.T(), webhook.DeliveryFailed, deliveries[0].Status)
+}
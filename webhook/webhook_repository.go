@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// Repository describes interactions with webhook subscriptions and their deliveries
+type Repository interface {
+	Create(ctx context.Context, s *Subscription) error
+	Load(ctx context.Context, spaceID uuid.UUID, id uuid.UUID) (*Subscription, error)
+	List(ctx context.Context, spaceID uuid.UUID) ([]*Subscription, error)
+	Delete(ctx context.Context, spaceID uuid.UUID, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*Delivery, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for webhook subscriptions.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new subscription. A secret is generated if the subscription has none. The URL
+// must not point to a loopback, private or link-local address.
+// returns BadParameterError or InternalError
+func (r *GormRepository) Create(ctx context.Context, s *Subscription) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "create"}, time.Now())
+	u, err := url.Parse(s.URL)
+	if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.NewBadParameterError("url", s.URL).Expected("an absolute http or https URL")
+	}
+	if err := checkTargetHost(u.Hostname()); err != nil {
+		return errors.NewBadParameterError("url", s.URL).Expected("a URL of a public host")
+	}
+	for _, pattern := range s.Events {
+		if !validPattern(pattern) {
+			return errors.NewBadParameterError("events", pattern).Expected("an event name or kind, e.g. workitem.updated or workitem.*")
+		}
+	}
+	if s.Secret == "" {
+		secret := make([]byte, 20)
+		if _, err := rand.Read(secret); err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		s.Secret = hex.EncodeToString(secret)
+	}
+	s.ID = uuid.NewV4()
+	if err := r.db.Create(s).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"spaceID": s.SpaceID,
+			"err":     err,
+		}, "unable to create the webhook subscription")
+		return errors.NewInternalError(err.Error())
+	}
+	log.Info(ctx, map[string]interface{}{
+		"pkg":       "webhook",
+		"spaceID":   s.SpaceID,
+		"webhookID": s.ID,
+	}, "Webhook subscription created")
+	return nil
+}
+
+// Load returns the subscription with the given id in the given space
+// returns NotFoundError or InternalError
+func (r *GormRepository) Load(ctx context.Context, spaceID uuid.UUID, id uuid.UUID) (*Subscription, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "get"}, time.Now())
+	var s Subscription
+	tx := r.db.Where("space_id = ? and id = ?", spaceID, id).First(&s)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("webhook", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &s, nil
+}
+
+// List returns the subscriptions of the given space, oldest first
+func (r *GormRepository) List(ctx context.Context, spaceID uuid.UUID) ([]*Subscription, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "query"}, time.Now())
+	result := []*Subscription{}
+	if err := r.db.Where("space_id = ?", spaceID).Order("created_at").Find(&result).Error; err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return result, nil
+}
+
+// Delete removes the subscription with the given id from the given space. Pending deliveries of the
+// subscription are not sent anymore, the delivery log is kept.
+// returns NotFoundError or InternalError
+func (r *GormRepository) Delete(ctx context.Context, spaceID uuid.UUID, id uuid.UUID) error {
+	tx := r.db.Where("space_id = ?", spaceID).Delete(&Subscription{ID: id})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("webhook", id.String())
+	}
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of the given subscription, newest first
+func (r *GormRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*Delivery, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "query"}, time.Now())
+	result := []*Delivery{}
+	err := r.db.Where("subscription_id = ?", subscriptionID).Order("created_at desc").Limit(limit).Find(&result).Error
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return result, nil
+}
+
+// Emit queues the delivery of an event to all subscriptions of the given space whose filter matches it.
+// Call it with the database transaction that makes the change, so the event is only sent if the change
+// is committed.
+func Emit(ctx context.Context, db *gorm.DB, spaceID uuid.UUID, eventType string, data interface{}) error {
+	var subscriptions []Subscription
+	if err := db.Where("space_id = ?", spaceID).Find(&subscriptions).Error; err != nil {
+		return errs.WithStack(err)
+	}
+	return queue(ctx, db, subscriptions, spaceID, eventType, data)
+}
+
+// EmitForWorkItem queues the delivery of an event concerning the work item with the given id, e.g. a
+// comment, to the subscriptions of the space of the work item. Nothing is sent for work items without space.
+func EmitForWorkItem(ctx context.Context, db *gorm.DB, workItemID uint64, eventType string, data interface{}) error {
+	var subscriptions []Subscription
+	err := db.Where("space_id = (select space_id from work_items where id = ?)", workItemID).Find(&subscriptions).Error
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+	return queue(ctx, db, subscriptions, subscriptions[0].SpaceID, eventType, data)
+}
+
+func queue(ctx context.Context, db *gorm.DB, subscriptions []Subscription, spaceID uuid.UUID, eventType string, data interface{}) error {
+	if len(subscriptions) == 0 {
+		return nil
+	}
+	event := Event{
+		ID:      uuid.NewV4(),
+		Type:    eventType,
+		Time:    time.Now().UTC(),
+		SpaceID: spaceID,
+		Data:    data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	for _, s := range subscriptions {
+		if !s.Events.Matches(eventType) {
+			continue
+		}
+		delivery := Delivery{
+			ID:             uuid.NewV4(),
+			SubscriptionID: s.ID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         DeliveryPending,
+			NextAttemptAt:  event.Time,
+		}
+		if err := db.Create(&delivery).Error; err != nil {
+			return errs.WithStack(err)
+		}
+		log.Debug(ctx, map[string]interface{}{
+			"pkg":        "webhook",
+			"webhookID":  s.ID,
+			"deliveryID": delivery.ID,
+			"event":      eventType,
+		}, "Webhook delivery queued")
+	}
+	return nil
+}
@@ -56,8 +56,12 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 			return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
 		}
 
+		details, err := loadCommentDetails(ctx, appl, &newComment)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		res := &app.CommentSingle{
-			Data: ConvertComment(ctx.RequestData, &newComment),
+			Data: ConvertComment(ctx.RequestData, &newComment, details),
 		}
 		return ctx.OK(res)
 	})
@@ -65,6 +69,10 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 
 // List runs the list action.
 func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext) error {
+	page, err := computePage(ctx.PageAfter, ctx.PageBefore, ctx.PageLimit)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
 	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
 	return application.Transactional(c.db, func(appl application.Application) error {
 		_, err := appl.WorkItems().Load(ctx, ctx.ID)
@@ -75,20 +83,52 @@ func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext)
 		res := &app.CommentList{}
 		res.Data = []*app.Comment{}
 
+		if page != nil {
+			comments, more, tc, err := appl.Comments().ListPage(ctx, ctx.ID, *page)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			details, err := loadCommentDetails(ctx, appl, comments...)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			res.Meta = &app.CommentListMeta{TotalCount: int(tc)}
+			res.Data = ConvertComments(ctx.RequestData, comments, details)
+			res.Links = &app.PagingLinks{}
+			setCursorLinks(res.Links, buildAbsoluteURL(ctx.RequestData), *page, threadIDs(comments), more)
+			return ctx.OK(res)
+		}
+
 		comments, tc, err := appl.Comments().List(ctx, ctx.ID, &offset, &limit)
 		count := int(tc)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
 		}
+		details, err := loadCommentDetails(ctx, appl, comments...)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		res.Meta = &app.CommentListMeta{TotalCount: count}
-		res.Data = ConvertComments(ctx.RequestData, comments)
+		res.Data = ConvertComments(ctx.RequestData, comments, details)
 		res.Links = &app.PagingLinks{}
-		setPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), len(comments), offset, limit, count)
+		setPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), len(threadIDs(comments)), offset, limit, count)
 
 		return ctx.OK(res)
 	})
 }
 
+// threadIDs returns the IDs of the first comments of the threads in the given list of comments, the
+// paging of comments applies to them
+func threadIDs(comments []*comment.Comment) []string {
+	ids := []string{}
+	for _, c := range comments {
+		if c.ParentCommentID == nil {
+			ids = append(ids, c.ID.String())
+		}
+	}
+	return ids
+}
+
 // Relations runs the relation action.
 // TODO: Should only return Resource Identifier Objects, not complete object (See List)
 func (c *WorkItemCommentsController) Relations(ctx *app.RelationsWorkItemCommentsContext) error {
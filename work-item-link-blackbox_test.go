@@ -19,6 +19,7 @@ import (
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
 	testsupport "github.com/almighty/almighty-core/test"
 	almtoken "github.com/almighty/almighty-core/token"
 	"github.com/almighty/almighty-core/workitem"
@@ -248,6 +249,7 @@ func CreateWorkItem(workItemType string, title string) *app.CreateWorkitemPayloa
 				workitem.SystemState: workitem.SystemStateClosed,
 			},
 			Relationships: &app.WorkItemRelationships{
+				Space: spaceRelation(space.SystemSpace),
 				BaseType: &app.RelationBaseType{
 					Data: &app.BaseTypeData{
 						ID:   workItemType,
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WorkItemRevisionsController implements the work-item-revisions resource.
+type WorkItemRevisionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemRevisionsController creates a work-item-revisions controller.
+func NewWorkItemRevisionsController(service *goa.Service, db application.DB) *WorkItemRevisionsController {
+	return &WorkItemRevisionsController{Controller: service.NewController("WorkItemRevisionsController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemRevisionsController) List(ctx *app.ListWorkItemRevisionsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		revisions, err := appl.WorkItemRevisions().List(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WorkItemRevisionList{
+			Data: make([]*app.WorkItemRevision, len(revisions)),
+		}
+		for i, revision := range revisions {
+			res.Data[i] = ConvertWorkItemRevision(ctx.RequestData, revision, false)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Show runs the show action.
+func (c *WorkItemRevisionsController) Show(ctx *app.ShowWorkItemRevisionsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		revision, err := appl.WorkItemRevisions().Load(ctx, ctx.ID, ctx.Version)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WorkItemRevisionSingle{
+			Data: ConvertWorkItemRevision(ctx.RequestData, revision, true),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Restore runs the restore action.
+func (c *WorkItemRevisionsController) Restore(ctx *app.RestoreWorkItemRevisionsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		wi, err := appl.WorkItems().Restore(ctx, ctx.ID, ctx.Version, ctx.CurrentVersion, currentUser)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "error restoring version %d of work item %s", ctx.Version, ctx.ID))
+		}
+		resp := &app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: rest.AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+			},
+		}
+		return ctx.OK(resp)
+	})
+}
+
+// ConvertWorkItemRevision converts from internal to external REST representation. The field values
+// of the work item are only included if withFields is set, to keep revision lists small.
+func ConvertWorkItemRevision(request *goa.RequestData, revision *workitem.Revision, withFields bool) *app.WorkItemRevision {
+	workItemID := strconv.FormatUint(revision.WorkItemID, 10)
+	selfURL := rest.AbsoluteURL(request, app.WorkItemRevisionsHref(workItemID, revision.WorkItemVersion))
+	workItemURL := rest.AbsoluteURL(request, app.WorkitemHref(workItemID))
+	workItemType := APIStringTypeWorkItem
+	r := &app.WorkItemRevision{
+		Type: APIStringTypeWorkItemRevision,
+		ID:   revision.ID,
+		Attributes: &app.WorkItemRevisionAttributes{
+			RevisionType: revision.Type.String(),
+			Time:         revision.Time,
+			Version:      revision.WorkItemVersion,
+			Type:         revision.WorkItemType,
+		},
+		Relationships: &app.WorkItemRevisionRelations{
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &workItemType,
+					ID:   &workItemID,
+				},
+				Links: &app.GenericLinks{
+					Self: &workItemURL,
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+	if revision.WorkItemChanges != nil {
+		r.Attributes.Changes = make(map[string]*app.FieldChange, len(revision.WorkItemChanges))
+		for name, change := range revision.WorkItemChanges {
+			r.Attributes.Changes[name] = &app.FieldChange{Old: change.Old, New: change.New}
+		}
+	}
+	if withFields {
+		r.Attributes.Fields = map[string]interface{}(revision.WorkItemFields)
+	}
+	if modifier, err := uuid.FromString(revision.Modifier); err == nil {
+		r.Relationships.Modifier = &app.CommentCreatedBy{
+			Data: &app.IdentityRelationData{
+				Type: APIStringTypeUser,
+				ID:   &modifier,
+			},
+		}
+	}
+	return r
+}
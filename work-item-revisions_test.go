@@ -0,0 +1,125 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemRevisionsREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunWorkItemRevisionsREST(t *testing.T) {
+	suite.Run(t, &TestWorkItemRevisionsREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestWorkItemRevisionsREST) SetupTest() {
+	resource.Require(rest.T(), resource.Database)
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestWorkItemRevisionsREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestWorkItemRevisionsREST) SecuredControllers() (*goa.Service, *WorkitemController, *WorkItemRevisionsController) {
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+
+	svc := testsupport.ServiceAsUser("WorkItemRevisions-Service", almtoken.NewManagerWithPrivateKey(priv), testsupport.TestIdentity)
+	return svc, NewWorkitemController(svc, rest.db), NewWorkItemRevisionsController(svc, rest.db)
+}
+
+func (rest *TestWorkItemRevisionsREST) TestListAndShowRevisions() {
+	// given
+	svc, wiCtrl, revisionsCtrl := rest.SecuredControllers()
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "Title"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, wi := test.CreateWorkitemCreated(rest.T(), svc.Context, svc, wiCtrl, &payload)
+
+	update := minimumRequiredUpdatePayload()
+	update.Data.ID = wi.Data.ID
+	update.Data.Attributes = wi.Data.Attributes
+	update.Data.Attributes[workitem.SystemState] = workitem.SystemStateOpen
+	test.UpdateWorkitemOK(rest.T(), svc.Context, svc, wiCtrl, *wi.Data.ID, &update)
+	// when
+	_, revisions := test.ListWorkItemRevisionsOK(rest.T(), svc.Context, svc, revisionsCtrl, *wi.Data.ID)
+	// then
+	require.Len(rest.T(), revisions.Data, 2)
+	assert.Equal(rest.T(), "create", revisions.Data[0].Attributes.RevisionType)
+	assert.Equal(rest.T(), "update", revisions.Data[1].Attributes.RevisionType)
+	assert.Equal(rest.T(), 1, revisions.Data[1].Attributes.Version)
+	assert.Equal(rest.T(), &app.FieldChange{Old: workitem.SystemStateNew, New: workitem.SystemStateOpen}, revisions.Data[1].Attributes.Changes[workitem.SystemState])
+	assert.Nil(rest.T(), revisions.Data[1].Attributes.Fields)
+	require.NotNil(rest.T(), revisions.Data[1].Relationships.Modifier)
+	assert.Equal(rest.T(), testsupport.TestIdentity.ID, *revisions.Data[1].Relationships.Modifier.Data.ID)
+	// when
+	_, revision := test.ShowWorkItemRevisionsOK(rest.T(), svc.Context, svc, revisionsCtrl, *wi.Data.ID, 1)
+	// then
+	assert.Equal(rest.T(), revisions.Data[1].ID, revision.Data.ID)
+	assert.Equal(rest.T(), workitem.SystemStateOpen, revision.Data.Attributes.Fields[workitem.SystemState])
+}
+
+func (rest *TestWorkItemRevisionsREST) TestRestoreRevision() {
+	// given
+	svc, wiCtrl, revisionsCtrl := rest.SecuredControllers()
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "Title"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, wi := test.CreateWorkitemCreated(rest.T(), svc.Context, svc, wiCtrl, &payload)
+
+	update := minimumRequiredUpdatePayload()
+	update.Data.ID = wi.Data.ID
+	update.Data.Attributes = wi.Data.Attributes
+	update.Data.Attributes[workitem.SystemTitle] = "Changed Title"
+	test.UpdateWorkitemOK(rest.T(), svc.Context, svc, wiCtrl, *wi.Data.ID, &update)
+	// when the client missed the update
+	test.RestoreWorkItemRevisionsBadRequest(rest.T(), svc.Context, svc, revisionsCtrl, *wi.Data.ID, 0, 0)
+	// when
+	_, restored := test.RestoreWorkItemRevisionsOK(rest.T(), svc.Context, svc, revisionsCtrl, *wi.Data.ID, 0, 1)
+	// then
+	assert.Equal(rest.T(), "Title", restored.Data.Attributes[workitem.SystemTitle])
+	assert.Equal(rest.T(), 2, restored.Data.Attributes["version"])
+	test.RestoreWorkItemRevisionsNotFound(rest.T(), svc.Context, svc, revisionsCtrl, *wi.Data.ID, 42, 2)
+}
+
+func (rest *TestWorkItemRevisionsREST) TestUndeleteWorkItem() {
+	// given
+	svc, wiCtrl, _ := rest.SecuredControllers()
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "Title"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, wi := test.CreateWorkitemCreated(rest.T(), svc.Context, svc, wiCtrl, &payload)
+	test.DeleteWorkitemOK(rest.T(), svc.Context, svc, wiCtrl, *wi.Data.ID)
+	test.ShowWorkitemNotFound(rest.T(), svc.Context, svc, wiCtrl, *wi.Data.ID)
+	// when
+	_, undeleted := test.UndeleteWorkitemOK(rest.T(), svc.Context, svc, wiCtrl, *wi.Data.ID)
+	// then
+	assert.Equal(rest.T(), "Title", undeleted.Data.Attributes[workitem.SystemTitle])
+	test.ShowWorkitemOK(rest.T(), svc.Context, svc, wiCtrl, *wi.Data.ID)
+	test.UndeleteWorkitemNotFound(rest.T(), svc.Context, svc, wiCtrl, *wi.Data.ID)
+}
+
+func (rest *TestWorkItemRevisionsREST) TestShowRevisionNotFound() {
+	svc, _, revisionsCtrl := rest.SecuredControllers()
+	test.ListWorkItemRevisionsNotFound(rest.T(), svc.Context, svc, revisionsCtrl, "4242424242")
+	test.ShowWorkItemRevisionsNotFound(rest.T(), svc.Context, svc, revisionsCtrl, "4242424242", 0)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/reference"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemTasksController implements the work-item-tasks resource.
+type WorkItemTasksController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemTasksController creates a work-item-tasks controller.
+func NewWorkItemTasksController(service *goa.Service, db application.DB) *WorkItemTasksController {
+	return &WorkItemTasksController{Controller: service.NewController("WorkItemTasksController"), db: db}
+}
+
+// Toggle runs the toggle action.
+func (c *WorkItemTasksController) Toggle(ctx *app.ToggleWorkItemTasksContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		wi, err := appl.WorkItems().Load(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		description := rendering.NewMarkupContentFromValue(wi.Fields[workitem.SystemDescription])
+		if description == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("index", ctx.Index))
+		}
+		content, err := rendering.ToggleTask(description.Content, description.Markup, ctx.Index, ctx.Payload.Data.Attributes.Checked)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi.Fields[workitem.SystemDescription] = rendering.NewMarkupContent(content, description.Markup)
+		wi.Version = ctx.Payload.Data.Attributes.Version
+		wi, err = appl.WorkItems().Save(ctx, *wi, currentUser)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		refs, err := appl.References().List(ctx, reference.SourceWorkItem, wi.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi, WorkItemIncludeReferences(refs)),
+		})
+	})
+}
@@ -0,0 +1,97 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestTasksREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunTasksREST(t *testing.T) {
+	suite.Run(t, &TestTasksREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestTasksREST) SetupTest() {
+	resource.Require(rest.T(), resource.Database)
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestTasksREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestTasksREST) SecuredControllers() (*goa.Service, *WorkitemController, *WorkItemTasksController) {
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+
+	svc := testsupport.ServiceAsUser("Tasks-Service", almtoken.NewManagerWithPrivateKey(priv), testsupport.TestIdentity)
+	return svc, NewWorkitemController(svc, rest.db), NewWorkItemTasksController(svc, rest.db)
+}
+
+func newToggleWorkItemTasksPayload(version int, checked *bool) *app.ToggleWorkItemTasksPayload {
+	return &app.ToggleWorkItemTasksPayload{
+		Data: &app.TaskToggle{
+			Type: "tasks",
+			Attributes: &app.TaskToggleAttributes{
+				Version: version,
+				Checked: checked,
+			},
+		},
+	}
+}
+
+func (rest *TestTasksREST) TestToggleWorkItemTask() {
+	// given
+	svc, wiCtrl, tasksCtrl := rest.SecuredControllers()
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "Title"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	description := rendering.NewMarkupContent("- [x] first\n- [ ] second", rendering.SystemMarkupMarkdown)
+	payload.Data.Attributes[workitem.SystemDescription] = description.ToMap()
+	_, wi := test.CreateWorkitemCreated(rest.T(), svc.Context, svc, wiCtrl, &payload)
+	assert.Equal(rest.T(), rendering.TaskListProgress{Done: 1, Total: 2}, wi.Data.Attributes[workitem.SystemDescriptionProgress])
+	version := wi.Data.Attributes["version"].(int)
+	// when
+	_, toggled := test.ToggleWorkItemTasksOK(rest.T(), svc.Context, svc, tasksCtrl, *wi.Data.ID, 1, newToggleWorkItemTasksPayload(version, nil))
+	// then
+	require.NotNil(rest.T(), toggled)
+	assert.Equal(rest.T(), "- [x] first\n- [x] second", toggled.Data.Attributes[workitem.SystemDescription])
+	assert.Equal(rest.T(), rendering.TaskListProgress{Done: 2, Total: 2}, toggled.Data.Attributes[workitem.SystemDescriptionProgress])
+	assert.Contains(rest.T(), toggled.Data.Attributes[workitem.SystemDescriptionRendered], `class="task-list-item-checkbox" disabled checked>`)
+}
+
+func (rest *TestTasksREST) TestToggleWorkItemTaskVersionConflict() {
+	// given
+	svc, wiCtrl, tasksCtrl := rest.SecuredControllers()
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "Title"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	description := rendering.NewMarkupContent("- [ ] task", rendering.SystemMarkupMarkdown)
+	payload.Data.Attributes[workitem.SystemDescription] = description.ToMap()
+	_, wi := test.CreateWorkitemCreated(rest.T(), svc.Context, svc, wiCtrl, &payload)
+	// when/then
+	test.ToggleWorkItemTasksBadRequest(rest.T(), svc.Context, svc, tasksCtrl, *wi.Data.ID, 0, newToggleWorkItemTasksPayload(2398475203, nil))
+	// an index without a checkbox is rejected as well
+	version := wi.Data.Attributes["version"].(int)
+	test.ToggleWorkItemTasksBadRequest(rest.T(), svc.Context, svc, tasksCtrl, *wi.Data.ID, 1, newToggleWorkItemTasksPayload(version, nil))
+}
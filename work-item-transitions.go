@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemTransitionsController implements the work-item-transitions resource.
+type WorkItemTransitionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemTransitionsController creates a work-item-transitions controller.
+func NewWorkItemTransitionsController(service *goa.Service, db application.DB) *WorkItemTransitionsController {
+	return &WorkItemTransitionsController{Controller: service.NewController("WorkItemTransitionsController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemTransitionsController) List(ctx *app.ListWorkItemTransitionsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		transitions, err := appl.WorkItems().Transitions(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WorkItemTransitionList{
+			Data: make([]*app.WorkItemTransition, len(transitions)),
+		}
+		for i, transition := range transitions {
+			res.Data[i] = ConvertWorkItemTransition(transition)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// ConvertWorkItemTransition converts from internal to external REST representation
+func ConvertWorkItemTransition(transition workitem.Transition) *app.WorkItemTransition {
+	return &app.WorkItemTransition{
+		Type: APIStringTypeWorkItemTransition,
+		ID:   transition.To,
+		Attributes: &app.WorkItemTransitionAttributes{
+			To:            transition.To,
+			Available:     len(transition.MissingFields) == 0,
+			MissingFields: transition.MissingFields,
+		},
+	}
+}
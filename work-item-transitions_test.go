@@ -0,0 +1,114 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type TestWorkItemTransitionsREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunWorkItemTransitionsREST(t *testing.T) {
+	suite.Run(t, &TestWorkItemTransitionsREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestWorkItemTransitionsREST) SetupTest() {
+	resource.Require(rest.T(), resource.Database)
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestWorkItemTransitionsREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestWorkItemTransitionsREST) SecuredControllers() (*goa.Service, *WorkitemController, *WorkItemTransitionsController) {
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+
+	svc := testsupport.ServiceAsUser("WorkItemTransitions-Service", almtoken.NewManagerWithPrivateKey(priv), testsupport.TestIdentity)
+	return svc, NewWorkitemController(svc, rest.db), NewWorkItemTransitionsController(svc, rest.db)
+}
+
+// createWorkflowType creates a planner item type with a workflow and returns its name
+func (rest *TestWorkItemTransitionsREST) createWorkflowType() string {
+	witRepo := workitem.NewWorkItemTypeRepository(rest.DB)
+	extended := workitem.SystemPlannerItem
+	name := "workflow-" + uuid.NewV4().String()
+	wit, err := witRepo.Create(context.Background(), &extended, name, map[string]app.FieldDefinition{})
+	require.Nil(rest.T(), err)
+	_, err = witRepo.Update(context.Background(), name, wit.Version, map[string]app.FieldDefinition{}, nil, &app.Workflow{
+		States: []string{workitem.SystemStateNew, workitem.SystemStateOpen, workitem.SystemStateInProgress, workitem.SystemStateClosed},
+		Transitions: map[string][]string{
+			workitem.SystemStateNew:        {workitem.SystemStateOpen, workitem.SystemStateInProgress, workitem.SystemStateClosed},
+			workitem.SystemStateOpen:       {workitem.SystemStateInProgress, workitem.SystemStateClosed},
+			workitem.SystemStateInProgress: {workitem.SystemStateClosed},
+		},
+		Guards: map[string][]string{
+			workitem.SystemStateInProgress: {workitem.SystemAssignees},
+		},
+	})
+	require.Nil(rest.T(), err)
+	return name
+}
+
+func (rest *TestWorkItemTransitionsREST) TestListTransitions() {
+	// given
+	svc, wiCtrl, transitionsCtrl := rest.SecuredControllers()
+	payload := minimumRequiredCreateWithType(rest.createWorkflowType())
+	payload.Data.Attributes[workitem.SystemTitle] = "Title"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, wi := test.CreateWorkitemCreated(rest.T(), svc.Context, svc, wiCtrl, &payload)
+	// when
+	_, transitions := test.ListWorkItemTransitionsOK(rest.T(), svc.Context, svc, transitionsCtrl, *wi.Data.ID)
+	// then
+	available := map[string]bool{}
+	for _, transition := range transitions.Data {
+		available[transition.ID] = transition.Attributes.Available
+	}
+	assert.Equal(rest.T(), map[string]bool{
+		workitem.SystemStateOpen:       true,
+		workitem.SystemStateInProgress: false,
+		workitem.SystemStateClosed:     true,
+	}, available)
+}
+
+func (rest *TestWorkItemTransitionsREST) TestUpdateRejectsIllegalTransition() {
+	// given
+	svc, wiCtrl, _ := rest.SecuredControllers()
+	payload := minimumRequiredCreateWithType(rest.createWorkflowType())
+	payload.Data.Attributes[workitem.SystemTitle] = "Title"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, wi := test.CreateWorkitemCreated(rest.T(), svc.Context, svc, wiCtrl, &payload)
+
+	update := minimumRequiredUpdatePayload()
+	update.Data.ID = wi.Data.ID
+	update.Data.Attributes = wi.Data.Attributes
+	update.Data.Attributes[workitem.SystemState] = workitem.SystemStateInProgress
+	// when/then
+	test.UpdateWorkitemBadRequest(rest.T(), svc.Context, svc, wiCtrl, *wi.Data.ID, &update)
+}
+
+func (rest *TestWorkItemTransitionsREST) TestListTransitionsNotFound() {
+	svc, _, transitionsCtrl := rest.SecuredControllers()
+	test.ListWorkItemTransitionsNotFound(rest.T(), svc.Context, svc, transitionsCtrl, "2398475203")
+}
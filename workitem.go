@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"html"
+	"net/url"
 	"strconv"
 
 	"golang.org/x/net/context"
@@ -13,7 +14,8 @@ import (
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
-	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/almighty/almighty-core/query/lang"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/almighty/almighty-core/workitem"
@@ -24,9 +26,11 @@ import (
 
 // Defines the constants to be used in json api "type" attribute
 const (
-	APIStringTypeUser         = "identities"
-	APIStringTypeWorkItem     = "workitems"
-	APIStringTypeWorkItemType = "workitemtypes"
+	APIStringTypeUser               = "identities"
+	APIStringTypeWorkItem           = "workitems"
+	APIStringTypeWorkItemType       = "workitemtypes"
+	APIStringTypeWorkItemRevision   = "workitemrevisions"
+	APIStringTypeWorkItemTransition = "workitemtransitions"
 )
 
 // WorkitemController implements the workitem resource.
@@ -47,50 +51,164 @@ func NewWorkitemController(service *goa.Service, db application.DB) *WorkitemCon
 // Prev and Next links will be present only when there actually IS a next or previous page.
 // Last will always be present. Total Item count needs to be computed from the "Last" link.
 func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
-	var additionalQuery []string
-	exp, err := query.Parse(ctx.Filter)
+	query := workItemListQuery{
+		filter:             ctx.Filter,
+		filterAssignee:     ctx.FilterAssignee,
+		filterIteration:    ctx.FilterIteration,
+		filterWorkitemtype: ctx.FilterWorkitemtype,
+		filterArea:         ctx.FilterArea,
+		sort:               ctx.Sort,
+		pageOffset:         ctx.PageOffset,
+		pageLimit:          ctx.PageLimit,
+		pageAfter:          ctx.PageAfter,
+		pageBefore:         ctx.PageBefore,
+	}
+	var restriction criteria.Expression
+	if ctx.FilterSpace != nil {
+		spaceID, err := uuid.FromString(*ctx.FilterSpace)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("filter[space]", *ctx.FilterSpace))
+		}
+		restriction = criteria.Equals(criteria.Field(workitem.SystemSpace), criteria.Literal(spaceID.String()))
+		query.additionalQuery = append(query.additionalQuery, "filter[space]="+*ctx.FilterSpace)
+	}
+	response, err := listWorkItems(ctx, c.db, ctx.RequestData, query, restriction)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(response)
+}
+
+// workItemListQuery holds the parameters shared by the actions listing work items
+type workItemListQuery struct {
+	filter             *string
+	filterAssignee     *string
+	filterIteration    *string
+	filterWorkitemtype *string
+	filterArea         *string
+	sort               *string
+	pageOffset         *string
+	pageLimit          *int
+	pageAfter          *string
+	pageBefore         *string
+	// query parameters to add to the paging links in addition to the ones above
+	additionalQuery []string
+}
+
+// listWorkItems returns the page of work items matching the given query, restricted by the given
+// expression if it is not nil
+func listWorkItems(ctx context.Context, db application.DB, request *goa.RequestData, query workItemListQuery, restriction criteria.Expression) (*app.WorkItem2List, error) {
+	additionalQuery := query.additionalQuery
+	exp, err := lang.Parse(query.filter, filterVariables(ctx))
 	if err != nil {
-		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("could not parse filter", err))
+		return nil, errors.NewBadParameterError("could not parse filter", err)
 	}
-	if ctx.FilterAssignee != nil {
-		assignee := ctx.FilterAssignee
+	if query.filter != nil && *query.filter != "" {
+		additionalQuery = append(additionalQuery, "filter="+url.QueryEscape(*query.filter))
+	}
+	if restriction != nil {
+		exp = criteria.And(exp, restriction)
+	}
+	if query.filterAssignee != nil {
+		assignee := query.filterAssignee
 		exp = criteria.And(exp, criteria.Equals(criteria.Field("system.assignees"), criteria.Literal([]string{*assignee})))
 		additionalQuery = append(additionalQuery, "filter[assignee]="+*assignee)
 	}
-	if ctx.FilterIteration != nil {
-		iteration := ctx.FilterIteration
+	if query.filterIteration != nil {
+		iteration := query.filterIteration
 		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemIteration), criteria.Literal(string(*iteration))))
 		additionalQuery = append(additionalQuery, "filter[iteration]="+*iteration)
 	}
-	if ctx.FilterWorkitemtype != nil {
-		wit := ctx.FilterWorkitemtype
+	if query.filterWorkitemtype != nil {
+		wit := query.filterWorkitemtype
 		exp = criteria.And(exp, criteria.Equals(criteria.Field("Type"), criteria.Literal([]string{*wit})))
 		additionalQuery = append(additionalQuery, "filter[workitemtype]="+*wit)
 	}
-	if ctx.FilterArea != nil {
-		area := ctx.FilterArea
+	if query.filterArea != nil {
+		area := query.filterArea
 		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemArea), criteria.Literal(string(*area))))
 		additionalQuery = append(additionalQuery, "filter[area]="+*area)
 	}
-	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
-	return application.Transactional(c.db, func(tx application.Application) error {
-		result, tc, err := tx.WorkItems().List(ctx.Context, exp, &offset, &limit)
+	sort, err := workitem.ParseSort(query.sort)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if len(sort) > 0 {
+		additionalQuery = append(additionalQuery, "sort="+workitem.FormatSort(sort))
+	}
+	page, err := computePage(query.pageAfter, query.pageBefore, query.pageLimit)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	var response *app.WorkItem2List
+	if page != nil {
+		err = application.Transactional(db, func(tx application.Application) error {
+			result, more, tc, err := tx.WorkItems().ListPage(ctx, exp, sort, *page)
+			if err != nil {
+				return errs.Wrap(err, "Error listing work items")
+			}
+			ids := make([]string, len(result))
+			for i, wi := range result {
+				ids[i] = wi.ID
+			}
+			refs, err := tx.References().List(ctx, reference.SourceWorkItem, ids...)
+			if err != nil {
+				return errs.WithStack(err)
+			}
+			response = &app.WorkItem2List{
+				Links: &app.PagingLinks{},
+				Meta:  &app.WorkItemListResponseMeta{TotalCount: int(tc)},
+				Data:  ConvertWorkItems(request, result, WorkItemIncludeReferences(refs)),
+			}
+			setCursorLinks(response.Links, buildAbsoluteURL(request), *page, ids, more, additionalQuery...)
+			return nil
+		})
+		return response, err
+	}
+	offset, limit := computePagingLimts(query.pageOffset, query.pageLimit)
+	err = application.Transactional(db, func(tx application.Application) error {
+		result, tc, err := tx.WorkItems().List(ctx, exp, sort, &offset, &limit)
 		count := int(tc)
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error listing work items"))
+			return errs.Wrap(err, "Error listing work items")
 		}
-		response := app.WorkItem2List{
+		ids := make([]string, len(result))
+		for i, wi := range result {
+			ids[i] = wi.ID
+		}
+		refs, err := tx.References().List(ctx, reference.SourceWorkItem, ids...)
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		response = &app.WorkItem2List{
 			Links: &app.PagingLinks{},
 			Meta:  &app.WorkItemListResponseMeta{TotalCount: count},
-			Data:  ConvertWorkItems(ctx.RequestData, result),
+			Data:  ConvertWorkItems(request, result, WorkItemIncludeReferences(refs)),
 		}
-		setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count, additionalQuery...)
-		return ctx.OK(&response)
+		setPagingLinks(response.Links, buildAbsoluteURL(request), len(result), offset, limit, count, additionalQuery...)
+		return nil
 	})
+	return response, err
+}
+
+// filterVariables resolves the variables that can be used in a work item filter expression
+func filterVariables(ctx context.Context) lang.VariableResolver {
+	return func(name string) (interface{}, error) {
+		switch name {
+		case "me":
+			return login.ContextIdentity(ctx)
+		}
+		return nil, errs.Errorf("unknown variable %s", name)
+	}
 }
 
 // Update does PATCH workitem
 func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
 	return application.Transactional(c.db, func(appl application.Application) error {
 		if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.ID == nil {
 			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("missing data.ID element in request", nil))
@@ -107,11 +225,15 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
 		wi.Type = oldType
-		wi, err = appl.WorkItems().Save(ctx, *wi)
+		wi, err = appl.WorkItems().Save(ctx, *wi, currentUser)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error updating work item"))
 		}
-		wi2 := ConvertWorkItem(ctx.RequestData, wi)
+		refs, err := appl.References().List(ctx, reference.SourceWorkItem, wi.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi2 := ConvertWorkItem(ctx.RequestData, wi, WorkItemIncludeReferences(refs))
 		resp := &app.WorkItem2Single{
 			Data: wi2,
 			Links: &app.WorkItemLinks{
@@ -122,35 +244,184 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 	})
 }
 
-// Create does POST workitem
-func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
+// maxBulkWorkItems limits the number of work items changed by a single bulk request
+const maxBulkWorkItems = 100
+
+// bulkSavepoint is the savepoint used to undo the changes of a single work item in a bulk request
+const bulkSavepoint = "bulk_work_item"
+
+// bulkTarget is a work item selected by a bulk request together with the changes to apply to it
+type bulkTarget struct {
+	id string
+	// the changes to apply, not used for deletes
+	changes app.WorkItem2
+	// the part of the request that selected the work item, as JSONAPI error source
+	source map[string]interface{}
+}
+
+// BulkUpdate does PATCH workitems, updating or deleting many work items in one transaction
+func (c *WorkitemController) BulkUpdate(ctx *app.BulkUpdateWorkitemContext) error {
 	currentUser, err := login.ContextIdentity(ctx)
 	if err != nil {
 		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 		return ctx.Unauthorized(jerrors)
 	}
-	var wit *string
-	if ctx.Payload.Data != nil && ctx.Payload.Data.Relationships != nil &&
-		ctx.Payload.Data.Relationships.BaseType != nil && ctx.Payload.Data.Relationships.BaseType.Data != nil {
-		wit = &ctx.Payload.Data.Relationships.BaseType.Data.ID
+	deleting := ctx.Payload.Operation != nil && *ctx.Payload.Operation == "delete"
+	atomic := ctx.Payload.Atomic != nil && *ctx.Payload.Atomic
+	results := []*app.WorkItemBulkResult{}
+	failures := []*app.JSONAPIError{}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		targets, err := bulkTargets(ctx, appl, ctx.Payload, deleting)
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		// the changes of a failed work item are undone, so the transaction goes on with the next one. In
+		// atomic mode the whole transaction is rolled back at the end if any work item failed.
+		savepoints, _ := appl.(application.Savepoints)
+		for _, target := range targets {
+			if savepoints != nil {
+				if err := savepoints.Savepoint(bulkSavepoint); err != nil {
+					return errs.WithStack(err)
+				}
+			}
+			result := &app.WorkItemBulkResult{ID: target.id}
+			result.Data, err = applyBulkOperation(ctx, appl, ctx.RequestData, target, deleting, currentUser)
+			if savepoints != nil {
+				if err != nil {
+					if err := savepoints.RollbackToSavepoint(bulkSavepoint); err != nil {
+						return errs.WithStack(err)
+					}
+				}
+				if err := savepoints.ReleaseSavepoint(bulkSavepoint); err != nil {
+					return errs.WithStack(err)
+				}
+			}
+			switch {
+			case err != nil:
+				jerr, _ := jsonapi.ErrorToJSONAPIError(err)
+				jerr.Source = target.source
+				jerr.Meta = map[string]interface{}{"id": target.id}
+				failures = append(failures, &jerr)
+				result.Status = "failed"
+				result.Errors = []*app.JSONAPIError{&jerr}
+			case deleting:
+				result.Status = "deleted"
+			default:
+				result.Status = "updated"
+			}
+			results = append(results, result)
+		}
+		if atomic && len(failures) > 0 {
+			return errs.Errorf("bulk update failed for %d work items", len(failures))
+		}
+		return nil
+	})
+	if atomic && len(failures) > 0 {
+		return ctx.BadRequest(&app.JSONAPIErrors{Errors: failures})
 	}
-	if wit == nil { // TODO Figure out path source etc. Should be a required relation
-		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("Data.Relationships.BaseType.Data.ID", err))
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	wi := app.WorkItem{
-		Fields: make(map[string]interface{}),
+	return ctx.OK(&app.WorkItemBulkResultList{Data: results})
+}
+
+// bulkTargets returns the work items selected by the given bulk request, first the ones given explicitly
+// and then the ones matching the filter
+func bulkTargets(ctx context.Context, appl application.Application, payload *app.BulkUpdateWorkitemPayload, deleting bool) ([]bulkTarget, error) {
+	targets := []bulkTarget{}
+	selected := map[string]bool{}
+	for i, data := range payload.Data {
+		if data == nil || data.ID == nil {
+			return nil, errors.NewBadParameterError(fmt.Sprintf("data[%d].id", i), nil)
+		}
+		targets = append(targets, bulkTarget{
+			id:      *data.ID,
+			changes: *data,
+			source:  map[string]interface{}{"pointer": fmt.Sprintf("/data/%d", i)},
+		})
+		selected[*data.ID] = true
 	}
-	return application.Transactional(c.db, func(appl application.Application) error {
-		err := ConvertJSONAPIToWorkItem(appl, *ctx.Payload.Data, &wi)
+	if payload.Filter != nil {
+		if !deleting && payload.Patch == nil {
+			return nil, errors.NewBadParameterError("patch", nil).Expected("the changes to apply to the work items selected by the filter")
+		}
+		exp, err := lang.Parse(payload.Filter, filterVariables(ctx))
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Error creating work item")))
+			return nil, errors.NewBadParameterError("filter", *payload.Filter)
+		}
+		start, limit := 0, maxBulkWorkItems+1
+		wis, _, err := appl.WorkItems().List(ctx, exp, nil, &start, &limit)
+		if err != nil {
+			return nil, errs.Wrap(err, "Error listing work items")
+		}
+		for _, wi := range wis {
+			if selected[wi.ID] {
+				continue
+			}
+			target := bulkTarget{
+				id:     wi.ID,
+				source: map[string]interface{}{"parameter": "filter"},
+			}
+			if payload.Patch != nil {
+				target.changes = *payload.Patch
+				target.changes.Attributes = map[string]interface{}{}
+				for key, value := range payload.Patch.Attributes {
+					target.changes.Attributes[key] = value
+				}
+				target.changes.Attributes["version"] = wi.Version
+			}
+			targets = append(targets, target)
 		}
+	}
+	if len(targets) == 0 {
+		return nil, errors.NewBadParameterError("data", nil).Expected("work items or a filter selecting them")
+	}
+	if len(targets) > maxBulkWorkItems {
+		return nil, errors.NewBadParameterError("data", len(targets)).Expected(fmt.Sprintf("at most %d work items", maxBulkWorkItems))
+	}
+	return targets, nil
+}
 
-		wi, err := appl.WorkItems().Create(ctx, *wit, wi.Fields, currentUser)
+// applyBulkOperation updates or deletes the given work item and returns the updated work item
+func applyBulkOperation(ctx context.Context, appl application.Application, request *goa.RequestData, target bulkTarget, deleting bool, currentUser string) (*app.WorkItem2, error) {
+	if deleting {
+		if err := appl.WorkItems().Delete(ctx, target.id, currentUser); err != nil {
+			return nil, errs.Wrapf(err, "error deleting work item %s", target.id)
+		}
+		if err := appl.WorkItemLinks().DeleteRelatedLinks(ctx, target.id); err != nil {
+			return nil, errs.Wrapf(err, "failed to delete work item links related to work item %s", target.id)
+		}
+		return nil, nil
+	}
+	wi, err := appl.WorkItems().Load(ctx, target.id)
+	if err != nil {
+		return nil, errs.Wrapf(err, "Failed to load work item with id %v", target.id)
+	}
+	// type changes are not allowed, see Update
+	oldType := wi.Type
+	if err := ConvertJSONAPIToWorkItem(appl, target.changes, wi); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	wi.Type = oldType
+	wi, err = appl.WorkItems().Save(ctx, *wi, currentUser)
+	if err != nil {
+		return nil, errs.Wrap(err, "Error updating work item")
+	}
+	return ConvertWorkItem(request, wi), nil
+}
+
+// Create does POST workitem
+func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		wi2, err := createWorkItem(ctx, appl, ctx.RequestData, ctx.Payload.Data, currentUser, nil)
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Error creating work item")))
+			return jsonapi.JSONErrorResponse(ctx, err)
 		}
-		wi2 := ConvertWorkItem(ctx.RequestData, wi)
 		resp := &app.WorkItem2Single{
 			Data: wi2,
 			Links: &app.WorkItemLinks{
@@ -162,6 +433,38 @@ func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 	})
 }
 
+// createWorkItem creates a work item from the given payload data on behalf of the given user.
+// If spaceID is not nil, the work item is created in that space regardless of the payload.
+func createWorkItem(ctx context.Context, appl application.Application, request *goa.RequestData, data *app.WorkItem2, currentUser string, spaceID *uuid.UUID) (*app.WorkItem2, error) {
+	var wit *string
+	if data != nil && data.Relationships != nil &&
+		data.Relationships.BaseType != nil && data.Relationships.BaseType.Data != nil {
+		wit = &data.Relationships.BaseType.Data.ID
+	}
+	if wit == nil { // TODO Figure out path source etc. Should be a required relation
+		return nil, errors.NewBadParameterError("Data.Relationships.BaseType.Data.ID", nil)
+	}
+	wi := app.WorkItem{
+		Fields: make(map[string]interface{}),
+	}
+	err := ConvertJSONAPIToWorkItem(appl, *data, &wi)
+	if err != nil {
+		return nil, errs.Wrap(err, fmt.Sprintf("Error creating work item"))
+	}
+	if spaceID != nil {
+		wi.Fields[workitem.SystemSpace] = spaceID.String()
+	}
+	created, err := appl.WorkItems().Create(ctx, *wit, wi.Fields, currentUser)
+	if err != nil {
+		return nil, errs.Wrap(err, fmt.Sprintf("Error creating work item"))
+	}
+	refs, err := appl.References().List(ctx, reference.SourceWorkItem, created.ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return ConvertWorkItem(request, created, WorkItemIncludeReferences(refs)), nil
+}
+
 // Show does GET workitem
 func (c *WorkitemController) Show(ctx *app.ShowWorkitemContext) error {
 	return application.Transactional(c.db, func(appl application.Application) error {
@@ -170,7 +473,11 @@ func (c *WorkitemController) Show(ctx *app.ShowWorkitemContext) error {
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Fail to load work item with id %v", ctx.ID)))
 		}
-		wi2 := ConvertWorkItem(ctx.RequestData, wi, comments)
+		refs, err := appl.References().List(ctx, reference.SourceWorkItem, wi.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi2 := ConvertWorkItem(ctx.RequestData, wi, comments, WorkItemIncludeReferences(refs))
 		resp := &app.WorkItem2Single{
 			Data: wi2,
 		}
@@ -180,8 +487,13 @@ func (c *WorkitemController) Show(ctx *app.ShowWorkitemContext) error {
 
 // Delete does DELETE workitem
 func (c *WorkitemController) Delete(ctx *app.DeleteWorkitemContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
 	return application.Transactional(c.db, func(appl application.Application) error {
-		err := appl.WorkItems().Delete(ctx, ctx.ID)
+		err := appl.WorkItems().Delete(ctx, ctx.ID, currentUser)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "error deleting work item %s", ctx.ID))
 		}
@@ -192,6 +504,28 @@ func (c *WorkitemController) Delete(ctx *app.DeleteWorkitemContext) error {
 	})
 }
 
+// Undelete does POST workitem undelete
+func (c *WorkitemController) Undelete(ctx *app.UndeleteWorkitemContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		wi, err := appl.WorkItems().Undelete(ctx, ctx.ID, currentUser)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "error undeleting work item %s", ctx.ID))
+		}
+		resp := &app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi),
+			Links: &app.WorkItemLinks{
+				Self: rest.AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID)),
+			},
+		}
+		return ctx.OK(resp)
+	})
+}
+
 // ConvertJSONAPIToWorkItem is responsible for converting given WorkItem model object into a
 // response resource object by jsonapi.org specifications
 func ConvertJSONAPIToWorkItem(appl application.Application, source app.WorkItem2, target *app.WorkItem) error {
@@ -250,6 +584,22 @@ func ConvertJSONAPIToWorkItem(appl application.Application, source app.WorkItem2
 			target.Fields[workitem.SystemArea] = areaUUID.String()
 		}
 	}
+	if source.Relationships != nil && source.Relationships.Space != nil {
+		if source.Relationships.Space.Data == nil {
+			// an explicit nil falls back to the space of the iteration or area, work items can't be without a space
+			target.Fields[workitem.SystemSpace] = nil
+		} else {
+			d := source.Relationships.Space.Data
+			spaceUUID, err := uuid.FromString(*d.ID)
+			if err != nil {
+				return errors.NewBadParameterError("data.relationships.space.data.id", *d.ID)
+			}
+			if _, err = appl.Spaces().Load(context.Background(), spaceUUID); err != nil {
+				return errors.NewBadParameterError("data.relationships.space.data.id", *d.ID)
+			}
+			target.Fields[workitem.SystemSpace] = spaceUUID.String()
+		}
+	}
 	if source.Relationships != nil && source.Relationships.BaseType != nil {
 		if source.Relationships.BaseType.Data != nil {
 			target.Type = source.Relationships.BaseType.Data.ID
@@ -360,6 +710,13 @@ func ConvertWorkItem(request *goa.RequestData, wi *app.WorkItem, additional ...W
 					Data: ConvertAreaSimple(request, valStr),
 				}
 			}
+		case workitem.SystemSpace:
+			if val != nil {
+				valStr := val.(string)
+				op.Relationships.Space = &app.RelationGeneric{
+					Data: ConvertSpaceSimple(request, valStr),
+				}
+			}
 
 		case workitem.SystemTitle:
 			// 'HTML escape' the title to prevent script injection
@@ -372,6 +729,9 @@ func ConvertWorkItem(request *goa.RequestData, wi *app.WorkItem, additional ...W
 				// let's include the rendered description while 'HTML escaping' it to prevent script injection
 				op.Attributes[workitem.SystemDescriptionRendered] =
 					rendering.RenderMarkupToHTML(html.EscapeString((*description).Content), (*description).Markup)
+				// the read-only progress of the task lists in the description
+				op.Attributes[workitem.SystemDescriptionProgress] =
+					rendering.GetTaskListProgress((*description).Content, (*description).Markup)
 			}
 
 		default:
@@ -387,6 +747,9 @@ func ConvertWorkItem(request *goa.RequestData, wi *app.WorkItem, additional ...W
 	if op.Relationships.Area == nil {
 		op.Relationships.Area = &app.RelationGeneric{Data: nil}
 	}
+	if op.Relationships.Space == nil {
+		op.Relationships.Space = &app.RelationGeneric{Data: nil}
+	}
 	// Always include Comments Link, but optionally use WorkItemIncludeCommentsAndTotal
 	WorkItemIncludeComments(request, wi, op)
 	for _, add := range additional {
@@ -1,11 +1,15 @@
 package workitem
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/rendering"
+	errs "github.com/pkg/errors"
 )
 
 const (
@@ -15,11 +19,21 @@ const (
 // Compile takes an expression and compiles it to a where clause for use with gorm.DB.Where()
 // Returns the number of expected parameters for the query and a slice of errors if something goes wrong
 func Compile(where criteria.Expression) (whereClause string, parameters []interface{}, err []error) {
+	return CompileWithKinds(where, nil)
+}
+
+// CompileWithKinds works like Compile, but uses the given kinds of the fields referenced in the expression to
+// compare the values of JSON fields with the right type, e.g. instants and numbers are compared numerically.
+// JSON fields with unknown kind are compared according to the type of the literal they are compared with.
+func CompileWithKinds(where criteria.Expression, kinds map[string]Kind) (whereClause string, parameters []interface{}, err []error) {
 	criteria.IteratePostOrder(where, bubbleUpJSONContext)
 
-	compiler := newExpressionCompiler()
+	compiler := newExpressionCompiler(kinds)
 	compiled := where.Accept(&compiler)
-
+	if compiled == nil {
+		// errors have been accumulated in the compiler
+		return "", compiler.parameters, compiler.err
+	}
 	return compiled.(string), compiler.parameters, compiler.err
 }
 
@@ -30,40 +44,55 @@ func bubbleUpJSONContext(exp criteria.Expression) bool {
 		if isJSONField(t.FieldName) {
 			t.SetAnnotation(jsonAnnotation, true)
 		}
-	case *criteria.EqualsExpression:
-		if t.Left().Annotation(jsonAnnotation) == true || t.Right().Annotation(jsonAnnotation) == true {
+	case *criteria.EqualsExpression, *criteria.NotEqualsExpression, *criteria.InExpression,
+		*criteria.LessThanExpression, *criteria.LessOrEqualsExpression,
+		*criteria.GreaterThanExpression, *criteria.GreaterOrEqualsExpression,
+		*criteria.SubstringExpression:
+		b := t.(criteria.BinaryExpression)
+		if b.Left().Annotation(jsonAnnotation) == true || b.Right().Annotation(jsonAnnotation) == true {
+			b.SetAnnotation(jsonAnnotation, true)
+		}
+	case *criteria.IsNullExpression:
+		if t.Operand().Annotation(jsonAnnotation) == true {
 			t.SetAnnotation(jsonAnnotation, true)
 		}
 	}
 	return true
 }
 
+// columns maps the names of fields that are stored in a table column to the column name
+var columns = map[string]string{
+	"ID":            "ID",
+	"Type":          "Type",
+	"Version":       "Version",
+	SystemCreatedAt: "created_at",
+	SystemSpace:     "space_id",
+}
+
 // does the field name reference a json field or a column?
 func isJSONField(fieldName string) bool {
-	switch fieldName {
-	case "ID", "Type", "Version":
-		return false
-	}
-	return true
+	_, isColumn := columns[fieldName]
+	return !isColumn
 }
 
-func newExpressionCompiler() expressionCompiler {
-	return expressionCompiler{parameters: []interface{}{}}
+func newExpressionCompiler(kinds map[string]Kind) expressionCompiler {
+	return expressionCompiler{parameters: []interface{}{}, kinds: kinds}
 }
 
 // expressionCompiler takes an expression and compiles it to a where clause for our gorm models
 // implements criteria.ExpressionVisitor
 type expressionCompiler struct {
-	parameters []interface{} // records the number of parameter expressions encountered
-	err        []error       // record any errors found in the expression
+	parameters []interface{}   // records the number of parameter expressions encountered
+	err        []error         // record any errors found in the expression
+	kinds      map[string]Kind // the kinds of the json fields, if known
 }
 
 // visitor implementation
 // the convention is to return nil when the expression cannot be compiled and to append an error to the err field
 
 func (c *expressionCompiler) Field(f *criteria.FieldExpression) interface{} {
-	if !isJSONField(f.FieldName) {
-		return f.FieldName
+	if column, ok := columns[f.FieldName]; ok {
+		return column
 	}
 	if strings.Contains(f.FieldName, "'") {
 		// beware of injection, it's a reasonable restriction for field names, make sure it's not allowed when creating wi types
@@ -91,13 +120,310 @@ func (c *expressionCompiler) Or(a *criteria.OrExpression) interface{} {
 	return c.binary(a, "or")
 }
 
+func (c *expressionCompiler) Not(n *criteria.NotExpression) interface{} {
+	operand := n.Operand().Accept(c)
+	if operand == nil {
+		return nil
+	}
+	return "(not " + operand.(string) + ")"
+}
+
 func (c *expressionCompiler) Equals(e *criteria.EqualsExpression) interface{} {
 	if isInJSONContext(e.Left()) {
+		if field, value, ok := fieldAndLiteral(e); ok {
+			return c.parenthesize(c.containment(field, value))
+		}
 		return c.binary(e, ":")
 	}
 	return c.binary(e, "=")
 }
 
+func (c *expressionCompiler) NotEquals(e *criteria.NotEqualsExpression) interface{} {
+	if isInJSONContext(e.Left()) {
+		// a missing field is considered to be different from any value
+		var contained interface{}
+		if field, value, ok := fieldAndLiteral(e); ok {
+			contained = c.parenthesize(c.containment(field, value))
+		} else {
+			contained = c.binary(e, ":")
+		}
+		if contained == nil {
+			return nil
+		}
+		return "(not " + contained.(string) + ")"
+	}
+	return c.binary(e, "!=")
+}
+
+func (c *expressionCompiler) LessThan(e *criteria.LessThanExpression) interface{} {
+	return c.comparison(e, "<")
+}
+
+func (c *expressionCompiler) LessOrEquals(e *criteria.LessOrEqualsExpression) interface{} {
+	return c.comparison(e, "<=")
+}
+
+func (c *expressionCompiler) GreaterThan(e *criteria.GreaterThanExpression) interface{} {
+	return c.comparison(e, ">")
+}
+
+func (c *expressionCompiler) GreaterOrEquals(e *criteria.GreaterOrEqualsExpression) interface{} {
+	return c.comparison(e, ">=")
+}
+
+// comparison compiles ordering operators. JSON field values are cast according to the kind of the field.
+func (c *expressionCompiler) comparison(e criteria.BinaryExpression, op string) interface{} {
+	if !isInJSONContext(e.Left()) {
+		return c.binary(e, op)
+	}
+	field, value, ok := fieldAndLiteral(e)
+	if !ok {
+		c.err = append(c.err, fmt.Errorf("operator %s on JSON fields needs a field on the left and a literal on the right", op))
+		return nil
+	}
+	text := c.jsonText(field)
+	if text == nil {
+		return nil
+	}
+	kind := c.kinds[field.FieldName]
+	converted, err := convertToKind(value, kind)
+	if err != nil {
+		c.err = append(c.err, errs.Wrapf(err, "cannot compare field %s", field.FieldName))
+		return nil
+	}
+	c.parameters = append(c.parameters, converted)
+	return "(" + text.(string) + castForKind(kind, converted) + " " + op + " ?)"
+}
+
+// In compiles to a disjunction of containment tests for JSON fields and to an SQL "in" for columns
+func (c *expressionCompiler) In(e *criteria.InExpression) interface{} {
+	values, ok := e.Right().(*criteria.LiteralExpression)
+	if !ok {
+		c.err = append(c.err, fmt.Errorf("right side of in expression must be a literal"))
+		return nil
+	}
+	list, ok := values.Value.([]interface{})
+	if !ok || len(list) == 0 {
+		c.err = append(c.err, fmt.Errorf("right side of in expression must be a non-empty list: %v", values.Value))
+		return nil
+	}
+	if isInJSONContext(e.Left()) {
+		field, ok := e.Left().(*criteria.FieldExpression)
+		if !ok {
+			c.err = append(c.err, fmt.Errorf("left side of in expression must be a field"))
+			return nil
+		}
+		terms := make([]string, len(list))
+		for i, value := range list {
+			term := c.containment(field, value)
+			if term == nil {
+				return nil
+			}
+			terms[i] = term.(string)
+		}
+		return "(" + strings.Join(terms, " or ") + ")"
+	}
+	left := e.Left().Accept(c)
+	if left == nil {
+		return nil
+	}
+	terms := make([]string, len(list))
+	for i, value := range list {
+		c.parameters = append(c.parameters, value)
+		terms[i] = "?"
+	}
+	return "(" + left.(string) + " in (" + strings.Join(terms, ",") + "))"
+}
+
+func (c *expressionCompiler) IsNull(e *criteria.IsNullExpression) interface{} {
+	var operand interface{}
+	if isInJSONContext(e.Operand()) {
+		field, ok := e.Operand().(*criteria.FieldExpression)
+		if !ok {
+			c.err = append(c.err, fmt.Errorf("is null on JSON values needs a field"))
+			return nil
+		}
+		// missing fields and fields with a JSON null value are both extracted as SQL NULL
+		operand = c.jsonText(field)
+	} else {
+		operand = e.Operand().Accept(c)
+	}
+	if operand == nil {
+		return nil
+	}
+	return "(" + operand.(string) + " is null)"
+}
+
+// Substring compiles to a case insensitive "like" with the literal escaped
+func (c *expressionCompiler) Substring(e *criteria.SubstringExpression) interface{} {
+	var field *criteria.FieldExpression
+	var value interface{}
+	var ok bool
+	if field, value, ok = fieldAndLiteral(e); !ok {
+		c.err = append(c.err, fmt.Errorf("substring expression needs a field on the left and a literal on the right"))
+		return nil
+	}
+	pattern, ok := value.(string)
+	if !ok {
+		c.err = append(c.err, fmt.Errorf("substring of field %s must be a string, but is %T", field.FieldName, value))
+		return nil
+	}
+	var text interface{}
+	if isInJSONContext(field) {
+		text = c.jsonText(field)
+	} else {
+		text = c.Field(field)
+		if text != nil {
+			text = text.(string) + "::text"
+		}
+	}
+	if text == nil {
+		return nil
+	}
+	c.parameters = append(c.parameters, "%"+escapeLike(pattern)+"%")
+	return "(" + text.(string) + " ilike ?)"
+}
+
+// escapeLike escapes the wildcard characters of a "like" pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// fieldAndLiteral returns the field on the left and the value of the literal on the right side of
+// the given expression, if the expression has that form
+func fieldAndLiteral(e criteria.BinaryExpression) (*criteria.FieldExpression, interface{}, bool) {
+	field, ok := e.Left().(*criteria.FieldExpression)
+	if !ok {
+		return nil, nil, false
+	}
+	literal, ok := e.Right().(*criteria.LiteralExpression)
+	if !ok {
+		return nil, nil, false
+	}
+	return field, literal.Value, true
+}
+
+// containment compiles a test whether the given json field contains the value, converted to the kind of the field
+func (c *expressionCompiler) containment(field *criteria.FieldExpression, value interface{}) interface{} {
+	left := c.Field(field)
+	if left == nil {
+		return nil
+	}
+	kind := c.kinds[field.FieldName]
+	converted, err := convertToKind(value, kind)
+	if err != nil {
+		c.err = append(c.err, errs.Wrapf(err, "cannot compare field %s", field.FieldName))
+		return nil
+	}
+	var stringVal string
+	switch t := converted.(type) {
+	case []string:
+		stringVal = "[" + c.wrapStrings(t) + "]"
+	case []interface{}:
+		stringVal, err = c.convertListToString(t)
+	default:
+		stringVal, err = c.convertToString(converted)
+		if err == nil && kind == KindList {
+			// a single value matches lists that contain the value
+			stringVal = "[" + stringVal + "]"
+		}
+	}
+	if err != nil {
+		c.err = append(c.err, err)
+		return nil
+	}
+	return left.(string) + " : " + stringVal + "}'"
+}
+
+func (c *expressionCompiler) parenthesize(compiled interface{}) interface{} {
+	if compiled == nil {
+		return nil
+	}
+	return "(" + compiled.(string) + ")"
+}
+
+// jsonText compiles to the text value of the given json field. Markup fields are represented by their content.
+func (c *expressionCompiler) jsonText(field *criteria.FieldExpression) interface{} {
+	if strings.Contains(field.FieldName, "'") {
+		c.err = append(c.err, fmt.Errorf("single quote not allowed in field name"))
+		return nil
+	}
+	if c.kinds[field.FieldName] == KindMarkup {
+		return "(Fields->'" + field.FieldName + "'->>'" + rendering.ContentKey + "')"
+	}
+	return "(Fields->>'" + field.FieldName + "')"
+}
+
+// castForKind returns the SQL cast for the text value of a json field of the given kind.
+// If the kind is unknown, the type of the value the field is compared with is used.
+func castForKind(kind Kind, value interface{}) string {
+	switch kind {
+	case KindInstant, KindInteger, KindDuration, KindWorkitemReference:
+		return "::bigint"
+	case KindFloat:
+		return "::float8"
+	case "":
+		switch value.(type) {
+		case int, int64, uint, uint64, float64:
+			return "::numeric"
+		case bool:
+			return "::boolean"
+		}
+	}
+	return ""
+}
+
+// convertToKind converts a literal value to the representation used to store values of the given kind.
+// Values for fields with unknown kind are returned unchanged.
+func convertToKind(value interface{}, kind Kind) (interface{}, error) {
+	switch kind {
+	case KindInstant:
+		switch t := value.(type) {
+		case time.Time:
+			return t.UnixNano(), nil
+		case string:
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+				if parsed, err := time.Parse(layout, t); err == nil {
+					return parsed.UnixNano(), nil
+				}
+			}
+			return nil, errs.Errorf("value %v should be an RFC 3339 timestamp or a date (yyyy-mm-dd)", value)
+		}
+		return toInt64(value)
+	case KindInteger, KindDuration, KindWorkitemReference:
+		if s, ok := value.(string); ok && kind == KindWorkitemReference {
+			// work item references are passed as strings in the API
+			return strconv.ParseInt(s, 10, 64)
+		}
+		return toInt64(value)
+	case KindFloat:
+		switch t := value.(type) {
+		case float64:
+			return t, nil
+		case int:
+			return float64(t), nil
+		case int64:
+			return float64(t), nil
+		}
+		return nil, errs.Errorf("value %v should be a number", value)
+	}
+	return value, nil
+}
+
+func toInt64(value interface{}) (interface{}, error) {
+	switch t := value.(type) {
+	case int:
+		return int64(t), nil
+	case int64:
+		return t, nil
+	case float64:
+		if t == float64(int64(t)) {
+			return int64(t), nil
+		}
+	}
+	return nil, errs.Errorf("value %v should be an integer", value)
+}
+
 func (c *expressionCompiler) Parameter(v *criteria.ParameterExpression) interface{} {
 	c.err = append(c.err, fmt.Errorf("Parameter expression not supported"))
 	return nil
@@ -139,11 +465,30 @@ func (c *expressionCompiler) Literal(v *criteria.LiteralExpression) interface{}
 func (c *expressionCompiler) wrapStrings(value []string) string {
 	wrapped := []string{}
 	for i := 0; i < len(value); i++ {
-		wrapped = append(wrapped, "\""+value[i]+"\"")
+		wrapped = append(wrapped, quoteJSONString(value[i]))
 	}
 	return strings.Join(wrapped, ",")
 }
 
+// quoteJSONString turns the given value into a JSON string that can safely be embedded in a
+// single-quoted SQL string literal
+func quoteJSONString(value string) string {
+	quoted, _ := json.Marshal(value)
+	return strings.Replace(string(quoted), "'", "''", -1)
+}
+
+func (c *expressionCompiler) convertListToString(value []interface{}) (string, error) {
+	converted := make([]string, len(value))
+	for i, element := range value {
+		var err error
+		converted[i], err = c.convertToString(element)
+		if err != nil {
+			return "", err
+		}
+	}
+	return "[" + strings.Join(converted, ",") + "]", nil
+}
+
 func (c *expressionCompiler) convertToString(value interface{}) (string, error) {
 	var result string
 	switch t := value.(type) {
@@ -158,7 +503,7 @@ func (c *expressionCompiler) convertToString(value interface{}) (string, error)
 	case uint64:
 		result = strconv.FormatUint(t, 10)
 	case string:
-		result = "\"" + t + "\""
+		result = quoteJSONString(t)
 	case bool:
 		result = strconv.FormatBool(t)
 	default:
@@ -1,9 +1,11 @@
 package workitem_test
 
 import (
+	"fmt"
 	"reflect"
 	"runtime/debug"
 	"testing"
+	"time"
 
 	. "github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/resource"
@@ -16,6 +18,7 @@ func TestField(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	expect(t, Equals(Field("foo"), Literal(23)), "(Fields@>'{\"foo\" : 23}')", []interface{}{})
 	expect(t, Equals(Field("Type"), Literal("abcd")), "(Type = ?)", []interface{}{"abcd"})
+	expect(t, Equals(Field("system.space"), Literal("abcd")), "(space_id = ?)", []interface{}{"abcd"})
 }
 
 func TestAndOr(t *testing.T) {
@@ -27,8 +30,120 @@ func TestAndOr(t *testing.T) {
 	expect(t, Or(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields@>'{\"foo\" : \"abcd\"}') or (? = ?))", []interface{}{true, false})
 }
 
+func TestNot(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, Not(Equals(Field("foo"), Literal("abcd"))), "(not (Fields@>'{\"foo\" : \"abcd\"}'))", []interface{}{})
+	expect(t, Not(Equals(Field("Type"), Literal("abcd"))), "(not (Type = ?))", []interface{}{"abcd"})
+}
+
+func TestNotEquals(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, NotEquals(Field("foo"), Literal(23)), "(not (Fields@>'{\"foo\" : 23}'))", []interface{}{})
+	expect(t, NotEquals(Field("Type"), Literal("abcd")), "(Type != ?)", []interface{}{"abcd"})
+}
+
+func TestIn(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, In(Field("system.state"), Literal([]interface{}{"open", "closed"})), "(Fields@>'{\"system.state\" : \"open\"}' or Fields@>'{\"system.state\" : \"closed\"}')", []interface{}{})
+	expect(t, In(Field("ID"), Literal([]interface{}{1, 2, 3})), "(ID in (?,?,?))", []interface{}{1, 2, 3})
+
+	_, _, err := Compile(In(Field("ID"), Literal([]interface{}{})))
+	assert.NotEmpty(t, err)
+	_, _, err = Compile(In(Field("ID"), Field("Version")))
+	assert.NotEmpty(t, err)
+}
+
+func TestColumnComparison(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, LessThan(Field("Version"), Literal(3)), "(Version < ?)", []interface{}{3})
+	expect(t, LessOrEquals(Field("Version"), Literal(3)), "(Version <= ?)", []interface{}{3})
+	expect(t, GreaterThan(Field("Version"), Literal(3)), "(Version > ?)", []interface{}{3})
+	expect(t, GreaterOrEquals(Field("Version"), Literal(3)), "(Version >= ?)", []interface{}{3})
+	expect(t, GreaterOrEquals(Field("system.created_at"), Literal("2016-11-01")), "(created_at >= ?)", []interface{}{"2016-11-01"})
+}
+
+func TestStringEscaping(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, Equals(Field("foo"), Literal(`it's a "quote"`)), `(Fields@>'{"foo" : "it''s a \"quote\""}')`, []interface{}{})
+}
+
+func TestJSONComparison(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	kinds := map[string]Kind{
+		"created":  KindInstant,
+		"estimate": KindFloat,
+		"points":   KindInteger,
+	}
+	instant := time.Date(2016, 11, 1, 0, 0, 0, 0, time.UTC)
+	expectWithKinds(t, kinds, LessThan(Field("created"), Literal("2016-11-01T00:00:00Z")), "((Fields->>'created')::bigint < ?)", []interface{}{instant.UnixNano()})
+	expectWithKinds(t, kinds, GreaterOrEquals(Field("created"), Literal("2016-11-01")), "((Fields->>'created')::bigint >= ?)", []interface{}{instant.UnixNano()})
+	expectWithKinds(t, kinds, LessOrEquals(Field("estimate"), Literal(int64(3))), "((Fields->>'estimate')::float8 <= ?)", []interface{}{float64(3)})
+	expectWithKinds(t, kinds, GreaterThan(Field("points"), Literal(float64(5))), "((Fields->>'points')::bigint > ?)", []interface{}{int64(5)})
+	// unknown kinds are compared according to the literal
+	expectWithKinds(t, kinds, GreaterThan(Field("foo"), Literal(int64(5))), "((Fields->>'foo')::numeric > ?)", []interface{}{int64(5)})
+	expectWithKinds(t, kinds, GreaterThan(Field("foo"), Literal("abc")), "((Fields->>'foo') > ?)", []interface{}{"abc"})
+
+	_, _, err := CompileWithKinds(LessThan(Field("created"), Literal("yesterday")), kinds)
+	assert.NotEmpty(t, err)
+	_, _, err = CompileWithKinds(LessThan(Field("points"), Literal(2.5)), kinds)
+	assert.NotEmpty(t, err)
+}
+
+func TestTypedEquals(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	kinds := map[string]Kind{
+		"system.assignees": KindList,
+		"created":          KindInstant,
+	}
+	instant := time.Date(2016, 11, 1, 0, 0, 0, 0, time.UTC)
+	expectWithKinds(t, kinds, Equals(Field("system.assignees"), Literal("me")), "(Fields@>'{\"system.assignees\" : [\"me\"]}')", []interface{}{})
+	expectWithKinds(t, kinds, Equals(Field("system.assignees"), Literal([]string{"a", "b"})), "(Fields@>'{\"system.assignees\" : [\"a\",\"b\"]}')", []interface{}{})
+	expectWithKinds(t, kinds, Equals(Field("created"), Literal(instant)), fmt.Sprintf("(Fields@>'{\"created\" : %d}')", instant.UnixNano()), []interface{}{})
+	expectWithKinds(t, kinds, In(Field("system.assignees"), Literal([]interface{}{"a", "b"})), "(Fields@>'{\"system.assignees\" : [\"a\"]}' or Fields@>'{\"system.assignees\" : [\"b\"]}')", []interface{}{})
+}
+
+func TestIsNull(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, IsNull(Field("system.iteration")), "((Fields->>'system.iteration') is null)", []interface{}{})
+	expect(t, Not(IsNull(Field("system.iteration"))), "(not ((Fields->>'system.iteration') is null))", []interface{}{})
+	expect(t, IsNull(Field("Type")), "(Type is null)", []interface{}{})
+}
+
+func TestSubstring(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, Substring(Field("system.title"), Literal("foo")), "((Fields->>'system.title') ilike ?)", []interface{}{"%foo%"})
+	expect(t, Substring(Field("system.title"), Literal(`50%_\`)), "((Fields->>'system.title') ilike ?)", []interface{}{`%50\%\_\\%`})
+	expect(t, Substring(Field("Type"), Literal("bug")), "(Type::text ilike ?)", []interface{}{"%bug%"})
+	expectWithKinds(t, map[string]Kind{"system.description": KindMarkup}, Substring(Field("system.description"), Literal("foo")), "((Fields->'system.description'->>'content') ilike ?)", []interface{}{"%foo%"})
+
+	_, _, err := Compile(Substring(Field("system.title"), Literal(5)))
+	assert.NotEmpty(t, err)
+}
+
+func TestSingleQuoteInFieldName(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	_, _, err := Compile(LessThan(Field("a'b"), Literal(5)))
+	assert.NotEmpty(t, err)
+	_, _, err = Compile(IsNull(Field("a'b")))
+	assert.NotEmpty(t, err)
+}
+
 func expect(t *testing.T, expr Expression, expectedClause string, expectedParameters []interface{}) {
-	clause, parameters, err := Compile(expr)
+	expectWithKinds(t, nil, expr, expectedClause, expectedParameters)
+}
+
+func expectWithKinds(t *testing.T, kinds map[string]Kind, expr Expression, expectedClause string, expectedParameters []interface{}) {
+	clause, parameters, err := CompileWithKinds(expr, kinds)
 	if len(err) > 0 {
 		debug.PrintStack()
 		t.Fatal(err[0].Error())
@@ -51,6 +51,8 @@ type FieldType interface {
 type FieldDefinition struct {
 	Required bool
 	Type     FieldType
+	// deprecated fields are kept for existing work items, but should not be used anymore
+	Deprecated bool `json:",omitempty"`
 }
 
 // Ensure FieldDefinition implements the Equaler interface
@@ -66,6 +68,9 @@ func (self FieldDefinition) Equal(u convert.Equaler) bool {
 	if self.Required != other.Required {
 		return false
 	}
+	if self.Deprecated != other.Deprecated {
+		return false
+	}
 	return self.Type.Equal(other.Type)
 }
 
@@ -86,8 +91,9 @@ func (f FieldDefinition) ConvertFromModel(name string, value interface{}) (inter
 }
 
 type rawFieldDef struct {
-	Required bool
-	Type     *json.RawMessage
+	Required   bool
+	Type       *json.RawMessage
+	Deprecated bool
 }
 
 // Ensure rawFieldDef implements the Equaler interface
@@ -103,6 +109,9 @@ func (self rawFieldDef) Equal(u convert.Equaler) bool {
 	if self.Required != other.Required {
 		return false
 	}
+	if self.Deprecated != other.Deprecated {
+		return false
+	}
 	if self.Type == nil && other.Type == nil {
 		return true
 	}
@@ -135,21 +144,21 @@ func (f *FieldDefinition) UnmarshalJSON(bytes []byte) error {
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		*f = FieldDefinition{Type: theType, Required: temp.Required}
+		*f = FieldDefinition{Type: theType, Required: temp.Required, Deprecated: temp.Deprecated}
 	case KindEnum:
 		theType := EnumType{}
 		err = json.Unmarshal(*temp.Type, &theType)
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		*f = FieldDefinition{Type: theType, Required: temp.Required}
+		*f = FieldDefinition{Type: theType, Required: temp.Required, Deprecated: temp.Deprecated}
 	default:
 		theType := SimpleType{}
 		err = json.Unmarshal(*temp.Type, &theType)
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		*f = FieldDefinition{Type: theType, Required: temp.Required}
+		*f = FieldDefinition{Type: theType, Required: temp.Required, Deprecated: temp.Deprecated}
 	}
 	return nil
 }
@@ -34,3 +34,24 @@ func TestListFieldDefMarshalling(t *testing.T) {
 		t.Errorf("field should be %v, but is %v", def, unmarshalled)
 	}
 }
+
+func TestDeprecatedFieldDefMarshalling(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	def := FieldDefinition{
+		Type:       SimpleType{Kind: KindString},
+		Deprecated: true,
+	}
+	bytes, err := json.Marshal(def)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	unmarshalled := FieldDefinition{}
+	json.Unmarshal(bytes, &unmarshalled)
+
+	if !reflect.DeepEqual(def, unmarshalled) {
+		t.Errorf("field should be %v, but is %v", def, unmarshalled)
+	}
+}
@@ -0,0 +1,172 @@
+package workitem
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/models"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/robfig/cron"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// fieldMigrationBatchSize is the number of work items converted in one transaction
+const fieldMigrationBatchSize = 100
+
+// FieldMigration records an incompatible change to a field of a work item type. The values of the field
+// in existing work items of the type and of the subtypes that received the change are converted in the background.
+type FieldMigration struct {
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CreatedAt time.Time
+	// the path of the changed work item type
+	TypePath string
+	// the types below the path whose field was changed, subtypes that redefined the field are left out
+	Types TypeRefs `sql:"type:jsonb"`
+	// the version of the work item type that introduced the change
+	TypeVersion int
+	FieldName   string
+	// maps the string representation of old values to the new values
+	ValueMapping Fields `sql:"type:jsonb"`
+	// when all work items were converted, nil while the migration is pending
+	CompletedAt *time.Time
+}
+
+// TableName implements gorm.tabler
+func (m FieldMigration) TableName() string {
+	return "work_item_field_migrations"
+}
+
+// TypeRef identifies a work item type by its space and name
+type TypeRef struct {
+	SpaceID uuid.UUID `json:"space_id"`
+	Name    string    `json:"name"`
+}
+
+// TypeRefs is a list of work item types stored as a JSON array
+type TypeRefs []TypeRef
+
+// Value implements driver.Valuer
+func (r TypeRefs) Value() (driver.Value, error) {
+	return toBytes(r)
+}
+
+// Scan implements sql.Scanner
+func (r *TypeRefs) Scan(src interface{}) error {
+	return fromBytes(src, r)
+}
+
+// FieldMigrator converts the fields of existing work items according to the pending field migrations
+type FieldMigrator struct {
+	db   *gorm.DB
+	cron *cron.Cron
+}
+
+// NewFieldMigrator creates a FieldMigrator
+func NewFieldMigrator(db *gorm.DB) *FieldMigrator {
+	return &FieldMigrator{db: db, cron: cron.New()}
+}
+
+// Start runs the pending field migrations periodically with the given cron schedule
+func (m *FieldMigrator) Start(schedule string) error {
+	if err := m.cron.AddFunc(schedule, func() {
+		if err := m.Run(context.Background()); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+			}, "field migration failed")
+		}
+	}); err != nil {
+		return errs.WithStack(err)
+	}
+	m.cron.Start()
+	return nil
+}
+
+// Stop stops running field migrations
+// This should be called only from main
+func (m *FieldMigrator) Stop() {
+	m.cron.Stop()
+}
+
+// Run completes all pending field migrations, oldest first
+func (m *FieldMigrator) Run(ctx context.Context) error {
+	var pending []FieldMigration
+	if err := m.db.Where("completed_at is null").Order("created_at").Find(&pending).Error; err != nil {
+		return errs.WithStack(err)
+	}
+	for _, migration := range pending {
+		for {
+			var converted int
+			err := models.Transactional(m.db, func(tx *gorm.DB) error {
+				var err error
+				converted, err = migrateFieldValues(ctx, tx, migration, fieldMigrationBatchSize)
+				return err
+			})
+			if err != nil {
+				return errs.WithStack(err)
+			}
+			if converted < fieldMigrationBatchSize {
+				break
+			}
+		}
+		if err := m.db.Model(&migration).Update("completed_at", gorm.NowFunc()).Error; err != nil {
+			return errs.WithStack(err)
+		}
+		log.Info(ctx, map[string]interface{}{
+			"pkg":       "workitem",
+			"typePath":  migration.TypePath,
+			"fieldName": migration.FieldName,
+		}, "Completed field migration")
+	}
+	return nil
+}
+
+// migrateFieldValues converts the field values of at most limit work items affected by the given migration
+// and returns the number of converted work items. Every conversion is recorded as a revision without modifier.
+func migrateFieldValues(ctx context.Context, db *gorm.DB, migration FieldMigration, limit int) (int, error) {
+	oldValues := make([]string, 0, len(migration.ValueMapping))
+	for oldValue := range migration.ValueMapping {
+		oldValues = append(oldValues, oldValue)
+	}
+	if len(oldValues) == 0 {
+		return 0, nil
+	}
+	// a work item has the type of its space with its type name, or the global one if the space has none
+	var items []WorkItem
+	err := db.Where(`exists (select 1 from work_item_types t, work_item_field_migrations m where m.id = ?
+			and m.types @> jsonb_build_array(jsonb_build_object('space_id', t.space_id, 'name', t.name))
+			and t.deleted_at is null and t.name = work_items.type
+			and (t.space_id = work_items.space_id or t.space_id = ? and not exists (select 1 from work_item_types o
+				where o.deleted_at is null and o.name = work_items.type and o.space_id = work_items.space_id)))
+		and Fields->>? in (?)`, migration.ID, uuid.Nil, migration.FieldName, oldValues).
+		Order("id").Limit(limit).Find(&items).Error
+	if err != nil {
+		return 0, errs.WithStack(err)
+	}
+	for _, wi := range items {
+		oldFields := Fields{}
+		for name, value := range wi.Fields {
+			oldFields[name] = value
+		}
+		wi.Fields[migration.FieldName] = migration.ValueMapping[fmt.Sprint(wi.Fields[migration.FieldName])]
+		tx := db.Model(&wi).Where("version = ?", wi.Version).Updates(map[string]interface{}{
+			"version": wi.Version + 1,
+			"fields":  wi.Fields,
+		})
+		if tx.Error != nil {
+			return 0, errs.WithStack(tx.Error)
+		}
+		if tx.RowsAffected == 0 {
+			// changed concurrently, the next batch will pick it up again
+			continue
+		}
+		wi.Version++
+		if err := createRevision(db, RevisionTypeUpdate, "", oldFields, wi); err != nil {
+			return 0, errs.WithStack(err)
+		}
+	}
+	return len(items), nil
+}
@@ -7,8 +7,10 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/eventstream"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
@@ -71,11 +73,11 @@ func (r *GormWorkItemLinkRepository) ValidateCorrectSourceAndTargetType(ctx cont
 		return errs.WithStack(err)
 	}
 	// Fetch the concrete work item types of the target and the source.
-	sourceWorkItemType, err := r.workItemTypeRepo.LoadTypeFromDB(ctx, source.Type)
+	sourceWorkItemType, err := r.workItemTypeRepo.LoadTypeInSpaceFromDB(ctx, &source.SpaceID, source.Type)
 	if err != nil {
 		return errs.WithStack(err)
 	}
-	targetWorkItemType, err := r.workItemTypeRepo.LoadTypeFromDB(ctx, target.Type)
+	targetWorkItemType, err := r.workItemTypeRepo.LoadTypeInSpaceFromDB(ctx, &target.SpaceID, target.Type)
 	if err != nil {
 		return errs.WithStack(err)
 	}
@@ -111,6 +113,9 @@ func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targe
 		}
 		return nil, errors.NewInternalError(db.Error.Error())
 	}
+	if err := r.emit(ctx, webhook.EventWorkItemLinkCreated, *link); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
 	// Convert the created link type entry into a JSONAPI response
 	result := ConvertLinkFromModel(*link)
 	return &result, nil
@@ -207,15 +212,20 @@ func (r *GormWorkItemLinkRepository) Delete(ctx context.Context, ID string) erro
 		// treat as not found: clients don't know it must be a UUID
 		return errors.NewNotFoundError("work item link", ID)
 	}
-	var link = WorkItemLink{
-		ID: id,
-	}
+	var link = WorkItemLink{}
 	log.Info(ctx, map[string]interface{}{
 		"pkg":   "link",
 		"wilID": ID,
 	}, "Deleting the work item link repository")
 
-	db := r.db.Delete(&link)
+	db := r.db.Where("id=?", id).First(&link)
+	if db.RecordNotFound() {
+		return errors.NewNotFoundError("work item link", id.String())
+	}
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	db = r.db.Delete(&link)
 	if db.Error != nil {
 		log.Error(ctx, map[string]interface{}{
 			"wilID": ID,
@@ -226,6 +236,9 @@ func (r *GormWorkItemLinkRepository) Delete(ctx context.Context, ID string) erro
 	if db.RowsAffected == 0 {
 		return errors.NewNotFoundError("work item link", id.String())
 	}
+	if err := r.emit(ctx, webhook.EventWorkItemLinkDeleted, link); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
 	return nil
 }
 
@@ -237,10 +250,20 @@ func (r *GormWorkItemLinkRepository) DeleteRelatedLinks(ctx context.Context, wiI
 		// treat as not found: clients don't know it must be a uint64
 		return errors.NewNotFoundError("work item link", wiIDStr)
 	}
-	db := r.db.Where("? in (source_id, target_id)", wiId).Delete(&WorkItemLink{})
+	var links []WorkItemLink
+	db := r.db.Where("? in (source_id, target_id)", wiId).Find(&links)
 	if db.Error != nil {
 		return errors.NewInternalError(db.Error.Error())
 	}
+	db = r.db.Where("? in (source_id, target_id)", wiId).Delete(&WorkItemLink{})
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	for _, link := range links {
+		if err := r.emit(ctx, webhook.EventWorkItemLinkDeleted, link); err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+	}
 	return nil
 }
 
@@ -284,6 +307,10 @@ func (r *GormWorkItemLinkRepository) Save(ctx context.Context, lt app.WorkItemLi
 		return nil, errors.NewInternalError(db.Error.Error())
 	}
 
+	if err := r.emit(ctx, webhook.EventWorkItemLinkUpdated, res); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+
 	log.Info(ctx, map[string]interface{}{
 		"pkg":   "link",
 		"wilID": res.ID,
@@ -291,3 +318,20 @@ func (r *GormWorkItemLinkRepository) Save(ctx context.Context, lt app.WorkItemLi
 	result := ConvertLinkFromModel(res)
 	return &result, nil
 }
+
+// emit queues the given event for the webhooks and the event stream of the space of the source work item of the link
+func (r *GormWorkItemLinkRepository) emit(ctx context.Context, eventType string, link WorkItemLink) error {
+	source := strconv.FormatUint(link.SourceID, 10)
+	err := webhook.EmitForWorkItem(ctx, r.db, link.SourceID, eventType, map[string]interface{}{
+		"id":        link.ID,
+		"source":    source,
+		"target":    strconv.FormatUint(link.TargetID, 10),
+		"link-type": link.LinkTypeID,
+		"version":   link.Version,
+	})
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	version := link.Version
+	return eventstream.PublishForWorkItem(r.db, link.SourceID, eventstream.Notification{Type: eventType, ID: link.ID.String(), Version: &version, WorkItemID: source})
+}
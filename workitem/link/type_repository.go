@@ -270,13 +270,14 @@ func (r *GormWorkItemLinkTypeRepository) ListSourceLinkTypes(ctx context.Context
 		query := fmt.Sprintf(`
 			-- Get link types we can use with a specific WIT if the WIT is at the
 			-- source of the link.
-			(SELECT path FROM %[2]s WHERE name = %[1]s.source_type_name LIMIT 1)
+			-- Link types refer to the global work item types.
+			(SELECT path FROM %[2]s WHERE name = %[1]s.source_type_name AND space_id = ? LIMIT 1)
 			@>
-			(SELECT path FROM %[2]s WHERE name = ? LIMIT 1)`,
+			(SELECT path FROM %[2]s WHERE name = ? AND space_id = ? LIMIT 1)`,
 			WorkItemLinkType{}.TableName(),
 			workitem.WorkItemType{}.TableName(),
 		)
-		db = db.Where(query, witName)
+		db = db.Where(query, satoriuuid.Nil, witName, satoriuuid.Nil)
 		var rows []WorkItemLinkType
 		db = db.Find(&rows)
 		if db.RecordNotFound() {
@@ -295,13 +296,14 @@ func (r *GormWorkItemLinkTypeRepository) ListTargetLinkTypes(ctx context.Context
 		query := fmt.Sprintf(`
 			-- Get link types we can use with a specific WIT if the WIT is at the
 			-- target of the link.
-			(SELECT path FROM %[2]s WHERE name = %[1]s.target_type_name LIMIT 1)
+			-- Link types refer to the global work item types.
+			(SELECT path FROM %[2]s WHERE name = %[1]s.target_type_name AND space_id = ? LIMIT 1)
 			@>
-			(SELECT path FROM %[2]s WHERE name = ? LIMIT 1)`,
+			(SELECT path FROM %[2]s WHERE name = ? AND space_id = ? LIMIT 1)`,
 			WorkItemLinkType{}.TableName(),
 			workitem.WorkItemType{}.TableName(),
 		)
-		db = db.Where(query, witName)
+		db = db.Where(query, satoriuuid.Nil, witName, satoriuuid.Nil)
 		var rows []WorkItemLinkType
 		db = db.Find(&rows)
 		if db.RecordNotFound() {
@@ -31,7 +31,7 @@ func (self ListType) Equal(u convert.Equaler) bool {
 
 // ConvertToModel implements the FieldType interface
 func (fieldType ListType) ConvertToModel(value interface{}) (interface{}, error) {
-	// the assumption is that work item types do not change over time...only new ones can be created
+	// the kind of a field never changes when a work item type is updated, so stored values always match the component type
 	return convertList(func(fieldType FieldType, value interface{}) (interface{}, error) {
 		return fieldType.ConvertToModel(value)
 	}, fieldType.ComponentType, value)
@@ -40,7 +40,7 @@ func (fieldType ListType) ConvertToModel(value interface{}) (interface{}, error)
 
 // ConvertFromModel implements the FieldType interface
 func (fieldType ListType) ConvertFromModel(value interface{}) (interface{}, error) {
-	// the assumption is that work item types do not change over time...only new ones can be created
+	// the kind of a field never changes when a work item type is updated, so stored values always match the component type
 	return convertList(func(fieldType FieldType, value interface{}) (interface{}, error) {
 		return fieldType.ConvertFromModel(value)
 	}, fieldType.ComponentType, value)
@@ -54,7 +54,7 @@ const (
 )
 
 func convertList(converter converter, componentType SimpleType, value interface{}) ([]interface{}, error) {
-	// the assumption is that work item types do not change over time...only new ones can be created
+	// the kind of a field never changes when a work item type is updated, see GormWorkItemTypeRepository.Update
 	valueType := reflect.TypeOf(value)
 
 	if value == nil {
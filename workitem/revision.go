@@ -0,0 +1,192 @@
+package workitem
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// RevisionType defines the kind of change applied to a work item in a revision
+type RevisionType int
+
+const (
+	// RevisionTypeCreate a work item was created
+	RevisionTypeCreate RevisionType = 1
+	// RevisionTypeUpdate the fields or type of a work item were updated
+	RevisionTypeUpdate RevisionType = 2
+	// RevisionTypeDelete a work item was deleted
+	RevisionTypeDelete RevisionType = 3
+	// RevisionTypeUndelete a deleted work item was recovered
+	RevisionTypeUndelete RevisionType = 4
+)
+
+// String returns the name of the revision type as used in the API
+func (t RevisionType) String() string {
+	switch t {
+	case RevisionTypeCreate:
+		return "create"
+	case RevisionTypeUpdate:
+		return "update"
+	case RevisionTypeDelete:
+		return "delete"
+	case RevisionTypeUndelete:
+		return "undelete"
+	}
+	return strconv.Itoa(int(t))
+}
+
+// FieldChange holds the value of a work item field before and after a revision
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// FieldChanges maps the names of the fields changed in a revision to their old and new values
+type FieldChanges map[string]FieldChange
+
+// Value implements driver.Valuer
+func (c FieldChanges) Value() (driver.Value, error) {
+	return toBytes(c)
+}
+
+// Scan implements sql.Scanner
+func (c *FieldChanges) Scan(src interface{}) error {
+	return fromBytes(src, c)
+}
+
+// Revision represents the state of a work item after a change, as it is stored in the database
+type Revision struct {
+	ID uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	// the time of the change
+	Time time.Time `gorm:"column:revision_time"`
+	// the kind of change
+	Type RevisionType `gorm:"column:revision_type"`
+	// the user who made the change, usually an identity ID. Updates imported from remote trackers have no modifier
+	Modifier string
+	// the work item state after the change
+	WorkItemID      uint64
+	WorkItemType    string
+	WorkItemVersion int
+	WorkItemFields  Fields `sql:"type:jsonb"`
+	// the fields changed by this revision. Nil for revisions recorded before changes were tracked
+	WorkItemChanges FieldChanges `sql:"type:jsonb"`
+}
+
+// TableName implements gorm.tabler
+func (r Revision) TableName() string {
+	return "work_item_revisions"
+}
+
+// NewRevision returns a revision recording the given change to a work item. old holds the fields
+// before the change and is nil for newly created work items.
+func NewRevision(revisionType RevisionType, modifier string, old Fields, wi WorkItem) Revision {
+	return Revision{
+		Time:            gorm.NowFunc(),
+		Type:            revisionType,
+		Modifier:        modifier,
+		WorkItemID:      wi.ID,
+		WorkItemType:    wi.Type,
+		WorkItemVersion: wi.Version,
+		WorkItemFields:  wi.Fields,
+		WorkItemChanges: computeChanges(old, wi.Fields),
+	}
+}
+
+// computeChanges returns the fields that differ between old and new, fields that are nil on
+// one side and missing on the other are not considered changed
+func computeChanges(old Fields, new Fields) FieldChanges {
+	result := FieldChanges{}
+	for name, value := range new {
+		if !sameValue(old[name], value) {
+			result[name] = FieldChange{Old: old[name], New: value}
+		}
+	}
+	for name, value := range old {
+		if _, ok := new[name]; !ok && value != nil {
+			result[name] = FieldChange{Old: value}
+		}
+	}
+	return result
+}
+
+// sameValue compares field values by their JSON representation, as values read from the
+// database (e.g. []interface{}, float64) differ in type from the converted values being stored
+func sameValue(a interface{}, b interface{}) bool {
+	aBytes, aErr := json.Marshal(a)
+	bBytes, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
+
+// RevisionRepository encapsulates retrieval of work item revisions
+type RevisionRepository interface {
+	List(ctx context.Context, workItemID string) ([]*Revision, error)
+	Load(ctx context.Context, workItemID string, version int) (*Revision, error)
+}
+
+// NewRevisionRepository creates a work item revision repository based on gorm
+func NewRevisionRepository(db *gorm.DB) *GormRevisionRepository {
+	return &GormRevisionRepository{db}
+}
+
+// GormRevisionRepository implements RevisionRepository using gorm
+type GormRevisionRepository struct {
+	db *gorm.DB
+}
+
+// List returns all revisions of the work item with the given id, oldest first
+// returns NotFoundError or InternalError
+func (r *GormRevisionRepository) List(ctx context.Context, workItemID string) ([]*Revision, error) {
+	id, err := strconv.ParseUint(workItemID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, errors.NewNotFoundError("work item", workItemID)
+	}
+	result := []*Revision{}
+	if err := r.db.Where("work_item_id = ?", id).Order("work_item_version asc").Find(&result).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if len(result) == 0 {
+		return nil, errors.NewNotFoundError("work item", workItemID)
+	}
+	return result, nil
+}
+
+// Load returns the revision of the work item with the given id that resulted in the given version
+// returns NotFoundError or InternalError
+func (r *GormRevisionRepository) Load(ctx context.Context, workItemID string, version int) (*Revision, error) {
+	id, err := strconv.ParseUint(workItemID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, errors.NewNotFoundError("work item", workItemID)
+	}
+	result := Revision{}
+	tx := r.db.Where("work_item_id = ? and work_item_version = ?", id, version).First(&result)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("work item revision", strconv.Itoa(version))
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &result, nil
+}
+
+// createRevision stores a revision recording the given change to a work item
+func createRevision(db *gorm.DB, revisionType RevisionType, modifier string, old Fields, wi WorkItem) error {
+	revision := NewRevision(revisionType, modifier, old, wi)
+	revision.ID = uuid.NewV4()
+	if err := db.Create(&revision).Error; err != nil {
+		return errs.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package workitem_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/migration"
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type revisionRepoBlackBoxTest struct {
+	gormsupport.DBTestSuite
+	repo         workitem.WorkItemRepository
+	revisionRepo workitem.RevisionRepository
+}
+
+func TestRunRevisionRepoBlackBoxTest(t *testing.T) {
+	suite.Run(t, &revisionRepoBlackBoxTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (s *revisionRepoBlackBoxTest) SetupSuite() {
+	s.DBTestSuite.SetupSuite()
+
+	// Make sure the database is populated with the correct types (e.g. bug etc.)
+	if _, c := os.LookupEnv(resource.Database); c != false {
+		if err := models.Transactional(s.DB, func(tx *gorm.DB) error {
+			return migration.PopulateCommonTypes(context.Background(), tx, workitem.NewWorkItemTypeRepository(tx))
+		}); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+func (s *revisionRepoBlackBoxTest) SetupTest() {
+	s.repo = workitem.NewWorkItemRepository(s.DB)
+	s.revisionRepo = workitem.NewRevisionRepository(s.DB)
+}
+
+func (s *revisionRepoBlackBoxTest) TestRevisions() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	wi, err := s.repo.Create(
+		context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "creator")
+	require.Nil(s.T(), err, "Could not create work item")
+
+	wi.Fields[workitem.SystemState] = workitem.SystemStateOpen
+	wi, err = s.repo.Save(context.Background(), *wi, "modifier")
+	require.Nil(s.T(), err)
+
+	err = s.repo.Delete(context.Background(), wi.ID, "suppressor")
+	require.Nil(s.T(), err)
+
+	revisions, err := s.revisionRepo.List(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), revisions, 3)
+
+	assert.Equal(s.T(), workitem.RevisionTypeCreate, revisions[0].Type)
+	assert.Equal(s.T(), "creator", revisions[0].Modifier)
+	assert.Equal(s.T(), 0, revisions[0].WorkItemVersion)
+	assert.Equal(s.T(), workitem.FieldChange{New: workitem.SystemStateNew}, revisions[0].WorkItemChanges[workitem.SystemState])
+
+	assert.Equal(s.T(), workitem.RevisionTypeUpdate, revisions[1].Type)
+	assert.Equal(s.T(), "modifier", revisions[1].Modifier)
+	assert.Equal(s.T(), 1, revisions[1].WorkItemVersion)
+	assert.Equal(s.T(), workitem.FieldChanges{
+		workitem.SystemState: {Old: workitem.SystemStateNew, New: workitem.SystemStateOpen},
+	}, revisions[1].WorkItemChanges)
+	assert.Equal(s.T(), workitem.SystemStateOpen, revisions[1].WorkItemFields[workitem.SystemState])
+
+	assert.Equal(s.T(), workitem.RevisionTypeDelete, revisions[2].Type)
+	assert.Equal(s.T(), "suppressor", revisions[2].Modifier)
+	assert.Equal(s.T(), 2, revisions[2].WorkItemVersion)
+	assert.Empty(s.T(), revisions[2].WorkItemChanges)
+
+	revision, err := s.revisionRepo.Load(context.Background(), wi.ID, 1)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), revisions[1].ID, revision.ID)
+
+	_, err = s.revisionRepo.Load(context.Background(), wi.ID, 3)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *revisionRepoBlackBoxTest) TestRestore() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	wi, err := s.repo.Create(
+		context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "creator")
+	require.Nil(s.T(), err, "Could not create work item")
+	wi.Fields[workitem.SystemState] = workitem.SystemStateOpen
+	wi, err = s.repo.Save(context.Background(), *wi, "modifier")
+	require.Nil(s.T(), err)
+
+	// a stale version is rejected
+	_, err = s.repo.Restore(context.Background(), wi.ID, 0, 0, "restorer")
+	require.IsType(s.T(), errors.VersionConflictError{}, errs.Cause(err))
+	restored, err := s.repo.Restore(context.Background(), wi.ID, 0, wi.Version, "restorer")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, restored.Version)
+	assert.Equal(s.T(), workitem.SystemStateNew, restored.Fields[workitem.SystemState])
+	assert.Equal(s.T(), "Title", restored.Fields[workitem.SystemTitle])
+
+	revision, err := s.revisionRepo.Load(context.Background(), wi.ID, 2)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), workitem.RevisionTypeUpdate, revision.Type)
+	assert.Equal(s.T(), "restorer", revision.Modifier)
+	assert.Equal(s.T(), workitem.FieldChanges{
+		workitem.SystemState: {Old: workitem.SystemStateOpen, New: workitem.SystemStateNew},
+	}, revision.WorkItemChanges)
+
+	_, err = s.repo.Restore(context.Background(), wi.ID, 42, restored.Version, "restorer")
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *revisionRepoBlackBoxTest) TestUndelete() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	wi, err := s.repo.Create(
+		context.Background(), workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "creator")
+	require.Nil(s.T(), err, "Could not create work item")
+
+	_, err = s.repo.Undelete(context.Background(), wi.ID, "modifier")
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+
+	err = s.repo.Delete(context.Background(), wi.ID, "suppressor")
+	require.Nil(s.T(), err)
+	_, err = s.repo.Load(context.Background(), wi.ID)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+
+	undeleted, err := s.repo.Undelete(context.Background(), wi.ID, "modifier")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, undeleted.Version)
+	assert.Equal(s.T(), "Title", undeleted.Fields[workitem.SystemTitle])
+	_, err = s.repo.Load(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+
+	revisions, err := s.revisionRepo.List(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), revisions, 3)
+	assert.Equal(s.T(), workitem.RevisionTypeUndelete, revisions[2].Type)
+	assert.Equal(s.T(), "modifier", revisions[2].Modifier)
+}
+
+func (s *revisionRepoBlackBoxTest) TestListUnknownWorkItem() {
+	_, err := s.revisionRepo.List(context.Background(), "0")
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+	_, err = s.revisionRepo.List(context.Background(), "foo")
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func TestNewRevision(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	old := workitem.Fields{
+		workitem.SystemTitle:     "Title",
+		workitem.SystemAssignees: []interface{}{"A"},
+		"story_points":           float64(3),
+		"obsolete":               "value",
+		"unset":                  nil,
+	}
+	wi := workitem.WorkItem{
+		ID:      1,
+		Type:    workitem.SystemBug,
+		Version: 2,
+		Fields: workitem.Fields{
+			workitem.SystemTitle:     "New Title",
+			workitem.SystemAssignees: []string{"A"},
+			"story_points":           3,
+		},
+	}
+	revision := workitem.NewRevision(workitem.RevisionTypeUpdate, "modifier", old, wi)
+	assert.Equal(t, workitem.RevisionTypeUpdate, revision.Type)
+	assert.Equal(t, "modifier", revision.Modifier)
+	assert.Equal(t, uint64(1), revision.WorkItemID)
+	assert.Equal(t, 2, revision.WorkItemVersion)
+	assert.Equal(t, workitem.FieldChanges{
+		workitem.SystemTitle: {Old: "Title", New: "New Title"},
+		"obsolete":           {Old: "value"},
+	}, revision.WorkItemChanges)
+	assert.Equal(t, "update", revision.Type.String())
+}
@@ -0,0 +1,94 @@
+package workitem
+
+import (
+	"strings"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	errs "github.com/pkg/errors"
+)
+
+// SortKey describes a field work items are ordered by
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSort parses a sort parameter in JSON API style, i.e. a comma separated list of field names,
+// each optionally prefixed with "-" for descending order, e.g. "-system.created_at,system.title".
+// Returns an empty slice if sort is nil or empty.
+func ParseSort(sort *string) ([]SortKey, error) {
+	result := []SortKey{}
+	if sort == nil || strings.TrimSpace(*sort) == "" {
+		return result, nil
+	}
+	for _, field := range strings.Split(*sort, ",") {
+		field = strings.TrimSpace(field)
+		key := SortKey{Field: field}
+		if strings.HasPrefix(field, "-") {
+			key.Field = strings.TrimPrefix(field, "-")
+			key.Descending = true
+		}
+		if key.Field == "" || strings.Contains(key.Field, "'") {
+			return nil, errors.NewBadParameterError("sort", *sort)
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+// FormatSort is the inverse of ParseSort
+func FormatSort(keys []SortKey) string {
+	fields := make([]string, len(keys))
+	for i, key := range keys {
+		fields[i] = key.Field
+		if key.Descending {
+			fields[i] = "-" + key.Field
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// CompileSort compiles the given sort keys into an "order by" clause for use with gorm.DB.Order().
+// Column fields are sorted by their column, all other fields must be defined in the given field kinds
+// and are sorted by their JSON value, cast according to their kind. Returns "" if there are no keys.
+func CompileSort(keys []SortKey, kinds map[string]Kind) (string, error) {
+	terms, err := CompileSortTerms(keys, kinds)
+	if err != nil {
+		return "", errs.WithStack(err)
+	}
+	clauses := make([]string, len(terms))
+	for i, term := range terms {
+		if term.Descending {
+			clauses[i] = term.Expression + " desc"
+		} else {
+			clauses[i] = term.Expression + " asc"
+		}
+	}
+	return strings.Join(clauses, ","), nil
+}
+
+// CompileSortTerms compiles the given sort keys like CompileSort, but returns the individual order terms,
+// e.g. for use in a gormsupport.Keyset
+func CompileSortTerms(keys []SortKey, kinds map[string]Kind) ([]gormsupport.OrderTerm, error) {
+	compiler := newExpressionCompiler(kinds)
+	terms := make([]gormsupport.OrderTerm, len(keys))
+	for i, key := range keys {
+		terms[i].Descending = key.Descending
+		if column, ok := columns[key.Field]; ok {
+			terms[i].Expression = WorkItem{}.TableName() + "." + column
+			continue
+		}
+		kind, ok := kinds[key.Field]
+		if !ok {
+			return nil, errors.NewBadParameterError("sort", key.Field).Expected("a field defined by the work item types")
+		}
+		text := compiler.jsonText(criteria.Field(key.Field).(*criteria.FieldExpression))
+		if text == nil {
+			return nil, errors.NewBadParameterError("sort", key.Field)
+		}
+		terms[i].Expression = text.(string) + castForKind(kind, nil)
+	}
+	return terms, nil
+}
@@ -0,0 +1,54 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSort(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	keys, err := ParseSort(nil)
+	require.Nil(t, err)
+	assert.Empty(t, keys)
+
+	sort := " -system.created_at, system.title "
+	keys, err = ParseSort(&sort)
+	require.Nil(t, err)
+	assert.Equal(t, []SortKey{{Field: SystemCreatedAt, Descending: true}, {Field: SystemTitle}}, keys)
+	assert.Equal(t, "-system.created_at,system.title", FormatSort(keys))
+
+	for _, invalid := range []string{"system.title,", "-", "foo'bar"} {
+		_, err = ParseSort(&invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestCompileSort(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	kinds := map[string]Kind{
+		SystemTitle:    KindString,
+		"story_points": KindFloat,
+	}
+
+	order, err := CompileSort(nil, kinds)
+	require.Nil(t, err)
+	assert.Equal(t, "", order)
+
+	order, err = CompileSort([]SortKey{{Field: SystemCreatedAt, Descending: true}, {Field: "ID"}}, kinds)
+	require.Nil(t, err)
+	assert.Equal(t, "work_items.created_at desc,work_items.ID asc", order)
+
+	order, err = CompileSort([]SortKey{{Field: SystemTitle}, {Field: "story_points", Descending: true}}, kinds)
+	require.Nil(t, err)
+	assert.Equal(t, "(Fields->>'system.title') asc,(Fields->>'story_points')::float8 desc", order)
+
+	_, err = CompileSort([]SortKey{{Field: "unknown"}}, kinds)
+	assert.NotNil(t, err)
+}
@@ -37,8 +37,13 @@ func (r *UndoableWorkItemRepository) Load(ctx context.Context, ID string) (*app.
 	return r.wrapped.Load(ctx, ID)
 }
 
+// Transitions implements application.WorkItemRepository
+func (r *UndoableWorkItemRepository) Transitions(ctx context.Context, ID string) ([]Transition, error) {
+	return r.wrapped.Transitions(ctx, ID)
+}
+
 // Save implements application.WorkItemRepository
-func (r *UndoableWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error) {
+func (r *UndoableWorkItemRepository) Save(ctx context.Context, wi app.WorkItem, modifier string) (*app.WorkItem, error) {
 	id, err := strconv.ParseUint(wi.ID, 10, 64)
 	if err != nil {
 		// treating this as a not found error: the fact that we're using number internal is implementation detail
@@ -55,9 +60,12 @@ func (r *UndoableWorkItemRepository) Save(ctx context.Context, wi app.WorkItem)
 		return nil, errors.NewInternalError(fmt.Sprintf("could not load %s, %s", wi.ID, db.Error.Error()))
 	}
 
-	res, err := r.wrapped.Save(ctx, wi)
+	res, err := r.wrapped.Save(ctx, wi, modifier)
 	if err == nil {
 		r.undo.Append(func(db *gorm.DB) error {
+			if err := deleteRevision(db, old.ID, old.Version+1); err != nil {
+				return err
+			}
 			db = db.Save(&old)
 			return db.Error
 		})
@@ -66,7 +74,7 @@ func (r *UndoableWorkItemRepository) Save(ctx context.Context, wi app.WorkItem)
 }
 
 // Delete implements application.WorkItemRepository
-func (r *UndoableWorkItemRepository) Delete(ctx context.Context, ID string) error {
+func (r *UndoableWorkItemRepository) Delete(ctx context.Context, ID string, suppressor string) error {
 	id, err := strconv.ParseUint(ID, 10, 64)
 	if err != nil {
 		// treating this as a not found error: the fact that we're using number internal is implementation detail
@@ -84,9 +92,12 @@ func (r *UndoableWorkItemRepository) Delete(ctx context.Context, ID string) erro
 		return errors.NewInternalError(fmt.Sprintf("could not load %s, %s", ID, db.Error.Error()))
 	}
 
-	err = r.wrapped.Delete(ctx, ID)
+	err = r.wrapped.Delete(ctx, ID, suppressor)
 	if err == nil {
 		r.undo.Append(func(db *gorm.DB) error {
+			if err := deleteRevision(db, old.ID, old.Version+1); err != nil {
+				return err
+			}
 			old.DeletedAt = nil
 			db = db.Save(&old)
 			return db.Error
@@ -95,6 +106,69 @@ func (r *UndoableWorkItemRepository) Delete(ctx context.Context, ID string) erro
 	return errs.WithStack(err)
 }
 
+// Undelete implements application.WorkItemRepository
+func (r *UndoableWorkItemRepository) Undelete(ctx context.Context, ID string, modifier string) (*app.WorkItem, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, errors.NewNotFoundError("deleted work item", ID)
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"pkg": "workitem",
+		"id":  id,
+	}, "Loading deleted work item")
+
+	old := WorkItem{}
+	db := r.wrapped.db.Unscoped().First(&old, id)
+	if db.Error != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("could not load %s, %s", ID, db.Error.Error()))
+	}
+
+	res, err := r.wrapped.Undelete(ctx, ID, modifier)
+	if err == nil {
+		r.undo.Append(func(db *gorm.DB) error {
+			if err := deleteRevision(db, old.ID, old.Version+1); err != nil {
+				return err
+			}
+			db = db.Save(&old)
+			return db.Error
+		})
+	}
+	return res, errs.WithStack(err)
+}
+
+// Restore implements application.WorkItemRepository
+func (r *UndoableWorkItemRepository) Restore(ctx context.Context, ID string, version int, currentVersion int, modifier string) (*app.WorkItem, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, errors.NewNotFoundError("work item", ID)
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"pkg": "workitem",
+		"id":  id,
+	}, "Loading work item")
+	old := WorkItem{}
+	db := r.wrapped.db.First(&old, id)
+	if db.Error != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("could not load %s, %s", ID, db.Error.Error()))
+	}
+
+	res, err := r.wrapped.Restore(ctx, ID, version, currentVersion, modifier)
+	if err == nil {
+		r.undo.Append(func(db *gorm.DB) error {
+			if err := deleteRevision(db, old.ID, old.Version+1); err != nil {
+				return err
+			}
+			db = db.Save(&old)
+			return db.Error
+		})
+	}
+	return res, errs.WithStack(err)
+}
+
 // Create implements application.WorkItemRepository
 func (r *UndoableWorkItemRepository) Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
 	result, err := r.wrapped.Create(ctx, typeID, fields, creator)
@@ -118,10 +192,20 @@ func (r *UndoableWorkItemRepository) Create(ctx context.Context, typeID string,
 }
 
 // List implements application.WorkItemRepository
-func (r *UndoableWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error) {
-	return r.wrapped.List(ctx, criteria, start, length)
+func (r *UndoableWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, sort []SortKey, start *int, length *int) ([]*app.WorkItem, uint64, error) {
+	return r.wrapped.List(ctx, criteria, sort, start, length)
+}
+
+// ListPage implements application.WorkItemRepository
+func (r *UndoableWorkItemRepository) ListPage(ctx context.Context, criteria criteria.Expression, sort []SortKey, page gormsupport.Page) ([]*app.WorkItem, bool, uint64, error) {
+	return r.wrapped.ListPage(ctx, criteria, sort, page)
 }
 
 func (r *UndoableWorkItemRepository) GetCountsPerIteration(ctx context.Context, spaceId uuid.UUID) (map[string]WICountsPerIteration, error) {
 	return map[string]WICountsPerIteration{}, nil
 }
+
+// deleteRevision removes the revision recorded for the given version of a work item
+func deleteRevision(db *gorm.DB, workItemID uint64, version int) error {
+	return db.Where("work_item_id = ? and work_item_version = ?", workItemID, version).Delete(&Revision{}).Error
+}
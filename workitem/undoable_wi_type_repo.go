@@ -7,6 +7,7 @@ import (
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 var _ WorkItemTypeRepository = &UndoableWorkItemTypeRepository{}
@@ -28,11 +29,21 @@ func (r *UndoableWorkItemTypeRepository) Load(ctx context.Context, name string)
 	return r.wrapped.Load(ctx, name)
 }
 
+// LoadInSpace implements application.WorkItemTypeRepository
+func (r *UndoableWorkItemTypeRepository) LoadInSpace(ctx context.Context, spaceID uuid.UUID, name string) (*app.WorkItemType, error) {
+	return r.wrapped.LoadInSpace(ctx, spaceID, name)
+}
+
 // List implements application.WorkItemTypeRepository
 func (r *UndoableWorkItemTypeRepository) List(ctx context.Context, start *int, length *int) ([]*app.WorkItemType, error) {
 	return r.wrapped.List(ctx, start, length)
 }
 
+// ListInSpace implements application.WorkItemTypeRepository
+func (r *UndoableWorkItemTypeRepository) ListInSpace(ctx context.Context, spaceID uuid.UUID, start *int, length *int) ([]*app.WorkItemType, error) {
+	return r.wrapped.ListInSpace(ctx, spaceID, start, length)
+}
+
 // Create implements application.WorkItemTypeRepository
 func (r *UndoableWorkItemTypeRepository) Create(ctx context.Context, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
 	res, err := r.wrapped.Create(ctx, extendedTypeID, name, fields)
@@ -44,3 +55,49 @@ func (r *UndoableWorkItemTypeRepository) Create(ctx context.Context, extendedTyp
 	}
 	return res, errors.WithStack(err)
 }
+
+// CreateInSpace implements application.WorkItemTypeRepository
+func (r *UndoableWorkItemTypeRepository) CreateInSpace(ctx context.Context, spaceID uuid.UUID, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
+	res, err := r.wrapped.CreateInSpace(ctx, spaceID, extendedTypeID, name, fields)
+	if err == nil {
+		r.undo.Append(func(db *gorm.DB) error {
+			db = db.Unscoped().Where("space_id = ?", spaceID).Delete(&WorkItemType{Name: name, SpaceID: spaceID})
+			return db.Error
+		})
+	}
+	return res, errors.WithStack(err)
+}
+
+// Update implements application.WorkItemTypeRepository
+func (r *UndoableWorkItemTypeRepository) Update(ctx context.Context, name string, version int, fields map[string]app.FieldDefinition, valueMapping map[string]map[string]interface{}, workflow *app.Workflow) (*app.WorkItemType, error) {
+	old, err := r.wrapped.LoadTypeFromDB(ctx, name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var family []WorkItemType
+	if err := r.wrapped.db.Where("path <@ ?", old.Path).Find(&family).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res, err := r.wrapped.Update(ctx, name, version, fields, valueMapping, workflow)
+	if err == nil {
+		r.undo.Append(func(db *gorm.DB) error {
+			for _, t := range family {
+				db = db.Model(&WorkItemType{}).Where("name = ? and space_id = ?", t.Name, t.SpaceID).Updates(map[string]interface{}{
+					"fields":   t.Fields,
+					"workflow": t.Workflow,
+					"version":  t.Version,
+				})
+				if db.Error != nil {
+					return db.Error
+				}
+			}
+			db = db.Where("type_path = ? and type_version = ?", old.Path, version+1).Delete(&FieldMigration{})
+			if db.Error != nil {
+				return db.Error
+			}
+			ClearGlobalWorkItemTypeCache()
+			return nil
+		})
+	}
+	return res, errors.WithStack(err)
+}
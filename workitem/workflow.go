@@ -0,0 +1,195 @@
+package workitem
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Workflow restricts the states of the work items of a type and the transitions between them
+type Workflow struct {
+	// the states a work item can be in, a subset of the values of the system.state field
+	States []string
+	// maps a state to the states a work item can move to from it
+	Transitions map[string][]string
+	// maps a state to the fields that must have a value before a work item can move to it,
+	// e.g. system.assignees for "in progress"
+	Guards map[string][]string `json:",omitempty"`
+}
+
+// Transition describes a state a work item can move to
+type Transition struct {
+	To string
+	// the guarded fields that need a value before the transition is possible, empty if it is possible right away
+	MissingFields []string
+}
+
+// Value implements driver.Valuer
+func (w Workflow) Value() (driver.Value, error) {
+	return toBytes(w)
+}
+
+// Scan implements sql.Scanner
+func (w *Workflow) Scan(src interface{}) error {
+	return fromBytes(src, w)
+}
+
+// Equal returns true if both workflows are nil or define the same states, transitions and guards
+func (w *Workflow) Equal(other *Workflow) bool {
+	return reflect.DeepEqual(w, other)
+}
+
+// NextStates returns the states a work item in the given state can move to. Work items in a state
+// unknown to the workflow, e.g. because they were created before it was defined, can move to any state.
+func (w Workflow) NextStates(from string) []string {
+	if !containsString(w.States, from) {
+		return w.States
+	}
+	return w.Transitions[from]
+}
+
+// AvailableTransitions returns the transitions possible for a work item with the given fields
+func (w Workflow) AvailableTransitions(fields map[string]interface{}) []Transition {
+	from, _ := fields[SystemState].(string)
+	next := w.NextStates(from)
+	result := make([]Transition, len(next))
+	for i, to := range next {
+		result[i] = Transition{To: to, MissingFields: w.missingFields(to, fields)}
+	}
+	return result
+}
+
+// CheckTransition returns a BadParameterError if a work item with the given fields must not move
+// from the given state to the state in its fields
+func (w Workflow) CheckTransition(from interface{}, fields map[string]interface{}) error {
+	to, _ := fields[SystemState].(string)
+	if from == to {
+		return nil
+	}
+	fromState, _ := from.(string)
+	next := w.NextStates(fromState)
+	if !containsString(next, to) {
+		if len(next) == 0 {
+			return errors.NewBadParameterError(SystemState, to).Expected(fmt.Sprintf("no state change, '%s' is final", fromState))
+		}
+		return errors.NewBadParameterError(SystemState, to).Expected("one of " + strings.Join(next, ", "))
+	}
+	if missing := w.missingFields(to, fields); len(missing) > 0 {
+		return errors.NewBadParameterError(missing[0], nil).Expected(fmt.Sprintf("a value to move to state '%s'", to))
+	}
+	return nil
+}
+
+// CheckInitialState returns a BadParameterError if a work item must not be created with the given fields,
+// i.e. if its state is not one of the states of the workflow or a field guarding the state has no value
+func (w Workflow) CheckInitialState(fields map[string]interface{}) error {
+	state, _ := fields[SystemState].(string)
+	if !containsString(w.States, state) {
+		return errors.NewBadParameterError(SystemState, fields[SystemState]).Expected("one of " + strings.Join(w.States, ", "))
+	}
+	if missing := w.missingFields(state, fields); len(missing) > 0 {
+		return errors.NewBadParameterError(missing[0], nil).Expected(fmt.Sprintf("a value to create a work item in state '%s'", state))
+	}
+	return nil
+}
+
+// missingFields returns the fields guarding the given state which have no value
+func (w Workflow) missingFields(to string, fields map[string]interface{}) []string {
+	var missing []string
+	for _, field := range w.Guards[to] {
+		if isEmptyValue(fields[field]) {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// validate checks that the workflow only refers to values of the system.state field and to existing fields
+func (w Workflow) validate(fields FieldDefinitions) error {
+	stateField, ok := fields[SystemState]
+	if !ok {
+		return fmt.Errorf("a workflow requires the field %s", SystemState)
+	}
+	enum, ok := stateField.Type.(EnumType)
+	if !ok {
+		return fmt.Errorf("a workflow requires %s to be an enum", SystemState)
+	}
+	if len(w.States) == 0 {
+		return fmt.Errorf("a workflow requires at least one state")
+	}
+	for _, state := range w.States {
+		if !contains(enum.Values, state) {
+			return fmt.Errorf("unknown state %s", state)
+		}
+	}
+	for from, targets := range w.Transitions {
+		if !containsString(w.States, from) {
+			return fmt.Errorf("transition from unknown state %s", from)
+		}
+		for _, to := range targets {
+			if !containsString(w.States, to) {
+				return fmt.Errorf("transition from %s to unknown state %s", from, to)
+			}
+		}
+	}
+	for state, guarded := range w.Guards {
+		if !containsString(w.States, state) {
+			return fmt.Errorf("guard for unknown state %s", state)
+		}
+		for _, field := range guarded {
+			if _, ok := fields[field]; !ok {
+				return fmt.Errorf("guard for state %s requires unknown field %s", state, field)
+			}
+		}
+	}
+	return nil
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func containsString(a []string, v string) bool {
+	for _, element := range a {
+		if element == v {
+			return true
+		}
+	}
+	return false
+}
+
+// convertWorkflowToModels converts a workflow from app to models representation
+func convertWorkflowToModels(w *app.Workflow) *Workflow {
+	if w == nil {
+		return nil
+	}
+	return &Workflow{
+		States:      w.States,
+		Transitions: w.Transitions,
+		Guards:      w.Guards,
+	}
+}
+
+// convertWorkflowFromModels converts a workflow from models to app representation
+func convertWorkflowFromModels(w *Workflow) *app.Workflow {
+	if w == nil {
+		return nil
+	}
+	return &app.Workflow{
+		States:      w.States,
+		Transitions: w.Transitions,
+		Guards:      w.Guards,
+	}
+}
@@ -0,0 +1,97 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testWorkflow() workitem.Workflow {
+	return workitem.Workflow{
+		States: []string{workitem.SystemStateNew, workitem.SystemStateInProgress, workitem.SystemStateClosed},
+		Transitions: map[string][]string{
+			workitem.SystemStateNew:        {workitem.SystemStateInProgress, workitem.SystemStateClosed},
+			workitem.SystemStateInProgress: {workitem.SystemStateClosed},
+		},
+		Guards: map[string][]string{
+			workitem.SystemStateInProgress: {workitem.SystemAssignees},
+		},
+	}
+}
+
+func TestWorkflow_CheckTransition(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	w := testWorkflow()
+
+	// staying in the same state is always allowed
+	assert.Nil(t, w.CheckTransition(workitem.SystemStateClosed, map[string]interface{}{workitem.SystemState: workitem.SystemStateClosed}))
+	assert.Nil(t, w.CheckTransition(workitem.SystemStateNew, map[string]interface{}{workitem.SystemState: workitem.SystemStateClosed}))
+
+	// illegal transitions list the legal next states
+	err := w.CheckTransition(workitem.SystemStateInProgress, map[string]interface{}{workitem.SystemState: workitem.SystemStateNew})
+	require.IsType(t, errors.BadParameterError{}, err)
+	assert.Contains(t, err.Error(), "one of closed")
+	err = w.CheckTransition(workitem.SystemStateClosed, map[string]interface{}{workitem.SystemState: workitem.SystemStateNew})
+	require.IsType(t, errors.BadParameterError{}, err)
+	assert.Contains(t, err.Error(), "final")
+
+	// guards require the fields to have a value
+	err = w.CheckTransition(workitem.SystemStateNew, map[string]interface{}{workitem.SystemState: workitem.SystemStateInProgress})
+	require.IsType(t, errors.BadParameterError{}, err)
+	assert.Contains(t, err.Error(), workitem.SystemAssignees)
+	err = w.CheckTransition(workitem.SystemStateNew, map[string]interface{}{
+		workitem.SystemState:     workitem.SystemStateInProgress,
+		workitem.SystemAssignees: []interface{}{},
+	})
+	require.IsType(t, errors.BadParameterError{}, err)
+	assert.Nil(t, w.CheckTransition(workitem.SystemStateNew, map[string]interface{}{
+		workitem.SystemState:     workitem.SystemStateInProgress,
+		workitem.SystemAssignees: []interface{}{"me"},
+	}))
+
+	// work items in states unknown to the workflow can move to any state
+	assert.Nil(t, w.CheckTransition(workitem.SystemStateResolved, map[string]interface{}{workitem.SystemState: workitem.SystemStateNew}))
+}
+
+func TestWorkflow_CheckInitialState(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	w := testWorkflow()
+
+	assert.Nil(t, w.CheckInitialState(map[string]interface{}{workitem.SystemState: workitem.SystemStateNew}))
+
+	// the state must be one of the workflow
+	err := w.CheckInitialState(map[string]interface{}{workitem.SystemState: workitem.SystemStateResolved})
+	require.IsType(t, errors.BadParameterError{}, err)
+	assert.Contains(t, err.Error(), "one of new, in progress, closed")
+	err = w.CheckInitialState(map[string]interface{}{})
+	require.IsType(t, errors.BadParameterError{}, err)
+
+	// guards apply to the initial state
+	err = w.CheckInitialState(map[string]interface{}{workitem.SystemState: workitem.SystemStateInProgress})
+	require.IsType(t, errors.BadParameterError{}, err)
+	assert.Contains(t, err.Error(), workitem.SystemAssignees)
+	assert.Nil(t, w.CheckInitialState(map[string]interface{}{
+		workitem.SystemState:     workitem.SystemStateInProgress,
+		workitem.SystemAssignees: []interface{}{"me"},
+	}))
+}
+
+func TestWorkflow_AvailableTransitions(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	w := testWorkflow()
+
+	transitions := w.AvailableTransitions(map[string]interface{}{workitem.SystemState: workitem.SystemStateNew})
+	assert.Equal(t, []workitem.Transition{
+		{To: workitem.SystemStateInProgress, MissingFields: []string{workitem.SystemAssignees}},
+		{To: workitem.SystemStateClosed},
+	}, transitions)
+	transitions = w.AvailableTransitions(map[string]interface{}{workitem.SystemState: workitem.SystemStateClosed})
+	assert.Empty(t, transitions)
+}
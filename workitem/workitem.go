@@ -6,6 +6,7 @@ import (
 	"github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	uuid "github.com/satori/go.uuid"
 )
 
 // WorkItem represents a work item as it is stored in the database
@@ -14,6 +15,8 @@ type WorkItem struct {
 	ID uint64 `gorm:"primary_key"`
 	// Id of the type of this work item
 	Type string
+	// Id of the space this work item belongs to
+	SpaceID uuid.UUID `sql:"type:uuid"`
 	// Version for optimistic concurrency control
 	Version int
 	// the field values
@@ -42,6 +45,9 @@ func (wi WorkItem) Equal(u convert.Equaler) bool {
 	if wi.Type != other.Type {
 		return false
 	}
+	if !uuid.Equal(wi.SpaceID, other.SpaceID) {
+		return false
+	}
 	if wi.ID != other.ID {
 		return false
 	}
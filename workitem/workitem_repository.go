@@ -1,15 +1,23 @@
 package workitem
 
 import (
+	"fmt"
 	"strconv"
 
 	"golang.org/x/net/context"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/eventstream"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/iteration"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
@@ -18,10 +26,14 @@ import (
 // WorkItemRepository encapsulates storage & retrieval of work items
 type WorkItemRepository interface {
 	Load(ctx context.Context, ID string) (*app.WorkItem, error)
-	Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error)
-	Delete(ctx context.Context, ID string) error
+	Save(ctx context.Context, wi app.WorkItem, modifier string) (*app.WorkItem, error)
+	Delete(ctx context.Context, ID string, suppressor string) error
+	Undelete(ctx context.Context, ID string, modifier string) (*app.WorkItem, error)
+	Restore(ctx context.Context, ID string, version int, currentVersion int, modifier string) (*app.WorkItem, error)
+	Transitions(ctx context.Context, ID string) ([]Transition, error)
 	Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error)
-	List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	List(ctx context.Context, criteria criteria.Expression, sort []SortKey, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	ListPage(ctx context.Context, criteria criteria.Expression, sort []SortKey, page gormsupport.Page) ([]*app.WorkItem, bool, uint64, error)
 	GetCountsPerIteration(ctx context.Context, spaceID uuid.UUID) (map[string]WICountsPerIteration, error)
 }
 
@@ -64,38 +76,160 @@ func (r *GormWorkItemRepository) Load(ctx context.Context, ID string) (*app.Work
 	if err != nil {
 		return nil, errs.WithStack(err)
 	}
-	wiType, err := r.wir.LoadTypeFromDB(ctx, res.Type)
+	wiType, err := r.wir.LoadTypeInSpaceFromDB(ctx, &res.SpaceID, res.Type)
 	if err != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
 	return convertWorkItemModelToApp(wiType, res)
 }
 
-// Delete deletes the work item with the given id
+// Transitions returns the state changes possible for the work item with the given id. Without a workflow,
+// the work item can move to any other value of its state field.
 // returns NotFoundError or InternalError
-func (r *GormWorkItemRepository) Delete(ctx context.Context, ID string) error {
-	var workItem = WorkItem{}
-	id, err := strconv.ParseUint(ID, 10, 64)
-	if err != nil || id == 0 {
-		// treat as not found: clients don't know it must be a number
-		return errors.NewNotFoundError("work item", ID)
+func (r *GormWorkItemRepository) Transitions(ctx context.Context, ID string) ([]Transition, error) {
+	res, err := r.LoadFromDB(ctx, ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	wiType, err := r.wir.LoadTypeInSpaceFromDB(ctx, &res.SpaceID, res.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if wiType.Workflow != nil {
+		return wiType.Workflow.AvailableTransitions(res.Fields), nil
 	}
-	workItem.ID = id
-	tx := r.db.Delete(workItem)
+	result := []Transition{}
+	if enum, ok := wiType.Fields[SystemState].Type.(EnumType); ok {
+		for _, value := range enum.Values {
+			if state, ok := value.(string); ok && value != res.Fields[SystemState] {
+				result = append(result, Transition{To: state})
+			}
+		}
+	}
+	return result, nil
+}
 
+// Delete deletes the work item with the given id. The deletion counts as a change, so the version
+// of the work item is incremented and a revision is recorded for the given suppressor.
+// returns NotFoundError or InternalError
+func (r *GormWorkItemRepository) Delete(ctx context.Context, ID string, suppressor string) error {
+	workItem, err := r.LoadFromDB(ctx, ID)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	tx := r.db.Model(workItem).Where("version = ?", workItem.Version).Updates(map[string]interface{}{
+		"version":    workItem.Version + 1,
+		"deleted_at": gorm.NowFunc(),
+	})
 	if err = tx.Error; err != nil {
 		return errors.NewInternalError(err.Error())
 	}
 	if tx.RowsAffected == 0 {
 		return errors.NewNotFoundError("work item", ID)
 	}
-
+	workItem.Version++
+	if err := createRevision(r.db, RevisionTypeDelete, suppressor, workItem.Fields, *workItem); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if err := emitWorkItemEvent(ctx, r.db, webhook.EventWorkItemDeleted, *workItem); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
 	return nil
 }
 
-// Save updates the given work item in storage. Version must be the same as the one int the stored version
+// Undelete recovers the deleted work item with the given id. Like the deletion, this counts as a change:
+// the version of the work item is incremented and a revision is recorded for the given modifier.
+// Links removed along with the work item are not recovered.
+// returns NotFoundError or InternalError
+func (r *GormWorkItemRepository) Undelete(ctx context.Context, ID string, modifier string) (*app.WorkItem, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treat as not found: clients don't know it must be a number
+		return nil, errors.NewNotFoundError("deleted work item", ID)
+	}
+	res := WorkItem{}
+	tx := r.db.Unscoped().Where("deleted_at is not null").First(&res, id)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("deleted work item", ID)
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	wiType, err := r.wir.LoadTypeInSpaceFromDB(ctx, &res.SpaceID, res.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	tx = r.db.Unscoped().Model(&res).Where("version = ?", res.Version).Updates(map[string]interface{}{
+		"version":    res.Version + 1,
+		"deleted_at": nil,
+	})
+	if err = tx.Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	res.Version++
+	res.DeletedAt = nil
+	if err := createRevision(r.db, RevisionTypeUndelete, modifier, res.Fields, res); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := emitWorkItemEvent(ctx, r.db, webhook.EventWorkItemUpdated, res); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	log.Info(ctx, map[string]interface{}{
+		"pkg":  "workitem",
+		"wiID": ID,
+	}, "Undeleted work item")
+	return convertWorkItemModelToApp(wiType, &res)
+}
+
+// Restore writes the type and field values the work item with the given id had in the given version back
+// as a new version. The restored work item is stored like any other update, so the current version must
+// be the stored one and a revision is recorded for the given modifier.
 // returns NotFoundError, VersionConflictError, ConversionError or InternalError
-func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error) {
+func (r *GormWorkItemRepository) Restore(ctx context.Context, ID string, version int, currentVersion int, modifier string) (*app.WorkItem, error) {
+	current, err := r.LoadFromDB(ctx, ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	revision, err := NewRevisionRepository(r.db).Load(ctx, ID, version)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	wiType, err := r.wir.LoadTypeInSpaceFromDB(ctx, &current.SpaceID, revision.WorkItemType)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	restored, err := convertWorkItemModelToApp(wiType, &WorkItem{
+		ID:      current.ID,
+		Type:    revision.WorkItemType,
+		Version: currentVersion,
+		Fields:  revision.WorkItemFields,
+	})
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return r.Save(ctx, *restored, modifier)
+}
+
+// Save updates the given work item in storage and records a revision for the given modifier.
+// Version must be the same as the one int the stored version. State changes must follow the workflow of the type.
+// returns NotFoundError, BadParameterError, VersionConflictError, ConversionError or InternalError
+func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem, modifier string) (*app.WorkItem, error) {
+	return r.save(ctx, wi, modifier, false)
+}
+
+// SaveFromRemote updates the given work item with the state of the remote tracker it was imported from. The
+// revision is recorded without modifier and the workflow of the type isn't enforced, the remote tracker
+// decides which states follow each other.
+// returns NotFoundError, BadParameterError, VersionConflictError, ConversionError or InternalError
+func (r *GormWorkItemRepository) SaveFromRemote(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error) {
+	return r.save(ctx, wi, "", true)
+}
+
+// save updates the given work item, system changes bypass the workflow
+func (r *GormWorkItemRepository) save(ctx context.Context, wi app.WorkItem, modifier string, system bool) (*app.WorkItem, error) {
 	res := WorkItem{}
 	id, err := strconv.ParseUint(wi.ID, 10, 64)
 	if err != nil || id == 0 {
@@ -120,13 +254,27 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 		return nil, errors.NewVersionConflictError("version conflict")
 	}
 
-	wiType, err := r.wir.LoadTypeFromDB(ctx, wi.Type)
+	spaceID := res.SpaceID
+	if relocated(res, wi.Fields) {
+		if _, ok := wi.Fields[SystemSpace]; !ok {
+			// keep the space if the caller did not ask to move the work item
+			wi.Fields[SystemSpace] = res.SpaceID.String()
+		}
+		spaceID, err = r.resolveSpace(ctx, wi.Fields)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+	}
+
+	wiType, err := r.wir.LoadTypeInSpaceFromDB(ctx, &spaceID, wi.Type)
 	if err != nil {
 		return nil, errors.NewBadParameterError("Type", wi.Type)
 	}
 
+	oldFields := res.Fields
 	res.Version = res.Version + 1
 	res.Type = wi.Type
+	res.SpaceID = spaceID
 	res.Fields = Fields{}
 
 	for fieldName, fieldDef := range wiType.Fields {
@@ -140,6 +288,11 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
 		}
 	}
+	if wiType.Workflow != nil && !system {
+		if err := wiType.Workflow.CheckTransition(oldFields[SystemState], res.Fields); err != nil {
+			return nil, errs.WithStack(err)
+		}
+	}
 
 	tx = tx.Where("Version = ?", wi.Version).Save(&res)
 	if err := tx.Error; err != nil {
@@ -152,6 +305,15 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 	if tx.RowsAffected == 0 {
 		return nil, errors.NewVersionConflictError("version conflict")
 	}
+	if err := createRevision(r.db, RevisionTypeUpdate, modifier, oldFields, res); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := updateReferences(ctx, r.db, res); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := emitWorkItemEvent(ctx, r.db, webhook.EventWorkItemUpdated, res); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
 	log.Info(ctx, map[string]interface{}{
 		"pkg":  "workitem",
 		"wiID": wi.ID,
@@ -159,16 +321,34 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 	return convertWorkItemModelToApp(wiType, &res)
 }
 
-// Create creates a new work item in the repository
+// Create creates a new work item in the repository. The initial state must be one of the states of the workflow
+// of the type and the fields guarding it must have a value.
 // returns BadParameterError, ConversionError or InternalError
 func (r *GormWorkItemRepository) Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
-	wiType, err := r.wir.LoadTypeFromDB(ctx, typeID)
+	return r.create(ctx, typeID, fields, creator, false)
+}
+
+// CreateFromRemote creates a new work item imported from a remote tracker. The workflow of the type isn't
+// enforced, the remote tracker decides which state the work item is in.
+// returns BadParameterError, ConversionError or InternalError
+func (r *GormWorkItemRepository) CreateFromRemote(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
+	return r.create(ctx, typeID, fields, creator, true)
+}
+
+// create creates a new work item, system creations bypass the workflow
+func (r *GormWorkItemRepository) create(ctx context.Context, typeID string, fields map[string]interface{}, creator string, system bool) (*app.WorkItem, error) {
+	spaceID, err := r.resolveSpace(ctx, fields)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	wiType, err := r.wir.LoadTypeInSpaceFromDB(ctx, &spaceID, typeID)
 	if err != nil {
 		return nil, errors.NewBadParameterError("type", typeID)
 	}
 	wi := WorkItem{
-		Type:   typeID,
-		Fields: Fields{},
+		Type:    typeID,
+		SpaceID: spaceID,
+		Fields:  Fields{},
 	}
 	fields[SystemCreator] = creator
 	for fieldName, fieldDef := range wiType.Fields {
@@ -188,13 +368,116 @@ func (r *GormWorkItemRepository) Create(ctx context.Context, typeID string, fiel
 			}
 		}
 	}
+	if wiType.Workflow != nil && !system {
+		if err := wiType.Workflow.CheckInitialState(wi.Fields); err != nil {
+			return nil, errs.WithStack(err)
+		}
+	}
 	tx := r.db
 	if err = tx.Create(&wi).Error; err != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
+	if err := createRevision(tx, RevisionTypeCreate, creator, nil, wi); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if err := updateReferences(ctx, tx, wi); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := emitWorkItemEvent(ctx, tx, webhook.EventWorkItemCreated, wi); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
 	return convertWorkItemModelToApp(wiType, &wi)
 }
 
+// updateReferences records the mentions and work item references of the description of the given work item
+func updateReferences(ctx context.Context, db *gorm.DB, wi WorkItem) error {
+	var content, markup string
+	if description := rendering.NewMarkupContentFromValue(wi.Fields[SystemDescription]); description != nil {
+		content, markup = description.Content, description.Markup
+	}
+	source := reference.Source{Type: reference.SourceWorkItem, ID: strconv.FormatUint(wi.ID, 10), WorkItemID: wi.ID}
+	_, err := reference.NewRepository(db).Update(ctx, source, content, markup)
+	return err
+}
+
+// emitWorkItemEvent queues the given event for the webhooks and the event stream of the space of the work item
+func emitWorkItemEvent(ctx context.Context, db *gorm.DB, eventType string, wi WorkItem) error {
+	id := strconv.FormatUint(wi.ID, 10)
+	err := webhook.Emit(ctx, db, wi.SpaceID, eventType, map[string]interface{}{
+		"id":      id,
+		"type":    wi.Type,
+		"version": wi.Version,
+		"fields":  wi.Fields,
+	})
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	version := wi.Version
+	return eventstream.Publish(db, eventstream.Notification{Type: eventType, SpaceID: wi.SpaceID, ID: id, Version: &version})
+}
+
+// resolveSpace returns the space a work item with the given fields belongs to: the space given in the
+// system.space field or, if there is none, the space of its iteration or area. Every work item belongs to
+// a space and its iteration and area must belong to the same space.
+// returns BadParameterError or InternalError
+func (r *GormWorkItemRepository) resolveSpace(ctx context.Context, fields map[string]interface{}) (uuid.UUID, error) {
+	var spaceID *uuid.UUID
+	if value, ok := fields[SystemSpace]; ok && value != nil {
+		id, err := uuid.FromString(fmt.Sprint(value))
+		if err != nil {
+			return uuid.Nil, errors.NewBadParameterError(SystemSpace, value)
+		}
+		if _, err := space.NewRepository(r.db).Load(ctx, id); err != nil {
+			return uuid.Nil, errors.NewBadParameterError(SystemSpace, value)
+		}
+		spaceID = &id
+	}
+	if value, ok := fields[SystemIteration]; ok && value != nil {
+		id, err := uuid.FromString(fmt.Sprint(value))
+		if err != nil {
+			return uuid.Nil, errors.NewBadParameterError(SystemIteration, value)
+		}
+		itr, err := iteration.NewIterationRepository(r.db).Load(ctx, id)
+		if err != nil {
+			return uuid.Nil, errors.NewBadParameterError(SystemIteration, value)
+		}
+		if spaceID == nil {
+			spaceID = &itr.SpaceID
+		} else if !uuid.Equal(*spaceID, itr.SpaceID) {
+			return uuid.Nil, errors.NewBadParameterError(SystemIteration, value).Expected("iteration of space " + spaceID.String())
+		}
+	}
+	if value, ok := fields[SystemArea]; ok && value != nil {
+		id, err := uuid.FromString(fmt.Sprint(value))
+		if err != nil {
+			return uuid.Nil, errors.NewBadParameterError(SystemArea, value)
+		}
+		a, err := area.NewAreaRepository(r.db).Load(ctx, id)
+		if err != nil {
+			return uuid.Nil, errors.NewBadParameterError(SystemArea, value)
+		}
+		if spaceID == nil {
+			spaceID = &a.SpaceID
+		} else if !uuid.Equal(*spaceID, a.SpaceID) {
+			return uuid.Nil, errors.NewBadParameterError(SystemArea, value).Expected("area of space " + spaceID.String())
+		}
+	}
+	if spaceID == nil {
+		return uuid.Nil, errors.NewBadParameterError(SystemSpace, nil).Expected("a space, or an iteration or area of a space")
+	}
+	return *spaceID, nil
+}
+
+// relocated tells whether the given fields change the space, iteration or area of the given work item
+func relocated(wi WorkItem, fields map[string]interface{}) bool {
+	if value, ok := fields[SystemSpace]; ok {
+		if value == nil || fmt.Sprint(value) != wi.SpaceID.String() {
+			return true
+		}
+	}
+	return !sameValue(wi.Fields[SystemIteration], fields[SystemIteration]) || !sameValue(wi.Fields[SystemArea], fields[SystemArea])
+}
+
 func convertWorkItemModelToApp(wiType *WorkItemType, wi *WorkItem) (*app.WorkItem, error) {
 	result, err := wiType.ConvertFromModel(*wi)
 	if err != nil {
@@ -203,17 +486,47 @@ func convertWorkItemModelToApp(wiType *WorkItemType, wi *WorkItem) (*app.WorkIte
 	if _, ok := wiType.Fields[SystemCreatedAt]; ok {
 		result.Fields[SystemCreatedAt] = wi.CreatedAt
 	}
+	result.Fields[SystemSpace] = wi.SpaceID.String()
 	return result, nil
 
 }
 
+// restrictedSpaces returns the space the given expression restricts the work items to by an equality on
+// their space, possibly within a conjunction, none if there is no such restriction
+func restrictedSpaces(exp criteria.Expression) []uuid.UUID {
+	switch e := exp.(type) {
+	case *criteria.AndExpression:
+		if spaces := restrictedSpaces(e.Left()); len(spaces) > 0 {
+			return spaces
+		}
+		return restrictedSpaces(e.Right())
+	case *criteria.EqualsExpression:
+		field, isField := e.Left().(*criteria.FieldExpression)
+		literal, isLiteral := e.Right().(*criteria.LiteralExpression)
+		if isField && isLiteral && field.FieldName == SystemSpace {
+			if id, err := uuid.FromString(fmt.Sprint(literal.Value)); err == nil {
+				return []uuid.UUID{id}
+			}
+		}
+	}
+	return nil
+}
+
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
-func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, start *int, limit *int) ([]WorkItem, uint64, error) {
-	where, parameters, compileError := Compile(criteria)
+func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, sort []SortKey, start *int, limit *int) ([]WorkItem, uint64, error) {
+	kinds, err := r.wir.LoadFieldKinds(ctx, restrictedSpaces(criteria)...)
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
+	where, parameters, compileError := CompileWithKinds(criteria, kinds)
 	if compileError != nil {
 		return nil, 0, errors.NewBadParameterError("expression", criteria)
 	}
+	order, err := CompileSort(sort, kinds)
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
 
 	log.Info(ctx, map[string]interface{}{
 		"pkg":        "workitem",
@@ -223,6 +536,10 @@ func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria c
 
 	db := r.db.Model(&WorkItem{}).Where(where, parameters...)
 	orgDB := db
+	if order != "" {
+		// the ID breaks ties, so pages don't overlap
+		db = db.Order(order + "," + WorkItem{}.TableName() + ".id")
+	}
 	if start != nil {
 		if *start < 0 {
 			return nil, 0, errors.NewBadParameterError("start", *start)
@@ -287,40 +604,107 @@ func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria c
 	return result, count, nil
 }
 
-// List returns work item selected by the given criteria.Expression, starting with start (zero-based) and returning at most limit items
-func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
-	result, count, err := r.listItemsFromDB(ctx, criteria, start, limit)
+// List returns work item selected by the given criteria.Expression, ordered by the given sort keys,
+// starting with start (zero-based) and returning at most limit items
+func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, sort []SortKey, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
+	result, count, err := r.listItemsFromDB(ctx, criteria, sort, start, limit)
 	if err != nil {
 		return nil, 0, errs.WithStack(err)
 	}
 
-	res := make([]*app.WorkItem, len(result))
+	res, err := r.convertWorkItems(ctx, result)
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
+	return res, count, nil
+}
+
+// ListPage returns the given page of work items selected by the given criteria.Expression, ordered by the given
+// sort keys and by ID to break ties. It also returns whether there are more work items beyond the page in paging
+// direction and the total number of selected work items.
+func (r *GormWorkItemRepository) ListPage(ctx context.Context, criteria criteria.Expression, sort []SortKey, page gormsupport.Page) ([]*app.WorkItem, bool, uint64, error) {
+	if page.ID != "" {
+		if id, err := strconv.ParseUint(page.ID, 10, 64); err != nil || id == 0 {
+			return nil, false, 0, errors.NewBadParameterError("cursor", page.ID)
+		}
+	}
+	kinds, err := r.wir.LoadFieldKinds(ctx, restrictedSpaces(criteria)...)
+	if err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	where, parameters, compileError := CompileWithKinds(criteria, kinds)
+	if compileError != nil {
+		return nil, false, 0, errors.NewBadParameterError("expression", criteria)
+	}
+	terms, err := CompileSortTerms(sort, kinds)
+	if err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	keyset := gormsupport.Keyset{
+		Table:    WorkItem{}.TableName(),
+		IDColumn: "id",
+		Order:    append(terms, gormsupport.OrderTerm{Expression: WorkItem{}.TableName() + ".id"}),
+	}
+
+	db := r.db.Model(&WorkItem{}).Where(where, parameters...)
+	var count uint64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	db, err = keyset.Apply(db, page)
+	if err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	result := []WorkItem{}
+	if err := db.Find(&result).Error; err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	more := len(result) > page.Limit
+	if more {
+		result = result[:page.Limit]
+	}
+	if page.Before {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+	res, err := r.convertWorkItems(ctx, result)
+	if err != nil {
+		return nil, false, 0, errs.WithStack(err)
+	}
+	return res, more, count, nil
+}
 
-	for index, value := range result {
-		wiType, err := r.wir.LoadTypeFromDB(ctx, value.Type)
+// convertWorkItems converts the given work items from model to app representation
+func (r *GormWorkItemRepository) convertWorkItems(ctx context.Context, items []WorkItem) ([]*app.WorkItem, error) {
+	res := make([]*app.WorkItem, len(items))
+	for index, value := range items {
+		wiType, err := r.wir.LoadTypeInSpaceFromDB(ctx, &value.SpaceID, value.Type)
 		if err != nil {
-			return nil, 0, errors.NewInternalError(err.Error())
+			return nil, errors.NewInternalError(err.Error())
 		}
 		res[index], err = convertWorkItemModelToApp(wiType, &value)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
 	}
-
-	return res, count, nil
+	return res, nil
 }
 
 // GetCountsPerIteration fetches WI count from DB and returns a map of iterationID->WICountsPerIteration
 // This function executes following query to fetch 'closed' and 'total' counts of the WI for each iteration in given spaceID
-// 	SELECT iterations.id as IterationId, count(*) as Total,
+// 	SELECT fields->>'system.iteration' as IterationId, count(*) as Total,
 // 		count( case fields->>'system.state' when 'closed' then '1' else null end ) as Closed
-// 		FROM "work_items" left join iterations
-// 		on fields@> concat('{"system.iteration": "', iterations.id, '"}')::jsonb
-// 		WHERE (iterations.space_id = '33406de1-25f1-4969-bcec-88f29d0a7de3'
+// 		FROM "work_items"
+// 		WHERE (space_id = '33406de1-25f1-4969-bcec-88f29d0a7de3'
+// 		and fields->>'system.iteration' IS NOT NULL
 // 		and work_items.deleted_at IS NULL) GROUP BY IterationId
 
 func (r *GormWorkItemRepository) GetCountsPerIteration(ctx context.Context, spaceID uuid.UUID) (map[string]WICountsPerIteration, error) {
 	var res []WICountsPerIteration
-	db := r.db.Table("work_items").Select(`iterations.id as IterationId, count(*) as Total,
-				count( case fields->>'system.state' when 'closed' then '1' else null end ) as Closed`).Joins(`left join iterations
-				on fields@> concat('{"system.iteration": "', iterations.id, '"}')::jsonb`).Where(`iterations.space_id = ?
+	db := r.db.Table("work_items").Select(`fields->>'system.iteration' as IterationId, count(*) as Total,
+				count( case fields->>'system.state' when 'closed' then '1' else null end ) as Closed`).Where(`space_id = ?
+				and fields->>'system.iteration' IS NOT NULL
 				and work_items.deleted_at IS NULL`, spaceID).Group(`IterationId`).Scan(&res)
 	if db.Error != nil {
 		return nil, errors.NewInternalError(db.Error.Error())
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/area"
+	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
@@ -60,12 +64,13 @@ func (s *workItemRepoBlackBoxTest) TestFailDeleteZeroID() {
 	_, err := s.repo.Create(
 		context.Background(), workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
 		}, "xx")
 	require.Nil(s.T(), err, "Could not create work item")
 
-	err = s.repo.Delete(context.Background(), "0")
+	err = s.repo.Delete(context.Background(), "0", "xx")
 	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
 }
 
@@ -76,12 +81,13 @@ func (s *workItemRepoBlackBoxTest) TestFailSaveZeroID() {
 	wi, err := s.repo.Create(
 		context.Background(), workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
 		}, "xx")
 	require.Nil(s.T(), err, "Could not create workitem")
 	wi.ID = "0"
-	_, err = s.repo.Save(context.Background(), *wi)
+	_, err = s.repo.Save(context.Background(), *wi, "xx")
 	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
 }
 
@@ -92,6 +98,7 @@ func (s *workItemRepoBlackBoxTest) TestFaiLoadZeroID() {
 	_, err := s.repo.Create(
 		context.Background(), workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
 		}, "xx")
@@ -107,6 +114,7 @@ func (s *workItemRepoBlackBoxTest) TestSaveAssignees() {
 	wi, err := s.repo.Create(
 		context.Background(), workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:     space.SystemSpace.String(),
 			workitem.SystemTitle:     "Title",
 			workitem.SystemState:     workitem.SystemStateNew,
 			workitem.SystemAssignees: []string{"A", "B"},
@@ -119,12 +127,101 @@ func (s *workItemRepoBlackBoxTest) TestSaveAssignees() {
 	assert.Equal(s.T(), "A", wi.Fields[workitem.SystemAssignees].([]interface{})[0])
 }
 
+// createWorkflowType creates a planner item type restricted by the test workflow and returns its name
+func (s *workItemRepoBlackBoxTest) createWorkflowType() string {
+	witRepo := workitem.NewWorkItemTypeRepository(s.DB)
+	extended := workitem.SystemPlannerItem
+	name := "workflow-" + uuid.NewV4().String()
+	wit, err := witRepo.Create(context.Background(), &extended, name, map[string]app.FieldDefinition{})
+	require.Nil(s.T(), err)
+	w := testWorkflow()
+	_, err = witRepo.Update(context.Background(), name, wit.Version, map[string]app.FieldDefinition{}, nil, &app.Workflow{
+		States:      w.States,
+		Transitions: w.Transitions,
+		Guards:      w.Guards,
+	})
+	require.Nil(s.T(), err)
+	return name
+}
+
+func (s *workItemRepoBlackBoxTest) TestSaveFollowsWorkflow() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	wi, err := s.repo.Create(
+		context.Background(), s.createWorkflowType(),
+		map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+	require.Nil(s.T(), err)
+
+	// moving to in progress requires an assignee
+	wi.Fields[workitem.SystemState] = workitem.SystemStateInProgress
+	_, err = s.repo.Save(context.Background(), *wi, "xx")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	assert.Contains(s.T(), err.Error(), workitem.SystemAssignees)
+
+	wi.Fields[workitem.SystemState] = workitem.SystemStateClosed
+	wi, err = s.repo.Save(context.Background(), *wi, "xx")
+	require.Nil(s.T(), err)
+
+	transitions, err := s.repo.Transitions(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), transitions)
+
+	// closed is final, even for changes without modifier
+	wi.Fields[workitem.SystemState] = workitem.SystemStateNew
+	_, err = s.repo.Save(context.Background(), *wi, "")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	// changes imported from remote trackers are not restricted
+	_, err = workitem.NewWorkItemRepository(s.DB).SaveFromRemote(context.Background(), *wi)
+	require.Nil(s.T(), err)
+}
+
+func (s *workItemRepoBlackBoxTest) TestCreateFollowsWorkflow() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	witName := s.createWorkflowType()
+
+	// the initial state must be one of the workflow
+	_, err := s.repo.Create(context.Background(), witName, map[string]interface{}{
+		workitem.SystemSpace: space.SystemSpace.String(),
+		workitem.SystemTitle: "Title",
+		workitem.SystemState: workitem.SystemStateResolved,
+	}, "xx")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	// and its guards apply
+	_, err = s.repo.Create(context.Background(), witName, map[string]interface{}{
+		workitem.SystemSpace: space.SystemSpace.String(),
+		workitem.SystemTitle: "Title",
+		workitem.SystemState: workitem.SystemStateInProgress,
+	}, "xx")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	assert.Contains(s.T(), err.Error(), workitem.SystemAssignees)
+	_, err = s.repo.Create(context.Background(), witName, map[string]interface{}{
+		workitem.SystemSpace:     space.SystemSpace.String(),
+		workitem.SystemTitle:     "Title",
+		workitem.SystemState:     workitem.SystemStateInProgress,
+		workitem.SystemAssignees: []string{"A"},
+	}, "xx")
+	require.Nil(s.T(), err)
+
+	// work items imported from remote trackers are not restricted
+	_, err = workitem.NewWorkItemRepository(s.DB).CreateFromRemote(context.Background(), witName, map[string]interface{}{
+		workitem.SystemTitle: "Title",
+		workitem.SystemState: workitem.SystemStateResolved,
+	}, "xx")
+	require.Nil(s.T(), err)
+}
+
 func (s *workItemRepoBlackBoxTest) TestSaveForUnchangedCreatedDate() {
 	defer cleaner.DeleteCreatedEntities(s.DB)()
 
 	wi, err := s.repo.Create(
 		context.Background(), workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
 		}, "xx")
@@ -133,7 +230,7 @@ func (s *workItemRepoBlackBoxTest) TestSaveForUnchangedCreatedDate() {
 	wi, err = s.repo.Load(context.Background(), wi.ID)
 	require.Nil(s.T(), err)
 
-	wiNew, err := s.repo.Save(context.Background(), *wi)
+	wiNew, err := s.repo.Save(context.Background(), *wi, "xx")
 	require.Nil(s.T(), err)
 	assert.Equal(s.T(), wi.Fields[workitem.SystemCreatedAt], wiNew.Fields[workitem.SystemCreatedAt])
 }
@@ -144,6 +241,7 @@ func (s *workItemRepoBlackBoxTest) TestCreateWorkItemWithDescriptionNoMarkup() {
 	wi, err := s.repo.Create(
 		context.Background(), workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "Title",
 			workitem.SystemDescription: rendering.NewMarkupContentFromLegacy("Description"),
 			workitem.SystemState:       workitem.SystemStateNew,
@@ -161,6 +259,7 @@ func (s *workItemRepoBlackBoxTest) TestCreateWorkItemWithDescriptionMarkup() {
 	wi, err := s.repo.Create(
 		context.Background(), workitem.SystemBug,
 		map[string]interface{}{
+			workitem.SystemSpace:       space.SystemSpace.String(),
 			workitem.SystemTitle:       "Title",
 			workitem.SystemDescription: rendering.NewMarkupContent("Description", rendering.SystemMarkupMarkdown),
 			workitem.SystemState:       workitem.SystemStateNew,
@@ -182,6 +281,7 @@ func (s *workItemRepoBlackBoxTest) TestTypeChangeIsNotProhibitedOnDBLayer() {
 	wi, err := s.repo.Create(
 		context.Background(), "bug",
 		map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
 		}, "xx")
@@ -190,7 +290,7 @@ func (s *workItemRepoBlackBoxTest) TestTypeChangeIsNotProhibitedOnDBLayer() {
 
 	wi.Type = "feature"
 
-	newWi, err := s.repo.Save(context.Background(), *wi)
+	newWi, err := s.repo.Save(context.Background(), *wi, "xx")
 	require.Nil(s.T(), err)
 	require.Equal(s.T(), "feature", newWi.Type)
 }
@@ -249,3 +349,231 @@ func (s *workItemRepoBlackBoxTest) TestGetCountsPerIteration() {
 	assert.Equal(s.T(), 5, countsMap[iteration1.ID.String()].Total)
 	assert.Equal(s.T(), 2, countsMap[iteration1.ID.String()].Closed)
 }
+
+func (s *workItemRepoBlackBoxTest) TestListWithComparisonFilters() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	spaceInstance := space.Space{Name: "Comparison filters space"}
+	_, err := space.NewRepository(s.DB).Create(context.Background(), &spaceInstance)
+	require.Nil(s.T(), err)
+	iterationInstance := iteration.Iteration{Name: "Sprint", SpaceID: spaceInstance.ID}
+	require.Nil(s.T(), iteration.NewIterationRepository(s.DB).Create(context.Background(), &iterationInstance))
+	iterationID := iterationInstance.ID.String()
+	for i := 0; i < 3; i++ {
+		fields := map[string]interface{}{
+			workitem.SystemTitle: fmt.Sprintf("Login issue #%d", i),
+			workitem.SystemState: workitem.SystemStateNew,
+		}
+		if i == 0 {
+			fields[workitem.SystemIteration] = iterationID
+		}
+		_, err := s.repo.Create(context.Background(), workitem.SystemBug, fields, "xx")
+		require.Nil(s.T(), err)
+	}
+	_, err = s.repo.Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemSpace: space.SystemSpace.String(),
+		workitem.SystemTitle: "Unrelated",
+		workitem.SystemState: workitem.SystemStateClosed,
+	}, "xx")
+	require.Nil(s.T(), err)
+
+	_, count, err := s.repo.List(context.Background(), criteria.Substring(criteria.Field(workitem.SystemTitle), criteria.Literal("LOGIN")), nil, nil, nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), uint64(3), count)
+
+	_, count, err = s.repo.List(context.Background(), criteria.And(
+		criteria.Substring(criteria.Field(workitem.SystemTitle), criteria.Literal("login")),
+		criteria.IsNull(criteria.Field(workitem.SystemIteration))), nil, nil, nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), uint64(2), count)
+
+	_, count, err = s.repo.List(context.Background(), criteria.And(
+		criteria.NotEquals(criteria.Field(workitem.SystemState), criteria.Literal(workitem.SystemStateClosed)),
+		criteria.LessThan(criteria.Field(workitem.SystemCreatedAt), criteria.Literal(time.Now().Add(time.Hour)))), nil, nil, nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), uint64(3), count)
+}
+
+func (s *workItemRepoBlackBoxTest) TestSpace() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	spaceRepo := space.NewRepository(s.DB)
+	space1 := space.Space{Name: "Work item space 1"}
+	_, err := spaceRepo.Create(context.Background(), &space1)
+	require.Nil(s.T(), err)
+	space2 := space.Space{Name: "Work item space 2"}
+	_, err = spaceRepo.Create(context.Background(), &space2)
+	require.Nil(s.T(), err)
+	iteration1 := iteration.Iteration{Name: "Sprint 1", SpaceID: space1.ID}
+	require.Nil(s.T(), iteration.NewIterationRepository(s.DB).Create(context.Background(), &iteration1))
+	area2 := area.Area{Name: "Area 2", SpaceID: space2.ID}
+	require.Nil(s.T(), area.NewAreaRepository(s.DB).Create(context.Background(), &area2))
+
+	// the space is taken from the iteration if not given
+	wi, err := s.repo.Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:     "In space 1",
+		workitem.SystemState:     workitem.SystemStateNew,
+		workitem.SystemIteration: iteration1.ID.String(),
+	}, "xx")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), space1.ID.String(), wi.Fields[workitem.SystemSpace])
+
+	_, err = s.repo.Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "In space 2",
+		workitem.SystemState: workitem.SystemStateNew,
+		workitem.SystemSpace: space2.ID.String(),
+	}, "xx")
+	require.Nil(s.T(), err)
+
+	// iteration and area must belong to the same space
+	_, err = s.repo.Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:     "Mismatch",
+		workitem.SystemState:     workitem.SystemStateNew,
+		workitem.SystemIteration: iteration1.ID.String(),
+		workitem.SystemArea:      area2.ID.String(),
+	}, "xx")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	// the space must exist
+	_, err = s.repo.Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "Unknown space",
+		workitem.SystemState: workitem.SystemStateNew,
+		workitem.SystemSpace: uuid.NewV4().String(),
+	}, "xx")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	// every work item belongs to a space
+	_, err = s.repo.Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "No space",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, "xx")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	result, count, err := s.repo.List(context.Background(), criteria.Equals(criteria.Field(workitem.SystemSpace), criteria.Literal(space1.ID.String())), nil, nil, nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), uint64(1), count)
+	assert.Equal(s.T(), wi.ID, result[0].ID)
+
+	// moving the work item to another space is rejected while the iteration stays in the old one
+	wi.Fields[workitem.SystemSpace] = space2.ID.String()
+	_, err = s.repo.Save(context.Background(), *wi, "xx")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	delete(wi.Fields, workitem.SystemIteration)
+	wi, err = s.repo.Save(context.Background(), *wi, "xx")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), space2.ID.String(), wi.Fields[workitem.SystemSpace])
+	wi.Fields[workitem.SystemSpace] = nil
+	_, err = s.repo.Save(context.Background(), *wi, "xx")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestSpaceWorkItemType() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	spaceInstance := space.Space{Name: "Space with spikes"}
+	_, err := space.NewRepository(s.DB).Create(context.Background(), &spaceInstance)
+	require.Nil(s.T(), err)
+	plannerItem := workitem.SystemPlannerItem
+	_, err = workitem.NewWorkItemTypeRepository(s.DB).CreateInSpace(context.Background(), spaceInstance.ID, &plannerItem, "spike", map[string]app.FieldDefinition{
+		"timebox": {
+			Type: &app.FieldType{Kind: string(workitem.KindString)},
+		},
+	})
+	require.Nil(s.T(), err)
+
+	wi, err := s.repo.Create(context.Background(), "spike", map[string]interface{}{
+		workitem.SystemTitle: "Try it",
+		workitem.SystemState: workitem.SystemStateNew,
+		workitem.SystemSpace: spaceInstance.ID.String(),
+		"timebox":            "3h",
+	}, "xx")
+	require.Nil(s.T(), err)
+	loaded, err := s.repo.Load(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "spike", loaded.Type)
+	assert.NotNil(s.T(), loaded.Fields["timebox"])
+
+	// the type is not available outside of the space
+	_, err = s.repo.Create(context.Background(), "spike", map[string]interface{}{
+		workitem.SystemSpace: space.SystemSpace.String(),
+		workitem.SystemTitle: "Try it elsewhere",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, "xx")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestListSorted() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	for _, title := range []string{"Sort B", "Sort C", "Sort A"} {
+		_, err := s.repo.Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
+			workitem.SystemTitle: title,
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+		require.Nil(s.T(), err)
+	}
+	filter := criteria.Substring(criteria.Field(workitem.SystemTitle), criteria.Literal("Sort "))
+
+	result, _, err := s.repo.List(context.Background(), filter, []workitem.SortKey{{Field: workitem.SystemTitle}}, nil, nil)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 3)
+	assert.Equal(s.T(), "Sort A", result[0].Fields[workitem.SystemTitle])
+	assert.Equal(s.T(), "Sort C", result[2].Fields[workitem.SystemTitle])
+
+	result, _, err = s.repo.List(context.Background(), filter, []workitem.SortKey{{Field: workitem.SystemCreatedAt, Descending: true}}, nil, nil)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 3)
+	assert.Equal(s.T(), "Sort A", result[0].Fields[workitem.SystemTitle])
+
+	_, _, err = s.repo.List(context.Background(), filter, []workitem.SortKey{{Field: "no.such.field"}}, nil, nil)
+	assert.NotNil(s.T(), err)
+}
+
+func (s *workItemRepoBlackBoxTest) TestListPage() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	for _, title := range []string{"Page D", "Page B", "Page E", "Page A", "Page C"} {
+		_, err := s.repo.Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+			workitem.SystemSpace: space.SystemSpace.String(),
+			workitem.SystemTitle: title,
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+		require.Nil(s.T(), err)
+	}
+	filter := criteria.Substring(criteria.Field(workitem.SystemTitle), criteria.Literal("Page "))
+	sort := []workitem.SortKey{{Field: workitem.SystemTitle}}
+	titles := func(items []*app.WorkItem) []interface{} {
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = item.Fields[workitem.SystemTitle]
+		}
+		return result
+	}
+
+	result, more, count, err := s.repo.ListPage(context.Background(), filter, sort, gormsupport.Page{Limit: 2})
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []interface{}{"Page A", "Page B"}, titles(result))
+	assert.True(s.T(), more)
+	assert.Equal(s.T(), uint64(5), count)
+
+	// a new item sorted before the cursor does not shift the next page
+	_, err = s.repo.Create(context.Background(), workitem.SystemBug, map[string]interface{}{
+		workitem.SystemSpace: space.SystemSpace.String(),
+		workitem.SystemTitle: "Page 0",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, "xx")
+	require.Nil(s.T(), err)
+	result, more, _, err = s.repo.ListPage(context.Background(), filter, sort, gormsupport.Page{ID: result[1].ID, Limit: 2})
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []interface{}{"Page C", "Page D"}, titles(result))
+	assert.True(s.T(), more)
+
+	result, more, _, err = s.repo.ListPage(context.Background(), filter, sort, gormsupport.Page{ID: result[1].ID, Limit: 2})
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []interface{}{"Page E"}, titles(result))
+	assert.False(s.T(), more)
+
+	result, more, _, err = s.repo.ListPage(context.Background(), filter, sort, gormsupport.Page{ID: result[0].ID, Before: true, Limit: 2})
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []interface{}{"Page C", "Page D"}, titles(result))
+	assert.True(s.T(), more)
+
+	_, _, _, err = s.repo.ListPage(context.Background(), filter, sort, gormsupport.Page{ID: "foo", Limit: 2})
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
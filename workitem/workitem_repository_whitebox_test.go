@@ -0,0 +1,24 @@
+package workitem
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestrictedSpaces(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	spaceID := uuid.NewV4()
+	inSpace := criteria.Equals(criteria.Field(SystemSpace), criteria.Literal(spaceID.String()))
+	titled := criteria.Equals(criteria.Field(SystemTitle), criteria.Literal("title"))
+
+	assert.Equal(t, []uuid.UUID{spaceID}, restrictedSpaces(inSpace))
+	assert.Equal(t, []uuid.UUID{spaceID}, restrictedSpaces(criteria.And(titled, inSpace)))
+	assert.Empty(t, restrictedSpaces(criteria.Or(titled, inSpace)))
+	assert.Empty(t, restrictedSpaces(titled))
+	assert.Empty(t, restrictedSpaces(criteria.Equals(criteria.Field(SystemSpace), criteria.Literal("not a space"))))
+}
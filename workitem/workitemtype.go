@@ -8,6 +8,7 @@ import (
 	"github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 // String constants for the local work item types.
@@ -20,12 +21,14 @@ const (
 	SystemDescription         = "system.description"
 	SystemDescriptionMarkup   = "system.description.markup"
 	SystemDescriptionRendered = "system.description.rendered"
+	SystemDescriptionProgress = "system.description.progress"
 	SystemState               = "system.state"
 	SystemAssignees           = "system.assignees"
 	SystemCreator             = "system.creator"
 	SystemCreatedAt           = "system.created_at"
 	SystemIteration           = "system.iteration"
 	SystemArea                = "system.area"
+	SystemSpace               = "system.space"
 
 	// base item type with common fields for planner item types like userstory, experience, bug, feature, etc.
 	SystemPlannerItem = "planneritem"
@@ -48,14 +51,18 @@ const (
 // WorkItemType represents a work item type as it is stored in the db
 type WorkItemType struct {
 	gormsupport.Lifecycle
-	// the unique name of this work item type.
+	// the name of this work item type, unique within its space.
 	Name string `gorm:"primary_key"`
+	// the space owning this work item type, uuid.Nil for the global types available in every space
+	SpaceID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
 	// Version for optimistic concurrency control
 	Version int
 	// the id's of the parents, separated with some separator
 	Path string
 	// definitions of the fields this work item type supports
 	Fields FieldDefinitions `sql:"type:jsonb"`
+	// the allowed states and transitions of work items of this type, nil if any state can follow any other
+	Workflow *Workflow `sql:"type:jsonb"`
 }
 
 // GetTypePathSeparator returns the work item type's path separator "."
@@ -87,9 +94,15 @@ func (wit WorkItemType) Equal(u convert.Equaler) bool {
 	if wit.Name != other.Name {
 		return false
 	}
+	if !uuid.Equal(wit.SpaceID, other.SpaceID) {
+		return false
+	}
 	if wit.Path != other.Path {
 		return false
 	}
+	if !wit.Workflow.Equal(other.Workflow) {
+		return false
+	}
 	if len(wit.Fields) != len(other.Fields) {
 		return false
 	}
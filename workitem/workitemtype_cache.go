@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/almighty/almighty-core/log"
+	uuid "github.com/satori/go.uuid"
 )
 
 // WorkItemTypeCache represents WorkItemType cache
@@ -19,12 +20,18 @@ func NewWorkItemTypeCache() *WorkItemTypeCache {
 	return &witCache
 }
 
-// Get returns WorkItemType by name.
+// Get returns the global WorkItemType by name.
 // The second value (ok) is a bool that is true if the WorkItemType exists in the cache, and false if not.
 func (c *WorkItemTypeCache) Get(typeName string) (WorkItemType, bool) {
+	return c.GetInSpace(uuid.Nil, typeName)
+}
+
+// GetInSpace returns the WorkItemType owned by the given space by name.
+// The second value (ok) is a bool that is true if the WorkItemType exists in the cache, and false if not.
+func (c *WorkItemTypeCache) GetInSpace(spaceID uuid.UUID, typeName string) (WorkItemType, bool) {
 	c.mapLock.RLock()
 	defer c.mapLock.RUnlock()
-	w, ok := c.cache[typeName]
+	w, ok := c.cache[cacheKey(spaceID, typeName)]
 	return w, ok
 }
 
@@ -32,7 +39,22 @@ func (c *WorkItemTypeCache) Get(typeName string) (WorkItemType, bool) {
 func (c *WorkItemTypeCache) Put(wit WorkItemType) {
 	c.mapLock.Lock()
 	defer c.mapLock.Unlock()
-	c.cache[wit.Name] = wit
+	c.cache[cacheKey(wit.SpaceID, wit.Name)] = wit
+}
+
+// Delete removes the WorkItemType with the given name owned by the given space from the cache
+func (c *WorkItemTypeCache) Delete(spaceID uuid.UUID, typeName string) {
+	c.mapLock.Lock()
+	defer c.mapLock.Unlock()
+	delete(c.cache, cacheKey(spaceID, typeName))
+}
+
+// cacheKey returns the key of the work item type with the given name owned by the given space
+func cacheKey(spaceID uuid.UUID, typeName string) string {
+	if uuid.Equal(spaceID, uuid.Nil) {
+		return typeName
+	}
+	return spaceID.String() + "/" + typeName
 }
 
 // Clear clears the cache
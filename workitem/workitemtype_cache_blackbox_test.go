@@ -6,6 +6,7 @@ import (
 
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -34,6 +35,23 @@ func TestGetReturnsPreviouslyPutWIT(t *testing.T) {
 	assert.Equal(t, wit, cachedWit)
 }
 
+func TestGetInSpaceReturnsTypeOfThatSpace(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	spaceID := uuid.NewV4()
+	wit := workitem.WorkItemType{Name: "testInSpace", SpaceID: spaceID}
+	cache.Put(wit)
+
+	cachedWit, ok := cache.GetInSpace(spaceID, "testInSpace")
+	assert.True(t, ok)
+	assert.Equal(t, wit, cachedWit)
+	_, ok = cache.Get("testInSpace")
+	assert.False(t, ok)
+	_, ok = cache.GetInSpace(uuid.NewV4(), "testInSpace")
+	assert.False(t, ok)
+}
+
 func TestGetReturnNotOkAfterClear(t *testing.T) {
 	t.Parallel()
 	resource.Require(t, resource.UnitTest)
@@ -48,6 +66,21 @@ func TestGetReturnNotOkAfterClear(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestGetReturnNotOkAfterDelete(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	spaceID := uuid.NewV4()
+	cache.Put(workitem.WorkItemType{Name: "testDelete", SpaceID: spaceID})
+	cache.Put(workitem.WorkItemType{Name: "testDelete"})
+
+	cache.Delete(spaceID, "testDelete")
+	_, ok := cache.GetInSpace(spaceID, "testDelete")
+	assert.False(t, ok)
+	_, ok = cache.Get("testDelete")
+	assert.True(t, ok)
+}
+
 func TestNoFailuresWithConcurrentMapReadAndMapWrite(t *testing.T) {
 	t.Parallel()
 	resource.Require(t, resource.UnitTest)
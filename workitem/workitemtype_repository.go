@@ -12,6 +12,7 @@ import (
 
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 var cache = NewWorkItemTypeCache()
@@ -19,8 +20,12 @@ var cache = NewWorkItemTypeCache()
 // WorkItemTypeRepository encapsulates storage & retrieval of work item types
 type WorkItemTypeRepository interface {
 	Load(ctx context.Context, name string) (*app.WorkItemType, error)
+	LoadInSpace(ctx context.Context, spaceID uuid.UUID, name string) (*app.WorkItemType, error)
 	Create(ctx context.Context, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error)
+	CreateInSpace(ctx context.Context, spaceID uuid.UUID, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error)
 	List(ctx context.Context, start *int, length *int) ([]*app.WorkItemType, error)
+	ListInSpace(ctx context.Context, spaceID uuid.UUID, start *int, length *int) ([]*app.WorkItemType, error)
+	Update(ctx context.Context, name string, version int, fields map[string]app.FieldDefinition, valueMapping map[string]map[string]interface{}, workflow *app.Workflow) (*app.WorkItemType, error)
 }
 
 // NewWorkItemRepository creates a wi repository based on gorm
@@ -38,7 +43,7 @@ type GormWorkItemTypeRepository struct {
 	db *gorm.DB
 }
 
-// Load returns the work item for the given id
+// Load returns the global work item type with the given name
 // returns NotFoundError, InternalError
 func (r *GormWorkItemTypeRepository) Load(ctx context.Context, name string) (*app.WorkItemType, error) {
 	res, err := r.LoadTypeFromDB(ctx, name)
@@ -50,21 +55,56 @@ func (r *GormWorkItemTypeRepository) Load(ctx context.Context, name string) (*ap
 	return &result, nil
 }
 
-// LoadTypeFromDB return work item type for the given id
+// LoadInSpace returns the work item type with the given name as seen from the given space, that is
+// the type owned by the space or, if there is none, the global type
+// returns NotFoundError, InternalError
+func (r *GormWorkItemTypeRepository) LoadInSpace(ctx context.Context, spaceID uuid.UUID, name string) (*app.WorkItemType, error) {
+	res, err := r.LoadTypeInSpaceFromDB(ctx, &spaceID, name)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+
+	result := convertTypeFromModels(res)
+	return &result, nil
+}
+
+// LoadTypeFromDB return the global work item type for the given name
 func (r *GormWorkItemTypeRepository) LoadTypeFromDB(ctx context.Context, name string) (*WorkItemType, error) {
+	return r.loadType(ctx, uuid.Nil, name)
+}
+
+// LoadTypeInSpaceFromDB return the work item type for the given name as seen from the given space. Types
+// owned by the space take precedence over global ones. If spaceID is nil, only global types are considered.
+func (r *GormWorkItemTypeRepository) LoadTypeInSpaceFromDB(ctx context.Context, spaceID *uuid.UUID, name string) (*WorkItemType, error) {
+	if spaceID != nil && !uuid.Equal(*spaceID, uuid.Nil) {
+		res, err := r.loadType(ctx, *spaceID, name)
+		if err == nil {
+			return res, nil
+		}
+		if _, ok := errs.Cause(err).(errors.NotFoundError); !ok {
+			return nil, errs.WithStack(err)
+		}
+	}
+	return r.loadType(ctx, uuid.Nil, name)
+}
+
+// loadType returns the work item type with the given name owned by the given space
+func (r *GormWorkItemTypeRepository) loadType(ctx context.Context, spaceID uuid.UUID, name string) (*WorkItemType, error) {
 	log.Logger().Infoln("Loading work item type", name)
-	res, ok := cache.Get(name)
+	res, ok := cache.GetInSpace(spaceID, name)
 	if !ok {
 		log.Info(ctx, map[string]interface{}{
-			"pkg":  "workitem",
-			"type": name,
+			"pkg":     "workitem",
+			"type":    name,
+			"spaceID": spaceID,
 		}, "Work item type doesn't exist in the cache. Loading from DB...")
 		res = WorkItemType{}
 
-		db := r.db.Model(&res).Where("name=?", name).First(&res)
+		db := r.db.Model(&res).Where("name = ? and space_id = ?", name, spaceID).First(&res)
 		if db.RecordNotFound() {
 			log.Error(ctx, map[string]interface{}{
 				"witName": name,
+				"spaceID": spaceID,
 			}, "work item type repository not found")
 			return nil, errors.NewNotFoundError("work item type", name)
 		}
@@ -82,43 +122,55 @@ func ClearGlobalWorkItemTypeCache() {
 	cache.Clear()
 }
 
-// Create creates a new work item in the repository
+// Create creates a new global work item type in the repository
 // returns BadParameterError, ConversionError or InternalError
 func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeName *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
-	existing, _ := r.LoadTypeFromDB(ctx, name)
+	return r.create(ctx, uuid.Nil, extendedTypeName, name, fields)
+}
+
+// CreateInSpace creates a new work item type owned by the given space. The type may extend a global
+// type (e.g. planneritem) or another type of the same space, but must not have the name of a global type.
+// returns BadParameterError, ConversionError or InternalError
+func (r *GormWorkItemTypeRepository) CreateInSpace(ctx context.Context, spaceID uuid.UUID, extendedTypeName *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
+	if uuid.Equal(spaceID, uuid.Nil) {
+		return nil, errors.NewBadParameterError("spaceID", spaceID)
+	}
+	return r.create(ctx, spaceID, extendedTypeName, name, fields)
+}
+
+func (r *GormWorkItemTypeRepository) create(ctx context.Context, spaceID uuid.UUID, extendedTypeName *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
+	existing, _ := r.LoadTypeInSpaceFromDB(ctx, &spaceID, name)
 	if existing != nil {
-		log.Error(ctx, map[string]interface{}{"witName": name}, "unable to create new work item type")
+		log.Error(ctx, map[string]interface{}{"witName": name, "spaceID": spaceID}, "unable to create new work item type")
 		return nil, errors.NewBadParameterError("name", name)
 	}
 	allFields := map[string]FieldDefinition{}
 	path := name
+	var workflow *Workflow
 	if extendedTypeName != nil {
-		extendedType := WorkItemType{}
-		db := r.db.First(&extendedType, "name = ?", extendedTypeName)
-		if db.RecordNotFound() {
-			return nil, errors.NewBadParameterError("extendedTypeName", *extendedTypeName)
-		}
-		if err := db.Error; err != nil {
-			return nil, errors.NewInternalError(err.Error())
+		extendedType, err := r.LoadTypeInSpaceFromDB(ctx, &spaceID, *extendedTypeName)
+		if err != nil {
+			if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+				return nil, errors.NewBadParameterError("extendedTypeName", *extendedTypeName)
+			}
+			return nil, errs.WithStack(err)
 		}
 		// copy fields from extended type
 		for key, value := range extendedType.Fields {
 			allFields[key] = value
 		}
 		path = extendedType.Path + pathSep + name
+		// the workflow is inherited, too
+		workflow = extendedType.Workflow
 	}
 
 	// now process new fields, checking whether they are ok to add.
 	for field, definition := range fields {
 		existing, exists := allFields[field]
-		ct, err := convertFieldTypeToModels(*definition.Type)
+		converted, err := convertFieldDefinitionToModels(definition)
 		if err != nil {
 			return nil, errs.WithStack(err)
 		}
-		converted := FieldDefinition{
-			Required: definition.Required,
-			Type:     ct,
-		}
 		if exists && !compatibleFields(existing, converted) {
 			return nil, fmt.Errorf("incompatible change for field %s", field)
 		}
@@ -126,13 +178,15 @@ func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeNam
 	}
 
 	created := WorkItemType{
-		Version: 0,
-		Name:    name,
-		Path:    path,
-		Fields:  allFields,
+		Version:  0,
+		Name:     name,
+		SpaceID:  spaceID,
+		Path:     path,
+		Fields:   allFields,
+		Workflow: workflow,
 	}
 
-	if err := r.db.Save(&created).Error; err != nil {
+	if err := r.db.Create(&created).Error; err != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
 
@@ -140,15 +194,22 @@ func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeNam
 	return &result, nil
 }
 
-// List returns work item types selected by the given criteria.Expression, starting with start (zero-based) and returning at most "limit" item types
+// List returns the global work item types, starting with start (zero-based) and returning at most "limit" item types
 func (r *GormWorkItemTypeRepository) List(ctx context.Context, start *int, limit *int) ([]*app.WorkItemType, error) {
-	// Currently we don't implement filtering here, so leave this empty
-	// TODO: (kwk) implement criteria parsing just like for work items
-	var where string
-	var parameters []interface{}
+	return r.list(ctx, r.db.Where("space_id = ?", uuid.Nil), start, limit)
+}
+
+// ListInSpace returns the work item types available in the given space, i.e. the global ones and the ones
+// owned by the space, starting with start (zero-based) and returning at most "limit" item types
+func (r *GormWorkItemTypeRepository) ListInSpace(ctx context.Context, spaceID uuid.UUID, start *int, limit *int) ([]*app.WorkItemType, error) {
+	return r.list(ctx, r.db.Where("space_id in (?, ?)", uuid.Nil, spaceID), start, limit)
+}
 
+func (r *GormWorkItemTypeRepository) list(ctx context.Context, db *gorm.DB, start *int, limit *int) ([]*app.WorkItemType, error) {
+	// Currently we don't implement filtering here
+	// TODO: (kwk) implement criteria parsing just like for work items
 	var rows []WorkItemType
-	db := r.db.Where(where, parameters...)
+	db = db.Order("space_id, name")
 	if start != nil {
 		db = db.Offset(*start)
 	}
@@ -168,6 +229,209 @@ func (r *GormWorkItemTypeRepository) List(ctx context.Context, start *int, limit
 	return result, nil
 }
 
+// Update changes the fields and the workflow of the global work item type with the given name and of all types
+// extending it. New fields must be optional, existing fields can be deprecated and the values of enum fields can
+// be changed. When enum values are removed, the values of existing work items are converted in the background:
+// valueMapping maps the field name to the replacements of its removed values, removed values without replacement
+// are cleared. A nil workflow leaves the workflow unchanged.
+// The version must match the stored one and is incremented, as are the versions of all extending types.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (r *GormWorkItemTypeRepository) Update(ctx context.Context, name string, version int, fields map[string]app.FieldDefinition, valueMapping map[string]map[string]interface{}, workflow *app.Workflow) (*app.WorkItemType, error) {
+	wit := WorkItemType{}
+	db := r.db.Where("name = ? and space_id = ?", name, uuid.Nil).First(&wit)
+	if db.RecordNotFound() {
+		return nil, errors.NewNotFoundError("work item type", name)
+	}
+	if err := db.Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if wit.Version != version {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+
+	changed := FieldDefinitions{}
+	migrations := map[string]*FieldMigration{}
+	for field, definition := range fields {
+		converted, err := convertFieldDefinitionToModels(definition)
+		if err != nil {
+			return nil, errors.NewBadParameterError("fields."+field, err.Error())
+		}
+		existing, exists := wit.Fields[field]
+		if !exists {
+			if converted.Required {
+				return nil, errors.NewBadParameterError("fields."+field+".required", true).Expected("new fields to be optional")
+			}
+			changed[field] = converted
+			continue
+		}
+		if converted.Equal(existing) {
+			continue
+		}
+		removed, err := evolveField(existing, converted)
+		if err != nil {
+			return nil, errors.NewBadParameterError("fields."+field, err.Error())
+		}
+		if len(removed) > 0 {
+			mapping := Fields{}
+			for _, value := range removed {
+				key := fmt.Sprint(value)
+				replacement := valueMapping[field][key]
+				if replacement == nil {
+					if converted.Required {
+						return nil, errors.NewBadParameterError("valueMapping."+field+"."+key, nil).Expected("replacement of removed value of required field")
+					}
+					mapping[key] = nil
+					continue
+				}
+				if mapping[key], err = converted.ConvertToModel(field, replacement); err != nil {
+					return nil, errors.NewBadParameterError("valueMapping."+field+"."+key, replacement)
+				}
+			}
+			migrations[field] = &FieldMigration{
+				TypePath:     wit.Path,
+				TypeVersion:  wit.Version + 1,
+				FieldName:    field,
+				ValueMapping: mapping,
+				Types:        TypeRefs{},
+			}
+		}
+		changed[field] = converted
+	}
+	newWorkflow := wit.Workflow
+	if workflow != nil {
+		newWorkflow = convertWorkflowToModels(workflow)
+	}
+	if newWorkflow != nil {
+		newFields := FieldDefinitions{}
+		for field, definition := range wit.Fields {
+			newFields[field] = definition
+		}
+		for field, definition := range changed {
+			newFields[field] = definition
+		}
+		if err := newWorkflow.validate(newFields); err != nil {
+			return nil, errors.NewBadParameterError("workflow", err.Error())
+		}
+	}
+
+	// apply the changes to the type and all types that copied the fields or the workflow from it
+	var family []WorkItemType
+	if err := r.db.Where("path <@ ?", wit.Path).Find(&family).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	var result *WorkItemType
+	for i := range family {
+		t := &family[i]
+		for field, definition := range changed {
+			if existing, ok := t.Fields[field]; ok && !existing.Equal(wit.Fields[field]) {
+				// redefined by the extending type, leave it alone
+				continue
+			}
+			t.Fields[field] = definition
+			if migration, ok := migrations[field]; ok {
+				// only the work items of types that received the change need to be converted
+				migration.Types = append(migration.Types, TypeRef{SpaceID: t.SpaceID, Name: t.Name})
+			}
+		}
+		if t.Workflow.Equal(wit.Workflow) {
+			t.Workflow = newWorkflow
+		}
+		db := r.db.Model(&WorkItemType{}).Where("name = ? and space_id = ? and version = ?", t.Name, t.SpaceID, t.Version).Updates(map[string]interface{}{
+			"fields":   t.Fields,
+			"workflow": t.Workflow,
+			"version":  t.Version + 1,
+		})
+		if err := db.Error; err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		if db.RowsAffected == 0 {
+			return nil, errors.NewVersionConflictError("version conflict")
+		}
+		t.Version++
+		// the transaction isn't committed yet, the next load fills the cache again
+		cache.Delete(t.SpaceID, t.Name)
+		if t.Name == wit.Name && uuid.Equal(t.SpaceID, wit.SpaceID) {
+			result = t
+		}
+	}
+	for _, migration := range migrations {
+		migration.ID = uuid.NewV4()
+		if err := r.db.Create(migration).Error; err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+	}
+	log.Info(ctx, map[string]interface{}{
+		"pkg":        "workitem",
+		"witName":    name,
+		"version":    result.Version,
+		"migrations": len(migrations),
+	}, "Updated work item type")
+	converted := convertTypeFromModels(result)
+	return &converted, nil
+}
+
+// evolveField checks that a field definition can be changed to the given one without breaking existing
+// work items and returns the enum values that are no longer allowed
+func evolveField(existing FieldDefinition, changed FieldDefinition) ([]interface{}, error) {
+	if existing.Required != changed.Required {
+		return nil, fmt.Errorf("a field can't be made required or optional")
+	}
+	if existing.Type.GetKind() != changed.Type.GetKind() {
+		return nil, fmt.Errorf("the kind of a field can't be changed")
+	}
+	existingEnum, ok := existing.Type.(EnumType)
+	if !ok {
+		if !existing.Type.Equal(changed.Type) {
+			return nil, fmt.Errorf("only deprecation and enum values of a field can be changed")
+		}
+		return nil, nil
+	}
+	changedEnum := changed.Type.(EnumType)
+	if !existingEnum.BaseType.Equal(changedEnum.BaseType) {
+		return nil, fmt.Errorf("the base type of an enum can't be changed")
+	}
+	var removed []interface{}
+	for _, value := range existingEnum.Values {
+		if !contains(changedEnum.Values, value) {
+			removed = append(removed, value)
+		}
+	}
+	return removed, nil
+}
+
+// LoadFieldKinds returns the kinds of the fields defined by the work item types of the given spaces and by the
+// system types, or by any work item type if no space is given.
+// Fields that are defined with different kinds by different types have an empty kind.
+func (r *GormWorkItemTypeRepository) LoadFieldKinds(ctx context.Context, spaceIDs ...uuid.UUID) (map[string]Kind, error) {
+	db := r.db
+	if len(spaceIDs) > 0 {
+		ids := []string{uuid.Nil.String()}
+		for _, id := range spaceIDs {
+			ids = append(ids, id.String())
+		}
+		db = db.Where("space_id in (?)", ids)
+	}
+	var rows []WorkItemType
+	if err := db.Select("name, fields").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	kinds := map[string]Kind{}
+	ambiguous := map[string]bool{}
+	for _, wit := range rows {
+		for name, def := range wit.Fields {
+			kind := def.Type.GetKind()
+			if existing, ok := kinds[name]; ok && existing != kind {
+				ambiguous[name] = true
+			}
+			kinds[name] = kind
+		}
+	}
+	for name := range ambiguous {
+		kinds[name] = ""
+	}
+	return kinds, nil
+}
+
 func compatibleFields(existing FieldDefinition, new FieldDefinition) bool {
 	return reflect.DeepEqual(existing, new)
 }
@@ -175,9 +439,14 @@ func compatibleFields(existing FieldDefinition, new FieldDefinition) bool {
 // converts from models to app representation
 func convertTypeFromModels(t *WorkItemType) app.WorkItemType {
 	var converted = app.WorkItemType{
-		Name:    t.Name,
-		Version: t.Version,
-		Fields:  map[string]*app.FieldDefinition{},
+		Name:     t.Name,
+		Version:  t.Version,
+		Fields:   map[string]*app.FieldDefinition{},
+		Workflow: convertWorkflowFromModels(t.Workflow),
+	}
+	if !uuid.Equal(t.SpaceID, uuid.Nil) {
+		spaceID := t.SpaceID
+		converted.Space = &spaceID
 	}
 	for name, def := range t.Fields {
 		ct := convertFieldTypeFromModels(def.Type)
@@ -185,6 +454,10 @@ func convertTypeFromModels(t *WorkItemType) app.WorkItemType {
 			Required: def.Required,
 			Type:     &ct,
 		}
+		if def.Deprecated {
+			deprecated := true
+			converted.Fields[name].Deprecated = &deprecated
+		}
 	}
 	return converted
 }
@@ -262,18 +535,27 @@ func convertFieldTypeToModels(t app.FieldType) (FieldType, error) {
 	}
 }
 
+// convertFieldDefinitionToModels converts a field definition from app to models representation
+func convertFieldDefinitionToModels(definition app.FieldDefinition) (FieldDefinition, error) {
+	ct, err := convertFieldTypeToModels(*definition.Type)
+	if err != nil {
+		return FieldDefinition{}, errs.WithStack(err)
+	}
+	return FieldDefinition{
+		Required:   definition.Required,
+		Type:       ct,
+		Deprecated: definition.Deprecated != nil && *definition.Deprecated,
+	}, nil
+}
+
 func TEMPConvertFieldTypesToModel(fields map[string]app.FieldDefinition) (map[string]FieldDefinition, error) {
 
 	allFields := map[string]FieldDefinition{}
 	for field, definition := range fields {
-		ct, err := convertFieldTypeToModels(*definition.Type)
+		converted, err := convertFieldDefinitionToModels(definition)
 		if err != nil {
 			return nil, errs.WithStack(err)
 		}
-		converted := FieldDefinition{
-			Required: definition.Required,
-			Type:     ct,
-		}
 		allFields[field] = converted
 	}
 	return allFields, nil
@@ -8,6 +8,8 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/space"
 	"github.com/almighty/almighty-core/workitem"
 	errs "github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -128,3 +130,201 @@ func (s *workItemTypeRepoBlackBoxTest) TestDoNotCreateWITWithMissingBaseType() {
 	assert.NotNil(s.T(), err)
 	assert.Nil(s.T(), extendedWit)
 }
+
+func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWITInSpace() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	spaceRepo := space.NewRepository(s.DB)
+	space1, err := spaceRepo.Create(context.Background(), &space.Space{Name: "WIT space 1"})
+	require.Nil(s.T(), err)
+	space2, err := spaceRepo.Create(context.Background(), &space.Space{Name: "WIT space 2"})
+	require.Nil(s.T(), err)
+	basetype := "foo_bar"
+	_, err = s.repo.Create(context.Background(), nil, basetype, map[string]app.FieldDefinition{
+		"foo": {
+			Required: true,
+			Type:     &app.FieldType{Kind: string(workitem.KindFloat)},
+		},
+	})
+	require.Nil(s.T(), err)
+
+	// the same name can be used in different spaces
+	spike, err := s.repo.CreateInSpace(context.Background(), space1.ID, &basetype, "spike", map[string]app.FieldDefinition{
+		"timebox": {
+			Type: &app.FieldType{Kind: string(workitem.KindDuration)},
+		},
+	})
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), space1.ID, *spike.Space)
+	assert.NotNil(s.T(), spike.Fields["foo"])
+	assert.NotNil(s.T(), spike.Fields["timebox"])
+	_, err = s.repo.CreateInSpace(context.Background(), space2.ID, nil, "spike", map[string]app.FieldDefinition{})
+	require.Nil(s.T(), err)
+
+	// names of global types and types of the same space can't be reused
+	_, err = s.repo.CreateInSpace(context.Background(), space1.ID, nil, "spike", map[string]app.FieldDefinition{})
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.CreateInSpace(context.Background(), space1.ID, nil, basetype, map[string]app.FieldDefinition{})
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	loaded, err := s.repo.LoadInSpace(context.Background(), space1.ID, "spike")
+	require.Nil(s.T(), err)
+	assert.NotNil(s.T(), loaded.Fields["timebox"])
+	loaded, err = s.repo.LoadInSpace(context.Background(), space1.ID, basetype)
+	require.Nil(s.T(), err)
+	assert.Nil(s.T(), loaded.Space)
+	_, err = s.repo.Load(context.Background(), "spike")
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+
+	names := func(wits []*app.WorkItemType) []string {
+		result := []string{}
+		for _, wit := range wits {
+			result = append(result, wit.Name)
+		}
+		return result
+	}
+	wits, err := s.repo.ListInSpace(context.Background(), space1.ID, nil, nil)
+	require.Nil(s.T(), err)
+	assert.Contains(s.T(), names(wits), "spike")
+	assert.Contains(s.T(), names(wits), basetype)
+	wits, err = s.repo.List(context.Background(), nil, nil)
+	require.Nil(s.T(), err)
+	assert.NotContains(s.T(), names(wits), "spike")
+}
+
+func (s *workItemTypeRepoBlackBoxTest) TestLoadFieldKindsInSpace() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	spaceRepo := space.NewRepository(s.DB)
+	space1, err := spaceRepo.Create(context.Background(), &space.Space{Name: "Field kinds space 1"})
+	require.Nil(s.T(), err)
+	space2, err := spaceRepo.Create(context.Background(), &space.Space{Name: "Field kinds space 2"})
+	require.Nil(s.T(), err)
+	_, err = s.repo.CreateInSpace(context.Background(), space1.ID, nil, "estimated", map[string]app.FieldDefinition{
+		"estimate": {Type: &app.FieldType{Kind: string(workitem.KindFloat)}},
+	})
+	require.Nil(s.T(), err)
+	_, err = s.repo.CreateInSpace(context.Background(), space2.ID, nil, "estimated", map[string]app.FieldDefinition{
+		"estimate": {Type: &app.FieldType{Kind: string(workitem.KindString)}},
+	})
+	require.Nil(s.T(), err)
+	repo := workitem.NewWorkItemTypeRepository(s.DB)
+
+	// the kinds of the other space don't interfere
+	kinds, err := repo.LoadFieldKinds(context.Background(), space1.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), workitem.KindFloat, kinds["estimate"])
+	assert.Equal(s.T(), workitem.KindString, kinds[workitem.SystemTitle])
+	kinds, err = repo.LoadFieldKinds(context.Background())
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), workitem.Kind(""), kinds["estimate"])
+}
+
+func (s *workItemTypeRepoBlackBoxTest) TestUpdateWIT() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	str := string(workitem.KindString)
+	colors := func(values ...interface{}) *app.FieldType {
+		return &app.FieldType{Kind: string(workitem.KindEnum), BaseType: &str, Values: values}
+	}
+	basetype := "foo_bar"
+	_, err := s.repo.Create(context.Background(), nil, basetype, map[string]app.FieldDefinition{
+		"color": {Type: colors("red", "green")},
+		"name":  {Type: &app.FieldType{Kind: str}},
+	})
+	require.Nil(s.T(), err)
+	_, err = s.repo.Create(context.Background(), &basetype, "foo_bar_sub", map[string]app.FieldDefinition{})
+	require.Nil(s.T(), err)
+	wi, err := workitem.NewWorkItemRepository(s.DB).Create(context.Background(), basetype, map[string]interface{}{
+		workitem.SystemSpace: space.SystemSpace.String(),
+		"color":              "green",
+	}, "xx")
+	require.Nil(s.T(), err)
+
+	// incompatible changes are rejected
+	_, err = s.repo.Update(context.Background(), basetype, 0, map[string]app.FieldDefinition{
+		"size": {Required: true, Type: &app.FieldType{Kind: str}},
+	}, nil, nil)
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Update(context.Background(), basetype, 0, map[string]app.FieldDefinition{
+		"name": {Type: &app.FieldType{Kind: string(workitem.KindInteger)}},
+	}, nil, nil)
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Update(context.Background(), basetype, 1, map[string]app.FieldDefinition{}, nil, nil)
+	assert.IsType(s.T(), errors.VersionConflictError{}, errs.Cause(err))
+	// a workflow requires a state field
+	_, err = s.repo.Update(context.Background(), basetype, 0, nil, nil, &app.Workflow{States: []string{workitem.SystemStateNew}})
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	deprecated := true
+	wit, err := s.repo.Update(context.Background(), basetype, 0, map[string]app.FieldDefinition{
+		"size":  {Type: &app.FieldType{Kind: str}},
+		"name":  {Type: &app.FieldType{Kind: str}, Deprecated: &deprecated},
+		"color": {Type: colors("red", "blue")},
+	}, map[string]map[string]interface{}{
+		"color": {"green": "blue"},
+	}, nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, wit.Version)
+	assert.NotNil(s.T(), wit.Fields["size"])
+	assert.True(s.T(), *wit.Fields["name"].Deprecated)
+	assert.Equal(s.T(), []interface{}{"red", "blue"}, wit.Fields["color"].Type.Values)
+
+	// the changes are applied to extending types, too
+	sub, err := s.repo.Load(context.Background(), "foo_bar_sub")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, sub.Version)
+	assert.NotNil(s.T(), sub.Fields["size"])
+
+	// existing work items are converted in the background
+	err = workitem.NewFieldMigrator(s.DB).Run(context.Background())
+	require.Nil(s.T(), err)
+	migrated, err := workitem.NewWorkItemRepository(s.DB).Load(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "blue", migrated.Fields["color"])
+	assert.Equal(s.T(), 1, migrated.Version)
+}
+
+func (s *workItemTypeRepoBlackBoxTest) TestUpdateWITRedefinedField() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	str := string(workitem.KindString)
+	colors := func(values ...interface{}) *app.FieldType {
+		return &app.FieldType{Kind: string(workitem.KindEnum), BaseType: &str, Values: values}
+	}
+	basetype := "foo_bar"
+	subtype := "foo_bar_sub"
+	_, err := s.repo.Create(context.Background(), nil, basetype, map[string]app.FieldDefinition{
+		"color": {Type: colors("red", "green")},
+	})
+	require.Nil(s.T(), err)
+	_, err = s.repo.Create(context.Background(), &basetype, subtype, map[string]app.FieldDefinition{})
+	require.Nil(s.T(), err)
+	// the extending type redefines the field with an additional value
+	_, err = s.repo.Update(context.Background(), subtype, 0, map[string]app.FieldDefinition{
+		"color": {Type: colors("red", "green", "yellow")},
+	}, nil, nil)
+	require.Nil(s.T(), err)
+	wiRepo := workitem.NewWorkItemRepository(s.DB)
+	wi, err := wiRepo.Create(context.Background(), basetype, map[string]interface{}{"color": "green", workitem.SystemSpace: space.SystemSpace.String()}, "xx")
+	require.Nil(s.T(), err)
+	subWI, err := wiRepo.Create(context.Background(), subtype, map[string]interface{}{"color": "green", workitem.SystemSpace: space.SystemSpace.String()}, "xx")
+	require.Nil(s.T(), err)
+
+	_, err = s.repo.Update(context.Background(), basetype, 0, map[string]app.FieldDefinition{
+		"color": {Type: colors("red", "blue")},
+	}, map[string]map[string]interface{}{
+		"color": {"green": "blue"},
+	}, nil)
+	require.Nil(s.T(), err)
+	sub, err := s.repo.Load(context.Background(), subtype)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []interface{}{"red", "green", "yellow"}, sub.Fields["color"].Type.Values)
+
+	// only the work items of the type that received the change are converted
+	err = workitem.NewFieldMigrator(s.DB).Run(context.Background())
+	require.Nil(s.T(), err)
+	migrated, err := wiRepo.Load(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "blue", migrated.Fields["color"])
+	unchanged, err := wiRepo.Load(context.Background(), subWI.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "green", unchanged.Fields["color"])
+	assert.Equal(s.T(), subWI.Version, unchanged.Version)
+}
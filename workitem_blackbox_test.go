@@ -3,6 +3,7 @@ package main_test
 import (
 	"bytes"
 	"crypto/rsa"
+	"encoding/base64"
 	"fmt"
 	"html"
 	"net/http"
@@ -20,6 +21,7 @@ import (
 	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/configuration"
 	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/iteration"
 	"github.com/almighty/almighty-core/jsonapi"
@@ -27,6 +29,7 @@ import (
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
 	testsupport "github.com/almighty/almighty-core/test"
 	almtoken "github.com/almighty/almighty-core/token"
 	"github.com/almighty/almighty-core/workitem"
@@ -78,7 +81,7 @@ func TestGetWorkItemWithLegacyDescription(t *testing.T) {
 	payload2.Data.ID = wi.Data.ID
 	payload2.Data.Attributes = wi.Data.Attributes
 
-	_, updated := test.UpdateWorkitemOK(t, nil, nil, controller, *wi.Data.ID, &payload2)
+	_, updated := test.UpdateWorkitemOK(t, svc.Context, svc, controller, *wi.Data.ID, &payload2)
 	assert.NotNil(t, updated.Data.Attributes[workitem.SystemCreatedAt])
 
 	assert.Equal(t, (result.Data.Attributes["version"].(int) + 1), updated.Data.Attributes["version"])
@@ -86,7 +89,7 @@ func TestGetWorkItemWithLegacyDescription(t *testing.T) {
 	assert.Equal(t, wi.Data.Attributes[workitem.SystemTitle], updated.Data.Attributes[workitem.SystemTitle])
 	assert.Equal(t, updatedDescription, updated.Data.Attributes[workitem.SystemDescription])
 
-	test.DeleteWorkitemOK(t, nil, nil, controller, *result.Data.ID)
+	test.DeleteWorkitemOK(t, svc.Context, svc, controller, *result.Data.ID)
 }
 
 func TestCreateWI(t *testing.T) {
@@ -141,7 +144,7 @@ func TestListByFields(t *testing.T) {
 	filter := "{\"system.title\":\"run integration test\"}"
 	offset := "0"
 	limit := 1
-	_, result := test.ListWorkitemOK(t, nil, nil, controller, &filter, nil, nil, nil, nil, &limit, &offset)
+	_, result := test.ListWorkitemOK(t, nil, nil, controller, &filter, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 
 	if result == nil {
 		t.Errorf("nil result")
@@ -152,7 +155,7 @@ func TestListByFields(t *testing.T) {
 	}
 
 	filter = fmt.Sprintf("{\"system.creator\":\"%s\"}", testsupport.TestIdentity.ID.String())
-	_, result = test.ListWorkitemOK(t, nil, nil, controller, &filter, nil, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, nil, nil, controller, &filter, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 
 	if result == nil {
 		t.Errorf("nil result")
@@ -162,7 +165,7 @@ func TestListByFields(t *testing.T) {
 		t.Errorf("unexpected length, should be %d but is %d ", 1, len(result.Data))
 	}
 
-	test.DeleteWorkitemOK(t, nil, nil, controller, *wi.Data.ID)
+	test.DeleteWorkitemOK(t, svc.Context, svc, controller, *wi.Data.ID)
 }
 
 func getWorkItemTestData(t *testing.T) []testSecureAPI {
@@ -308,7 +311,7 @@ func createPagingTest(t *testing.T, controller *WorkitemController, repo *testsu
 		count := computeCount(totalCount, int(start), int(limit))
 		repo.ListReturns(makeWorkItems(count), uint64(totalCount), nil)
 		offset := strconv.Itoa(start)
-		_, response := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &limit, &offset)
+		_, response := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 		assertLink(t, "first", first, response.Links.First)
 		assertLink(t, "last", last, response.Links.Last)
 		assertLink(t, "prev", prev, response.Links.Prev)
@@ -375,6 +378,18 @@ func TestPagingLinks(t *testing.T) {
 
 	pagingTest = createPagingTest(t, controller, repo, 0)
 	pagingTest(2, 5, "page[offset]=0&page[limit]=2", "page[offset]=0&page[limit]=2", "", "")
+
+	// the links keep the filter
+	repo.ListReturns(makeWorkItems(5), uint64(13), nil)
+	filter := `system.state = "open"`
+	limit := 5
+	offset := "2"
+	_, response := test.ListWorkitemOK(t, context.Background(), nil, controller, &filter, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
+	escaped := "&filter=system.state+%3D+%22open%22"
+	assertLink(t, "first", "page[offset]=0&page[limit]=2"+escaped, response.Links.First)
+	assertLink(t, "last", "page[offset]=12&page[limit]=5"+escaped, response.Links.Last)
+	assertLink(t, "prev", "page[offset]=0&page[limit]=2"+escaped, response.Links.Prev)
+	assertLink(t, "next", "page[offset]=7&page[limit]=5"+escaped, response.Links.Next)
 }
 
 func TestPagingErrors(t *testing.T) {
@@ -387,28 +402,28 @@ func TestPagingErrors(t *testing.T) {
 
 	var offset string = "-1"
 	var limit int = 2
-	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[offset]=0") {
 		assert.Fail(t, "Offset is negative", "Expected offset to be %d, but was %s", 0, *result.Links.First)
 	}
 
 	offset = "0"
 	limit = 0
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is 0", "Expected limit to be default size %d, but was %s", 20, *result.Links.First)
 	}
 
 	offset = "0"
 	limit = -1
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is negative", "Expected limit to be default size %d, but was %s", 20, *result.Links.First)
 	}
 
 	offset = "-3"
 	limit = -1
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is negative", "Expected limit to be default size %d, but was %s", 20, *result.Links.First)
 	}
@@ -418,7 +433,7 @@ func TestPagingErrors(t *testing.T) {
 
 	offset = "ALPHA"
 	limit = 40
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=40") {
 		assert.Fail(t, "Limit is within range", "Expected limit to be size %d, but was %s", 40, *result.Links.First)
 	}
@@ -427,6 +442,63 @@ func TestPagingErrors(t *testing.T) {
 	}
 }
 
+func TestCursorPagingLinks(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	svc := goa.New("TestCursorPagingLinks-Service")
+	db := testsupport.NewMockDB()
+	controller := NewWorkitemController(svc, db)
+	repo := db.WorkItems().(*testsupport.WorkItemRepository)
+	cursor := func(id string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(id))
+	}
+	limit := 3
+
+	// first page
+	repo.ListPageReturns(makeWorkItems(3), true, uint64(10), nil)
+	after := ""
+	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, &after, nil, &limit, nil, nil)
+	_, _, _, page := repo.ListPageArgsForCall(0)
+	assert.Equal(t, gormsupport.Page{Limit: 3}, page)
+	assertLink(t, "first", "page[after]=&page[limit]=3", result.Links.First)
+	assertLink(t, "last", "page[before]=&page[limit]=3", result.Links.Last)
+	assertLink(t, "prev", "", result.Links.Prev)
+	assertLink(t, "next", "page[after]="+cursor("id2")+"&page[limit]=3", result.Links.Next)
+	assert.Equal(t, 10, result.Meta.TotalCount)
+
+	// last page, following a cursor
+	repo.ListPageReturns(makeWorkItems(1), false, uint64(10), nil)
+	after = cursor("42")
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, &after, nil, &limit, nil, nil)
+	_, _, _, page = repo.ListPageArgsForCall(1)
+	assert.Equal(t, gormsupport.Page{ID: "42", Limit: 3}, page)
+	assertLink(t, "prev", "page[before]="+cursor("id0")+"&page[limit]=3", result.Links.Prev)
+	assertLink(t, "next", "", result.Links.Next)
+
+	// paging backwards from the end
+	repo.ListPageReturns(makeWorkItems(3), true, uint64(10), nil)
+	before := ""
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, &before, &limit, nil, nil)
+	_, _, _, page = repo.ListPageArgsForCall(2)
+	assert.Equal(t, gormsupport.Page{Before: true, Limit: 3}, page)
+	assertLink(t, "prev", "page[before]="+cursor("id0")+"&page[limit]=3", result.Links.Prev)
+	assertLink(t, "next", "", result.Links.Next)
+
+	// the links keep the filter
+	repo.ListPageReturns(makeWorkItems(3), true, uint64(10), nil)
+	filter := `system.title = "a & b"`
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, &filter, nil, nil, nil, nil, nil, &after, nil, &limit, nil, nil)
+	escaped := "&filter=system.title+%3D+%22a+%26+b%22"
+	assertLink(t, "first", "page[after]=&page[limit]=3"+escaped, result.Links.First)
+	assertLink(t, "next", "page[after]="+cursor("id2")+"&page[limit]=3"+escaped, result.Links.Next)
+
+	// page[after] and page[before] are mutually exclusive and cursors must be valid
+	assert.Equal(t, 4, repo.ListPageCallCount())
+	test.ListWorkitemBadRequest(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, &after, &before, &limit, nil, nil)
+	invalid := "not base64!"
+	test.ListWorkitemBadRequest(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, &invalid, nil, &limit, nil, nil)
+	assert.Equal(t, 4, repo.ListPageCallCount())
+}
+
 func TestPagingLinksHasAbsoluteURL(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	svc := goa.New("TestPaginAbsoluteURL-Service")
@@ -439,7 +511,7 @@ func TestPagingLinksHasAbsoluteURL(t *testing.T) {
 	repo := db.WorkItems().(*testsupport.WorkItemRepository)
 	repo.ListReturns(makeWorkItems(10), uint64(100), nil)
 
-	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.HasPrefix(*result.Links.First, "http://") {
 		assert.Fail(t, "Not Absolute URL", "Expected link %s to contain absolute URL but was %s", "First", *result.Links.First)
 	}
@@ -465,18 +537,18 @@ func TestPagingDefaultAndMaxSize(t *testing.T) {
 	repo := db.WorkItems().(*testsupport.WorkItemRepository)
 	repo.ListReturns(makeWorkItems(10), uint64(100), nil)
 
-	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, &offset)
+	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, nil, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is nil", "Expected limit to be default size %d, got %v", 20, *result.Links.First)
 	}
 	limit = 1000
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=100") {
 		assert.Fail(t, "Limit is more than max", "Expected limit to be %d, got %v", 100, *result.Links.First)
 	}
 
 	limit = 50
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=50") {
 		assert.Fail(t, "Limit is within range", "Expected limit to be %d, got %v", 50, *result.Links.First)
 	}
@@ -518,9 +590,23 @@ func minimumRequiredCreateWithType(wit string) app.CreateWorkitemPayload {
 func minimumRequiredCreatePayload() app.CreateWorkitemPayload {
 	return app.CreateWorkitemPayload{
 		Data: &app.WorkItem2{
-			Type:          APIStringTypeWorkItem,
-			Attributes:    map[string]interface{}{},
-			Relationships: &app.WorkItemRelationships{},
+			Type:       APIStringTypeWorkItem,
+			Attributes: map[string]interface{}{},
+			Relationships: &app.WorkItemRelationships{
+				Space: spaceRelation(space.SystemSpace),
+			},
+		},
+	}
+}
+
+// spaceRelation returns the relationship to the space with the given ID
+func spaceRelation(spaceID uuid.UUID) *app.RelationGeneric {
+	spaceType := "spaces"
+	id := spaceID.String()
+	return &app.RelationGeneric{
+		Data: &app.GenericData{
+			Type: &spaceType,
+			ID:   &id,
 		},
 	}
 }
@@ -661,6 +747,106 @@ func (s *WorkItem2Suite) TestWI2UpdateVersionConflict() {
 	test.UpdateWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID, s.minimumPayload)
 }
 
+func (s *WorkItem2Suite) TestWI2BulkUpdate() {
+	// given
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "Other WI"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, other := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wiCtrl, &payload)
+	bulk := app.BulkUpdateWorkitemPayload{
+		Data: []*app.WorkItem2{
+			{
+				Type: APIStringTypeWorkItem,
+				ID:   s.wi.ID,
+				Attributes: map[string]interface{}{
+					"version":            s.wi.Attributes["version"],
+					workitem.SystemTitle: "Bulk title",
+				},
+			},
+			{
+				Type: APIStringTypeWorkItem,
+				ID:   other.Data.ID,
+				Attributes: map[string]interface{}{
+					"version":            42,
+					workitem.SystemTitle: "Bulk title",
+				},
+			},
+		},
+	}
+	// when
+	atomic := true
+	bulk.Atomic = &atomic
+	_, jerrs := test.BulkUpdateWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &bulk)
+	// then
+	require.Len(s.T(), jerrs.Errors, 1)
+	assert.Equal(s.T(), jsonapi.ErrorCodeVersionConflict, *jerrs.Errors[0].Code)
+	assert.Equal(s.T(), "/data/1", jerrs.Errors[0].Source["pointer"])
+	_, unchanged := test.ShowWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID)
+	assert.Equal(s.T(), "Test WI", unchanged.Data.Attributes[workitem.SystemTitle])
+	// when every work item fails, each one reports its own failure
+	bulk.Data[0].Attributes["version"] = 42
+	_, jerrs = test.BulkUpdateWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &bulk)
+	// then
+	require.Len(s.T(), jerrs.Errors, 2)
+	for _, jerr := range jerrs.Errors {
+		assert.Equal(s.T(), jsonapi.ErrorCodeVersionConflict, *jerr.Code)
+	}
+	// when
+	bulk.Data[0].Attributes["version"] = s.wi.Attributes["version"]
+	bulk.Atomic = nil
+	_, results := test.BulkUpdateWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &bulk)
+	// then
+	require.Len(s.T(), results.Data, 2)
+	assert.Equal(s.T(), "updated", results.Data[0].Status)
+	assert.Equal(s.T(), "Bulk title", results.Data[0].Data.Attributes[workitem.SystemTitle])
+	assert.Equal(s.T(), "failed", results.Data[1].Status)
+	require.Len(s.T(), results.Data[1].Errors, 1)
+	assert.Equal(s.T(), jsonapi.ErrorCodeVersionConflict, *results.Data[1].Errors[0].Code)
+}
+
+func (s *WorkItem2Suite) TestWI2BulkUpdateByFilter() {
+	// given
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "Other WI"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, other := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wiCtrl, &payload)
+	filter := fmt.Sprintf("ID in (%s, %s)", *s.wi.ID, *other.Data.ID)
+	bulk := app.BulkUpdateWorkitemPayload{
+		Filter: &filter,
+		Patch: &app.WorkItem2{
+			Type: APIStringTypeWorkItem,
+			Attributes: map[string]interface{}{
+				workitem.SystemState: workitem.SystemStateClosed,
+			},
+		},
+	}
+	// when
+	_, results := test.BulkUpdateWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &bulk)
+	// then
+	require.Len(s.T(), results.Data, 2)
+	for _, result := range results.Data {
+		assert.Equal(s.T(), "updated", result.Status)
+		assert.Equal(s.T(), workitem.SystemStateClosed, result.Data.Attributes[workitem.SystemState])
+	}
+	// when
+	operation := "delete"
+	bulk = app.BulkUpdateWorkitemPayload{
+		Operation: &operation,
+		Filter:    &filter,
+	}
+	_, results = test.BulkUpdateWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &bulk)
+	// then
+	require.Len(s.T(), results.Data, 2)
+	assert.Equal(s.T(), "deleted", results.Data[0].Status)
+	test.ShowWorkitemNotFound(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *other.Data.ID)
+}
+
+func (s *WorkItem2Suite) TestWI2BulkUpdateWithoutWorkItems() {
+	test.BulkUpdateWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &app.BulkUpdateWorkitemPayload{})
+	filter := "ID in (1)"
+	test.BulkUpdateWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &app.BulkUpdateWorkitemPayload{Filter: &filter})
+}
+
 func (s *WorkItem2Suite) TestWI2UpdateWithNonExistentID() {
 	id := "2398475203"
 	s.minimumPayload.Data.ID = &id
@@ -817,6 +1003,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateWorkItem() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -846,6 +1033,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateWorkItemWithoutDescription() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -872,6 +1060,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateWorkItemWithLegacyDescription() {
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Attributes[workitem.SystemDescription] = "Description"
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -899,6 +1088,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateWorkItemWithDescriptionAndMarkup()
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Attributes[workitem.SystemDescription] = rendering.NewMarkupContent("Description", rendering.SystemMarkupMarkdown)
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -926,6 +1116,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateWorkItemWithDescriptionAndNoMarkup(
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Attributes[workitem.SystemDescription] = rendering.NewMarkupContentFromLegacy("Description")
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -953,6 +1144,7 @@ func (s *WorkItem2Suite) TestWI2FailCreateWorkItemWithDescriptionAndUnsupportedM
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Attributes[workitem.SystemDescription] = rendering.NewMarkupContent("Description", "foo")
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -981,6 +1173,7 @@ func (s *WorkItem2Suite) TestWI2FailCreateWithAssigneeAsField() {
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Attributes[workitem.SystemAssignees] = []string{"34343"}
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1003,6 +1196,7 @@ func (s *WorkItem2Suite) TestWI2FailCreateWithMissingTitle() {
 	c := minimumRequiredCreatePayload()
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1020,6 +1214,7 @@ func (s *WorkItem2Suite) TestWI2FailCreateWithEmptyTitle() {
 	c.Data.Attributes[workitem.SystemTitle] = ""
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1040,6 +1235,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateWithAssigneeRelation() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1074,6 +1270,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateWithAssigneesRelation() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1121,6 +1318,7 @@ func (s *WorkItem2Suite) TestWI2ListByAssigneeFilter() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1143,7 +1341,7 @@ func (s *WorkItem2Suite) TestWI2ListByAssigneeFilter() {
 	assert.Len(s.T(), wi.Data.Relationships.Assignees.Data, 1)
 	assert.Equal(s.T(), newUser.ID.String(), *wi.Data.Relationships.Assignees.Data[0].ID)
 	newUserID := newUser.ID.String()
-	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, &newUserID, nil, nil, nil, nil)
+	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, &newUserID, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Len(s.T(), list.Data, 1)
 	assert.Equal(s.T(), newUser.ID.String(), *list.Data[0].Relationships.Assignees.Data[0].ID)
 	assert.True(s.T(), strings.Contains(*list.Links.First, "filter[assignee]"))
@@ -1155,6 +1353,7 @@ func (s *WorkItem2Suite) TestWI2ListByWorkitemtypeFilter() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1169,7 +1368,7 @@ func (s *WorkItem2Suite) TestWI2ListByWorkitemtypeFilter() {
 	require.NotNil(s.T(), expected.Data.ID)
 	require.NotNil(s.T(), expected.Data.Type)
 	witBug := workitem.SystemBug
-	_, actual := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, nil, &witBug, nil, nil)
+	_, actual := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, nil, nil, &witBug, nil, nil, nil, nil, nil)
 	require.NotNil(s.T(), actual)
 	require.True(s.T(), len(actual.Data) > 1)
 	assert.Contains(s.T(), *actual.Links.First, fmt.Sprintf("filter[workitemtype]=%s", workitem.SystemBug))
@@ -1207,7 +1406,7 @@ func (s *WorkItem2Suite) TestWI2ListByAreaFilter() {
 	require.NotNil(s.T(), wi.Data.Relationships.Area)
 	assert.Equal(s.T(), areaID, *wi.Data.Relationships.Area.Data.ID)
 
-	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, &areaID, nil, nil, nil, nil, nil)
+	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, &areaID, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	require.Len(s.T(), list.Data, 1)
 	assert.Equal(s.T(), areaID, *list.Data[0].Relationships.Area.Data.ID)
 	assert.True(s.T(), strings.Contains(*list.Links.First, "filter[area]"))
@@ -1241,7 +1440,7 @@ func (s *WorkItem2Suite) TestWI2ListByIterationFilter() {
 	require.NotNil(s.T(), wi.Data.Relationships.Iteration)
 	assert.Equal(s.T(), iterationID, *wi.Data.Relationships.Iteration.Data.ID)
 
-	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, &iterationID, nil, nil, nil)
+	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, &iterationID, nil, nil, nil, nil, nil, nil, nil)
 	require.Len(s.T(), list.Data, 1)
 	assert.Equal(s.T(), iterationID, *list.Data[0].Relationships.Iteration.Data.ID)
 	assert.True(s.T(), strings.Contains(*list.Links.First, "filter[iteration]"))
@@ -1253,6 +1452,7 @@ func (s *WorkItem2Suite) TestWI2FailCreateInvalidAssignees() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1275,6 +1475,7 @@ func (s *WorkItem2Suite) TestWI2FailUpdateInvalidAssignees() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1310,6 +1511,7 @@ func (s *WorkItem2Suite) TestWI2SuccessUpdateWithAssigneesRelation() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1336,6 +1538,7 @@ func (s *WorkItem2Suite) TestWI2SuccessShow() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1365,6 +1568,7 @@ func (s *WorkItem2Suite) TestWI2SuccessDelete() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1387,6 +1591,7 @@ func (s *WorkItem2Suite) TestWI2DeleteLinksOnWIDeletionOK() {
 	c.Data.Attributes[workitem.SystemTitle] = "WI1"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1472,6 +1677,7 @@ func (s *WorkItem2Suite) TestWI2UpdateWithArea() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(areaInstance.SpaceID),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1568,6 +1774,7 @@ func (s *WorkItem2Suite) TestWI2UpdateWithIteration() {
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(iterationInstance.SpaceID),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1676,6 +1883,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateAndPreventJavascriptInjectionWithLe
 	c.Data.Attributes[workitem.SystemDescription] = description
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1699,6 +1907,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateAndPreventJavascriptInjectionWithPl
 	c.Data.Attributes[workitem.SystemDescription] = description
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
@@ -1722,6 +1931,7 @@ func (s *WorkItem2Suite) TestWI2SuccessCreateAndPreventJavascriptInjectionWithMa
 	c.Data.Attributes[workitem.SystemDescription] = description
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
 	c.Data.Relationships = &app.WorkItemRelationships{
+		Space: spaceRelation(space.SystemSpace),
 		BaseType: &app.RelationBaseType{
 			Data: &app.BaseTypeData{
 				Type: "workitemtypes",
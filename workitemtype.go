@@ -6,6 +6,8 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 )
 
@@ -58,6 +60,29 @@ func (c *WorkitemtypeController) Create(ctx *app.CreateWorkitemtypeContext) erro
 	})
 }
 
+// Update runs the update action.
+func (c *WorkitemtypeController) Update(ctx *app.UpdateWorkitemtypeContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		var fields = map[string]app.FieldDefinition{}
+		for key, fd := range ctx.Payload.Fields {
+			fields[key] = *fd
+		}
+		wit, err := appl.WorkItemTypes().Update(ctx.Context, ctx.Name, ctx.Payload.Version, fields, ctx.Payload.ValueMapping, ctx.Payload.Workflow)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(wit)
+	})
+	// loads during the transaction may have cached the types before the commit or rollback, the next load
+	// reads the committed ones
+	workitem.ClearGlobalWorkItemTypeCache()
+	return err
+}
+
 // List runs the list action
 func (c *WorkitemtypeController) List(ctx *app.ListWorkitemtypeContext) error {
 	start, limit, err := parseLimit(ctx.Page)
@@ -18,6 +18,8 @@ import (
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
 	"github.com/almighty/almighty-core/workitem"
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/goadesign/goa"
@@ -179,6 +181,43 @@ func (s *workItemTypeSuite) TestShowWorkItemType() {
 	require.EqualValues(s.T(), wit, wit2)
 }
 
+// TestUpdateWorkItemType tests if we can add a field to "animal" and remove one of its animal types
+func (s *workItemTypeSuite) TestUpdateWorkItemType() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	_, wit := s.createWorkItemTypeAnimal()
+	require.NotNil(s.T(), wit)
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	svc := testsupport.ServiceAsUser("workItemTypeSuite-Service", almtoken.NewManagerWithPrivateKey(priv), testsupport.TestIdentity)
+	ctrl := NewWorkitemtypeController(svc, gormapplication.NewGormDB(s.DB))
+
+	animalType := *wit.Fields["animal_type"]
+	animalType.Type.Values = []interface{}{"elephant", "Tyrannosaurus rex"}
+	payload := app.UpdateWorkItemTypePayload{
+		Version: wit.Version,
+		Fields: map[string]*app.FieldDefinition{
+			"animal_type": &animalType,
+			"weight": {
+				Type: &app.FieldType{Kind: "float"},
+			},
+		},
+	}
+	// the removed value of the required field must be replaced
+	test.UpdateWorkitemtypeBadRequest(s.T(), svc.Context, svc, ctrl, wit.Name, &payload)
+
+	payload.ValueMapping = map[string]map[string]interface{}{
+		"animal_type": {"blue whale": "elephant"},
+	}
+	_, updated := test.UpdateWorkitemtypeOK(s.T(), svc.Context, svc, ctrl, wit.Name, &payload)
+	assert.Equal(s.T(), wit.Version+1, updated.Version)
+	assert.NotNil(s.T(), updated.Fields["weight"])
+	assert.Equal(s.T(), animalType.Type.Values, updated.Fields["animal_type"].Type.Values)
+
+	// the version is outdated now
+	test.UpdateWorkitemtypeBadRequest(s.T(), svc.Context, svc, ctrl, wit.Name, &payload)
+	test.UpdateWorkitemtypeNotFound(s.T(), svc.Context, svc, ctrl, "unknown", &payload)
+}
+
 // TestListWorkItemType tests if we can find the work item types
 // "person" and "animal" in the list of work item types
 func (s *workItemTypeSuite) TestListWorkItemType() {